@@ -5,7 +5,9 @@
 // Package asn1 implements types for ASN.1 encoded data-structures as defined in
 // [Rec. ITU-T X.680]. This package only defines Go types for some types defined
 // by ASN.1. Encoding and decoding of data structures using different encoding
-// rules is implemented in subpackages of this package.
+// rules is implemented in subpackages of this package. Subpackages may provide
+// an implementation of the [Codec] interface, so that the encoding rules can be
+// selected at runtime.
 //
 // # Mapping of ASN.1 Types to Go Types
 //
@@ -20,7 +22,7 @@
 //   - The types float32 and float64 and [math/big.Float] correspond to the ASN.1
 //     REAL type. The supported size is limited by the Go type.
 //   - Go types with an underlying integer type correspond to the ASN.1 ENUMERATED
-//     type.
+//     type. The values of an ENUMERATED type can be defined by an [EnumDef].
 //   - The Go string type corresponds to ASN.1 UTF8String type. A string can be
 //     decoded from any ASN.1 string type defined in this package.
 //   - A byte slice or byte array corresponds to an ASN.1 OCTET STRING. - Types
@@ -28,6 +30,10 @@
 //     correspond to an ASN.1 OCTET string.
 //   - The type [time.Time] corresponds to the ASN.1 TIME type. A [time.Time]
 //     value can be decoded from any ASN.1 time type defined in this package.
+//     A universal tag such as `asn1:"universal,tag:23"` selects a different
+//     ASN.1 time type for a [time.Time] value. Encoding rules may treat a
+//     [time.Time] without a tag as the Time type of X.509 instead, a CHOICE of
+//     UTCTime and GeneralizedTime.
 //   - Go slices and arrays correspond to the ASN.1 SEQUENCE type. Their define
 //     the contents of the SEQUENCE.
 //   - Go structs correspond to the ASN.1 SEQUENCE type. The struct fields define
@@ -66,10 +72,15 @@
 // which the struct fields are defined, corresponds to the order of data values
 // within the SEQUENCE. Struct members must use exported (upper case) names.
 // Unexported members are ignored. Fields of anonymous struct members are
-// treated as if they were fields of the surrounding struct. Exported members
-// can be explicitly ignored by using a `asn1:"-"` struct tag. Additional
-// configuration is possible via struct tags. The following struct tags are
-// supported:
+// treated as if they were fields of the surrounding struct. The same applies to
+// named struct members with an `asn1:"inline"` tag. This makes it possible to
+// share common components between types without introducing an additional
+// nested SEQUENCE. The `asn1:"components"` struct tag corresponds to the
+// COMPONENTS OF notation of ASN.1. It works like "inline" but omits the
+// extension marker of the referenced type, so that only its root components
+// are included. Exported members can be explicitly ignored by using a
+// `asn1:"-"` struct tag. Additional configuration is possible via struct tags.
+// The following struct tags are supported:
 //
 //	tag:x       specifies the ASN.1 tag number; implies ASN.1 CONTEXT SPECIFIC
 //	application specifies that an APPLICATION tag is used
@@ -77,7 +88,25 @@
 //	explicit    mark the field as explicit
 //	optional    marks the field as ASN.1 OPTIONAL
 //	omitzero    omit this field if it is a zero value
+//	omitempty   omit this field if it is an empty slice, map or string
 //	nullable    allows ASN.1 NULL for this data value
+//	name:x      specifies the ASN.1 identifier of the field
+//	range:x..y  specifies a value range constraint for integer types
+//	size:x..y   specifies a size constraint for string and list types
+//	choice      marks a struct field as an ASN.1 CHOICE type
+//	text        encodes a field via encoding.TextMarshaler as UTF8String
+//	rest        collects the remaining data values of a SEQUENCE in a slice
+//	inline      treats the fields of a struct field as fields of the parent
+//	components  includes the root components of a struct as by COMPONENTS OF
+//	definedby:x selects the type of an open type field by the field x
+//	enum:x      encodes a string field as the ENUMERATED type x
+//	any         accepts data values with any tag (ASN.1 ANY)
+//	utctime     selects UTCTime for a time.Time field; same as utc
+//	toutc       converts time values to UTC before encoding
+//	precision:x limits time values to x fractional second digits
+//
+// The struct tags of the encoding/asn1 package are supported as well, see
+// below.
 //
 // Using the struct tag `asn1:"tag:x"` (where x is a non-negative integer)
 // overrides the intrinsic type of the member type. This corresponds to IMPLICIT
@@ -86,6 +115,12 @@
 // "private" tag. The "universal" tag is supported for completeness but its use
 // should be avoided as it can easily lead to invalid encodings.
 //
+// Instead of specifying the tag of a type on every field, a type can declare
+// its intrinsic tag by implementing the [Tagger] interface. This is useful for
+// types defined as an implicitly tagged universal type, such as the
+// application-wide types of SNMP. A tag specified via "tag:x" takes precedence
+// over the intrinsic tag.
+//
 // ASN.1 allows a subtype to be marked as EXPLICIT. The effect of the
 // `asn1:"explicit"` tag depends on the encoding rules used. When using
 // "explicit" you must also use "tag:x". Nested EXPLICIT tags cannot be
@@ -99,28 +134,104 @@
 // implements IsZero() bool, that method is consulted, otherwise the zero value
 // for its type will be used. Usually this should be paired with "optional" to
 // ensure consistent encodes and decodes for a type.
+// The [Optional] type tracks the presence of an optional field explicitly and
+// implies both tags.
 //
 // The `asn1:"nullable"` struct tag indicates that the type may contain an ASN.1
 // NULL instead of an actual value for the type. If NULL is encountered for a
 // "nullable" field, the field is set to its zero value. During encoding NULL is
 // written if the field contains the zero value for its type. Usually "nullable"
-// is used with pointer types.
+// is used with pointer types. The [Nullable] type provides the same semantics
+// without using a pointer and distinguishes NULL from the zero value.
+//
+// Some encoding rules (such as JER) represent the fields of a SEQUENCE by their
+// ASN.1 identifiers. By default, the identifier of a field is its name with the
+// first letter converted to lower case. A different identifier can be set using
+// the `asn1:"name:x"` struct tag. Encoding rules that do not make use of
+// identifiers ignore the "name" tag.
+//
+// Some encoding rules (such as PER) produce a more compact encoding for types
+// with PER-visible constraints. Value range constraints are specified with the
+// `asn1:"range:x..y"` struct tag. Size constraints are specified with the
+// `asn1:"size:x..y"` struct tag. The size of a string is the number of its
+// characters, the size of a BIT STRING is the number of its bits and the size
+// of a list type is the number of its elements. In both tags x and y are
+// integers. The lower bound x can be MIN and the upper bound y can be MAX to
+// indicate that the value is not bounded. The tag `asn1:"size:x"` is a shorthand
+// for `asn1:"size:x..x"`. Encoding rules that do not make use of constraints
+// for the encoding may still validate values against them.
+//
+// The `asn1:"choice"` struct tag marks a field of a struct type as an ASN.1
+// CHOICE. The fields of the struct are the alternatives of the CHOICE. Exactly
+// one alternative must have a non-zero value. Usually alternatives use pointer
+// types. Only the chosen alternative is encoded. In tag-based encoding rules
+// the tag of the alternative takes the place of the tag of the CHOICE, so a
+// "choice" field can only be tagged explicitly.
+//
+// The `asn1:"text"` struct tag causes a field whose type implements
+// [encoding.TextMarshaler] or [encoding.TextUnmarshaler] to be represented by
+// its text form as an ASN.1 UTF8String, similar to the OCTET STRING
+// representation of [encoding.BinaryMarshaler]. Support for the "text" tag
+// depends on the encoding rules.
+//
+// The `asn1:"enum:x"` struct tag causes a string field to be represented as the
+// ASN.1 ENUMERATED type defined by the [EnumDef] of the Go type named x, for
+// example `asn1:"enum:ldap.ResultCode"`. The string holds the name of the
+// value. This is useful for human-facing structs such as audit logs. Support
+// for the "enum" tag depends on the encoding rules.
+//
+// The `asn1:"toutc"` and `asn1:"precision:x"` struct tags control the encoding
+// of time values. If "toutc" is present, a time value is converted to UTC
+// before it is encoded. The "precision" tag truncates a time value to x
+// fractional second digits, where x is between 0 and 9. Together, these tags
+// can be used to produce time values that satisfy the restrictions of the
+// Distinguished Encoding Rules, for example
+// `asn1:"universal,tag:24,toutc,precision:0"` for the GeneralizedTime values of
+// X.509 certificates. Decoding ignores both tags.
+//
+// The `asn1:"definedby:x"` struct tag marks a field as an open type whose
+// actual type is identified by the value of the preceding field x, similar to
+// the ANY DEFINED BY construct of earlier ASN.1 versions. Usually x holds an
+// [ObjectIdentifier] and the field has an interface type. This pattern is
+// common in information objects such as an AlgorithmIdentifier. How concrete
+// types are registered for an identifier depends on the encoding rules.
+//
+// For compatibility with the encoding/asn1 package of the standard library,
+// its struct tags are recognized as well. This allows migrating existing types
+// without changing their struct tags. The tags "ia5", "printable", "utf8" and
+// "numeric" select the universal type of a string field. The "utc" and
+// "generalized" tags select UTCTime and GeneralizedTime for a field of type
+// [time.Time]. As in encoding/asn1, "utc" does not convert time values to UTC;
+// use "toutc" for that. The "set" tag encodes a slice as SET OF instead
+// of SEQUENCE OF. These tags have no effect if a tag number is specified via
+// "tag:x". The "any" tag marks a field as an ASN.1 ANY type that accepts data
+// values with any tag during decoding. The "omitempty" tag is supported as
+// well, but in contrast to encoding/asn1 it omits empty maps and strings in
+// addition to empty slices, like the encoding/json package. Support for these
+// tags depends on the encoding rules.
+//
+// Structs can embed the [Presence] type to record which fields were present
+// during decoding.
 //
 // Structs can make use of the [Extensible] type to be marked as extensible.
 // This corresponds to the ASN.1 extension marker. See the documentation on
-// [Extensible] for details.
-//
-// # Limitations
+// [Extensible] for details. The [ExtensibleData] type can be used instead to
+// retain unknown extensions.
 //
-// Currently the ASN.1 CHOICE type is not explicitly supported. Support can be
-// added by implementing custom encoding and decoding strategies for types
-// containing CHOICE components.
+// Unlike [Extensible], the `asn1:"rest"` struct tag retains additional data
+// values. It marks a field of a slice type that receives all data values of a
+// SEQUENCE following the preceding fields. When encoding, the elements of the
+// slice are written as individual components of the SEQUENCE. The "rest" field
+// must be the last non-ignored field of a struct. Usually the elements of the
+// slice use a type that can hold any data value, such as ber.RawValue. Support
+// for the "rest" tag depends on the encoding rules.
 //
 // [Rec. ITU-T X.680]: https://www.itu.int/rec/T-REC-X.680
 package asn1
 
 import (
 	"strconv"
+	"strings"
 )
 
 // Extensible marks a struct as extensible. It corresponds to the ASN.1
@@ -132,6 +243,54 @@ import (
 // or use the `asn1:"-"` struct tag.
 type Extensible struct{}
 
+// ExtensibleData marks a struct as extensible, just like [Extensible]. In
+// addition, ExtensibleData retains the encodings of additional data values
+// that are not known to the struct. When the struct is encoded again, the
+// retained encodings are written after the known fields. This allows a
+// decode-modify-encode cycle to preserve unknown extensions.
+//
+// The format of the encodings depends on the encoding rules. Encoding rules
+// that do not support retaining extensions treat ExtensibleData like
+// [Extensible].
+type ExtensibleData struct {
+	// Extensions contains the encodings of the additional data values in the
+	// order in which they appeared.
+	Extensions [][]byte
+}
+
+// Presence records which fields of a struct were present in an encoding. The
+// Presence type is intended to be embedded in a struct as an anonymous field.
+// When such a struct is decoded, the Presence is reset and each field that is
+// present in the encoding is recorded. This makes it possible to distinguish an
+// absent OPTIONAL field from a field that is present with its zero value,
+// without using pointer types.
+//
+// Fields are identified by their Go field names. The Presence does not affect
+// encoding. Support for Presence depends on the encoding rules.
+type Presence struct {
+	present map[string]bool
+}
+
+// IsPresent reports whether the field with the Go name field was present when
+// the struct containing p was last decoded.
+func (p *Presence) IsPresent(field string) bool {
+	return p.present[field]
+}
+
+// SetPresent records whether the field with the Go name field is present. This
+// method is used by encoding rules during decoding.
+func (p *Presence) SetPresent(field string, present bool) {
+	if p.present == nil {
+		p.present = make(map[string]bool)
+	}
+	p.present[field] = present
+}
+
+// Reset marks all fields as absent.
+func (p *Presence) Reset() {
+	p.present = nil
+}
+
 // Tag constitutes an ASN.1 tag, consisting of its class and number. The class
 // is indicated by the two most significant bits of the underlying integer. For
 // details, see Section 8 of Rec. ITU-T X.680.
@@ -146,6 +305,50 @@ type Extensible struct{}
 // bits in the BER encoding.
 type Tag uint16
 
+// Tagger is implemented by types that declare an intrinsic tag different from
+// the tag of their underlying type. The AsnTag method is called on the zero
+// value of the type and must return the same tag for all values:
+//
+//	type Counter32 uint32
+//
+//	func (Counter32) AsnTag() asn1.Tag { return asn1.ClassApplication | 1 }
+//
+// Encoding rules that honor the Tagger interface treat values of such a type
+// as if the tag had been specified via struct tags. Support for the Tagger
+// interface depends on the encoding rules.
+type Tagger interface {
+	AsnTag() Tag
+}
+
+// Validator is implemented by types that enforce invariants beyond what can be
+// expressed via struct tags, such as a validity period whose start must precede
+// its end. When a struct type implements Validator, the ValidateASN1 method is
+// called after a value of the type has been decoded completely. A non-nil error
+// is reported as a structural error of the decoded value:
+//
+//	func (v *Validity) ValidateASN1() error {
+//		if !v.NotBefore.Before(v.NotAfter) {
+//			return errors.New("notBefore must precede notAfter")
+//		}
+//		return nil
+//	}
+//
+// Support for the Validator interface depends on the encoding rules. Encoding
+// rules may also support Validator for other types, such as integer types.
+type Validator interface {
+	ValidateASN1() error
+}
+
+// Defaulter is implemented by types that provide default values for optional
+// struct fields. When an optional field of a type implementing Defaulter is
+// absent during decoding, the SetASN1Defaults method is called on the field.
+// This allows defaults to be computed at runtime, for example depending on the
+// current time. Support for the Defaulter interface depends on the encoding
+// rules.
+type Defaulter interface {
+	SetASN1Defaults()
+}
+
 // MaxTag is the maximum tag number supported by this package (for any class).
 const MaxTag = 0x3FFF
 
@@ -198,6 +401,73 @@ func (t Tag) String() string {
 	panic("unreachable")
 }
 
+// ParseTag parses a tag in the notation produced by [Tag.String]. The tag number
+// is enclosed by square brackets and may be prefixed with a class name
+// (UNIVERSAL, APPLICATION or PRIVATE). If no class name is given, the tag is
+// assumed to be CONTEXT SPECIFIC. Whitespace around the class and number is
+// ignored. The tag number must not exceed [MaxTag].
+func ParseTag(s string) (Tag, error) {
+	str, ok := strings.CutPrefix(s, "[")
+	if !ok {
+		return 0, &TagParseError{s, "missing opening bracket"}
+	}
+	if str, ok = strings.CutSuffix(str, "]"); !ok {
+		return 0, &TagParseError{s, "missing closing bracket"}
+	}
+	t := ClassContextSpecific
+	fields := strings.Fields(str)
+	switch len(fields) {
+	case 1:
+	case 2:
+		switch fields[0] {
+		case "UNIVERSAL":
+			t = ClassUniversal
+		case "APPLICATION":
+			t = ClassApplication
+		case "PRIVATE":
+			t = ClassPrivate
+		default:
+			return 0, &TagParseError{s, "unknown class " + strconv.Quote(fields[0])}
+		}
+		fields = fields[1:]
+	default:
+		return 0, &TagParseError{s, "invalid format"}
+	}
+	n, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil || n > MaxTag {
+		return 0, &TagParseError{s, "invalid tag number " + strconv.Quote(fields[0])}
+	}
+	return t | Tag(n), nil
+}
+
+// MarshalText implements [encoding.TextMarshaler]. The tag is formatted as
+// described in [Tag.String].
+func (t Tag) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. The text is parsed using
+// [ParseTag].
+func (t *Tag) UnmarshalText(text []byte) error {
+	tag, err := ParseTag(string(text))
+	if err != nil {
+		return err
+	}
+	*t = tag
+	return nil
+}
+
+// A TagParseError is returned by [ParseTag] if the input is not a valid tag
+// notation.
+type TagParseError struct {
+	Input string // the string that was parsed
+	Msg   string // description of the problem
+}
+
+func (e *TagParseError) Error() string {
+	return "asn1: cannot parse tag " + strconv.Quote(e.Input) + ": " + e.Msg
+}
+
 // TagReserved is the reserved tag number in the [ClassUniversal] namespace to
 // be used by encoding rules. This assignment is defined in Rec. ITU-T X.680,
 // Section 8, Table 1.
@@ -6,6 +6,7 @@ package asn1
 
 import (
 	"fmt"
+	"testing"
 )
 
 func ExampleTag_String() {
@@ -31,3 +32,61 @@ func ExampleExtensible() {
 		// Public int // not ok, cannot appear after Extensible
 	}
 }
+
+func ExampleParseTag() {
+	t, err := ParseTag("[APPLICATION 15]")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(t == ClassApplication|15)
+	// Output:
+	// true
+}
+
+func TestParseTag(t *testing.T) {
+	tests := map[string]struct {
+		s       string
+		want    Tag
+		wantErr bool
+	}{
+		"Universal":       {"[UNIVERSAL 2]", TagInteger, false},
+		"Application":     {"[APPLICATION 15]", ClassApplication | 15, false},
+		"ContextSpecific": {"[8]", ClassContextSpecific | 8, false},
+		"Private":         {"[PRIVATE 0]", ClassPrivate | 0, false},
+		"Whitespace":      {"[ APPLICATION  3 ]", ClassApplication | 3, false},
+		"MaxTag":          {"[16383]", ClassContextSpecific | MaxTag, false},
+		"TooLarge":        {"[16384]", 0, true},
+		"Negative":        {"[-1]", 0, true},
+		"NoBrackets":      {"APPLICATION 15", 0, true},
+		"UnknownClass":    {"[CONTEXT 15]", 0, true},
+		"Empty":           {"[]", 0, true},
+		"ExtraFields":     {"[APPLICATION 1 2]", 0, true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseTag(tc.s)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseTag(%q) error = %v, wantErr %v", tc.s, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseTag(%q) = %v, want %v", tc.s, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTag_MarshalText(t *testing.T) {
+	for _, tag := range []Tag{TagBoolean, ClassApplication | 17, ClassContextSpecific | 0, ClassPrivate | MaxTag} {
+		text, err := tag.MarshalText()
+		if err != nil {
+			t.Fatalf("%v.MarshalText() error = %v", tag, err)
+		}
+		var got Tag
+		if err = got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q) error = %v", text, err)
+		}
+		if got != tag {
+			t.Errorf("UnmarshalText(%q) = %v, want %v", text, got, tag)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package asn1test provides utilities for tests of code that encodes or decodes
+// ASN.1 data values.
+//
+// [MustHex] turns hexadecimal test fixtures into bytes. [Golden] compares an
+// encoding with a golden file and [Diff] reports the first data value in which
+// two BER encodings differ. Round trips through the BER encoding can be checked
+// using [codello.dev/asn1/ber/bertest.RoundTrip].
+package asn1test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"codello.dev/asn1/ber"
+)
+
+// update causes Golden to overwrite golden files instead of comparing them.
+var update = flag.Bool("asn1test.update", false, "update golden files")
+
+// MustHex returns the bytes represented by the hexadecimal string s. Whitespace
+// and colons in s are ignored, so that fixtures can be grouped for readability:
+//
+//	data := asn1test.MustHex("30 03 02 01 05")
+//
+// MustHex panics if s is not a valid hexadecimal string.
+func MustHex(s string) []byte {
+	b, err := parseHex(s)
+	if err != nil {
+		panic("asn1test: MustHex: " + err.Error())
+	}
+	return b
+}
+
+// parseHex returns the bytes represented by the hexadecimal string s, ignoring
+// whitespace and colons.
+func parseHex(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if r == ':' || r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	return hex.DecodeString(s)
+}
+
+// Golden compares got with the contents of the golden file at path. Golden
+// files contain the expected bytes in hexadecimal, as accepted by [MustHex].
+// If the contents differ, Golden reports an error via t, including the
+// description returned by [Diff].
+//
+// If the test binary is run with the -asn1test.update flag, Golden writes got
+// to the golden file instead, creating the file and its directory if
+// necessary.
+func Golden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Golden(%q): %v", path, err)
+		}
+		if err := os.WriteFile(path, formatHex(got), 0o644); err != nil {
+			t.Fatalf("Golden(%q): %v", path, err)
+		}
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Golden(%q): %v (run with -asn1test.update to create it)", path, err)
+	}
+	want, err := parseHex(string(b))
+	if err != nil {
+		t.Fatalf("Golden(%q): %v", path, err)
+	}
+	if d := Diff(want, got); d != "" {
+		t.Errorf("Golden(%q): %s", path, d)
+	}
+}
+
+// formatHex formats b as hexadecimal with 16 bytes per line.
+func formatHex(b []byte) []byte {
+	var buf bytes.Buffer
+	for len(b) > 0 {
+		n := min(len(b), 16)
+		fmt.Fprintf(&buf, "% X\n", b[:n])
+		b = b[n:]
+	}
+	return buf.Bytes()
+}
+
+// Diff returns a description of the first difference between the encodings
+// want and got, or an empty string if they are equal. If both are valid BER
+// encodings of one or more data values, the description contains the path of
+// the first data value that differs. The path consists of the index of the
+// top-level data value followed by the indices of the nested components, for
+// example "[0][2]" for the third component of the first data value. Otherwise,
+// the description contains the offset of the first differing byte.
+func Diff(want, got []byte) string {
+	if bytes.Equal(want, got) {
+		return ""
+	}
+	wantValues, err1 := decodeAll(want)
+	gotValues, err2 := decodeAll(got)
+	if err1 == nil && err2 == nil {
+		if d := diffValues("", wantValues, gotValues); d != "" {
+			return d
+		}
+	}
+	i := 0
+	for i < len(want) && i < len(got) && want[i] == got[i] {
+		i++
+	}
+	return fmt.Sprintf("encodings differ at offset %d: got % X, want % X", i, got, want)
+}
+
+// decodeAll decodes all data values in b.
+func decodeAll(b []byte) ([]ber.Value, error) {
+	var vs []ber.Value
+	d := ber.NewDecoder(bytes.NewReader(b))
+	for {
+		var v ber.Value
+		if err := d.Decode(&v); errors.Is(err, io.EOF) {
+			return vs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+}
+
+// diffValues returns a description of the first difference between the data
+// values want and got, the components of the data value at path.
+func diffValues(path string, want, got []ber.Value) string {
+	for i := range min(len(want), len(got)) {
+		if d := diffValue(path+"["+strconv.Itoa(i)+"]", &want[i], &got[i]); d != "" {
+			return d
+		}
+	}
+	if len(want) != len(got) {
+		p := path
+		if p == "" {
+			p = "top level"
+		}
+		return fmt.Sprintf("%s: got %d data values, want %d", p, len(got), len(want))
+	}
+	return ""
+}
+
+// diffValue returns a description of the difference between the data values
+// want and got at path.
+func diffValue(path string, want, got *ber.Value) string {
+	switch {
+	case want.Tag != got.Tag:
+		return fmt.Sprintf("%s: got tag %s, want %s", path, got.Tag, want.Tag)
+	case want.Constructed != got.Constructed:
+		return fmt.Sprintf("%s: got constructed = %t, want %t", path, got.Constructed, want.Constructed)
+	case want.Constructed:
+		return diffValues(path, want.Children, got.Children)
+	case !bytes.Equal(want.Prim, got.Prim):
+		return fmt.Sprintf("%s %s: got content % X, want % X", path, want.Tag, got.Prim, want.Prim)
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asn1test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"codello.dev/asn1/ber"
+)
+
+type testMessage struct {
+	ID    int
+	Valid bool
+}
+
+func TestMustHex(t *testing.T) {
+	tests := map[string]struct {
+		s    string
+		want []byte
+	}{
+		"Empty":      {"", []byte{}},
+		"Spaces":     {"30 03 02 01 05", []byte{0x30, 0x03, 0x02, 0x01, 0x05}},
+		"Colons":     {"ab:CD", []byte{0xAB, 0xCD}},
+		"Multiline":  {"01 02\n\t03\r\n", []byte{0x01, 0x02, 0x03}},
+		"NoSpaceHex": {"0a0B", []byte{0x0A, 0x0B}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := MustHex(tt.s); !bytes.Equal(got, tt.want) {
+				t.Errorf("MustHex(%q) = % X, want % X", tt.s, got, tt.want)
+			}
+		})
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustHex(%q) did not panic", "0x")
+		}
+	}()
+	MustHex("0x")
+}
+
+func TestDiff(t *testing.T) {
+	tests := map[string]struct {
+		want, got string
+		diff      string
+	}{
+		"Equal":         {"30 03 02 01 05", "30 03 02 01 05", ""},
+		"Content":       {"30 06 02 01 05 01 01 FF", "30 06 02 01 05 01 01 00", "[0][1] [UNIVERSAL 1]: got content 00, want FF"},
+		"Tag":           {"30 03 02 01 05", "30 03 0A 01 05", "[0][0]: got tag [UNIVERSAL 10], want [UNIVERSAL 2]"},
+		"Constructed":   {"24 00", "04 00", "[0]: got constructed = false, want true"},
+		"Components":    {"30 03 02 01 05", "30 00", "[0]: got 0 data values, want 1"},
+		"TopLevel":      {"05 00 05 00", "05 00", "top level: got 1 data values, want 2"},
+		"Length":        {"30 03 02 01 05", "30 80 02 01 05 00 00", "encodings differ at offset 1"},
+		"InvalidBER":    {"30 03 02 01 05", "30 05 02", "encodings differ at offset 1"},
+		"SecondElement": {"05 00 02 01 01", "05 00 02 01 02", "[1] [UNIVERSAL 2]: got content 02, want 01"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Diff(MustHex(tt.want), MustHex(tt.got))
+			if !strings.HasPrefix(got, tt.diff) || (tt.diff == "") != (got == "") {
+				t.Errorf("Diff() = %q, want %q", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestGolden(t *testing.T) {
+	data, err := ber.Marshal(testMessage{5, true})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	Golden(t, "testdata/message.golden", data)
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"time"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/vlq"
+)
+
+// This file contains functions that append the content octets of primitive
+// types. They make it possible to write encoders without reflection that
+// produce the same content octets as [Marshal]. The identifier and length
+// octets can be appended using [codello.dev/asn1/tlv.AppendHeader]:
+//
+//	content := ber.AppendInteger(nil, 42)
+//	b = tlv.AppendHeader(b, tlv.Header{Tag: asn1.TagInteger, Length: len(content)})
+//	b = append(b, content...)
+
+// AppendBoolean appends the content octets of the BOOLEAN v to dst and returns
+// the extended buffer. The value true is encoded as 0xFF.
+func AppendBoolean(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 0xff)
+	}
+	return append(dst, 0x00)
+}
+
+// AppendInteger appends the content octets of the INTEGER v to dst and returns
+// the extended buffer. The value is encoded using the minimum number of octets.
+func AppendInteger(dst []byte, v int64) []byte {
+	return appendInteger(dst, uint64(v), true)
+}
+
+// AppendUnsigned appends the content octets of the INTEGER v to dst and returns
+// the extended buffer. The value is encoded using the minimum number of octets,
+// which may require a leading zero octet.
+func AppendUnsigned(dst []byte, v uint64) []byte {
+	return appendInteger(dst, v, false)
+}
+
+// appendInteger appends the minimal two's complement representation of u64 to
+// dst. If signed is true, u64 is interpreted as an int64.
+func appendInteger(dst []byte, u64 uint64, signed bool) []byte {
+	n := u64
+	if signed && int64(u64) < 0 {
+		n = ^u64
+	}
+	// one additional bit is needed for the sign
+	l := (bits.Len64(n) + 8) / 8
+	var bs [9]byte
+	binary.BigEndian.PutUint64(bs[1:], u64)
+	if signed && int64(u64) < 0 {
+		bs[0] = 0xff
+	}
+	return append(dst, bs[9-l:]...)
+}
+
+// AppendOID appends the content octets of the OBJECT IDENTIFIER oid to dst and
+// returns the extended buffer. If oid is not a valid object identifier, dst is
+// returned unmodified together with an error.
+func AppendOID(dst []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	if len(oid) < 2 || oid[0] > 2 || (oid[0] < 2 && oid[1] > 40) {
+		return dst, errors.New("invalid asn1.ObjectIdentifier")
+	}
+	b := vlq.Append(dst, oid[0]*40+oid[1])
+	return AppendRelativeOID(b, asn1.RelativeOID(oid[2:])), nil
+}
+
+// AppendRelativeOID appends the content octets of the RELATIVE-OID oid to dst
+// and returns the extended buffer.
+func AppendRelativeOID(dst []byte, oid asn1.RelativeOID) []byte {
+	for _, n := range oid {
+		dst = vlq.Append(dst, n)
+	}
+	return dst
+}
+
+// AppendUTCTime appends the content octets of t as a UTCTime to dst and returns
+// the extended buffer. The time zone of t is retained. If the year of t cannot
+// be represented as a UTCTime, dst is returned unmodified together with an
+// error.
+func AppendUTCTime(dst []byte, t time.Time) ([]byte, error) {
+	if !asn1.UTCTime(t).IsValid() {
+		return dst, errors.New("cannot represent time as UTCTime")
+	}
+	return append(dst, asn1.UTCTime(t).String()...), nil
+}
+
+// AppendGeneralizedTime appends the content octets of t as a GeneralizedTime to
+// dst and returns the extended buffer. The time zone of t is retained. If t
+// cannot be represented as a GeneralizedTime, dst is returned unmodified
+// together with an error.
+func AppendGeneralizedTime(dst []byte, t time.Time) ([]byte, error) {
+	if !asn1.GeneralizedTime(t).IsValid() {
+		return dst, errors.New("cannot represent time as GeneralizedTime")
+	}
+	return append(dst, asn1.GeneralizedTime(t).String()...), nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"codello.dev/asn1"
+)
+
+func TestAppendInteger(t *testing.T) {
+	tests := map[string]struct {
+		val  int64
+		want []byte
+	}{
+		"Zero":            {0, []byte{0x00}},
+		"Positive":        {723, []byte{0x02, 0xD3}},
+		"PositiveHighBit": {128, []byte{0x00, 0x80}},
+		"Negative":        {-2, []byte{0xFE}},
+		"NegativeHighBit": {-129, []byte{0xFF, 0x7F}},
+		"MinInt":          {math.MinInt64, []byte{0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := AppendInteger([]byte{0xAB}, tt.val)
+			if !bytes.Equal(got, append([]byte{0xAB}, tt.want...)) {
+				t.Errorf("AppendInteger(%d) = % X, want AB % X", tt.val, got, tt.want)
+			}
+			data, err := Marshal(tt.val)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(data[2:], tt.want) {
+				t.Errorf("Marshal(%d) content = % X, want % X", tt.val, data[2:], tt.want)
+			}
+		})
+	}
+	if got := AppendUnsigned(nil, math.MaxUint64); !bytes.Equal(got, []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}) {
+		t.Errorf("AppendUnsigned(MaxUint64) = % X, want 00 FF FF FF FF FF FF FF FF", got)
+	}
+}
+
+func TestAppendOID(t *testing.T) {
+	got, err := AppendOID(nil, asn1.ObjectIdentifier{1, 2, 840, 113549})
+	if want := []byte{0x2A, 0x86, 0x48, 0x86, 0xF7, 0x0D}; err != nil || !bytes.Equal(got, want) {
+		t.Errorf("AppendOID() = % X, %v, want % X, <nil>", got, err, want)
+	}
+	dst := []byte{0xAB}
+	if got, err = AppendOID(dst, asn1.ObjectIdentifier{1, 41}); err == nil || !bytes.Equal(got, dst) {
+		t.Errorf("AppendOID() = % X, %v, want AB and an error", got, err)
+	}
+	if got = AppendRelativeOID(nil, asn1.RelativeOID{8571, 3, 2}); !bytes.Equal(got, []byte{0xC2, 0x7B, 0x03, 0x02}) {
+		t.Errorf("AppendRelativeOID() = % X, want C2 7B 03 02", got)
+	}
+}
+
+func TestAppendTime(t *testing.T) {
+	tm := time.Date(2024, 3, 15, 12, 30, 45, 0, time.UTC)
+	got, err := AppendUTCTime(nil, tm)
+	if err != nil || string(got) != "240315123045Z" {
+		t.Errorf("AppendUTCTime() = %q, %v, want \"240315123045Z\", <nil>", got, err)
+	}
+	if _, err = AppendUTCTime(nil, tm.AddDate(100, 0, 0)); err == nil {
+		t.Errorf("AppendUTCTime() error = <nil>, want an error")
+	}
+	got, err = AppendGeneralizedTime(nil, tm)
+	if err != nil || string(got) != "20240315123045Z" {
+		t.Errorf("AppendGeneralizedTime() = %q, %v, want \"20240315123045Z\", <nil>", got, err)
+	}
+}
@@ -9,7 +9,12 @@
 // See the package documentation of the asn1 package for details how Go types
 // translate to ASN.1 types. Types following that specification can be encoded
 // into and decoded from a stream of binary data using the Basic Encoding Rules
-// using this package. The following limitations apply:
+// using this package. In addition, iterators of type [iter.Seq] can be encoded
+// as a SEQUENCE OF. The elements are produced lazily during encoding and the
+// indefinite-length encoding is used. Values of type [netip.Addr], [net.IP] and
+// [netip.Prefix] are encoded as an OCTET STRING containing the address octets.
+// A prefix is followed by its network mask as in the iPAddress name constraints
+// of RFC 5280. The following limitations apply:
 //
 //   - When decoding an ASN.1 INTEGER type into a Go integer, the size of the
 //     integer is limited by the size of the Go type. This limitation does not apply
@@ -24,14 +29,46 @@
 //   - When decoding a constructed encoding into an array, the number of data values
 //     in the sequence must match the length of the array exactly.
 //   - Decoding into an interface{} will decode known types as their corresponding
-//     Go values. Unrecognized types will be stored as [RawValue].
+//     Go values. Unrecognized types will be stored as [RawValue]. Other
+//     interface types can be decoded if concrete types have been registered
+//     via [RegisterChoice]. Similarly, fields with a "definedby" struct tag are
+//     decoded into the types registered via [RegisterDefinedBy].
+//   - Values are validated against the range and size constraints specified via
+//     struct tags. Violations are reported as [*EncodeError] during encoding and
+//     as [*StructuralError] during decoding.
+//   - String fields with an "enum" struct tag are encoded as ENUMERATED using the
+//     values of the corresponding [asn1.EnumDef].
+//   - Struct fields with a "choice" struct tag are encoded as the single
+//     non-zero alternative using the tag of that alternative. During decoding
+//     the alternative is selected by the tag in the input.
+//   - Slices and arrays with a "set" struct tag are encoded as SET OF. Their
+//     elements are sorted by their encodings as required by DER. During
+//     decoding, the order of the elements in the input is retained.
+//   - A [time.Time] value without a tag is encoded as the Time type of X.509:
+//     Values are encoded as UTCTime in UTC if their year is between 1950 and
+//     2049 and as GeneralizedTime otherwise. During decoding, UTCTime,
+//     GeneralizedTime and TIME are accepted.
+//   - If a struct embeds [asn1.Presence], decoding records which of its fields
+//     were present in the encoding.
+//   - Decoded structs implementing [asn1.Validator] are validated after all
+//     fields have been decoded. Absent optional fields implementing
+//     [asn1.Defaulter] are set to their defaults.
+//   - Types implementing [BerTagger] or [asn1.Tagger] are implicitly tagged
+//     with their intrinsic tag, unless a different tag is specified via struct
+//     tags.
+//   - Custom encoding and decoding logic for types that cannot implement
+//     [BerEncoder] and [BerDecoder] can be registered via [RegisterCodec].
+//     Field parameters of struct types whose struct tags cannot be edited can
+//     be registered via [RegisterTypeOptions].
 //
 // [Rec. ITU-T X.690]: https://www.itu.int/rec/T-REC-X.690
 // [A Layman's Guide to a Subset of ASN.1, BER, and DER]: http://luca.ntop.org/Teaching/Appunti/asn1.html
 package ber
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 
 	"codello.dev/asn1"
 )
@@ -44,12 +81,68 @@ type Flag bool
 // structured encodings is validated so the Bytes are guaranteed to contain a
 // valid data value encoding. During encoding, the bytes are written as-is
 // without any validation.
+//
+// When decoding, Bytes is a copy of the content octets that does not reference
+// the input. A RawValue is always encoded using a header computed from Tag,
+// Constructed and Bytes, even if it was decoded from a non-minimal or
+// indefinite-length encoding. Use [RawElement] to retain the identifier and
+// length octets as well.
 type RawValue struct {
 	Tag         asn1.Tag
 	Constructed bool
 	Bytes       []byte
 }
 
+// A RawElement is a [RawValue] that additionally retains the complete data
+// value encoding it was decoded from. When decoding via [Unmarshal], Bytes and
+// FullBytes reference the input slice instead of copying it. This makes it
+// possible to process the exact encoding of a data value, e.g. to verify a
+// signature over it.
+//
+// A decoded RawElement re-encodes to exactly the bytes it was decoded from,
+// including non-minimal and indefinite lengths. This allows data values to be
+// passed through without altering their representation. To re-encode a value
+// using a minimal definite-length header, set FullBytes to nil or encode the
+// embedded RawValue instead.
+type RawElement struct {
+	RawValue
+
+	// FullBytes contains the complete data value encoding including the
+	// identifier and length octets. FullBytes is set during decoding. When
+	// encoding, FullBytes is written instead of the RawValue if it is a valid
+	// encoding of it. Otherwise, FullBytes is ignored.
+	FullBytes []byte
+}
+
+// ParseRawValue parses the data value encoding at the beginning of b and returns
+// it together with the remaining bytes of b. The Bytes of the returned RawValue
+// reference b instead of copying it. The complete encoding of rv is
+// b[:len(b)-len(rest)]. This makes it possible to hand off buffers between this
+// package and byte-oriented parsers such as the String type of
+// golang.org/x/crypto/cryptobyte without copying:
+//
+//	rv, rest, err := ber.ParseRawValue(s)
+//	s = cryptobyte.String(rest)
+//
+// Conversely, the complete encoding can be passed to the AddBytes method of a
+// cryptobyte.Builder. See also [codello.dev/asn1/tlv.Header.Identifier] for
+// converting tags.
+func ParseRawValue(b []byte) (rv RawValue, rest []byte, err error) {
+	re, rest, err := parseRawElement(b)
+	return re.RawValue, rest, err
+}
+
+// parseRawElement works like [ParseRawValue] but returns a [RawElement].
+func parseRawElement(b []byte) (re RawElement, rest []byte, err error) {
+	r := bytes.NewReader(b)
+	d := NewDecoder(r)
+	d.r.(*reader).src = &source{b, r}
+	if err = d.Decode(&re); err != nil {
+		return RawElement{}, b, err
+	}
+	return re, b[len(re.FullBytes):], nil
+}
+
 // String returns a string representation of rv. The byte contents of rv are
 // only included if they are short enough.
 func (rv RawValue) String() string {
@@ -62,3 +155,15 @@ func (rv RawValue) String() string {
 	}
 	return fmt.Sprintf("RawValue{%s (%s) {% X}}", rv.Tag.String(), constructed, rv.Bytes)
 }
+
+// Codec implements [asn1.Codec] using the Basic Encoding Rules. Its methods
+// call the functions of the same name in this package.
+var Codec asn1.Codec = encodingRules{}
+
+// encodingRules is the type of [Codec].
+type encodingRules struct{}
+
+func (encodingRules) Marshal(val any) ([]byte, error)     { return Marshal(val) }
+func (encodingRules) Unmarshal(b []byte, val any) error   { return Unmarshal(b, val) }
+func (encodingRules) NewEncoder(w io.Writer) asn1.Encoder { return NewEncoder(w) }
+func (encodingRules) NewDecoder(r io.Reader) asn1.Decoder { return NewDecoder(r) }
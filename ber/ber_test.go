@@ -4,7 +4,16 @@
 
 package ber
 
-import "testing"
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"codello.dev/asn1"
+)
 
 // This file contains general encoding/decoding tests not related to a specific type.
 
@@ -33,3 +42,203 @@ func TestCodec_TagOverride(t *testing.T) {
 			0x13, 0x04, 0x31, 0x32, 0x33, 0x34}, wantErr: &StructuralError{}},
 	})
 }
+
+// testCounter32 and testIPAddress model the SNMP types of the same name.
+type testCounter32 uint32
+
+func (testCounter32) BerTag() asn1.Tag { return asn1.ClassApplication | 1 }
+
+type testIPAddress [4]byte
+
+func (testIPAddress) BerTag() asn1.Tag { return asn1.ClassApplication | 0 }
+
+func TestCodec_BerTagger(t *testing.T) {
+	type snmpTest struct {
+		Addr     testIPAddress
+		Count    testCounter32
+		Override testCounter32 `asn1:"tag:0"`
+		Counts   []testCounter32
+	}
+	testCodec(t, map[string]testCase[testCounter32]{
+		"Counter32": {val: 300, data: []byte{0x41, 0x02, 0x01, 0x2C}},
+	}, nil, map[string]testCase[testCounter32]{
+		"Universal": {data: []byte{0x02, 0x02, 0x01, 0x2C}, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[snmpTest]{
+		"Struct": {val: snmpTest{testIPAddress{10, 0, 0, 1}, 5, 6, []testCounter32{7}}, data: []byte{0x30, 0x11,
+			0x40, 0x04, 0x0A, 0x00, 0x00, 0x01,
+			0x41, 0x01, 0x05,
+			0x80, 0x01, 0x06,
+			0x30, 0x03, 0x41, 0x01, 0x07}},
+	}, nil, nil)
+}
+
+// testTimeTicks models the SNMP type of the same name.
+type testTimeTicks uint32
+
+func (testTimeTicks) AsnTag() asn1.Tag { return asn1.ClassApplication | 3 }
+
+func TestCodec_Tagger(t *testing.T) {
+	type optionalTest struct {
+		Ticks testTimeTicks `asn1:"optional,omitzero"`
+		Count testCounter32 `asn1:"optional,omitzero"`
+	}
+	testCodec(t, map[string]testCase[testTimeTicks]{
+		"TimeTicks": {val: 5, data: []byte{0x43, 0x01, 0x05}},
+	}, nil, map[string]testCase[testTimeTicks]{
+		"Universal": {data: []byte{0x02, 0x01, 0x05}, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[optionalTest]{
+		"Both":      {val: optionalTest{1, 2}, data: []byte{0x30, 0x06, 0x43, 0x01, 0x01, 0x41, 0x01, 0x02}},
+		"TicksOnly": {val: optionalTest{Ticks: 1}, data: []byte{0x30, 0x03, 0x43, 0x01, 0x01}},
+		"CountOnly": {val: optionalTest{Count: 2}, data: []byte{0x30, 0x03, 0x41, 0x01, 0x02}},
+	}, nil, nil)
+}
+
+// testUUID models a third-party type whose methods are not suitable for BER.
+type testUUID [4]byte
+
+func (testUUID) MarshalBinary() ([]byte, error) { return nil, errors.New("not supported") }
+
+// testUUIDCodec encodes a testUUID as a UTF8String of its hex representation.
+type testUUIDCodec struct{ v reflect.Value }
+
+func (c testUUIDCodec) BerEncode() (Header, io.WriterTo, error) {
+	u := c.v.Interface().(testUUID)
+	return Header{Tag: asn1.TagUTF8String, Length: 8}, bytes.NewReader([]byte(hex.EncodeToString(u[:]))), nil
+}
+
+func (c testUUIDCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagUTF8String
+}
+
+func (c testUUIDCodec) BerDecode(_ asn1.Tag, r Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var u testUUID
+	if n, err := hex.Decode(u[:], b); err != nil || n != len(u) {
+		return errors.New("invalid UUID")
+	}
+	c.v.Set(reflect.ValueOf(u))
+	return nil
+}
+
+func init() {
+	RegisterCodec(reflect.TypeFor[testUUID](),
+		func(v reflect.Value) BerEncoder { return testUUIDCodec{v} },
+		func(v reflect.Value) BerDecoder { return testUUIDCodec{v} })
+}
+
+func TestRegisterCodec(t *testing.T) {
+	type uuidTest struct {
+		A *testUUID `asn1:"optional"`
+		B testUUID
+	}
+	testCodec(t, map[string]testCase[testUUID]{
+		"UUID": {val: testUUID{0x01, 0x23, 0xAB, 0xCD}, data: []byte{0x0C, 0x08, '0', '1', '2', '3', 'a', 'b', 'c', 'd'}},
+	}, nil, map[string]testCase[testUUID]{
+		"Mismatch": {data: []byte{0x04, 0x04, 0x01, 0x23, 0xAB, 0xCD}, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[uuidTest]{
+		"Pointer": {val: uuidTest{&testUUID{1, 2, 3, 4}, testUUID{5, 6, 7, 8}}, data: []byte{0x30, 0x14,
+			0x0C, 0x08, '0', '1', '0', '2', '0', '3', '0', '4',
+			0x0C, 0x08, '0', '5', '0', '6', '0', '7', '0', '8'}},
+	}, nil, nil)
+}
+
+// testOptionsStruct is configured via RegisterTypeOptions.
+type testOptionsStruct struct {
+	A int
+	B string `asn1:"optional"`
+	C bool   `asn1:"tag:5"`
+}
+
+func init() {
+	RegisterTypeOptions(reflect.TypeFor[testOptionsStruct](), TypeOptions{FieldParams: map[string]string{
+		"A": "tag:1,explicit",
+		"B": "tag:2,optional,omitzero",
+	}})
+}
+
+func TestRegisterTypeOptions(t *testing.T) {
+	testCodec(t, map[string]testCase[testOptionsStruct]{
+		"Tagged": {val: testOptionsStruct{1, "a", true}, data: []byte{0x30, 0x0B,
+			0xA1, 0x03, 0x02, 0x01, 0x01,
+			0x82, 0x01, 'a',
+			0x85, 0x01, 0xFF}},
+		"Omitted": {val: testOptionsStruct{2, "", false}, data: []byte{0x30, 0x08,
+			0xA1, 0x03, 0x02, 0x01, 0x02,
+			0x85, 0x01, 0x00}},
+	}, nil, nil)
+
+	tests := map[string]struct {
+		t    reflect.Type
+		opts TypeOptions
+	}{
+		"NonStruct":    {reflect.TypeFor[int](), TypeOptions{}},
+		"UnknownField": {reflect.TypeFor[struct{ A int }](), TypeOptions{FieldParams: map[string]string{"B": "tag:1"}}},
+		"Duplicate":    {reflect.TypeFor[testOptionsStruct](), TypeOptions{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterTypeOptions() did not panic")
+				}
+			}()
+			RegisterTypeOptions(tt.t, tt.opts)
+		})
+	}
+}
+
+func TestCodec_Interface(t *testing.T) {
+	var c asn1.Codec = Codec
+	data, err := c.Marshal(42)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var i int
+	if err = c.Unmarshal(data, &i); err != nil || i != 42 {
+		t.Fatalf("Unmarshal() = %v, %v, want 42, <nil>", i, err)
+	}
+
+	var buf bytes.Buffer
+	enc := c.NewEncoder(&buf)
+	if err = enc.Encode("a"); err == nil {
+		err = enc.Encode(true)
+	}
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	dec := c.NewDecoder(&buf)
+	var s string
+	var b bool
+	if err = dec.Decode(&s); err == nil {
+		err = dec.Decode(&b)
+	}
+	if err != nil || s != "a" || !b {
+		t.Errorf("Decode() = %q, %v, %v, want \"a\", true, <nil>", s, b, err)
+	}
+}
+
+func TestParseRawValue(t *testing.T) {
+	data := []byte{0x02, 0x01, 0x05, 0x30, 0x80, 0x05, 0x00, 0x00, 0x00, 0x01}
+	rv, rest, err := ParseRawValue(data)
+	if err != nil {
+		t.Fatalf("ParseRawValue() error = %v", err)
+	}
+	if rv.Tag != asn1.TagInteger || !bytes.Equal(rv.Bytes, []byte{0x05}) || &rv.Bytes[0] != &data[2] {
+		t.Errorf("ParseRawValue() = %v, want INTEGER 5 referencing the input", rv)
+	}
+	if rv, rest, err = ParseRawValue(rest); err != nil {
+		t.Fatalf("ParseRawValue() error = %v", err)
+	}
+	if !rv.Constructed || !bytes.Equal(rv.Bytes, data[5:7]) || !bytes.Equal(rest, data[9:]) {
+		t.Errorf("ParseRawValue() = %v, % X, want indefinite SEQUENCE and 1 remaining byte", rv, rest)
+	}
+	if _, rest, err = ParseRawValue(rest); err == nil || !bytes.Equal(rest, data[9:]) {
+		t.Errorf("ParseRawValue() = % X, %v, want truncation error", rest, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bertest provides utilities for testing and fuzzing the BER encoding
+// of Go types.
+//
+// [RoundTrip] checks that a value survives an encode and decode cycle.
+// [FuzzUnmarshal] implements a fuzz target that decodes arbitrary input into a
+// type and verifies that the decoded value can be encoded and decoded again.
+// The [Seeds] function returns a corpus of data value encodings that can be
+// used to seed a fuzz test. [Differential] compares the decoding of a value
+// with the [encoding/asn1] package of the standard library.
+package bertest
+
+import (
+	stdasn1 "encoding/asn1"
+	"reflect"
+	"testing"
+
+	"codello.dev/asn1/asn1test"
+	"codello.dev/asn1/ber"
+)
+
+// RoundTrip encodes val using [ber.Marshal] and decodes the result into a new
+// value of the same type. If encoding or decoding fails or the decoded value
+// is not deeply equal to val, RoundTrip reports an error via t. Additionally,
+// the decoded value must encode to the same bytes as val. RoundTrip returns the
+// encoding of val.
+func RoundTrip(t testing.TB, val any) []byte {
+	t.Helper()
+	data, err := ber.Marshal(val)
+	if err != nil {
+		t.Errorf("Marshal(%#v) error = %v", val, err)
+		return nil
+	}
+	got := reflect.New(reflect.TypeOf(val))
+	if err = ber.Unmarshal(data, got.Interface()); err != nil {
+		t.Errorf("Unmarshal(% X) error = %v", data, err)
+		return data
+	}
+	if !reflect.DeepEqual(got.Elem().Interface(), val) {
+		t.Errorf("Unmarshal(% X) got = %#v, want %#v", data, got.Elem().Interface(), val)
+	}
+	if data2, err := ber.Marshal(got.Elem().Interface()); err != nil {
+		t.Errorf("Marshal(%#v) error = %v", got.Elem().Interface(), err)
+	} else if d := asn1test.Diff(data, data2); d != "" {
+		t.Errorf("Marshal(%#v) differs from original encoding: %s", got.Elem().Interface(), d)
+	}
+	return data
+}
+
+// FuzzUnmarshal runs f with a fuzz target for the type T. The corpus is seeded
+// with the encodings of the values in seeds and the encodings returned by
+// [Seeds].
+//
+// The fuzz target decodes its input into a value of type T. If decoding
+// succeeds, the value is encoded again and the result is decoded into another
+// value of type T. The fuzz target fails if the second encode or decode fails
+// or if the two decoded values are not deeply equal. Consequently, T must be a
+// type that can be encoded as well as decoded.
+func FuzzUnmarshal[T any](f *testing.F, seeds ...T) {
+	f.Helper()
+	for _, seed := range seeds {
+		data, err := ber.Marshal(seed)
+		if err != nil {
+			f.Fatalf("Marshal(%#v) error = %v", seed, err)
+		}
+		f.Add(data)
+	}
+	for _, data := range Seeds() {
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v T
+		if err := ber.Unmarshal(data, &v); err != nil {
+			return
+		}
+		enc, err := ber.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(%#v) error = %v", v, err)
+		}
+		var v2 T
+		if err = ber.Unmarshal(enc, &v2); err != nil {
+			t.Fatalf("Unmarshal(% X) error = %v", enc, err)
+		}
+		if !reflect.DeepEqual(v2, v) {
+			t.Errorf("Unmarshal(% X) got = %#v, want %#v", enc, v2, v)
+		}
+	})
+}
+
+// Differential decodes data into a value of type T using [ber.Unmarshal] and
+// the [encoding/asn1] package of the standard library. Because the standard
+// library only supports DER and a subset of BER, an error is only reported via
+// t if the standard library succeeds and ber.Unmarshal fails or if both
+// succeed but produce values that are not deeply equal. T must be a type that
+// has the same meaning for both packages, such as integers, booleans, byte
+// slices and structs thereof.
+func Differential[T any](t testing.TB, data []byte) {
+	t.Helper()
+	var want T
+	rest, err := stdasn1.Unmarshal(data, &want)
+	if err != nil || len(rest) > 0 {
+		return
+	}
+	var got T
+	if err = ber.Unmarshal(data, &got); err != nil {
+		t.Errorf("Unmarshal(% X) error = %v, encoding/asn1 succeeded with %#v", data, err, want)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(% X) got = %#v, encoding/asn1 got %#v", data, got, want)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bertest
+
+import (
+	"math/big"
+	"testing"
+
+	"codello.dev/asn1/ber"
+)
+
+type testMessage struct {
+	ID    int
+	Name  string `asn1:"optional"`
+	Data  []byte
+	Valid bool
+}
+
+func TestSeeds(t *testing.T) {
+	for i, data := range Seeds() {
+		var rv ber.RawValue
+		if err := ber.Unmarshal(data, &rv); err != nil {
+			t.Errorf("Unmarshal(Seeds()[%d]) error = %v", i, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := map[string]any{
+		"Int":     42,
+		"String":  "hello",
+		"BigInt":  big.NewInt(-1 << 40),
+		"Message": testMessage{ID: 5, Name: "abc", Data: []byte{1, 2}, Valid: true},
+	}
+	for name, val := range tests {
+		t.Run(name, func(t *testing.T) {
+			if data := RoundTrip(t, val); len(data) == 0 {
+				t.Errorf("RoundTrip() = % X, want non-empty encoding", data)
+			}
+		})
+	}
+}
+
+func TestDifferential(t *testing.T) {
+	for _, data := range Seeds() {
+		Differential[int](t, data)
+		Differential[bool](t, data)
+		Differential[[]byte](t, data)
+		Differential[testMessage](t, data)
+	}
+}
+
+func FuzzUnmarshal_message(f *testing.F) {
+	FuzzUnmarshal(f, testMessage{ID: 1, Data: []byte{}}, testMessage{ID: -1, Name: "x", Data: []byte{0xFF}})
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bertest
+
+import "bytes"
+
+// seeds contains data value encodings covering the universal types and the
+// different length forms of BER.
+var seeds = [][]byte{
+	{0x01, 0x01, 0xFF},       // BOOLEAN
+	{0x02, 0x01, 0x00},       // INTEGER
+	{0x02, 0x02, 0xFF, 0x7F}, // negative INTEGER
+	{0x02, 0x09, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, // large INTEGER
+	{0x03, 0x02, 0x04, 0xF0},                                   // BIT STRING
+	{0x04, 0x03, 'a', 'b', 'c'},                                // OCTET STRING
+	{0x24, 0x80, 0x04, 0x01, 'a', 0x04, 0x01, 'b', 0x00, 0x00}, // constructed OCTET STRING
+	{0x05, 0x00},                   // NULL
+	{0x06, 0x03, 0x2A, 0x86, 0x48}, // OBJECT IDENTIFIER
+	{0x09, 0x03, 0x80, 0xFB, 0x05}, // REAL
+	{0x0A, 0x01, 0x02},             // ENUMERATED
+	{0x0C, 0x02, 0xC3, 0xA4},       // UTF8String
+	{0x0D, 0x02, 0x81, 0x01},       // RELATIVE-OID
+	{0x13, 0x02, 'h', 'i'},         // PrintableString
+	{0x16, 0x02, 'h', 'i'},         // IA5String
+	{0x17, 0x0D, '2', '5', '0', '1', '0', '1', '1', '2', '0', '0', '0', '0', 'Z'},           // UTCTime
+	{0x18, 0x0F, '2', '0', '2', '5', '0', '1', '0', '1', '1', '2', '0', '0', '0', '0', 'Z'}, // GeneralizedTime
+	{0x1E, 0x02, 0x00, 'a'},                          // BMPString
+	{0x30, 0x06, 0x02, 0x01, 0x01, 0x01, 0x01, 0x00}, // SEQUENCE
+	{0x30, 0x80, 0x02, 0x01, 0x01, 0x00, 0x00},       // indefinite-length SEQUENCE
+	{0x31, 0x06, 0x02, 0x01, 0x02, 0x02, 0x01, 0x01}, // SET
+	{0x30, 0x81, 0x03, 0x02, 0x01, 0x01},             // non-minimal length
+	{0x80, 0x01, 0x01},                               // context-specific
+	{0xA0, 0x03, 0x02, 0x01, 0x01},                   // explicit tag
+	{0x5F, 0x81, 0x00, 0x00},                         // high tag number
+}
+
+// Seeds returns a corpus of valid data value encodings. The corpus includes at
+// least one encoding of each commonly used universal type as well as examples
+// of constructed, indefinite-length and non-minimal encodings. The returned
+// slices can be modified by the caller.
+func Seeds() [][]byte {
+	ret := make([][]byte, len(seeds))
+	for i, s := range seeds {
+		ret[i] = bytes.Clone(s)
+	}
+	return ret
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+
+	"codello.dev/asn1"
+)
+
+// Canonicalize transcodes the BER-encoded data value in b into the
+// Distinguished Encoding Rules (DER). This is useful to verify signatures that
+// have been computed over a DER encoding but have been transported using BER.
+// If b contains more than one data value, an error is returned.
+//
+// Canonicalize works without knowledge of the ASN.1 schema of the data.
+// Therefore, only the following rules are applied:
+//
+//   - All lengths use the minimal definite-length encoding.
+//   - Constructed encodings of universal string types are converted into their
+//     primitive encoding.
+//   - The components of universal SET types are sorted by their encoding.
+//   - BOOLEAN values use 0xFF to indicate true.
+//   - INTEGER and ENUMERATED values use the minimal number of bytes.
+//   - Unused bits in BIT STRING values are set to zero.
+//
+// Data values using implicit tags are treated as opaque values. Their contents
+// are canonicalized recursively but their types cannot be inferred. In
+// particular the contents of primitive data values with non-universal tags are
+// copied as-is. Other DER requirements (such as the format of time types or the
+// omission of DEFAULT values) are not enforced.
+func Canonicalize(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(b))
+	r := bytes.NewReader(b)
+	d := NewDecoder(r)
+	h, er, err := d.Next()
+	if err != nil {
+		return nil, err
+	}
+	if err = canonicalize(&buf, h, er); err == nil {
+		err = er.Close()
+	}
+	if err == nil && r.Len() > 0 {
+		err = fmt.Errorf("%w after data value encoding", ErrExtraData)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Equal reports whether the BER-encoded data values a and b represent the same
+// abstract value. Two encodings are considered equal if their [Canonicalize]d
+// forms are identical. In particular differences in the length form, in the
+// segmentation of strings and in the order of SET components are ignored. The
+// limitations of Canonicalize apply. If a or b is not a valid encoding of a
+// single data value, Equal returns false.
+func Equal(a, b []byte) bool {
+	ca, err := Canonicalize(a)
+	if err != nil {
+		return false
+	}
+	cb, err := Canonicalize(b)
+	return err == nil && bytes.Equal(ca, cb)
+}
+
+// CanonicalizeStream reads BER-encoded data values from r and writes their DER
+// encoding to w until r returns io.EOF. See [Canonicalize] for details. Each
+// data value is buffered in memory before it is written to w.
+func CanonicalizeStream(w io.Writer, r io.Reader) error {
+	d := NewDecoder(r)
+	var buf bytes.Buffer
+	for {
+		h, er, err := d.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		buf.Reset()
+		if err = canonicalize(&buf, h, er); err == nil {
+			err = er.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+// canonicalize writes the DER encoding of the data value with header h read
+// from r into buf.
+func canonicalize(buf *bytes.Buffer, h Header, r Reader) error {
+	var content []byte
+	var err error
+	if r.Constructed() && isStringTag(h.Tag) {
+		h.Constructed = false
+		if h.Tag == asn1.TagBitString {
+			var bs asn1.BitString
+			if err = (bitStringCodec{ref: reflect.ValueOf(&bs).Elem()}).BerDecode(h.Tag, r); err != nil {
+				return err
+			}
+			content = appendBitString(nil, bs)
+		} else if content, err = NewStringReader(h.Tag, r).Bytes(); err != nil {
+			return err
+		}
+	} else if r.Constructed() {
+		if content, err = canonicalizeConstructed(h.Tag, r); err != nil {
+			return err
+		}
+	} else {
+		content = make([]byte, r.Len())
+		if _, err = io.ReadFull(r, content); err != nil {
+			return err
+		}
+		switch h.Tag {
+		case asn1.TagBoolean:
+			if len(content) != 1 {
+				return &SyntaxError{Tag: h.Tag, Err: errors.New("invalid BOOLEAN")}
+			}
+			if content[0] != 0x00 {
+				content[0] = 0xff
+			}
+		case asn1.TagInteger, asn1.TagEnumerated:
+			for len(content) > 1 && (content[0] == 0x00 && content[1]&0x80 == 0 || content[0] == 0xff && content[1]&0x80 != 0) {
+				content = content[1:]
+			}
+		case asn1.TagBitString:
+			if len(content) == 0 || content[0] > 7 || len(content) == 1 && content[0] != 0 {
+				return &SyntaxError{Tag: h.Tag, Err: errors.New("invalid BIT STRING")}
+			}
+			content[len(content)-1] &^= 1<<content[0] - 1
+		}
+	}
+	h.Length = len(content)
+	_, _ = h.writeTo(buf)
+	buf.Write(content)
+	return nil
+}
+
+// canonicalizeConstructed reads all data values from r and returns the
+// concatenation of their DER encodings. If tag is a SET, the encodings are
+// sorted.
+func canonicalizeConstructed(tag asn1.Tag, r Reader) ([]byte, error) {
+	var children [][]byte
+	var buf bytes.Buffer
+	for {
+		h, er, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		buf.Reset()
+		if err = canonicalize(&buf, h, er); err == nil {
+			err = er.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, bytes.Clone(buf.Bytes()))
+	}
+	if tag == asn1.TagSet {
+		slices.SortFunc(children, bytes.Compare)
+	}
+	return slices.Concat(children...), nil
+}
+
+// appendBitString appends the content octets of the DER encoding of bs to dst.
+func appendBitString(dst []byte, bs asn1.BitString) []byte {
+	padding := byte((8 - bs.BitLength%8) % 8)
+	dst = append(dst, padding)
+	if len(bs.Bytes) > 0 {
+		dst = append(dst, bs.Bytes...)
+		dst[len(dst)-1] &^= 1<<padding - 1
+	}
+	return dst
+}
+
+// isStringTag reports whether tag identifies a universal ASN.1 type that may
+// use the constructed string encoding.
+func isStringTag(tag asn1.Tag) bool {
+	switch tag {
+	case asn1.TagBitString,
+		asn1.TagOctetString,
+		asn1.TagObjectDescriptor,
+		asn1.TagUTF8String,
+		asn1.TagNumericString,
+		asn1.TagPrintableString,
+		asn1.TagTeletexString,
+		asn1.TagVideotexString,
+		asn1.TagIA5String,
+		asn1.TagUTCTime,
+		asn1.TagGeneralizedTime,
+		asn1.TagGraphicString,
+		asn1.TagVisibleString,
+		asn1.TagGeneralString,
+		asn1.TagUniversalString,
+		asn1.TagBMPString:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		want    []byte
+		wantErr bool
+	}{
+		"Primitive": {[]byte{0x02, 0x01, 0x05}, []byte{0x02, 0x01, 0x05}, false},
+		"NonMinimalLength": {[]byte{0x04, 0x82, 0x00, 0x02, 0x01, 0x02},
+			[]byte{0x04, 0x02, 0x01, 0x02}, false},
+		"Indefinite": {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00},
+			[]byte{0x30, 0x03, 0x02, 0x01, 0x05}, false},
+		"Boolean": {[]byte{0x01, 0x01, 0x15}, []byte{0x01, 0x01, 0xff}, false},
+		"Integer": {[]byte{0x02, 0x03, 0x00, 0x00, 0x80}, []byte{0x02, 0x02, 0x00, 0x80}, false},
+		"NegativeInteger": {[]byte{0x02, 0x03, 0xff, 0xff, 0x7f},
+			[]byte{0x02, 0x02, 0xff, 0x7f}, false},
+		"BitStringPadding": {[]byte{0x03, 0x02, 0x04, 0xff}, []byte{0x03, 0x02, 0x04, 0xf0}, false},
+		"ConstructedOctetString": {[]byte{0x24, 0x80, 0x04, 0x01, 0x01, 0x24, 0x03, 0x04, 0x01, 0x02, 0x00, 0x00},
+			[]byte{0x04, 0x02, 0x01, 0x02}, false},
+		"ConstructedBitString": {[]byte{0x23, 0x08, 0x03, 0x02, 0x00, 0x0f, 0x03, 0x02, 0x04, 0xff},
+			[]byte{0x03, 0x03, 0x04, 0x0f, 0xf0}, false},
+		"Set": {[]byte{0x31, 0x80, 0x02, 0x01, 0x07, 0x01, 0x01, 0x01, 0x02, 0x01, 0x03, 0x00, 0x00},
+			[]byte{0x31, 0x09, 0x01, 0x01, 0xff, 0x02, 0x01, 0x03, 0x02, 0x01, 0x07}, false},
+		"ImplicitConstructed": {[]byte{0xa1, 0x80, 0x04, 0x01, 0x01, 0x00, 0x00},
+			[]byte{0xa1, 0x03, 0x04, 0x01, 0x01}, false},
+
+		"ExtraData":        {[]byte{0x05, 0x00, 0x05, 0x00}, nil, true},
+		"Truncated":        {[]byte{0x30, 0x80, 0x02, 0x01, 0x05}, nil, true},
+		"InvalidBoolean":   {[]byte{0x01, 0x02, 0x01, 0x01}, nil, true},
+		"InvalidBitString": {[]byte{0x03, 0x01, 0x01}, nil, true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Canonicalize(tc.data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Canonicalize() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("Canonicalize() = % X, want % X", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeStream(t *testing.T) {
+	data := []byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00, 0x01, 0x01, 0x01}
+	want := []byte{0x30, 0x03, 0x02, 0x01, 0x05, 0x01, 0x01, 0xff}
+	var buf bytes.Buffer
+	if err := CanonicalizeStream(&buf, bytes.NewReader(data)); err != nil {
+		t.Fatalf("CanonicalizeStream() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("CanonicalizeStream() = % X, want % X", buf.Bytes(), want)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := map[string]struct {
+		a, b []byte
+		want bool
+	}{
+		"Identical":     {[]byte{0x02, 0x01, 0x05}, []byte{0x02, 0x01, 0x05}, true},
+		"LengthForm":    {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00}, []byte{0x30, 0x81, 0x03, 0x02, 0x01, 0x05}, true},
+		"Segmentation":  {[]byte{0x24, 0x06, 0x04, 0x01, 0x01, 0x04, 0x01, 0x02}, []byte{0x04, 0x02, 0x01, 0x02}, true},
+		"SetOrder":      {[]byte{0x31, 0x06, 0x02, 0x01, 0x07, 0x02, 0x01, 0x03}, []byte{0x31, 0x06, 0x02, 0x01, 0x03, 0x02, 0x01, 0x07}, true},
+		"DifferentTag":  {[]byte{0x02, 0x01, 0x05}, []byte{0x0A, 0x01, 0x05}, false},
+		"DifferentData": {[]byte{0x04, 0x01, 0x01}, []byte{0x04, 0x01, 0x02}, false},
+		"SequenceOrder": {[]byte{0x30, 0x06, 0x02, 0x01, 0x07, 0x02, 0x01, 0x03}, []byte{0x30, 0x06, 0x02, 0x01, 0x03, 0x02, 0x01, 0x07}, false},
+		"Invalid":       {[]byte{0x30, 0x80, 0x02, 0x01, 0x05}, []byte{0x30, 0x80, 0x02, 0x01, 0x05}, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(% X, % X) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+)
+
+// choices maps interface types to the factories registered via
+// [RegisterChoice]. The values are of type map[asn1.Tag]func() reflect.Value.
+var choices sync.Map
+
+// choicesMu serializes calls to [RegisterChoice].
+var choicesMu sync.Mutex
+
+// RegisterChoice registers factory as the source of values for the interface
+// type I that are encoded with the given tag. When a data value is decoded into
+// a struct field or variable of type I, the concrete value is obtained by
+// calling the factory registered for the tag of the data value. The data value
+// is then decoded into the returned value. If factory returns a pointer, the
+// data value is decoded into the pointed-to value.
+//
+// Registering multiple concrete types for a single interface type effectively
+// defines an open CHOICE type. If a data value does not match any registered
+// tag, decoding fails with an error wrapping [ErrTagMismatch]. In particular
+// this means that an OPTIONAL field of type I is considered absent if its tag
+// was not registered.
+//
+// RegisterChoice panics if I is not an interface type or if a factory has
+// already been registered for the same combination of I and tag. It is safe
+// to call RegisterChoice concurrently with decoding, but registration is
+// typically done during program initialization.
+func RegisterChoice[I any](tag asn1.Tag, factory func() I) {
+	t := reflect.TypeFor[I]()
+	if t.Kind() != reflect.Interface {
+		panic("ber: RegisterChoice of non-interface type " + t.String())
+	}
+	choicesMu.Lock()
+	defer choicesMu.Unlock()
+	m := make(map[asn1.Tag]func() reflect.Value)
+	if old, ok := choices.Load(t); ok {
+		// copy the map so that concurrent decoders never observe a partial update
+		m = maps.Clone(old.(map[asn1.Tag]func() reflect.Value))
+		if _, dup := m[tag]; dup {
+			panic(fmt.Sprintf("ber: RegisterChoice of duplicate tag %s for %s", tag, t))
+		}
+	}
+	m[tag] = func() reflect.Value { return reflect.ValueOf(factory()) }
+	choices.Store(t, m)
+}
+
+//region type choiceDecoder
+
+// choiceDecoder decodes a data value into an interface value using the
+// factories registered via [RegisterChoice].
+type choiceDecoder codec[map[asn1.Tag]func() reflect.Value]
+
+// choiceDecoderFor returns a decoder for the interface value v, if factories
+// have been registered for its type.
+func choiceDecoderFor(v reflect.Value) (*choiceDecoder, bool) {
+	m, ok := choices.Load(v.Type())
+	if !ok {
+		return nil, false
+	}
+	return &choiceDecoder{v, m.(map[asn1.Tag]func() reflect.Value)}, true
+}
+
+// BerMatch reports whether a factory has been registered for tag.
+func (d *choiceDecoder) BerMatch(tag asn1.Tag) bool {
+	_, ok := d.val[tag]
+	return ok
+}
+
+// BerDecode decodes a value of the type registered for tag and stores it in
+// the interface value.
+func (d *choiceDecoder) BerDecode(tag asn1.Tag, r Reader) error {
+	factory, ok := d.val[tag]
+	if !ok {
+		return &StructuralError{Tag: tag, Type: d.ref.Type(), Err: ErrTagMismatch}
+	}
+	x := factory()
+	if !x.IsValid() || !x.Type().AssignableTo(d.ref.Type()) {
+		return &InvalidDecodeError{Value: d.ref}
+	}
+	nv := x
+	if x.Kind() != reflect.Pointer || x.IsNil() {
+		nv = reflect.New(x.Type()).Elem()
+		nv.Set(x)
+	}
+	if err := decodeValue(tag, r, nv, internal.FieldParameters{}); err != nil {
+		return err
+	}
+	d.ref.Set(nv)
+	return nil
+}
+
+//endregion
+
+//region type choiceStructDecoder
+
+// errImplicitChoice indicates a CHOICE type with an implicit tag. The tag of a
+// CHOICE type is always explicit, because the tag of the chosen alternative
+// identifies it.
+var errImplicitChoice = errors.New("CHOICE type cannot be tagged implicitly")
+
+// choiceStructDecoder decodes a data value into a struct with the
+// `asn1:"choice"` struct tag. Each field of the struct is an alternative of the
+// CHOICE type. The alternative is selected by the tag of the data value. After
+// decoding, all other fields of the struct are zero.
+type choiceStructDecoder struct {
+	ref reflect.Value
+}
+
+// alternative returns a decoder for the alternative of d that matches tag. The
+// alternative is a field of val, which must be a value of the same type as
+// d.ref.
+func (d choiceStructDecoder) alternative(tag asn1.Tag, val reflect.Value) (BerDecoder, error) {
+	for field, params := range internal.StructFields(val) {
+		if internal.IsExtensible(field.Type()) {
+			continue
+		}
+		params.Optional, params.OmitZero = false, false
+		dec, err := makeDecoder(tag, field, params)
+		if err == nil {
+			return dec, nil
+		} else if !errors.Is(err, ErrTagMismatch) {
+			return nil, err
+		}
+	}
+	return nil, &StructuralError{Tag: tag, Type: d.ref.Type(), Err: fmt.Errorf("no CHOICE alternative: %w", ErrTagMismatch)}
+}
+
+// BerMatch reports whether an alternative of d matches tag.
+func (d choiceStructDecoder) BerMatch(tag asn1.Tag) bool {
+	_, err := d.alternative(tag, reflect.New(d.ref.Type()).Elem())
+	return err == nil
+}
+
+// BerDecode decodes the alternative matching tag and replaces the value of d.
+func (d choiceStructDecoder) BerDecode(tag asn1.Tag, r Reader) error {
+	val := reflect.New(d.ref.Type()).Elem()
+	dec, err := d.alternative(tag, val)
+	if err != nil {
+		return err
+	}
+	if err = dec.BerDecode(tag, r); err != nil {
+		return err
+	}
+	d.ref.Set(val)
+	return nil
+}
+
+//endregion
+
+// definedBy maps the string representation of object identifiers to the
+// factories registered via [RegisterDefinedBy].
+var definedBy sync.Map
+
+// RegisterDefinedBy registers factory as the source of values for struct fields
+// tagged with `asn1:"definedby:x"` where the field x contains the object
+// identifier id. When such a field is decoded, the data value is decoded into
+// the value returned by factory, which is then stored in the field. If factory
+// returns a pointer, the data value is decoded into the pointed-to value.
+// Usually fields using the "definedby" tag have an interface type.
+//
+// If no factory is registered for an identifier or the value returned by
+// factory is not assignable to the field, the field is decoded as usual. For a
+// field of type any this means that unrecognized types are stored as
+// [RawValue].
+//
+// RegisterDefinedBy panics if a factory has already been registered for id.
+func RegisterDefinedBy(id asn1.ObjectIdentifier, factory func() any) {
+	if _, dup := definedBy.LoadOrStore(id.String(), factory); dup {
+		panic("ber: RegisterDefinedBy of duplicate identifier " + id.String())
+	}
+}
+
+// definedByValue returns a new value for the field of the struct v whose type
+// is identified by the struct field name. If no matching type is registered
+// the returned value is invalid.
+func definedByValue(v, field reflect.Value, name string) reflect.Value {
+	id := v.FieldByName(name)
+	if id.Kind() == reflect.Pointer {
+		id = id.Elem()
+	}
+	oidType := reflect.TypeFor[asn1.ObjectIdentifier]()
+	if !id.IsValid() || !id.CanInterface() || !id.CanConvert(oidType) {
+		return reflect.Value{}
+	}
+	f, ok := definedBy.Load(id.Convert(oidType).Interface().(asn1.ObjectIdentifier).String())
+	if !ok {
+		return reflect.Value{}
+	}
+	x := reflect.ValueOf(f.(func() any)())
+	if !x.IsValid() || !x.Type().AssignableTo(field.Type()) {
+		return reflect.Value{}
+	}
+	nv := reflect.New(x.Type()).Elem()
+	nv.Set(x)
+	return nv
+}
@@ -11,8 +11,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"net/netip"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"codello.dev/asn1"
 	"codello.dev/asn1/internal"
@@ -57,6 +61,62 @@ type BerMatcher interface {
 
 //region error types
 
+// Sentinel errors that describe common categories of decoding errors. The
+// [SyntaxError] and [StructuralError] values returned by this package wrap
+// these errors where applicable, so that callers can test for them using
+// [errors.Is].
+var (
+	// ErrTagMismatch indicates that the tag of a data value encoding does not
+	// match the Go type it is decoded into. Decoding an optional struct field
+	// skips encodings with a mismatching tag.
+	ErrTagMismatch = errors.New("tag does not match")
+
+	// ErrTruncated indicates that the input ended before a data value encoding
+	// was complete. Errors wrapping ErrTruncated also match io.ErrUnexpectedEOF.
+	ErrTruncated = fmt.Errorf("truncated encoding: %w", io.ErrUnexpectedEOF)
+
+	// ErrExtraData indicates that the input contains more data than expected,
+	// for example after the top-level data value encoding or at the end of a
+	// non-extensible SEQUENCE.
+	ErrExtraData = errors.New("extra data")
+
+	// ErrRange indicates that a value violates a range constraint specified via
+	// the "range" struct tag.
+	ErrRange = internal.ErrRange
+
+	// ErrSize indicates that a value violates a size constraint specified via
+	// the "size" struct tag.
+	ErrSize = internal.ErrSize
+
+	// ErrLeapSecond is recorded as a warning if a time value with a leap second
+	// has been decoded. See [DecoderOptions.AllowLeapSeconds].
+	ErrLeapSecond = errors.New("leap second")
+
+	// ErrMaxDepth indicates that a data value is nested more deeply than
+	// permitted by [DecoderOptions.MaxDecodeDepth] and cannot be stored as a
+	// [RawValue].
+	ErrMaxDepth = errors.New("maximum decode depth exceeded")
+
+	// ErrIndefiniteLength indicates that a data value uses the
+	// indefinite-length encoding although it has been forbidden via
+	// [DecoderOptions.ForbidIndefiniteLength].
+	ErrIndefiniteLength = errors.New("indefinite-length encoding")
+
+	// ErrNonMinimalLength indicates that the length octets of a data value are
+	// not minimally encoded although this is required via
+	// [DecoderOptions.RequireMinimalLength].
+	ErrNonMinimalLength = errors.New("length not minimally encoded")
+
+	// ErrNonCanonicalBoolean indicates that the content octet of a BOOLEAN is
+	// neither 0x00 nor 0xFF although this is required via
+	// [DecoderOptions.RequireCanonicalBoolean].
+	ErrNonCanonicalBoolean = errors.New("non-canonical boolean")
+
+	// ErrValueTooLarge indicates that a data value encoding exceeds the
+	// maximum size of a [Feeder]. See [Feeder.SetMaxSize].
+	ErrValueTooLarge = errors.New("data value too large")
+)
+
 // InvalidDecodeError indicates that an invalid value was passed to an Unmarshal
 // or Decode function. The invalid value might be nested within the passed
 // value.
@@ -97,9 +157,17 @@ func (e *InvalidDecodeError) Error() string {
 //
 // For errors that are not directly related to the syntax of the BER byte
 // stream, [StructuralError] is a better fit.
+//
+// The Path and Offset locate the error within the decoded value. A path
+// consists of struct field names separated by dots and slice indices in
+// square brackets, e.g. "TBSCertificate.Extensions[2].Critical". The offset
+// counts bytes from the start of the input. If the offset is not known, it is
+// 0.
 type SyntaxError struct {
-	Tag asn1.Tag // where the syntax error occurred
-	Err error
+	Tag    asn1.Tag // where the syntax error occurred
+	Path   string   // path of struct fields and indices to the value (maybe empty)
+	Offset int64    // offset of the data value encoding containing the error
+	Err    error
 }
 
 func (e *SyntaxError) Error() string {
@@ -109,6 +177,14 @@ func (e *SyntaxError) Error() string {
 		s.WriteString(" decoding ")
 		s.WriteString(e.Tag.String())
 	}
+	if e.Path != "" {
+		s.WriteString(" in field ")
+		s.WriteString(e.Path)
+	}
+	if e.Offset > 0 {
+		s.WriteString(" at offset ")
+		s.WriteString(strconv.FormatInt(e.Offset, 10))
+	}
 	if e.Err != nil {
 		s.WriteString(": ")
 		s.WriteString(e.Err.Error())
@@ -121,13 +197,18 @@ func (e *SyntaxError) Unwrap() error {
 }
 
 // A StructuralError suggests that the ASN.1 data is valid, but the Go type
-// which is receiving it doesn't match or can't fit the data.
+// which is receiving it doesn't match or can't fit the data. This includes
+// values that violate the constraints specified via struct tags.
+//
+// The Path and Offset locate the error in the same way as for [SyntaxError].
 //
 // See also [SyntaxError].
 type StructuralError struct {
-	Tag  asn1.Tag
-	Type reflect.Type
-	Err  error
+	Tag    asn1.Tag
+	Type   reflect.Type
+	Path   string // path of struct fields and indices to the value (maybe empty)
+	Offset int64  // offset of the data value encoding containing the error
+	Err    error
 }
 
 func (e *StructuralError) Error() string {
@@ -144,6 +225,14 @@ func (e *StructuralError) Error() string {
 			s.WriteString(e.Type.String())
 		}
 	}
+	if e.Path != "" {
+		s.WriteString(" in field ")
+		s.WriteString(e.Path)
+	}
+	if e.Offset > 0 {
+		s.WriteString(" at offset ")
+		s.WriteString(strconv.FormatInt(e.Offset, 10))
+	}
 	if e.Err != nil {
 		s.WriteString(": ")
 		s.WriteString(e.Err.Error())
@@ -155,6 +244,50 @@ func (e *StructuralError) Unwrap() error {
 	return e.Err
 }
 
+// withPath prepends elem to the Path of the [SyntaxError], [StructuralError]
+// or [EncodeError] in err, if any. The elem is either the name of a struct
+// field or an index enclosed in square brackets.
+func withPath(err error, elem string) error {
+	var syn *SyntaxError
+	var se *StructuralError
+	var ee *EncodeError
+	if errors.As(err, &syn) {
+		syn.Path = joinPath(elem, syn.Path)
+	} else if errors.As(err, &se) {
+		se.Path = joinPath(elem, se.Path)
+	} else if errors.As(err, &ee) {
+		ee.Path = joinPath(elem, ee.Path)
+	}
+	return err
+}
+
+// withOffset sets the Offset of the [SyntaxError] or [StructuralError] in err
+// to off, unless a nested data value encoding has already set it. Because a
+// nested encoding always starts after its parent, only the outermost encoding
+// can have an offset of 0.
+func withOffset(err error, off int) error {
+	var syn *SyntaxError
+	var se *StructuralError
+	if errors.As(err, &syn) {
+		if syn.Offset == 0 {
+			syn.Offset = int64(off)
+		}
+	} else if errors.As(err, &se) {
+		if se.Offset == 0 {
+			se.Offset = int64(off)
+		}
+	}
+	return err
+}
+
+// joinPath joins elem and path as described in withPath.
+func joinPath(elem, path string) string {
+	if path == "" || path[0] == '[' {
+		return elem + path
+	}
+	return elem + "." + path
+}
+
 //endregion
 
 //region types Reader and reader
@@ -234,6 +367,39 @@ type reader struct {
 	// root indicates that Next() may return io.EOF when the underlying reader returns
 	// io.EOF at the start of a data value encoding.
 	root bool
+
+	// in counts the bytes read from the input. start is the offset of the
+	// identifier octets of r within the input or -1 if it is unknown.
+	in    *countingReader
+	start int
+
+	// src is set if the input is an in-memory byte slice. In that case start is
+	// also an index into src.b.
+	src *source
+
+	// opts are the options of the Decoder that created r and warnings are its
+	// recorded warnings. All readers of the same input share the same options
+	// and warnings.
+	opts     *DecoderOptions
+	warnings *[]error
+
+	// header records the identifier and length octets of r as they were read if
+	// the input is not a byte slice.
+	header recordingReader
+
+	// depth is the nesting level of r. Top-level data values have depth 1.
+	depth int
+
+	// hooks are the hooks of the Decoder that created r. The path of r is
+	// only tracked if there are hooks. hooked indicates that the hooks have
+	// been invoked for r. children counts the readers returned by Next.
+	hooks    *[]ValueHook
+	path     string
+	hooked   bool
+	children int
+
+	// stats are the statistics of the Decoder that created r.
+	stats *stats
 }
 
 // Constructed reports whether r is operating on a constructed or primitive
@@ -276,7 +442,7 @@ func (r *reader) Len() int {
 // discarded without validation when Next is called again.
 func (r *reader) Next() (h Header, er Reader, err error) {
 	if !r.Constructed() {
-		return Header{}, nil, &SyntaxError{r.H.Tag, errors.New("primitive encoding")}
+		return Header{}, nil, &SyntaxError{Tag: r.H.Tag, Err: errors.New("primitive encoding")}
 	}
 	// r.curr is only set if r.err == nil
 	if r.curr != nil {
@@ -288,13 +454,21 @@ func (r *reader) Next() (h Header, er Reader, err error) {
 	if r.err != nil {
 		return Header{}, nil, r.err
 	}
-	h, err = decodeHeader(r.R)
+	next := &reader{R: r.R, in: r.in, start: r.in.offset(), src: r.src, opts: r.opts, warnings: r.warnings, depth: r.depth + 1, hooks: r.hooks, stats: r.stats}
+	var minimal bool
+	if r.src == nil {
+		next.header = recordingReader{R: r.R}
+		next.header.B = next.header.buf[:0]
+		h, minimal, err = parseHeader(&next.header)
+	} else {
+		h, minimal, err = parseHeader(r.R)
+	}
 	if err != nil {
 		if err == io.EOF && r.H.Length == LengthIndefinite && !r.root {
 			err = io.ErrUnexpectedEOF
 		}
 		if err == io.ErrUnexpectedEOF {
-			err = &SyntaxError{r.H.Tag, fmt.Errorf("decoding child: %w", err)}
+			err = &SyntaxError{Tag: r.H.Tag, Err: fmt.Errorf("decoding child: %w", ErrTruncated)}
 		}
 		// Any error decoding the header is fatal: we might have read a partial header.
 		// We cannot know that the following bytes are the start of a new encoding.
@@ -304,17 +478,22 @@ func (r *reader) Next() (h Header, er Reader, err error) {
 		r.err = io.EOF
 		return Header{}, nil, r.err
 	} else if !h.Constructed && h.Length == LengthIndefinite {
-		r.err = &SyntaxError{r.H.Tag, fmt.Errorf("primitive encodoing %s has indefinite length", h.Tag.String())}
+		r.err = &SyntaxError{Tag: r.H.Tag, Err: fmt.Errorf("primitive encodoing %s has indefinite length", h.Tag.String())}
 		return Header{}, nil, r.err
 	}
 	// If we reach this point, the header is syntactically valid. All the following
 	// errors are non-fatal as we might be able to discard the encoding successfully.
 
 	if h == (Header{}) {
-		err = &SyntaxError{r.H.Tag, errors.New("unexpected end of contents")}
+		err = &SyntaxError{Tag: r.H.Tag, Err: errors.New("unexpected end of contents")}
 	} else if h.Tag == asn1.TagReserved && (h.Constructed || h.Length != 0) {
-		err = &SyntaxError{r.H.Tag, errors.New("encountered invalid end of contents")}
+		err = &SyntaxError{Tag: r.H.Tag, Err: errors.New("encountered invalid end of contents")}
+	} else if h.Length == LengthIndefinite && r.opts != nil && r.opts.ForbidIndefiniteLength {
+		err = &SyntaxError{Tag: r.H.Tag, Err: fmt.Errorf("encoding %s: %w", h.Tag.String(), ErrIndefiniteLength)}
+	} else if !minimal && r.opts != nil && r.opts.RequireMinimalLength {
+		err = &SyntaxError{Tag: r.H.Tag, Err: fmt.Errorf("encoding %s: %w", h.Tag.String(), ErrNonMinimalLength)}
 	}
+	next.H = h
 	lr := &limitReader{r.R, h.Length}
 	if h.Length == LengthIndefinite {
 		// This makes lr.Len() return a useful value. That way we can check if nested
@@ -325,9 +504,18 @@ func (r *reader) Next() (h Header, er Reader, err error) {
 		// We return the reader for the encoding as the content octets may still be
 		// useful. We do not adjust lr.Len() in order to trigger an ErrUnexpectedEOF
 		// when reading the encoding.
-		err = &SyntaxError{r.H.Tag, fmt.Errorf("encoding %s exceeds its parent", h.Tag.String())}
+		err = &SyntaxError{Tag: r.H.Tag, Err: fmt.Errorf("encoding %s exceeds its parent: %w", h.Tag.String(), ErrTruncated)}
+	}
+	next.R = lr
+	if r.hooks != nil && len(*r.hooks) > 0 && !r.root {
+		// callers may replace the index by a field name
+		next.path = r.path + "[" + strconv.Itoa(r.children) + "]"
 	}
-	r.curr = &reader{H: h, R: lr}
+	r.children++
+	if r.stats != nil {
+		r.stats.value(next.depth)
+	}
+	r.curr = next
 	return h, r.curr, err
 }
 
@@ -357,7 +545,7 @@ func (r *reader) Close() (err error) {
 	}
 	// FIXME: Maybe also check extensibilityImplied?
 	if extended {
-		return &SyntaxError{r.H.Tag, errors.New("extra data in non-extensible context")}
+		return &SyntaxError{Tag: r.H.Tag, Err: fmt.Errorf("%w in non-extensible context", ErrExtraData)}
 	}
 	return nil
 }
@@ -391,7 +579,7 @@ func (r *reader) discard() (err error) {
 // encoding, this method returns an error.
 func (r *reader) Read(p []byte) (n int, err error) {
 	if r.Constructed() {
-		return 0, &SyntaxError{r.H.Tag, errors.New("constructed encoding")}
+		return 0, &SyntaxError{Tag: r.H.Tag, Err: errors.New("constructed encoding")}
 	}
 	if r.err != nil {
 		return 0, r.err
@@ -403,7 +591,7 @@ func (r *reader) Read(p []byte) (n int, err error) {
 // constructed encoding, this method returns an error.
 func (r *reader) ReadByte() (byte, error) {
 	if r.Constructed() {
-		return 0, &SyntaxError{r.H.Tag, errors.New("constructed encoding")}
+		return 0, &SyntaxError{Tag: r.H.Tag, Err: errors.New("constructed encoding")}
 	}
 	if r.err != nil {
 		return 0, r.err
@@ -495,18 +683,7 @@ func (r *limitReader) Discard(n int) (discarded int, err error) {
 	if r.Limited() && r.Len() < discard {
 		discard = r.Len()
 	}
-	switch rd := r.R.(type) {
-	case interface{ Discard(int) (int, error) }: // implemented by *bufio.Reader
-		discarded, err = rd.Discard(discard)
-	case io.Seeker: // implemented by bytes.Reader
-		var d int64
-		d, err = rd.Seek(int64(discard), io.SeekCurrent)
-		discarded = int(d)
-	default:
-		var d int64
-		d, err = io.CopyN(io.Discard, rd, int64(discard))
-		discarded = int(d)
-	}
+	discarded, err = discardN(r.R, discard)
 	if r.Len() == LengthIndefinite {
 		return discard, err
 	}
@@ -521,6 +698,119 @@ func (r *limitReader) Discard(n int) (discarded int, err error) {
 
 //endregion
 
+// discardN discards n bytes from r. It returns the number of bytes discarded.
+// If r implements either its own Discard method or the io.Seeker interface,
+// these methods will be used for more efficient discarding.
+func discardN(r io.Reader, n int) (discarded int, err error) {
+	switch rd := r.(type) {
+	case interface{ Discard(int) (int, error) }: // implemented by *bufio.Reader
+		return rd.Discard(n)
+	case io.Seeker: // implemented by bytes.Reader
+		var d int64
+		d, err = rd.Seek(int64(n), io.SeekCurrent)
+		return int(d), err
+	default:
+		var d int64
+		d, err = io.CopyN(io.Discard, rd, int64(n))
+		return int(d), err
+	}
+}
+
+//region type countingReader
+
+// countingReader counts the number of bytes read from R in N. The counter is
+// shared by all readers of a [Decoder] so that their offsets within the input
+// can be determined.
+type countingReader struct {
+	R io.Reader
+	N *int64
+}
+
+// offset returns the number of bytes read from r. If r is nil, -1 is returned.
+func (r *countingReader) offset() int {
+	if r == nil {
+		return -1
+	}
+	return int(*r.N)
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.R.Read(p)
+	*r.N += int64(n)
+	return n, err
+}
+
+func (r *countingReader) ReadByte() (b byte, err error) {
+	if br, ok := r.R.(io.ByteReader); ok {
+		b, err = br.ReadByte()
+	} else {
+		var bs [1]byte
+		_, err = io.ReadFull(r.R, bs[:])
+		b = bs[0]
+	}
+	if err == nil {
+		*r.N++
+	}
+	return b, err
+}
+
+// Discard discards n bytes from r.
+func (r *countingReader) Discard(n int) (discarded int, err error) {
+	if s, ok := r.R.(io.Seeker); ok {
+		// Seek returns the new absolute offset
+		var start, end int64
+		if start, err = s.Seek(0, io.SeekCurrent); err == nil {
+			end, err = s.Seek(int64(n), io.SeekCurrent)
+			discarded = int(end - start)
+		}
+	} else {
+		discarded, err = discardN(r.R, n)
+	}
+	*r.N += int64(discarded)
+	return discarded, err
+}
+
+//endregion
+
+//region type recordingReader
+
+// recordingReader reads single bytes from R and records them in B.
+type recordingReader struct {
+	R   *limitReader
+	B   []byte
+	buf [12]byte // initial storage for B
+}
+
+func (r *recordingReader) ReadByte() (byte, error) {
+	b, err := r.R.ReadByte()
+	if err == nil {
+		r.B = append(r.B, b)
+	}
+	return b, err
+}
+
+//endregion
+
+//region type source
+
+// source gives access to the input of a [Decoder] reading from a byte slice.
+// Decoders use it to reference parts of the input without copying.
+type source struct {
+	b []byte
+	r *bytes.Reader // reads from b
+}
+
+// offset returns the current read offset within s.b. If s is nil, -1 is
+// returned.
+func (s *source) offset() int {
+	if s == nil {
+		return -1
+	}
+	return len(s.b) - s.r.Len()
+}
+
+//endregion
+
 //region type bufferedReader
 
 // bufferedReader wraps a [*bufio.Reader] together with another io.Reader in a
@@ -587,15 +877,20 @@ type explicitDecoder codec[BerDecoder]
 func (d *explicitDecoder) BerDecode(tag asn1.Tag, r Reader) (err error) {
 	if r.Len() == 0 {
 		if _, ok := d.val.(flagCodec); !ok {
-			return &StructuralError{tag, d.ref.Type(), errors.New("zero length explicit tag was not a asn1.Flag")}
+			return &StructuralError{Tag: tag, Type: d.ref.Type(), Err: errors.New("zero length explicit tag was not a asn1.Flag")}
 		}
 	} else if !r.Constructed() {
-		return &SyntaxError{tag, errors.New("non-constructed encoding for explicit type")}
+		return &SyntaxError{Tag: tag, Err: errors.New("non-constructed encoding for explicit type")}
 	}
 	h, er, err := r.Next()
 	if err != nil {
 		return err
 	}
+	// the explicitly tagged data value has the same path as its contents
+	setPath(er, r, "", -1)
+	if err = runHooks(er); err != nil {
+		return &StructuralError{Tag: h.Tag, Type: d.ref.Type(), Err: err}
+	}
 	if err = d.val.BerDecode(h.Tag, er); err != nil {
 		return err
 	}
@@ -604,7 +899,7 @@ func (d *explicitDecoder) BerDecode(tag asn1.Tag, r Reader) (err error) {
 	}
 	_, _, err = r.Next()
 	if err == nil {
-		return &SyntaxError{tag, errors.New("explicit type has multiple components")}
+		return &SyntaxError{Tag: tag, Err: errors.New("explicit type has multiple components")}
 	}
 	if err != io.EOF {
 		return err
@@ -659,7 +954,9 @@ func (d sequenceDecoder) BerDecode(tag asn1.Tag, r Reader) (err error) {
 		}
 		// allocate a new addressable zero value
 		vp := reflect.New(elemType)
+		setPath(er, r, "", i)
 		if err = decodeValue(h.Tag, er, vp.Elem(), params); err != nil {
+			err = withPath(err, "["+strconv.Itoa(i)+"]")
 			break
 		}
 		err = er.Close()
@@ -682,10 +979,10 @@ func (d sequenceDecoder) BerDecode(tag asn1.Tag, r Reader) (err error) {
 	}
 	i-- // the last EOF does not correspond to another value
 	if i > d.ref.Len() {
-		return &StructuralError{tag, d.ref.Type(), errors.New("too many values")}
+		return &StructuralError{Tag: tag, Type: d.ref.Type(), Err: fmt.Errorf("%w: too many values", ErrExtraData)}
 	}
 	if d.ref.Kind() == reflect.Array && i < d.ref.Len() {
-		return &StructuralError{tag, d.ref.Type(), errors.New("not enough values")}
+		return &StructuralError{Tag: tag, Type: d.ref.Type(), Err: errors.New("not enough values")}
 	}
 	return nil
 }
@@ -710,14 +1007,22 @@ func (d structDecoder) BerMatch(tag asn1.Tag) bool {
 // BerDecode decodes the BER-encoded data from r into the underlying struct of
 // d. Anonymous fields without struct tags are processed recursively.
 func (d structDecoder) BerDecode(tag asn1.Tag, r Reader) error {
+	presence := internal.PresenceOf(d.ref)
+	if presence != nil {
+		presence.Reset()
+	}
 	h, er, err := r.Next()
 	for field, params := range internal.StructFields(d.ref) {
 		if err != nil {
 			if err != io.EOF {
 				return err
 			}
-			if !params.Optional {
-				return &StructuralError{tag, d.ref.Type(), errors.New("not enough values")}
+			if field.Type() == internal.ExtensibleDataType {
+				field.SetZero()
+			} else if params.Optional {
+				internal.SetAbsent(field)
+			} else if !params.Rest && field.Type() != internal.ExtensibleType {
+				return &StructuralError{Tag: tag, Type: d.ref.Type(), Err: errors.New("not enough values")}
 			}
 			continue
 		}
@@ -731,18 +1036,58 @@ func (d structDecoder) BerDecode(tag asn1.Tag, r Reader) error {
 			}
 			continue
 		}
-		if err = decodeValue(h.Tag, er, field, params); err == nil {
+		if field.Type() == internal.ExtensibleDataType {
+			// retain all remaining data value encodings
+			var exts [][]byte
+			for err == nil {
+				var rv RawElement
+				if err = decodeValue(h.Tag, er, reflect.ValueOf(&rv).Elem(), internal.FieldParameters{}); err == nil {
+					err = er.Close()
+				}
+				if err != nil {
+					return err
+				}
+				exts = append(exts, bytes.Clone(rv.FullBytes))
+				h, er, err = r.Next()
+			}
+			field.Set(reflect.ValueOf(asn1.ExtensibleData{Extensions: exts}))
+			continue
+		}
+		if params.Rest {
+			if err = decodeRest(h, er, r, field, params.Field); err != io.EOF {
+				return withPath(err, params.Field)
+			}
+			if presence != nil {
+				presence.SetPresent(params.Field, true)
+			}
+			continue
+		}
+		target := field
+		if params.DefinedBy != "" {
+			if nv := definedByValue(d.ref, field, params.DefinedBy); nv.IsValid() {
+				target = nv
+			}
+		}
+		setPath(er, r, params.Field, -1)
+		if err = decodeValue(h.Tag, er, target, params); err == nil {
+			if target != field {
+				field.Set(target)
+			}
 			if err = er.Close(); err == nil {
+				if presence != nil {
+					presence.SetPresent(params.Field, true)
+				}
 				h, er, err = r.Next()
 				continue
 			}
 			return err
 		}
-		if errors.Is(err, errTagMismatch) && params.Optional {
+		if errors.Is(err, ErrTagMismatch) && params.Optional {
+			internal.SetAbsent(field)
 			err = nil
 			continue
 		}
-		return err
+		return withPath(err, params.Field)
 	}
 
 	hasExtra := false
@@ -760,36 +1105,73 @@ func (d structDecoder) BerDecode(tag asn1.Tag, r Reader) error {
 		return err
 	}
 	if hasExtra {
-		return &StructuralError{tag, d.ref.Type(), errors.New("too many values")}
+		return &StructuralError{Tag: tag, Type: d.ref.Type(), Err: fmt.Errorf("%w: too many values", ErrExtraData)}
+	}
+	if err = internal.Validate(d.ref); err != nil {
+		return &StructuralError{Tag: tag, Type: d.ref.Type(), Err: err}
 	}
 	return nil
 }
 
+// decodeRest decodes the data value with header h read from er as well as all
+// remaining data values read from r into new elements appended to the slice v,
+// the value of the struct field with the given name. This implements the
+// "rest" struct tag. If all data values have been decoded successfully, io.EOF
+// is returned.
+func decodeRest(h Header, er Reader, r Reader, v reflect.Value, field string) (err error) {
+	if v.Kind() != reflect.Slice {
+		return &InvalidDecodeError{Value: v}
+	}
+	v.SetLen(0)
+	for i := 0; err == nil; i++ {
+		v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		setPath(er, r, field, i)
+		if err = decodeValue(h.Tag, er, v.Index(i), internal.FieldParameters{}); err == nil {
+			err = er.Close()
+		}
+		if err != nil {
+			return withPath(err, "["+strconv.Itoa(i)+"]")
+		}
+		h, er, err = r.Next()
+	}
+	return err
+}
+
 //endregion
 
 //region decoderConfig and decoder selection
 
-// errTagMismatch is a sentinel error returned by decodeValue that indicates that
-// the field type did not match the provided header. This error is used to
-// implement optional types.
-var errTagMismatch = errors.New("tag does not match")
-
 // decodeValue is the main decoding function. It finds a BerDecoder for v using
 // the makeDecoder function and then invokes its BerDecode method. Any error
-// that occurs is returned. If the BerDecoder returns io.ErrUnexpectedEOF after
-// reading all its bytes, the error is replaced by a SyntaxError.
+// that occurs is returned. If the BerDecoder returns io.ErrUnexpectedEOF, or
+// an error wrapping it after reading all its bytes, the error is replaced by a
+// SyntaxError wrapping ErrTruncated.
 //
 // If it is determined that v does not match the header h, an error wrapping
-// errTagMismatch is returned. If no decoder is available for v, decodeValue
+// ErrTagMismatch is returned. If no decoder is available for v, decodeValue
 // returns an InvalidDecodeError.
 func decodeValue(tag asn1.Tag, r Reader, v reflect.Value, params internal.FieldParameters) error {
-	dec, err := makeDecoder(tag, v, params)
+	var dec BerDecoder
+	var err error
+	if opts := optionsOf(r); opts != nil && opts.MaxDecodeDepth > 0 && depthOf(r) > opts.MaxDecodeDepth && !params.Explicit {
+		dec, err = makeRawDecoder(tag, v, params)
+	} else {
+		dec, err = makeDecoder(tag, v, params)
+	}
 	if err != nil {
 		return err
 	}
-	err = dec.BerDecode(tag, r)
-	if errors.Is(err, io.ErrUnexpectedEOF) && r.Len() == 0 {
-		err = &SyntaxError{tag, errors.New("not enough bytes")}
+	if err = runHooks(r); err != nil {
+		err = &StructuralError{Tag: tag, Type: v.Type(), Err: err}
+	} else {
+		err = dec.BerDecode(tag, r)
+	}
+	if err == nil {
+		if err = internal.CheckConstraints(v, params); err != nil {
+			err = &StructuralError{Tag: tag, Type: v.Type(), Err: err}
+		}
+	} else if err == io.ErrUnexpectedEOF || (r.Len() == 0 && errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, ErrTruncated)) {
+		err = &SyntaxError{Tag: tag, Err: ErrTruncated}
 	} else if err == io.EOF {
 		// Semantically io.EOF does not really make sense. We assume that
 		// dec.BerDecode() returned an error from the underlying reader without properly
@@ -797,24 +1179,69 @@ func decodeValue(tag asn1.Tag, r Reader, v reflect.Value, params internal.FieldP
 		// treat this as a success value.
 		err = nil
 	}
+	if er, ok := r.(*reader); ok && err != nil && er.start >= 0 {
+		err = withOffset(err, er.start)
+	}
 	return err
 }
 
+// makeRawDecoder returns a decoder that stores a data value as a [RawValue] in
+// v. It is used for data values exceeding [DecoderOptions.MaxDecodeDepth]. If v
+// cannot hold a RawValue, an error wrapping [ErrMaxDepth] is returned.
+func makeRawDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters) (BerDecoder, error) {
+	if params.Tag != 0 && tag != params.Tag {
+		return nil, &StructuralError{Tag: tag, Type: v.Type(), Err: fmt.Errorf("explicit encoding %s: %w", params.Tag.String(), ErrTagMismatch)}
+	}
+	rawValueType := reflect.TypeFor[RawValue]()
+	if v.Kind() == reflect.Pointer && v.Type().Elem() == rawValueType {
+		if v.IsNil() {
+			v.Set(reflect.New(rawValueType))
+		}
+		v = v.Elem()
+	}
+	if v.Type() == rawValueType || v.Kind() == reflect.Interface && rawValueType.Implements(v.Type()) {
+		return rawValueCodec{ref: v}, nil
+	}
+	return nil, &StructuralError{Tag: tag, Type: v.Type(), Err: ErrMaxDepth}
+}
+
 // makeDecoder walks down v allocating pointers as needed, until it gets to a
 // non-pointer. If it encounters a type that implements [BerDecoder] or
 // [encoding.BinaryUnmarshaler], makeDecoder stops and returns that. If params
 // indicate an explicit tag that differs from h or if the decoder for type v
 // implements [BerMatcher] and does not match h, an error wrapping
-// errTagMismatch is returned. If no decoder is available for v, makeDecoder
+// ErrTagMismatch is returned. If no decoder is available for v, makeDecoder
 // returns an InvalidDecodeError.
 func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters) (ret BerDecoder, err error) {
+	if params.Any && params.Tag == 0 {
+		// an ANY type accepts data values with any tag
+		params.Tag = tag
+	}
+	params = applyType(v.Type(), params)
 	if params.Nullable && tag == asn1.TagNull {
 		return nullCodec{ref: v}, nil
 	}
+	if internal.IsNullable(v.Type()) {
+		if tag == asn1.TagNull {
+			return nullCodec{ref: v}, nil
+		}
+		dec, err := makeDecoder(tag, v.Field(0), params)
+		if err != nil {
+			return nil, err
+		}
+		return flagDecoder{dec, v.Field(1)}, nil
+	}
+	if internal.IsOptional(v.Type()) {
+		dec, err := makeDecoder(tag, v.Field(0), params)
+		if err != nil {
+			return nil, err
+		}
+		return flagDecoder{dec, v.Field(1)}, nil
+	}
 
 	// we have an explicitly set tag. ignore the intrinsic type match
 	if params.Tag != 0 && tag != params.Tag {
-		return nil, &StructuralError{tag, v.Type(), fmt.Errorf("explicit encoding %s: %w", params.Tag.String(), errTagMismatch)}
+		return nil, &StructuralError{Tag: tag, Type: v.Type(), Err: fmt.Errorf("explicit encoding %s: %w", params.Tag.String(), ErrTagMismatch)}
 	}
 
 	// if we encounter a (potentially nested) nil pointer we store it in field and
@@ -831,7 +1258,7 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 		if params.Tag == 0 && v.Kind() != reflect.Interface {
 			if m, ok := ret.(BerMatcher); ok && !m.BerMatch(tag) {
 				ret = nil
-				err = &StructuralError{tag, v.Type(), errTagMismatch}
+				err = &StructuralError{Tag: tag, Type: v.Type(), Err: ErrTagMismatch}
 				return
 			}
 		}
@@ -843,6 +1270,16 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 		}
 	}()
 
+	// interface types with registered choices select their concrete type by tag
+	if v.Kind() == reflect.Interface {
+		if d, ok := choiceDecoderFor(v); ok {
+			if params.Tag == 0 && !d.BerMatch(tag) {
+				return nil, &StructuralError{Tag: tag, Type: v.Type(), Err: ErrTagMismatch}
+			}
+			return d, nil
+		}
+	}
+
 	// Issue #24153 indicates that it is generally not a guaranteed property
 	// that you may round-trip a reflect.Value by calling Value.Addr().Elem()
 	// and expect the value to still be settable for values derived from
@@ -868,7 +1305,7 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 			if v.IsNil() {
 				if v.NumMethod() == 0 {
 					// v has type interface{}
-					return codecFor(v, nil, tag), nil
+					return codecFor(v, nil, tag, nil), nil
 				}
 			} else if e := v.Elem(); e.Kind() == reflect.Pointer && !e.IsNil() {
 				// Load value from interface, but only if the result will be usefully
@@ -886,7 +1323,7 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 		// In this case we pretend the value was set to nil and continue.
 		if v.Elem().Kind() == reflect.Interface && v.Elem().Elem() == v {
 			v = v.Elem()
-			return codecFor(v, nil, tag), nil
+			return codecFor(v, nil, tag, nil), nil
 		}
 		if v.IsNil() {
 			// Allocate a value for the pointer so that we can invoke methods. We do not set
@@ -899,9 +1336,21 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 				v = fieldValue
 			}
 		}
+		if dec := registeredDecoder(v.Type().Elem()); dec != nil {
+			if haveAddr {
+				return dec(v0), nil
+			}
+			return dec(v.Elem()), nil
+		}
+		if u, ok := v.Interface().(encoding.TextUnmarshaler); ok && params.Text {
+			return textUnmarshalerCodec{v, u}, nil
+		}
 		switch vv := v.Interface().(type) {
 		case BerDecoder:
 			return vv, nil
+		case *time.Time, *netip.Addr, *netip.Prefix:
+			// These types implement encoding.BinaryUnmarshaler but are handled
+			// by codecFor.
 		case encoding.BinaryUnmarshaler:
 			return binaryUnmarshalerCodec{v, vv}, nil
 		}
@@ -914,6 +1363,25 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 		}
 	}
 
+	if internal.IsNullable(v.Type()) || internal.IsOptional(v.Type()) {
+		// v has been reached through pointers. The recursive call handles the
+		// explicit tag, so it must not be applied again by the deferred function.
+		inner := params
+		params.Explicit = false
+		return makeDecoder(tag, v, inner)
+	}
+	if params.Choice && v.Kind() == reflect.Struct {
+		if params.Tag != 0 && !params.Explicit {
+			return nil, &StructuralError{Tag: tag, Type: v.Type(), Err: errImplicitChoice}
+		}
+		return choiceStructDecoder{v}, nil
+	}
+	if dec := registeredDecoder(v.Type()); dec != nil {
+		return dec(v), nil
+	}
+	if params.Enum != "" && v.Kind() == reflect.String {
+		return enumStringCodec{v, params.Enum}, nil
+	}
 	vif := v.Interface()
 	// handle value types that implement these interfaces and known Go types
 	switch vv := vif.(type) {
@@ -922,7 +1390,7 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 	case encoding.BinaryUnmarshaler:
 		return binaryUnmarshalerCodec{v, vv}, nil
 	}
-	dec := codecFor(v, vif, params.Tag)
+	dec := codecFor(v, vif, params.Tag, nil)
 	if dec != nil {
 		return dec, nil
 	}
@@ -941,6 +1409,115 @@ func makeDecoder(tag asn1.Tag, v reflect.Value, params internal.FieldParameters)
 
 //region type Decoder
 
+// DecoderOptions configure the behavior of a [Decoder]. The zero value
+// represents the default options.
+type DecoderOptions struct {
+	// UTCTimePivot is the first year of the century into which the two-digit
+	// years of UTCTime values are mapped. If UTCTimePivot is 0, the years 1950
+	// through 2049 are used as specified in RFC 5280.
+	UTCTimePivot int
+
+	// DisableUTCTime causes decoding of UTCTime values to fail. This can be used
+	// for protocols that require GeneralizedTime values.
+	DisableUTCTime bool
+
+	// AllowLeapSeconds permits a seconds value of 60 in UTCTime and
+	// GeneralizedTime values. Because leap seconds cannot be represented by
+	// [time.Time], such values are clamped to 59.999999 seconds and a warning
+	// wrapping [ErrLeapSecond] is recorded. See [Decoder.Warnings].
+	AllowLeapSeconds bool
+
+	// MaxDecodeDepth limits the nesting depth of data values that are decoded
+	// into Go values. Top-level data values have depth 1. Data values nested
+	// more deeply are not decoded. Instead, the entire data value is stored as
+	// a [RawValue] that can be decoded on demand. This enables fast inspection
+	// of the outer layers of large encodings. The Go value receiving such a
+	// data value must be of type RawValue, *RawValue or an interface type
+	// implemented by RawValue, such as any. Otherwise, decoding fails with an
+	// error wrapping [ErrMaxDepth]. A value of 0 disables the limit.
+	MaxDecodeDepth int
+
+	// ForbidIndefiniteLength causes data values using the indefinite-length
+	// encoding to be rejected with a [SyntaxError] wrapping
+	// [ErrIndefiniteLength]. This is useful for consumers of DER-encoded data,
+	// such as certificates or signatures, that must reject encodings that are
+	// not valid DER. The check is applied when the header of a data value is
+	// read, regardless of the Go value it is decoded into.
+	ForbidIndefiniteLength bool
+
+	// RequireMinimalLength causes data values whose length octets are not
+	// minimally encoded to be rejected with a [SyntaxError] wrapping
+	// [ErrNonMinimalLength]. Length octets are not minimal if the long form is
+	// used for a length that fits into the short form or if they contain
+	// leading zero octets. Like ForbidIndefiniteLength, this enforces a
+	// requirement of DER while reading the input.
+	RequireMinimalLength bool
+
+	// RequireCanonicalBoolean causes BOOLEAN values whose content octet is
+	// neither 0x00 nor 0xFF to be rejected with a [SyntaxError] wrapping
+	// [ErrNonCanonicalBoolean], as required by DER and CER. By default, any
+	// non-zero content octet is decoded as true.
+	RequireCanonicalBoolean bool
+
+	// ValidateIntegers causes INTEGER and ENUMERATED values decoded into Go
+	// integer types implementing [asn1.Validator] to be validated. This allows
+	// types such as
+	//
+	//	type Port uint16
+	//
+	//	func (p Port) ValidateASN1() error {
+	//		if p == 0 {
+	//			return errors.New("port 0 is reserved")
+	//		}
+	//		return nil
+	//	}
+	//
+	// to enforce their invariants in the same way as struct types. A non-nil
+	// error is returned as a [StructuralError]. By default, only the IsValid()
+	// bool method of integer types is consulted.
+	ValidateIntegers bool
+}
+
+// utcTimeYear maps the two-digit year of a UTCTime value into the century
+// starting at o.UTCTimePivot.
+func (o *DecoderOptions) utcTimeYear(year int) int {
+	pivot := 1950
+	if o != nil && o.UTCTimePivot != 0 {
+		pivot = o.UTCTimePivot
+	}
+	year += pivot - pivot%100
+	if year < pivot {
+		year += 100
+	}
+	return year
+}
+
+// warn records err as a warning of the [Decoder] that created r. If r was not
+// created by a [Decoder], err is discarded.
+func warn(r Reader, err error) {
+	if er, ok := r.(*reader); ok && er.warnings != nil {
+		*er.warnings = append(*er.warnings, withOffset(err, er.start))
+	}
+}
+
+// depthOf returns the nesting level of r. If r was not created by a
+// [Decoder], 0 is returned.
+func depthOf(r Reader) int {
+	if er, ok := r.(*reader); ok {
+		return er.depth
+	}
+	return 0
+}
+
+// optionsOf returns the options of the [Decoder] that created r. If r was not
+// created by a [Decoder], nil is returned.
+func optionsOf(r Reader) *DecoderOptions {
+	if er, ok := r.(*reader); ok {
+		return er.opts
+	}
+	return nil
+}
+
 // Decoder implements stream-based decoding of BER-encoded ASN.1 types. The
 // Decoder type implements specialized buffering for BER-data. See the
 // [NewDecoder] function for details.
@@ -956,6 +1533,26 @@ type Decoder struct {
 	// lr limits buf so that it does not exceed
 	// the current data value encoding.
 	lr *limitReader
+
+	opts     DecoderOptions
+	warnings []error
+	hooks    []ValueHook
+	stats    stats
+
+	// tokens are the constructed encodings started by Token.
+	tokens []openToken
+
+	// peeked holds the result of the last call to PeekHeader. It is returned
+	// by the next call to Next.
+	peeked *peekedValue
+}
+
+// peekedValue is the result of a call to [Decoder.Next] that has been made by
+// [Decoder.PeekHeader].
+type peekedValue struct {
+	h   Header
+	r   Reader
+	err error
 }
 
 // NewDecoder creates a new [Decoder] reading from r.
@@ -978,22 +1575,45 @@ func NewDecoder(r io.Reader) (d *Decoder) {
 	if er, ok := r.(Reader); ok && er.Constructed() {
 		return &Decoder{r: er}
 	}
+	cr := &countingReader{R: r, N: new(int64)}
 	er := &reader{
 		H:    Header{Constructed: true, Length: LengthIndefinite},
-		R:    &limitReader{r, LengthIndefinite},
+		R:    &limitReader{cr, LengthIndefinite},
 		root: true,
+		in:   cr,
 	}
 	d = &Decoder{r: er}
+	er.opts = &d.opts
+	er.warnings = &d.warnings
+	er.hooks = &d.hooks
+	er.stats = &d.stats
 	// if the underlying reader is an io.ByteReader we assume that it is efficient
 	// enough so we don't need to add buffering
 	if _, ok := r.(io.ByteReader); !ok {
 		d.lr = &limitReader{r, LengthIndefinite}
 		d.buf = bufio.NewReaderSize(d.lr, 512)
-		er.R.R = &bufferedReader{d.buf, r}
+		cr.R = &bufferedReader{d.buf, r}
 	}
 	return d
 }
 
+// SetOptions configures d for subsequent calls to [Decoder.Next],
+// [Decoder.Decode] and related methods. If d reads from a [Reader] passed to
+// [NewDecoder], the options have no effect.
+func (d *Decoder) SetOptions(opts DecoderOptions) {
+	d.opts = opts
+}
+
+// Warnings returns the warnings recorded by d since the last call to Warnings.
+// Warnings indicate deviations from the encoding rules that d tolerated because
+// of its options. A warning is usually a [*SyntaxError] wrapping a sentinel
+// error such as [ErrLeapSecond].
+func (d *Decoder) Warnings() []error {
+	w := d.warnings
+	d.warnings = nil
+	return w
+}
+
 // More indicates whether there might be more data values in d that can be decoded.
 //
 // If d encounters a syntactically invalid data value encoding, d tries to
@@ -1008,6 +1628,9 @@ func NewDecoder(r io.Reader) (d *Decoder) {
 //
 // After d.Next() has returned an io.EOF error, this method will return false.
 func (d *Decoder) More() bool {
+	if d.peeked != nil && d.peeked.err == nil {
+		return true
+	}
 	return d.r.More()
 }
 
@@ -1021,6 +1644,48 @@ func (d *Decoder) More() bool {
 //
 // If no more values are available, io.EOF is returned.
 func (d *Decoder) Next() (Header, Reader, error) {
+	if p := d.peeked; p != nil {
+		d.peeked = nil
+		return p.h, p.r, p.err
+	}
+	return d.next()
+}
+
+// PeekHeader returns the header of the next data value encoding in d without
+// advancing d. The header contains the tag and the declared length of the
+// data value, or [LengthIndefinite]. This makes it possible to enforce limits
+// on the size of a data value or to choose a Go type based on its tag before
+// decoding it:
+//
+//	h, err := d.PeekHeader()
+//	if err != nil {
+//		return err
+//	}
+//	if h.Length == ber.LengthIndefinite || h.Length > maxSize {
+//		return errMessageTooLarge
+//	}
+//	switch h.Tag {
+//	case tagRequest:
+//		err = d.Decode(&req)
+//	// ...
+//	}
+//
+// The identifier and length octets are read from the underlying reader of d,
+// but they are not consumed: The next call to [Decoder.Next], [Decoder.Decode]
+// or a related method processes the peeked data value. Calling PeekHeader
+// multiple times returns the same header. If reading the header fails, the
+// error is returned by PeekHeader as well as by the next call to Next.
+func (d *Decoder) PeekHeader() (Header, error) {
+	if d.peeked == nil {
+		h, er, err := d.next()
+		d.peeked = &peekedValue{h, er, err}
+	}
+	return d.peeked.h, d.peeked.err
+}
+
+// next implements [Decoder.Next] without considering a peeked header.
+func (d *Decoder) next() (Header, Reader, error) {
+	d.updateBytes()
 	h, er, err := d.r.Next()
 	if er != nil && d.buf != nil {
 		//goland:noinspection GoDfaErrorMayBeNotNil
@@ -1041,7 +1706,8 @@ func (d *Decoder) Next() (Header, Reader, error) {
 			// d.buf might have read to EOF of the d.lr so we need to reset
 			d.buf.Reset(d.lr)
 		}
-		er.(*reader).R.R = d.buf
+		// keep counting the bytes read by the returned Reader
+		er.(*reader).R.R = &countingReader{d.buf, er.(*reader).in.N}
 	}
 	return h, er, err
 }
@@ -1058,7 +1724,8 @@ func (d *Decoder) Decode(val any) error {
 // parameters applied to the top-level data value encoding. The format for
 // params is the same as for struct tags supported by this package. Using the
 // `asn1:"optional"` or `asn1:"-"` options has no effect here.
-func (d *Decoder) DecodeWithParams(val any, params string) error {
+func (d *Decoder) DecodeWithParams(val any, params string) (err error) {
+	defer func() { d.record(err) }()
 	fp := internal.ParseFieldParameters(params)
 	v := reflect.ValueOf(val)
 	if v.Kind() != reflect.Pointer || v.IsNil() {
@@ -1086,11 +1753,151 @@ func (d *Decoder) DecodeAll(val any) error {
 	if v.Kind() != reflect.Pointer || v.IsNil() {
 		return &InvalidDecodeError{Value: v}
 	}
-	return decodeValue(asn1.TagSequence, &decoderReader{d}, v.Elem(), internal.FieldParameters{})
+	err := decodeValue(asn1.TagSequence, &decoderReader{d}, v.Elem(), internal.FieldParameters{})
+	d.record(err)
+	return err
 }
 
 //endregion
 
+// DecodeSeq returns an iterator over the elements of the constructed data value
+// read by r. Each element is decoded into a new value of type T as described in
+// [Decoder.Decode]. Elements are decoded one at a time, so that a large SEQUENCE
+// OF or SET OF does not need to be held in memory at once. Usually r is
+// obtained from [Decoder.Next] or [Reader.Next]:
+//
+//	_, r, err := d.Next()
+//	if err != nil {
+//		return err
+//	}
+//	for entry, err := range ber.DecodeSeq[Entry](r) {
+//		if err != nil {
+//			return err
+//		}
+//		// process entry
+//	}
+//
+// If an error occurs, it is yielded together with the zero value of T and
+// iteration stops. If iteration stops early, the remaining elements are
+// discarded when r is closed or its parent advances.
+func DecodeSeq[T any](r Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if !r.Constructed() {
+			yield(zero, &SyntaxError{Err: errors.New("primitive encoding")})
+			return
+		}
+		for i := 0; ; i++ {
+			h, er, err := r.Next()
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(zero, err)
+				return
+			}
+			var val T
+			if err = decodeValue(h.Tag, er, reflect.ValueOf(&val).Elem(), internal.FieldParameters{}); err == nil {
+				err = er.Close()
+			}
+			if err != nil {
+				yield(zero, withPath(err, "["+strconv.Itoa(i)+"]"))
+				return
+			}
+			if !yield(val, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DecodeStream returns an iterator over the top-level data values read from r.
+// Each data value is decoded into a new value of type T as described in
+// [Decoder.Decode] until r returns io.EOF. This is useful for consuming
+// protocols that exchange a stream of messages, such as LDAP:
+//
+//	for msg, err := range ber.DecodeStream[LDAPMessage](conn) {
+//		if err != nil {
+//			return err
+//		}
+//		// process msg
+//	}
+//
+// The data values are read using a [Decoder], see [NewDecoder] for details on
+// buffering. If an error occurs, it is yielded together with the zero value of
+// T and iteration stops. The iterator is single-use.
+func DecodeStream[T any](r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		d := NewDecoder(r)
+		for {
+			var val T
+			err := d.Decode(&val)
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(val, nil) {
+				return
+			}
+		}
+	}
+}
+
+// NewReader returns a [Reader] for the content octets of a data value with
+// header h. The content octets are read from r, which must not contain the
+// identifier and length octets of the data value. If h uses the constructed
+// encoding, r is parsed as the BER-encodings of the components.
+//
+// NewReader can be used together with [DecodeElement] to decode data values
+// that have been framed by other means, for example by a [tlv.Decoder] or by
+// a custom protocol. Offsets reported in errors of the returned Reader are
+// relative to the start of the content octets.
+func NewReader(h Header, r io.Reader) Reader {
+	cr := &countingReader{R: r, N: new(int64)}
+	return &reader{
+		H:     h,
+		R:     &limitReader{cr, h.Length},
+		in:    cr,
+		start: -1,
+		depth: 1,
+	}
+}
+
+// DecodeElement decodes the data value with header h and content octets read
+// by r into the value pointed to by val. The format for params is the same as
+// for [Decoder.DecodeWithParams]. See [Decoder.Decode] for details on the
+// decoding process. If decoding succeeds, r is closed, validating the syntax
+// of any unread bytes.
+//
+// DecodeElement makes the codecs of this package available to callers that do
+// their own framing and do not use a [Decoder]. Usually r is obtained from
+// [Reader.Next] or [NewReader]:
+//
+//	th, content, err := d.ReadHeader() // d is a *tlv.Decoder
+//	if err != nil {
+//		return err
+//	}
+//	h := ber.Header{Tag: th.Tag, Length: th.Length, Constructed: th.Constructed}
+//	var n int
+//	err = ber.DecodeElement(h, ber.NewReader(h, content), &n, "")
+func DecodeElement(h Header, r Reader, val any, params string) error {
+	fp := internal.ParseFieldParameters(params)
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return &InvalidDecodeError{Value: v}
+	}
+	if h.Constructed != r.Constructed() {
+		return &SyntaxError{Tag: h.Tag, Err: errors.New("header does not match reader")}
+	}
+	err := decodeValue(h.Tag, r, v.Elem(), fp)
+	if err == nil {
+		err = r.Close()
+	}
+	return err
+}
+
 // Unmarshal parses a BER-encoded ASN.1 data structure from b. See
 // [Decoder.Decode] for details. If any data is left over in b after val has
 // been decoded, an error is returned.
@@ -1104,9 +1911,10 @@ func Unmarshal(b []byte, val any) error {
 func UnmarshalWithParams(b []byte, val any, params string) error {
 	r := bytes.NewReader(b)
 	d := NewDecoder(r)
+	d.r.(*reader).src = &source{b, r}
 	err := d.DecodeWithParams(val, params)
 	if err == nil && r.Len() > 0 {
-		return errors.New("extra data after data value encoding")
+		return fmt.Errorf("%w after data value encoding", ErrExtraData)
 	}
 	return err
 }
@@ -7,13 +7,18 @@ package ber
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"codello.dev/asn1"
+	"codello.dev/asn1/tlv"
 )
 
 func TestReader_Next(t *testing.T) {
@@ -151,6 +156,23 @@ func TestUnmarshal_Any(t *testing.T) {
 	}
 }
 
+func TestUnmarshal_AnyTag(t *testing.T) {
+	type S struct {
+		A int      `asn1:"any"`
+		B RawValue `asn1:"any"`
+		C int
+	}
+	data := []byte{0x30, 0x09, 0x85, 0x01, 0x07, 0x04, 0x01, 0xAA, 0x02, 0x01, 0x09}
+	want := S{7, RawValue{asn1.TagOctetString, false, []byte{0xAA}}, 9}
+	var got S
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %v, want %v", got, want)
+	}
+}
+
 func TestUnmarshal_SliceArray(t *testing.T) {
 	tests := map[string]struct {
 		data    []byte
@@ -186,6 +208,12 @@ func TestUnmarshal_SliceArray(t *testing.T) {
 	}
 }
 
+// testExtensibleComponents is an extensible type used via COMPONENTS OF.
+type testExtensibleComponents struct {
+	C int
+	asn1.Extensible
+}
+
 func TestUnmarshal_Struct(t *testing.T) {
 	tests := map[string]struct {
 		data    []byte
@@ -204,10 +232,34 @@ func TestUnmarshal_Struct(t *testing.T) {
 			A int
 			asn1.Extensible
 		}{A: 1}, nil},
+		"ExtensibleNoExtra": {[]byte{0x30, 0x03, 0x02, 0x01, 0x01}, struct {
+			A int
+			asn1.Extensible
+		}{A: 1}, nil},
+		"RetainExtra": {[]byte{0x30, 0x0B, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x30, 0x03, 0x02, 0x01, 0x03}, struct {
+			A int
+			asn1.ExtensibleData
+		}{1, asn1.ExtensibleData{Extensions: [][]byte{{0x02, 0x01, 0x02}, {0x30, 0x03, 0x02, 0x01, 0x03}}}}, nil},
+		"RetainNoExtra": {[]byte{0x30, 0x03, 0x02, 0x01, 0x01}, struct {
+			A int
+			asn1.ExtensibleData
+		}{A: 1}, nil},
 		"Nullable": {[]byte{0x30, 0x05, 0x05, 0x00, 0x02, 0x01, 0x05}, struct {
 			A *string `asn1:"nullable"`
 			B int
 		}{nil, 5}, nil},
+		"Inline": {[]byte{0x30, 0x09, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03}, struct {
+			A int
+			B struct{ C, D int } `asn1:"inline"`
+		}{1, struct{ C, D int }{2, 3}}, nil},
+		"Components": {[]byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, struct {
+			A int
+			B testExtensibleComponents `asn1:"components"`
+		}{1, testExtensibleComponents{C: 2}}, nil},
+		"ComponentsExtra": {[]byte{0x30, 0x09, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03}, struct {
+			A int
+			B testExtensibleComponents `asn1:"components"`
+		}{}, &StructuralError{}},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -229,6 +281,251 @@ func TestUnmarshal_Struct(t *testing.T) {
 	}
 }
 
+func TestUnmarshal_NestedPointers(t *testing.T) {
+	type inner struct {
+		A *int `asn1:"optional,explicit,tag:1"`
+	}
+	type S struct {
+		P **inner                  `asn1:"optional,explicit,tag:0"`
+		O **asn1.Optional[int]     `asn1:"optional,tag:2"`
+		N **asn1.Nullable[bool]    `asn1:"optional"`
+		Q ***int                   `asn1:"optional,tag:4"`
+		R **asn1.Optional[float64] `asn1:"optional,explicit,tag:5"`
+		I int
+	}
+	tests := map[string]struct {
+		data []byte
+		want string
+	}{
+		"Absent":   {[]byte{0x30, 0x03, 0x02, 0x01, 0x05}, "<nil> <nil> <nil> <nil> <nil> 5"},
+		"Inner":    {[]byte{0x30, 0x0C, 0xA0, 0x07, 0x30, 0x05, 0xA1, 0x03, 0x02, 0x01, 0x09, 0x02, 0x01, 0x05}, "{9} <nil> <nil> <nil> <nil> 5"},
+		"Optional": {[]byte{0x30, 0x06, 0x82, 0x01, 0x04, 0x02, 0x01, 0x05}, "<nil> {4 true} <nil> <nil> <nil> 5"},
+		"Nullable": {[]byte{0x30, 0x05, 0x05, 0x00, 0x02, 0x01, 0x05}, "<nil> <nil> {false false} <nil> <nil> 5"},
+		"Triple":   {[]byte{0x30, 0x06, 0x84, 0x01, 0x07, 0x02, 0x01, 0x05}, "<nil> <nil> <nil> 7 <nil> 5"},
+		"Explicit": {[]byte{0x30, 0x0A, 0xA5, 0x05, 0x09, 0x03, 0x80, 0x00, 0x01, 0x02, 0x01, 0x05}, "<nil> <nil> <nil> <nil> {1 true} 5"},
+	}
+	deref := func(v any) string {
+		rv := reflect.ValueOf(v)
+		for rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return "<nil>"
+			}
+			rv = rv.Elem()
+		}
+		if rv.Type() == reflect.TypeFor[inner]() {
+			return "{" + strconv.Itoa(*rv.Interface().(inner).A) + "}"
+		}
+		return fmt.Sprint(rv.Interface())
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var s S
+			if err := Unmarshal(tt.data, &s); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			got := strings.Join([]string{deref(s.P), deref(s.O), deref(s.N), deref(s.Q), deref(s.R), strconv.Itoa(s.I)}, " ")
+			if got != tt.want {
+				t.Errorf("Unmarshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Constraints(t *testing.T) {
+	type inner struct {
+		A int `asn1:"range:0..10"`
+	}
+	type sized struct {
+		A string `asn1:"size:1..3"`
+	}
+	type nested struct{ Values []inner }
+	tests := map[string]struct {
+		data     []byte
+		val      any
+		wantPath string
+		wantErr  error
+	}{
+		"Range": {[]byte{0x30, 0x03, 0x02, 0x01, 0x14}, &struct {
+			A int `asn1:"range:0..10"`
+		}{}, "A", ErrRange},
+		"Size":   {[]byte{0x30, 0x06, 0x0C, 0x04, 'a', 'b', 'c', 'd'}, &sized{}, "A", ErrSize},
+		"Nested": {[]byte{0x30, 0x0C, 0x30, 0x0A, 0x30, 0x03, 0x02, 0x01, 0x01, 0x30, 0x03, 0x02, 0x01, 0x0B}, &nested{}, "Values[1].A", ErrRange},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Unmarshal(tt.data, tt.val)
+			var structErr *StructuralError
+			if !errors.As(err, &structErr) {
+				t.Fatalf("Unmarshal() error = %v, want *StructuralError", err)
+			}
+			if structErr.Path != tt.wantPath {
+				t.Errorf("Unmarshal() error path = %q, want %q", structErr.Path, tt.wantPath)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// testRange implements asn1.Validator.
+type testRange struct {
+	Low, High int
+}
+
+var errTestRange = errors.New("low exceeds high")
+
+func (r *testRange) ValidateASN1() error {
+	if r.Low > r.High {
+		return errTestRange
+	}
+	return nil
+}
+
+func TestUnmarshal_Validator(t *testing.T) {
+	type nested struct {
+		A bool
+		R testRange
+	}
+	tests := map[string]struct {
+		data     []byte
+		val      any
+		wantPath string
+		wantErr  bool
+	}{
+		"Valid":         {[]byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, &testRange{}, "", false},
+		"Invalid":       {[]byte{0x30, 0x06, 0x02, 0x01, 0x02, 0x02, 0x01, 0x01}, &testRange{}, "", true},
+		"NestedValid":   {[]byte{0x30, 0x0B, 0x01, 0x01, 0xFF, 0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01}, &nested{}, "", false},
+		"NestedInvalid": {[]byte{0x30, 0x0B, 0x01, 0x01, 0xFF, 0x30, 0x06, 0x02, 0x01, 0x02, 0x02, 0x01, 0x01}, &nested{}, "R", true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Unmarshal(tt.data, tt.val)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Unmarshal() error = %v", err)
+				}
+				return
+			}
+			var structErr *StructuralError
+			if !errors.As(err, &structErr) {
+				t.Fatalf("Unmarshal() error = %v, want *StructuralError", err)
+			}
+			if structErr.Path != tt.wantPath {
+				t.Errorf("Unmarshal() error path = %q, want %q", structErr.Path, tt.wantPath)
+			}
+			if !errors.Is(err, errTestRange) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, errTestRange)
+			}
+		})
+	}
+}
+
+// testVersion implements asn1.Defaulter.
+type testVersion int
+
+func (v *testVersion) SetASN1Defaults() { *v = 2 }
+
+func TestUnmarshal_Defaulter(t *testing.T) {
+	type S struct {
+		A testVersion `asn1:"optional,explicit,tag:0"`
+		B int
+		C testVersion `asn1:"optional"`
+	}
+	tests := map[string]struct {
+		data []byte
+		want S
+	}{
+		"Present": {[]byte{0x30, 0x0B, 0xA0, 0x03, 0x0A, 0x01, 0x05, 0x02, 0x01, 0x01, 0x0A, 0x01, 0x07}, S{5, 1, 7}},
+		"Absent":  {[]byte{0x30, 0x03, 0x02, 0x01, 0x01}, S{2, 1, 2}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got S
+			if err := Unmarshal(tt.data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_ErrorLocation(t *testing.T) {
+	type inner struct {
+		C bool
+		D int `asn1:"range:0..1"`
+	}
+	type outer struct {
+		A int
+		B []inner
+	}
+	tests := map[string]struct {
+		data       []byte
+		wantErr    any
+		wantPath   string
+		wantOffset int64
+	}{
+		"Syntax":     {[]byte{0x30, 0x0D, 0x02, 0x01, 0x01, 0x30, 0x08, 0x30, 0x06, 0x01, 0x02, 0xFF, 0xFF, 0x02, 0x00}, &SyntaxError{}, "B[0].C", 9},
+		"Structural": {[]byte{0x30, 0x0D, 0x02, 0x01, 0x01, 0x30, 0x08, 0x30, 0x06, 0x01, 0x01, 0xFF, 0x02, 0x01, 0x05}, &StructuralError{}, "B[0].D", 12},
+	}
+	for name, tt := range tests {
+		for _, mode := range []string{"Bytes", "Stream"} {
+			t.Run(name+"/"+mode, func(t *testing.T) {
+				var err error
+				if mode == "Bytes" {
+					err = Unmarshal(tt.data, new(outer))
+				} else {
+					err = NewDecoder(iotest.OneByteReader(bytes.NewReader(tt.data))).Decode(new(outer))
+				}
+				var path string
+				var offset int64
+				switch e := tt.wantErr.(type) {
+				case *SyntaxError:
+					if !errors.As(err, &e) {
+						t.Fatalf("Decode() error = %v, want %T", err, tt.wantErr)
+					}
+					path, offset = e.Path, e.Offset
+				case *StructuralError:
+					if !errors.As(err, &e) {
+						t.Fatalf("Decode() error = %v, want %T", err, tt.wantErr)
+					}
+					path, offset = e.Path, e.Offset
+				}
+				if path != tt.wantPath {
+					t.Errorf("Decode() error path = %q, want %q", path, tt.wantPath)
+				}
+				if offset != tt.wantOffset {
+					t.Errorf("Decode() error offset = %d, want %d", offset, tt.wantOffset)
+				}
+			})
+		}
+	}
+}
+
+func TestUnmarshal_SentinelErrors(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		val     any
+		wantErr error
+	}{
+		"TagMismatch":     {[]byte{0x01, 0x01, 0xFF}, new(int), ErrTagMismatch},
+		"Truncated":       {[]byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01}, new(struct{ A, B int }), ErrTruncated},
+		"TruncatedChild":  {[]byte{0x30, 0x80, 0x02, 0x01, 0x01}, new(struct{ A, B int }), io.ErrUnexpectedEOF},
+		"ExtraData":       {[]byte{0x02, 0x01, 0x01, 0x00}, new(int), ErrExtraData},
+		"ExtraSeqElement": {[]byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, new(struct{ A int }), ErrExtraData},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Unmarshal(tt.data, tt.val)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestUnmarshal_IndefiniteLength(t *testing.T) {
 	type test struct{ A, B int }
 	testCodec(t, nil, nil, map[string]testCase[test]{
@@ -284,3 +581,721 @@ func TestDecoder_Buffer(t *testing.T) {
 		}
 	})
 }
+
+func TestDecoder_PeekHeader(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		want    Header
+		wantErr error
+	}{
+		"Primitive":  {[]byte{0x02, 0x01, 0x05}, Header{asn1.TagInteger, 1, false}, nil},
+		"Indefinite": {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00}, Header{asn1.TagSequence, LengthIndefinite, true}, nil},
+		"Tagged":     {[]byte{0xBF, 0x1F, 0x03, 0x02, 0x01, 0x05}, Header{asn1.ClassContextSpecific | 31, 3, true}, nil},
+		"Empty":      {[]byte{}, Header{}, io.EOF},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := bytes.NewReader(tt.data)
+			// The LimitReader hides the fact that bytes.Reader is an io.ByteReader.
+			d := NewDecoder(io.LimitReader(r, int64(r.Len())))
+			for range 2 {
+				got, err := d.PeekHeader()
+				if err != tt.wantErr {
+					t.Fatalf("d.PeekHeader() error = %v, want %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("d.PeekHeader() = %v, want %v", got, tt.want)
+				}
+			}
+			if d.More() != (tt.wantErr == nil) {
+				t.Errorf("d.More() = %t, want %t", d.More(), tt.wantErr == nil)
+			}
+			if tt.wantErr != nil {
+				if _, _, err := d.Next(); err != tt.wantErr {
+					t.Errorf("d.Next() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			var v any
+			if err := d.Decode(&v); err != nil {
+				t.Fatalf("d.Decode() error = %v", err)
+			}
+			if _, err := d.PeekHeader(); err != io.EOF {
+				t.Errorf("d.PeekHeader() error = %v, want %v", err, io.EOF)
+			}
+		})
+	}
+}
+
+func TestDecodeSeq(t *testing.T) {
+	tests := map[string]struct {
+		data     []byte
+		limit    int // stop after limit elements, 0 means no limit
+		want     []int
+		wantErr  bool
+		wantPath string
+	}{
+		"Empty":      {[]byte{0x30, 0x00}, 0, nil, false, ""},
+		"Values":     {[]byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, 0, []int{1, 2}, false, ""},
+		"Indefinite": {[]byte{0x30, 0x80, 0x02, 0x01, 0x01, 0x00, 0x00}, 0, []int{1}, false, ""},
+		"Break":      {[]byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, 1, []int{1}, false, ""},
+		"Mismatch":   {[]byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x01, 0x01, 0xFF}, 0, []int{1}, true, "[1]"},
+		"Primitive":  {[]byte{0x02, 0x01, 0x01}, 0, nil, true, ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.data))
+			_, r, err := d.Next()
+			if err != nil {
+				t.Fatalf("d.Next() error = %v", err)
+			}
+			var got []int
+			for v, err := range DecodeSeq[int](r) {
+				if err != nil {
+					if !tt.wantErr {
+						t.Fatalf("DecodeSeq() error = %v, want nil", err)
+					}
+					var se *StructuralError
+					if errors.As(err, &se) && se.Path != tt.wantPath {
+						t.Errorf("DecodeSeq() error path = %q, want %q", se.Path, tt.wantPath)
+					}
+					tt.wantErr = false
+					break
+				}
+				got = append(got, v)
+				if len(got) == tt.limit {
+					break
+				}
+			}
+			if tt.wantErr {
+				t.Errorf("DecodeSeq() error = nil, want error")
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DecodeSeq() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeElement(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	tests := map[string]struct {
+		h       Header
+		content []byte
+		params  string
+		want    point
+		wantErr bool
+	}{
+		"Sequence":   {Header{asn1.TagSequence, 6, true}, []byte{0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, "", point{1, 2}, false},
+		"Indefinite": {Header{asn1.TagSequence, LengthIndefinite, true}, []byte{0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x00, 0x00}, "", point{1, 2}, false},
+		"Tagged":     {Header{asn1.ClassContextSpecific | 3, 6, true}, []byte{0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, "tag:3", point{1, 2}, false},
+		"Mismatch":   {Header{asn1.TagSet, 6, true}, []byte{0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, "", point{}, true},
+		"ExtraData":  {Header{asn1.TagSequence, 9, true}, []byte{0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03}, "", point{1, 2}, true},
+		"Truncated":  {Header{asn1.TagSequence, 6, true}, []byte{0x02, 0x01, 0x01, 0x02, 0x01}, "", point{1, 0}, true},
+		"Primitive":  {Header{asn1.TagSequence, 0, false}, nil, "", point{}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got point
+			err := DecodeElement(tt.h, NewReader(tt.h, bytes.NewReader(tt.content)), &got, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeElement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeElement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("TLV", func(t *testing.T) {
+		// SEQUENCE { INTEGER 1, SEQUENCE { INTEGER 2, INTEGER 3 } }
+		d := tlv.NewDecoderBytes([]byte{0x30, 0x0B, 0x02, 0x01, 0x01, 0x30, 0x06, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03})
+		if _, _, err := d.ReadHeader(); err != nil {
+			t.Fatalf("d.ReadHeader() error = %v", err)
+		}
+		th, content, err := d.ReadHeader()
+		if err != nil {
+			t.Fatalf("d.ReadHeader() error = %v", err)
+		}
+		h := Header{Tag: th.Tag, Length: th.Length, Constructed: th.Constructed}
+		var n int
+		if err = DecodeElement(h, NewReader(h, content), &n, ""); err != nil {
+			t.Fatalf("DecodeElement() error = %v", err)
+		}
+		if err = content.Close(); err != nil {
+			t.Fatalf("content.Close() error = %v", err)
+		}
+		if n != 1 {
+			t.Errorf("DecodeElement() = %d, want %d", n, 1)
+		}
+	})
+}
+
+func TestDecodeStream(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		limit   int // stop after limit values, 0 means no limit
+		want    []int
+		wantErr bool
+	}{
+		"Empty":     {nil, 0, nil, false},
+		"Values":    {[]byte{0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03}, 0, []int{1, 2, 3}, false},
+		"Break":     {[]byte{0x02, 0x01, 0x01, 0x02, 0x01, 0x02}, 1, []int{1}, false},
+		"Mismatch":  {[]byte{0x02, 0x01, 0x01, 0x01, 0x01, 0xFF}, 0, []int{1}, true},
+		"Truncated": {[]byte{0x02, 0x01, 0x01, 0x02, 0x02, 0x01}, 0, []int{1}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []int
+			var err error
+			// The LimitReader hides the fact that bytes.Reader is an io.ByteReader.
+			for v, vErr := range DecodeStream[int](io.LimitReader(bytes.NewReader(tt.data), int64(len(tt.data)))) {
+				if err = vErr; err != nil {
+					break
+				}
+				got = append(got, v)
+				if len(got) == tt.limit {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DecodeStream() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_MaxDecodeDepth(t *testing.T) {
+	type inner struct {
+		X any
+		Y *RawValue
+	}
+	type outer struct {
+		A int
+		B inner
+	}
+	// SEQUENCE { INTEGER 1, SEQUENCE { INTEGER 2, SEQUENCE { NULL } } }
+	data := []byte{0x30, 0x0C, 0x02, 0x01, 0x01, 0x30, 0x07, 0x02, 0x01, 0x02, 0x30, 0x02, 0x05, 0x00}
+	tests := map[string]struct {
+		depth   int
+		want    outer
+		wantErr error
+	}{
+		"Unlimited": {0, outer{1, inner{2, &RawValue{asn1.TagSequence, true, data[12:]}}}, nil},
+		"Depth2":    {2, outer{1, inner{RawValue{asn1.TagInteger, false, data[9:10]}, &RawValue{asn1.TagSequence, true, data[12:]}}}, nil},
+		"Depth1":    {1, outer{}, ErrMaxDepth},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(data))
+			d.SetOptions(DecoderOptions{MaxDecodeDepth: tt.depth})
+			var got outer
+			err := d.Decode(&got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// primitive values exceeding the depth are stored as RawValue as well
+	d := NewDecoder(bytes.NewReader([]byte{0x30, 0x03, 0x02, 0x01, 0x05}))
+	d.SetOptions(DecoderOptions{MaxDecodeDepth: 1})
+	var got []any
+	if err := d.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := []any{RawValue{asn1.TagInteger, false, []byte{0x05}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() got = %v, want %v", got, want)
+	}
+}
+
+func TestDecoder_ForbidIndefiniteLength(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		wantErr error
+	}{
+		"Definite":   {[]byte{0x30, 0x03, 0x02, 0x01, 0x05}, nil},
+		"TopLevel":   {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00}, ErrIndefiniteLength},
+		"Nested":     {[]byte{0x30, 0x07, 0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00}, ErrIndefiniteLength},
+		"RawValue":   {[]byte{0x30, 0x09, 0x02, 0x01, 0x05, 0x24, 0x80, 0x04, 0x00, 0x00, 0x00}, ErrIndefiniteLength},
+		"Primitives": {[]byte{0x30, 0x06, 0x02, 0x01, 0x05, 0x04, 0x01, 0x06}, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.data))
+			d.SetOptions(DecoderOptions{ForbidIndefiniteLength: true})
+			var got any
+			err := d.Decode(&got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("Decode() error = %T, want *SyntaxError", err)
+			}
+			// without the option the same data is accepted
+			var v any
+			if err = NewDecoder(bytes.NewReader(tt.data)).Decode(&v); err != nil {
+				t.Errorf("Decode() without ForbidIndefiniteLength error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDecoder_RequireMinimalLength(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		wantErr error
+	}{
+		"Short":        {[]byte{0x04, 0x01, 0xAB}, nil},
+		"Long":         {append([]byte{0x04, 0x81, 0x80}, make([]byte, 128)...), nil},
+		"LongForShort": {[]byte{0x04, 0x81, 0x01, 0xAB}, ErrNonMinimalLength},
+		"LeadingZero":  {append([]byte{0x04, 0x82, 0x00, 0x80}, make([]byte, 128)...), ErrNonMinimalLength},
+		"Nested":       {[]byte{0x30, 0x04, 0x04, 0x81, 0x01, 0xAB}, ErrNonMinimalLength},
+		"Indefinite":   {[]byte{0x30, 0x80, 0x04, 0x01, 0xAB, 0x00, 0x00}, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.data))
+			d.SetOptions(DecoderOptions{RequireMinimalLength: true})
+			var got any
+			err := d.Decode(&got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			var v any
+			if err = NewDecoder(bytes.NewReader(tt.data)).Decode(&v); err != nil {
+				t.Errorf("Decode() without RequireMinimalLength error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDecoder_RequireCanonicalBoolean(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		params  string
+		want    bool
+		wantErr error
+	}{
+		"False":        {[]byte{0x01, 0x01, 0x00}, "", false, nil},
+		"True":         {[]byte{0x01, 0x01, 0xFF}, "", true, nil},
+		"NonCanonical": {[]byte{0x01, 0x01, 0x01}, "", false, ErrNonCanonicalBoolean},
+		"Implicit":     {[]byte{0x80, 0x01, 0x7F}, "tag:0", false, ErrNonCanonicalBoolean},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.data))
+			d.SetOptions(DecoderOptions{RequireCanonicalBoolean: true})
+			var got bool
+			err := d.DecodeWithParams(&got, tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			if err = NewDecoder(bytes.NewReader(tt.data)).DecodeWithParams(&got, tt.params); err != nil || !got {
+				t.Errorf("Decode() without RequireCanonicalBoolean = %v, %v, want true, <nil>", got, err)
+			}
+		})
+	}
+}
+
+var errTestPort = errors.New("port 0 is reserved")
+
+// testPort is an integer type implementing asn1.Validator.
+type testPort uint16
+
+func (p testPort) ValidateASN1() error {
+	if p == 0 {
+		return errTestPort
+	}
+	return nil
+}
+
+func TestDecoder_ValidateIntegers(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		params  string
+		want    testPort
+		wantErr error
+	}{
+		"Valid":    {[]byte{0x0A, 0x01, 0x50}, "", 80, nil},
+		"Invalid":  {[]byte{0x0A, 0x01, 0x00}, "", 0, errTestPort},
+		"Integer":  {[]byte{0x02, 0x01, 0x00}, "universal,tag:2", 0, errTestPort},
+		"Implicit": {[]byte{0x80, 0x01, 0x00}, "tag:0", 0, errTestPort},
+		"Valid16":  {[]byte{0x80, 0x02, 0x01, 0xBB}, "tag:0", 443, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.data))
+			d.SetOptions(DecoderOptions{ValidateIntegers: true})
+			var got testPort
+			err := d.DecodeWithParams(&got, tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			if err = NewDecoder(bytes.NewReader(tt.data)).DecodeWithParams(&got, tt.params); err != nil {
+				t.Errorf("Decode() without ValidateIntegers error = %v, want <nil>", err)
+			}
+		})
+	}
+}
+
+func TestDecoder_UTCTimeOptions(t *testing.T) {
+	tests := map[string]struct {
+		opts    DecoderOptions
+		data    string
+		want    int
+		wantErr bool
+	}{
+		"Default":     {DecoderOptions{}, "491231235959Z", 2049, false},
+		"DefaultLow":  {DecoderOptions{}, "500101000000Z", 1950, false},
+		"Pivot":       {DecoderOptions{UTCTimePivot: 2000}, "991231235959Z", 2099, false},
+		"PivotMiddle": {DecoderOptions{UTCTimePivot: 1970}, "690101000000Z", 2069, false},
+		"Disabled":    {DecoderOptions{DisableUTCTime: true}, "991231235959Z", 0, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := append([]byte{0x17, byte(len(tt.data))}, tt.data...)
+			d := NewDecoder(bytes.NewReader(data))
+			d.SetOptions(tt.opts)
+			var got asn1.UTCTime
+			err := d.Decode(&got)
+			if tt.wantErr {
+				var structErr *StructuralError
+				if !errors.As(err, &structErr) {
+					t.Fatalf("Decode() error = %v, want StructuralError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if year := time.Time(got).Year(); year != tt.want {
+				t.Errorf("Decode() year = %d, want %d", year, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_LeapSeconds(t *testing.T) {
+	tests := map[string]struct {
+		data []byte
+		want time.Time
+	}{
+		"UTCTime":         {append([]byte{0x17, 0x0D}, "161231235960Z"...), time.Date(2016, 12, 31, 23, 59, 59, 999999000, time.UTC)},
+		"GeneralizedTime": {append([]byte{0x18, 0x11}, "20161231235960.5Z"...), time.Date(2016, 12, 31, 23, 59, 59, 999999000, time.UTC)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got time.Time
+			d := NewDecoder(bytes.NewReader(tt.data))
+			if err := d.DecodeWithParams(&got, "universal,tag:"+strconv.Itoa(int(tt.data[0]))); err == nil {
+				t.Fatalf("Decode() error = nil, want error without AllowLeapSeconds")
+			}
+			d = NewDecoder(bytes.NewReader(tt.data))
+			d.SetOptions(DecoderOptions{AllowLeapSeconds: true})
+			if err := d.DecodeWithParams(&got, "universal,tag:"+strconv.Itoa(int(tt.data[0]))); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+			warnings := d.Warnings()
+			if len(warnings) != 1 || !errors.Is(warnings[0], ErrLeapSecond) {
+				t.Errorf("Warnings() = %v, want [%v]", warnings, ErrLeapSecond)
+			}
+			if w := d.Warnings(); len(w) != 0 {
+				t.Errorf("Warnings() after reset = %v, want []", w)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Rest(t *testing.T) {
+	type S struct {
+		A    int
+		Rest []RawValue `asn1:"rest"`
+	}
+	tests := map[string]struct {
+		data []byte
+		tags []asn1.Tag
+	}{
+		"None": {[]byte{0x30, 0x03, 0x02, 0x01, 0x01}, nil},
+		"Multiple": {[]byte{0x30, 0x0B, 0x02, 0x01, 0x01,
+			0x04, 0x01, 0xFF,
+			0xA0, 0x03, 0x01, 0x01, 0x00}, []asn1.Tag{asn1.TagOctetString, asn1.ClassContextSpecific | 0}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var s S
+			if err := Unmarshal(tt.data, &s); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if s.A != 1 || len(s.Rest) != len(tt.tags) {
+				t.Fatalf("Unmarshal() = %+v, want A = 1 and %d rest values", s, len(tt.tags))
+			}
+			for i, rv := range s.Rest {
+				if rv.Tag != tt.tags[i] {
+					t.Errorf("Unmarshal() Rest[%d].Tag = %v, want %v", i, rv.Tag, tt.tags[i])
+				}
+			}
+			got, err := Marshal(s)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("Marshal() = % X, want % X", got, tt.data)
+			}
+		})
+	}
+
+	t.Run("ErrorPath", func(t *testing.T) {
+		var s struct {
+			A    int
+			Rest []int `asn1:"rest"`
+		}
+		err := Unmarshal([]byte{0x30, 0x09, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x04, 0x01, 0x03}, &s)
+		var structErr *StructuralError
+		if !errors.As(err, &structErr) || structErr.Path != "Rest[1]" {
+			t.Errorf("Unmarshal() error = %v, want StructuralError at Rest[1]", err)
+		}
+	})
+}
+
+func TestExtensibleData_RoundTrip(t *testing.T) {
+	type V1 struct {
+		A int
+		asn1.ExtensibleData
+	}
+	type V2 struct {
+		A int
+		B string
+		C []int
+	}
+	data, err := Marshal(V2{1, "new", []int{2, 3}})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var v V1
+	if err = Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	v.A = 5
+	if data, err = Marshal(v); err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got V2
+	if err = Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if want := (V2{5, "new", []int{2, 3}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshal_Presence(t *testing.T) {
+	type S struct {
+		A int `asn1:"optional"`
+		B int `asn1:"optional,tag:0"`
+		C bool
+		asn1.Presence
+	}
+	tests := map[string]struct {
+		data    []byte
+		present []string
+		absent  []string
+	}{
+		"AllPresent":  {[]byte{0x30, 0x09, 0x02, 0x01, 0x00, 0x80, 0x01, 0x00, 0x01, 0x01, 0x00}, []string{"A", "B", "C"}, nil},
+		"ZeroAbsent":  {[]byte{0x30, 0x06, 0x80, 0x01, 0x00, 0x01, 0x01, 0x00}, []string{"B", "C"}, []string{"A"}},
+		"BothAbsent":  {[]byte{0x30, 0x03, 0x01, 0x01, 0x00}, []string{"C"}, []string{"A", "B"}},
+		"UnknownName": {[]byte{0x30, 0x03, 0x01, 0x01, 0x00}, nil, []string{"D"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var s S
+			s.SetPresent("A", true) // must be reset by decoding
+			if err := Unmarshal(tt.data, &s); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			for _, f := range tt.present {
+				if !s.IsPresent(f) {
+					t.Errorf("IsPresent(%q) = false, want true", f)
+				}
+			}
+			for _, f := range tt.absent {
+				if s.IsPresent(f) {
+					t.Errorf("IsPresent(%q) = true, want false", f)
+				}
+			}
+		})
+	}
+}
+
+type testChoice interface{ isTestChoice() }
+
+type testChoiceInt int
+
+func (testChoiceInt) isTestChoice() {}
+
+type testChoiceString struct {
+	S string
+}
+
+func (*testChoiceString) isTestChoice() {}
+
+func init() {
+	RegisterChoice[testChoice](asn1.TagEnumerated, func() testChoice { return testChoiceInt(0) })
+	RegisterChoice[testChoice](asn1.TagSequence, func() testChoice { return &testChoiceString{} })
+}
+
+func TestRegisterChoice(t *testing.T) {
+	type S struct {
+		A testChoice `asn1:"optional"`
+		B bool
+	}
+	tests := map[string]struct {
+		data    []byte
+		want    testChoice
+		wantErr error
+	}{
+		"Enumerated": {[]byte{0x30, 0x06, 0x0A, 0x01, 0x05, 0x01, 0x01, 0x00}, testChoiceInt(5), nil},
+		"Sequence":   {[]byte{0x30, 0x08, 0x30, 0x03, 0x0C, 0x01, 'a', 0x01, 0x01, 0x00}, &testChoiceString{"a"}, nil},
+		"Absent":     {[]byte{0x30, 0x03, 0x01, 0x01, 0x00}, nil, nil},
+		"Unknown":    {[]byte{0x30, 0x05, 0x04, 0x00, 0x01, 0x01, 0x00}, nil, ErrTagMismatch},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var s S
+			err := Unmarshal(tt.data, &s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(s.A, tt.want) {
+				t.Errorf("Unmarshal() got = %#v, want %#v", s.A, tt.want)
+			}
+		})
+	}
+}
+
+func TestChoiceStruct(t *testing.T) {
+	type body struct {
+		A *int  `asn1:"tag:0"`
+		B *bool `asn1:"tag:1"`
+	}
+	type msg struct {
+		Body body `asn1:"choice"`
+		Ext  body `asn1:"choice,explicit,tag:2,optional,omitzero"`
+		N    int
+	}
+	one, yes := 1, true
+	tests := map[string]struct {
+		val  msg
+		data []byte
+	}{
+		"First":    {msg{Body: body{A: &one}, N: 5}, []byte{0x30, 0x06, 0x80, 0x01, 0x01, 0x02, 0x01, 0x05}},
+		"Second":   {msg{Body: body{B: &yes}, N: 5}, []byte{0x30, 0x06, 0x81, 0x01, 0xFF, 0x02, 0x01, 0x05}},
+		"Explicit": {msg{Body: body{B: &yes}, Ext: body{A: &one}, N: 5}, []byte{0x30, 0x0B, 0x81, 0x01, 0xFF, 0xA2, 0x03, 0x80, 0x01, 0x01, 0x02, 0x01, 0x05}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := Marshal(tt.val)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(data, tt.data) {
+				t.Errorf("Marshal() = % X, want % X", data, tt.data)
+			}
+			// decoding must reset the other alternatives
+			got := msg{Body: body{A: new(int), B: new(bool)}}
+			if err = Unmarshal(tt.data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.val) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.val)
+			}
+		})
+	}
+
+	errTests := map[string]any{
+		"None":     msg{N: 5},
+		"Multiple": msg{Body: body{A: &one, B: &yes}},
+		"Implicit": struct {
+			Body body `asn1:"choice,tag:3"`
+		}{body{A: &one}},
+	}
+	for name, val := range errTests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Marshal(val); !errors.As(err, new(*EncodeError)) {
+				t.Errorf("Marshal() error = %v, want EncodeError", err)
+			}
+		})
+	}
+
+	t.Run("Unknown", func(t *testing.T) {
+		var got msg
+		if err := Unmarshal([]byte{0x30, 0x06, 0x84, 0x01, 0x01, 0x02, 0x01, 0x05}, &got); !errors.Is(err, ErrTagMismatch) {
+			t.Errorf("Unmarshal() error = %v, want %v", err, ErrTagMismatch)
+		}
+	})
+}
+
+type testDefinedParams struct {
+	N int
+}
+
+func init() {
+	RegisterDefinedBy(asn1.ObjectIdentifier{1, 2, 3, 4}, func() any { return &testDefinedParams{} })
+}
+
+func TestUnmarshal_DefinedBy(t *testing.T) {
+	type AlgorithmIdentifier struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters any `asn1:"optional,definedby:Algorithm"`
+	}
+	tests := map[string]struct {
+		data []byte
+		want any
+	}{
+		"Registered":   {[]byte{0x30, 0x0A, 0x06, 0x03, 0x2A, 0x03, 0x04, 0x30, 0x03, 0x02, 0x01, 0x07}, &testDefinedParams{7}},
+		"Absent":       {[]byte{0x30, 0x05, 0x06, 0x03, 0x2A, 0x03, 0x04}, nil},
+		"Unregistered": {[]byte{0x30, 0x08, 0x06, 0x03, 0x2A, 0x03, 0x05, 0x04, 0x01, 0xAA}, []byte{0xAA}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got AlgorithmIdentifier
+			if err := Unmarshal(tt.data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Parameters, tt.want) {
+				t.Errorf("Unmarshal() got = %#v, want %#v", got.Parameters, tt.want)
+			}
+		})
+	}
+}
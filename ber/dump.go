@@ -0,0 +1,227 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/tlv"
+)
+
+// DumpOptions control the output of [Dump]. The zero value is a valid
+// configuration.
+type DumpOptions struct {
+	// Offsets enables printing of the input offset of each data value.
+	Offsets bool
+
+	// Hex enables printing of the content octets of primitive data values in
+	// hexadecimal, in addition to their decoded value.
+	Hex bool
+
+	// MaxBytes limits the number of bytes printed in hexadecimal for a single data
+	// value. A value of 0 indicates a default of 32 bytes, a negative value
+	// disables the limit.
+	MaxBytes int
+
+	// Indent is the string used to indent nested data values. The default
+	// indentation uses two spaces.
+	Indent string
+
+	// OIDNames maps object identifiers in dot notation (e.g. "2.5.4.3") to
+	// descriptive names. The names are printed next to object identifier values.
+	OIDNames map[string]string
+}
+
+// Dump reads BER-encoded data values from r and writes a human-readable tree
+// of their headers and values to w. Dump is intended for debugging purposes.
+// The output format is not stable and may change in future versions.
+//
+// Each data value is printed on its own line. Nested data values are indented.
+// Primitive values using universal tags are decoded and printed according to
+// their type. All other primitive values are printed in hexadecimal.
+//
+// Dump reads until r returns io.EOF. If the input is not a valid BER encoding,
+// Dump prints all data values up to the erroneous one and returns the error.
+func Dump(w io.Writer, r io.Reader, opts *DumpOptions) error {
+	if opts == nil {
+		opts = &DumpOptions{}
+	}
+	indent := opts.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	d := tlv.NewDecoder(r)
+	var line []byte
+	for {
+		h, val, err := d.ReadHeader()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if h == tlv.EndOfContents {
+			continue
+		}
+
+		line = line[:0]
+		if opts.Offsets {
+			line = fmt.Appendf(line, "%6d: ", d.DataValueOffset())
+		}
+		line = append(line, strings.Repeat(indent, d.StackDepth()-1)...)
+		line = append(line, dumpTagName(h.Tag)...)
+		if h.Length == tlv.LengthIndefinite {
+			line = append(line, " (indefinite)"...)
+		} else if h.Length == 1 {
+			line = append(line, " (1 byte)"...)
+		} else {
+			line = fmt.Appendf(line, " (%d bytes)", h.Length)
+		}
+
+		if val != nil {
+			var content []byte
+			if content, err = d.ReadValueBytes(); err != nil {
+				return err
+			}
+			if s := dumpValue(h.Tag, content, opts); s != "" {
+				line = append(line, ' ')
+				line = append(line, s...)
+			}
+			if opts.Hex && len(content) > 0 {
+				line = append(line, " ["...)
+				line = append(line, dumpHex(content, opts.MaxBytes)...)
+				line = append(line, ']')
+			}
+		}
+		line = append(line, '\n')
+		if _, err = w.Write(line); err != nil {
+			return err
+		}
+	}
+}
+
+// universalTagNames contains the ASN.1 names of universal tags.
+var universalTagNames = map[asn1.Tag]string{
+	asn1.TagBoolean:          "BOOLEAN",
+	asn1.TagInteger:          "INTEGER",
+	asn1.TagBitString:        "BIT STRING",
+	asn1.TagOctetString:      "OCTET STRING",
+	asn1.TagNull:             "NULL",
+	asn1.TagOID:              "OBJECT IDENTIFIER",
+	asn1.TagObjectDescriptor: "ObjectDescriptor",
+	asn1.TagExternal:         "EXTERNAL",
+	asn1.TagReal:             "REAL",
+	asn1.TagEnumerated:       "ENUMERATED",
+	asn1.TagEmbeddedPDV:      "EMBEDDED PDV",
+	asn1.TagUTF8String:       "UTF8String",
+	asn1.TagRelativeOID:      "RELATIVE-OID",
+	asn1.TagTime:             "TIME",
+	asn1.TagSequence:         "SEQUENCE",
+	asn1.TagSet:              "SET",
+	asn1.TagNumericString:    "NumericString",
+	asn1.TagPrintableString:  "PrintableString",
+	asn1.TagTeletexString:    "TeletexString",
+	asn1.TagVideotexString:   "VideotexString",
+	asn1.TagIA5String:        "IA5String",
+	asn1.TagUTCTime:          "UTCTime",
+	asn1.TagGeneralizedTime:  "GeneralizedTime",
+	asn1.TagGraphicString:    "GraphicString",
+	asn1.TagVisibleString:    "VisibleString",
+	asn1.TagGeneralString:    "GeneralString",
+	asn1.TagUniversalString:  "UniversalString",
+	asn1.TagCharacterString:  "CHARACTER STRING",
+	asn1.TagBMPString:        "BMPString",
+	asn1.TagDate:             "DATE",
+	asn1.TagTimeOfDay:        "TIME-OF-DAY",
+	asn1.TagDateTime:         "DATE-TIME",
+	asn1.TagDuration:         "DURATION",
+}
+
+// dumpTagName returns the ASN.1 name of tag if it is known. Otherwise, the tag
+// is formatted using [asn1.Tag.String].
+func dumpTagName(tag asn1.Tag) string {
+	if name, ok := universalTagNames[tag]; ok {
+		return name
+	}
+	return tag.String()
+}
+
+// dumpValue formats the content octets of a primitive data value with the
+// given tag. If the value cannot be decoded, its hexadecimal representation is
+// returned.
+func dumpValue(tag asn1.Tag, content []byte, opts *DumpOptions) string {
+	if tag.Class() != asn1.ClassUniversal {
+		if opts.Hex {
+			return ""
+		}
+		return dumpHex(content, opts.MaxBytes)
+	}
+	val, err := decodePrimitive(tag, content)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []byte:
+		if opts.Hex {
+			return ""
+		}
+		return dumpHex(v, opts.MaxBytes)
+	case RawValue:
+		if opts.Hex {
+			return ""
+		}
+		return dumpHex(v.Bytes, opts.MaxBytes)
+	case asn1.BitString:
+		return strconv.Itoa(v.BitLength) + " bits " + dumpHex(v.Bytes, opts.MaxBytes)
+	case asn1.ObjectIdentifier:
+		s := v.String()
+		if name, ok := opts.OIDNames[s]; ok {
+			s += " (" + name + ")"
+		}
+		return s
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *big.Int:
+		return v.String()
+	default:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+			return strconv.Quote(rv.String())
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+// decodePrimitive decodes the content octets of a primitive data value with the
+// given universal tag into the Go value that is used when decoding into an
+// interface{}.
+func decodePrimitive(tag asn1.Tag, content []byte) (any, error) {
+	var val any
+	r := &reader{H: Header{tag, len(content), false}, R: &limitReader{bytes.NewReader(content), len(content)}}
+	err := decodeValue(tag, r, reflect.ValueOf(&val).Elem(), internal.FieldParameters{})
+	return val, err
+}
+
+// dumpHex returns the hexadecimal representation of b. If b contains more than
+// maxBytes bytes, the output is truncated.
+func dumpHex(b []byte, maxBytes int) string {
+	if maxBytes == 0 {
+		maxBytes = 32
+	}
+	if maxBytes < 0 || len(b) <= maxBytes {
+		return strings.ToUpper(hex.EncodeToString(b))
+	}
+	return strings.ToUpper(hex.EncodeToString(b[:maxBytes])) + "..."
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func ExampleDump() {
+	data := []byte{
+		0x30, 0x80,
+		0x02, 0x01, 0x05,
+		0x06, 0x03, 0x55, 0x04, 0x03,
+		0x0c, 0x02, 'h', 'i',
+		0xa2, 0x03, 0x04, 0x01, 0x01,
+		0x00, 0x00,
+	}
+	_ = Dump(os.Stdout, bytes.NewReader(data), &DumpOptions{
+		Offsets:  true,
+		OIDNames: map[string]string{"2.5.4.3": "commonName"},
+	})
+	// Output:
+	//      0: SEQUENCE (indefinite)
+	//      2:   INTEGER (1 byte) 5
+	//      5:   OBJECT IDENTIFIER (3 bytes) 2.5.4.3 (commonName)
+	//     10:   UTF8String (2 bytes) "hi"
+	//     14:   [2] (3 bytes)
+	//     16:     OCTET STRING (1 byte) 01
+}
+
+func TestDump(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		opts    *DumpOptions
+		want    string
+		wantErr bool
+	}{
+		"Null":    {[]byte{0x05, 0x00}, nil, "NULL (0 bytes)\n", false},
+		"Boolean": {[]byte{0x01, 0x01, 0xff}, nil, "BOOLEAN (1 byte) true\n", false},
+		"Hex": {[]byte{0x02, 0x02, 0x01, 0x00}, &DumpOptions{Hex: true},
+			"INTEGER (2 bytes) 256 [0100]\n", false},
+		"BitString": {[]byte{0x03, 0x02, 0x04, 0xf0}, nil, "BIT STRING (2 bytes) 4 bits F0\n", false},
+		"Real":      {[]byte{0x09, 0x03, 0x80, 0xfb, 0x05}, nil, "REAL (3 bytes) 0.15625\n", false},
+		"UTCTime": {append([]byte{0x17, 0x0d}, "910506234540Z"...), nil,
+			"UTCTime (13 bytes) 1991-05-06T23:45:40Z\n", false},
+		"ContextSpecific": {[]byte{0x81, 0x02, 0xab, 0xcd}, nil, "[1] (2 bytes) ABCD\n", false},
+		"MaxBytes": {[]byte{0x04, 0x03, 0x01, 0x02, 0x03}, &DumpOptions{MaxBytes: 2},
+			"OCTET STRING (3 bytes) 0102...\n", false},
+		"Indent": {[]byte{0x30, 0x02, 0x05, 0x00}, &DumpOptions{Indent: "| "},
+			"SEQUENCE (2 bytes)\n| NULL (0 bytes)\n", false},
+		"Multiple": {[]byte{0x05, 0x00, 0x05, 0x00}, nil, "NULL (0 bytes)\nNULL (0 bytes)\n", false},
+		"InvalidValue": {[]byte{0x01, 0x02, 0x00, 0x00}, nil,
+			"BOOLEAN (2 bytes) <", false},
+
+		"Truncated":      {[]byte{0x30, 0x80, 0x05, 0x00}, nil, "SEQUENCE (indefinite)\n  NULL (0 bytes)\n", true},
+		"TruncatedValue": {[]byte{0x04, 0x05, 0x01}, nil, "", true},
+		"HugeLength":     {[]byte{0x04, 0x88, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, nil, "", true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Dump(&buf, bytes.NewReader(tc.data), tc.opts)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Dump() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !strings.HasPrefix(buf.String(), tc.want) {
+				t.Errorf("Dump() = %q, want %q", buf.String(), tc.want)
+			}
+		})
+	}
+}
+
+func FuzzDump(f *testing.F) {
+	f.Add([]byte{0x30, 0x03, 0x01, 0x01, 0xFF})
+	f.Add([]byte{0x30, 0x80, 0x04, 0x01, 0x00, 0x00, 0x00})
+	f.Add([]byte{0x04, 0x88, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Dump must not panic or allocate the declared length of a value.
+		_ = Dump(io.Discard, bytes.NewReader(data), &DumpOptions{Hex: true})
+		_, _ = ToJSON(bytes.NewReader(data))
+	})
+}
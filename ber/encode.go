@@ -10,8 +10,13 @@ import (
 	"encoding"
 	"errors"
 	"io"
+	"net/netip"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"codello.dev/asn1"
 	"codello.dev/asn1/internal"
@@ -39,6 +44,128 @@ type BerEncoder interface {
 	BerEncode() (h Header, wt io.WriterTo, err error)
 }
 
+// BerTagger can be implemented by types that have an intrinsic tag different
+// from the tag of their underlying type. This is common in protocols that
+// define domain types by implicitly tagging a universal type, such as the
+// Counter32 type of SNMP, which is defined as [APPLICATION 1] IMPLICIT INTEGER:
+//
+//	type Counter32 uint32
+//
+//	func (Counter32) BerTag() asn1.Tag { return asn1.ClassApplication | 1 }
+//
+// Values of such types are encoded and decoded as if the tag had been specified
+// via struct tags. A tag specified via struct tags takes precedence over the
+// intrinsic tag. The BerTag method is called on the zero value of the type and
+// must return the same tag for all values. Types implementing [BerEncoder] or
+// [BerDecoder] do not need to implement BerTagger.
+//
+// BerTagger is specific to the Basic Encoding Rules. Types that declare their
+// tag for all encoding rules implement [asn1.Tagger] instead. If a type
+// implements both interfaces, BerTagger takes precedence.
+type BerTagger interface {
+	BerTag() asn1.Tag
+}
+
+// rawEncoding is an [io.WriterTo] that writes a complete data value encoding,
+// including its identifier and length octets. It enables a [BerEncoder] to
+// reproduce an encoding byte by byte, e.g. to preserve non-minimal or
+// indefinite lengths. The header returned alongside a rawEncoding describes the
+// content octets in case the encoding needs to be re-tagged.
+type rawEncoding struct {
+	full    []byte // complete encoding
+	content []byte // content octets
+}
+
+func (r rawEncoding) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(r.content)
+	return int64(n), err
+}
+
+// encodedLength returns the number of bytes written by writeValue for h and wt.
+func encodedLength(h Header, wt io.WriterTo) int {
+	if raw, ok := wt.(rawEncoding); ok {
+		return len(raw.full)
+	}
+	return CombinedLength(h.numBytes(), h.Length)
+}
+
+// inlineContent holds the content octets of a short primitive encoding. Codecs
+// of primitive types embed an inlineContent and return a pointer to it from
+// BerEncode. The codec is either allocated anyway when it is converted to a
+// [BerEncoder] or it is reused from an [inlineCodecs] value, so no additional
+// allocations are needed for the content octets. Content octets exceeding the
+// inline buffer are allocated on the heap.
+type inlineContent struct {
+	b   []byte // content octets, usually referencing buf
+	buf [24]byte
+}
+
+func (c *inlineContent) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(c.b)
+	return int64(n), err
+}
+
+// inlineCodecs holds reusable codecs of primitive types. Converting a codec to
+// a [BerEncoder] allocates it on the heap. When encoding a top-level value, the
+// codec is taken from an inlineCodecs value instead, so that encoding a single
+// BOOLEAN, INTEGER, NULL or OBJECT IDENTIFIER does not allocate. Codecs of
+// nested values are allocated as usual.
+//
+// A nil *inlineCodecs is valid and allocates a new codec for each call.
+type inlineCodecs struct {
+	b    boolCodec
+	i    intCodec
+	null nullCodec
+	oid  oidCodec
+	rel  relativeOIDCodec
+}
+
+// codecsPool holds inlineCodecs for functions that are not bound to an
+// [Encoder].
+var codecsPool = sync.Pool{
+	New: func() any { return new(inlineCodecs) },
+}
+
+func (c *inlineCodecs) newBool(v reflect.Value, val bool) *boolCodec {
+	if c == nil {
+		return &boolCodec{codec: codec[bool]{v, val}}
+	}
+	c.b = boolCodec{codec: codec[bool]{v, val}}
+	return &c.b
+}
+
+func (c *inlineCodecs) newInt(v reflect.Value, enum bool) *intCodec {
+	if c == nil {
+		return &intCodec{enum: enum, codec: codec[any]{ref: v}}
+	}
+	c.i = intCodec{enum: enum, codec: codec[any]{ref: v}}
+	return &c.i
+}
+
+func (c *inlineCodecs) newNull(v reflect.Value) *nullCodec {
+	if c == nil {
+		return &nullCodec{ref: v}
+	}
+	c.null = nullCodec{ref: v}
+	return &c.null
+}
+
+func (c *inlineCodecs) newOID(v reflect.Value, val asn1.ObjectIdentifier) *oidCodec {
+	if c == nil {
+		return &oidCodec{codec: codec[asn1.ObjectIdentifier]{v, val}}
+	}
+	c.oid = oidCodec{codec: codec[asn1.ObjectIdentifier]{v, val}}
+	return &c.oid
+}
+
+func (c *inlineCodecs) newRelativeOID(v reflect.Value, val asn1.RelativeOID) *relativeOIDCodec {
+	if c == nil {
+		return &relativeOIDCodec{codec: codec[asn1.RelativeOID]{v, val}}
+	}
+	c.rel = relativeOIDCodec{codec: codec[asn1.RelativeOID]{v, val}}
+	return &c.rel
+}
+
 // writerFunc wraps a function and implements the [io.WriterTo] interface. This
 // type can be useful when implementing a custom [BerEncoder].
 type writerFunc func(io.Writer) (int64, error)
@@ -57,7 +184,7 @@ type UnsupportedTypeError struct {
 }
 
 func (e *UnsupportedTypeError) Error() string {
-	if e.msg == "" {
+	if e.msg != "" {
 		return e.msg
 	}
 	if e.Type == nil {
@@ -76,6 +203,7 @@ func (e *UnsupportedTypeError) Error() string {
 // returned from the [Encoder].
 type EncodeError struct {
 	Value reflect.Value
+	Path  string // path of struct fields and indices to the value (maybe empty)
 	Err   error
 }
 
@@ -86,6 +214,10 @@ func (e *EncodeError) Error() string {
 		s.WriteString(" for ")
 		s.WriteString(e.Value.Type().String())
 	}
+	if e.Path != "" {
+		s.WriteString(" in field ")
+		s.WriteString(e.Path)
+	}
 	s.WriteString(": ")
 	s.WriteString(e.Err.Error())
 	return s.String()
@@ -184,12 +316,56 @@ func (s *Sequence) AppendWithParams(val any, params string) error {
 	return s.append(reflect.ValueOf(val), internal.ParseFieldParameters(params))
 }
 
+// Insert inserts a data value at index i of the sequence, moving subsequent
+// values back. Insert panics if i is out of range. The value is validated in
+// the same way as in [Sequence.Append].
+func (s *Sequence) Insert(i int, val any) error {
+	if i < 0 || i > s.Len() {
+		panic("ber: Sequence.Insert index out of range")
+	}
+	v := reflect.ValueOf(val)
+	enc, err := makeEncoder(v, internal.FieldParameters{}, nil)
+	if err != nil {
+		return err
+	}
+	if enc != nil {
+		s.values = slices.Insert(s.values, i, v)
+		s.encoders = slices.Insert(s.encoders, i, enc)
+		s.params = slices.Insert(s.params, i, internal.FieldParameters{})
+	}
+	return nil
+}
+
+// AppendRaw adds a pre-encoded data value to the end of the sequence. See
+// [RawValue] for details on how raw values are encoded.
+func (s *Sequence) AppendRaw(rv RawValue) error {
+	return s.append(reflect.ValueOf(rv), internal.FieldParameters{})
+}
+
+// Remove removes the data value at index i from the sequence, moving
+// subsequent values forward. Remove panics if i is out of range.
+func (s *Sequence) Remove(i int) {
+	if i < 0 || i >= s.Len() {
+		panic("ber: Sequence.Remove index out of range")
+	}
+	s.values = slices.Delete(s.values, i, i+1)
+	s.encoders = slices.Delete(s.encoders, i, i+1)
+	s.params = slices.Delete(s.params, i, i+1)
+}
+
+// Len returns the number of data values in the sequence. Values that are
+// omitted from the encoding (e.g. because of the "omitzero" parameter) are not
+// counted.
+func (s *Sequence) Len() int {
+	return len(s.encoders)
+}
+
 // append adds a data value to the end of the sequence. The value is converted
 // into a [BerDecoder]. If the conversion fails, an [UnsupportedTypeError] is
 // returned. In particular if the type of v is supported, no error will be
 // returned. Validation is deferred to the BerEncode method.
 func (s *Sequence) append(v reflect.Value, params internal.FieldParameters) error {
-	enc, err := makeEncoder(v, params)
+	enc, err := makeEncoder(v, params, nil)
 	if err != nil {
 		return err
 	}
@@ -226,13 +402,18 @@ func (s *Sequence) BerEncode() (Header, io.WriterTo, error) {
 		}
 		headers[i] = eh
 		writers[i] = wt
-		h.Length = CombinedLength(h.Length, eh.numBytes(), eh.Length)
+		h.Length = CombinedLength(h.Length, encodedLength(eh, wt))
 	}
 	return h, writerFunc(func(w io.Writer) (n int64, err error) {
 		var n2 int64
 		for i := 0; i < len(headers) && err == nil; i++ {
+			restore := enterElem(w, s.params[i].Field, i)
 			n2, err = writeValue(s.values[i], w, headers[i], writers[i])
+			restore()
 			n += n2
+			if err != nil {
+				err = withPath(err, elemName(s.params[i].Field, i))
+			}
 		}
 		return n, err
 	}), nil
@@ -240,6 +421,65 @@ func (s *Sequence) BerEncode() (Header, io.WriterTo, error) {
 
 //endregion
 
+//region type seqEncoder
+
+// seqEncoder encodes an iterator with the underlying type iter.Seq[T] as a
+// SEQUENCE OF. The values of the iterator are produced lazily while writing the
+// encoding, so the indefinite-length encoding is used. A nil iterator is
+// encoded as an empty sequence.
+type seqEncoder struct {
+	ref reflect.Value
+}
+
+// isSeq reports whether t has the underlying type iter.Seq[T] for some T.
+func isSeq(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+	y := t.In(0)
+	return y.Kind() == reflect.Func && y.NumIn() == 1 && y.NumOut() == 1 && y.Out(0).Kind() == reflect.Bool
+}
+
+func (e seqEncoder) BerEncode() (Header, io.WriterTo, error) {
+	return Header{asn1.TagSequence, LengthIndefinite, true}, writerFunc(func(w io.Writer) (n int64, err error) {
+		if e.ref.IsNil() {
+			return 0, nil
+		}
+		yieldType := e.ref.Type().In(0)
+		i := 0
+		yield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+			var n2 int64
+			restore := enterElem(w, "", i)
+			n2, err = writeElement(args[0], w)
+			restore()
+			n += n2
+			if err != nil {
+				err = withPath(err, "["+strconv.Itoa(i)+"]")
+			}
+			i++
+			return []reflect.Value{reflect.ValueOf(err == nil).Convert(yieldType.Out(0))}
+		})
+		e.ref.Call([]reflect.Value{yield})
+		return n, err
+	}), nil
+}
+
+// writeElement encodes v and writes it to w. This combines the two steps of the
+// encoding process for values that are not known in advance.
+func writeElement(v reflect.Value, w io.Writer) (int64, error) {
+	enc, err := makeEncoder(v, internal.FieldParameters{}, nil)
+	if err != nil || enc == nil {
+		return 0, err
+	}
+	h, wt, err := encodeValue(v, enc, internal.FieldParameters{})
+	if err != nil {
+		return 0, err
+	}
+	return writeValue(v, w, h, wt)
+}
+
+//endregion
+
 //region type explicitEncoder
 
 // explicitEncoder wraps a [BerEncoder] in another constructed encoding. The tag
@@ -253,7 +493,7 @@ func (e explicitEncoder) BerEncode() (Header, io.WriterTo, error) {
 	if err != nil {
 		return Header{}, nil, err
 	}
-	ret := Header{Length: CombinedLength(h.numBytes(), h.Length), Constructed: true} // class and tag are set explicitly
+	ret := Header{Length: encodedLength(h, wt), Constructed: true} // class and tag are set explicitly
 	return ret, writerFunc(func(w io.Writer) (int64, error) {
 		return writeValue(e.ref, w, h, wt)
 	}), nil
@@ -261,23 +501,62 @@ func (e explicitEncoder) BerEncode() (Header, io.WriterTo, error) {
 
 //endregion
 
+//region type implicitEncoder
+
+// implicitEncoder replaces the tag of a [BerEncoder] with the tag implied by
+// the type of the encoded value (see applyType).
+type implicitEncoder struct {
+	enc BerEncoder
+	tag asn1.Tag
+}
+
+// BerEncode returns the encoding of the underlying encoder of e using the tag
+// of e.
+func (e implicitEncoder) BerEncode() (Header, io.WriterTo, error) {
+	h, wt, err := e.enc.BerEncode()
+	if err == nil && h.Tag != e.tag {
+		h.Tag = e.tag
+		if raw, ok := wt.(rawEncoding); ok {
+			// the original encoding cannot be used with a different tag
+			wt = bytes.NewReader(raw.content)
+		}
+	}
+	return h, wt, err
+}
+
+//endregion
+
 //region main encoding functions
 
 // makeEncoder creates a [BerEncoder] that encodes v. If v is to be omitted, ret
 // and err will both be nil. If no [BerEncoder] can be created for v, an
 // [UnsupportedTypeError] will be returned.
-func makeEncoder(v reflect.Value, params internal.FieldParameters) (ret BerEncoder, err error) {
+//
+// If scratch is not nil, the codecs of primitive types are taken from scratch
+// instead of being allocated. The returned encoder is then only valid until
+// scratch is used again.
+func makeEncoder(v reflect.Value, params internal.FieldParameters, scratch *inlineCodecs) (ret BerEncoder, err error) {
 	if !v.IsValid() {
 		return nil, &UnsupportedTypeError{Type: nil}
 	}
 
 	if params.Explicit {
+		// the tag of the inner encoding is determined by the type of v
+		inner := params
+		inner.Explicit = false
+		inner.Tag = 0
+		if ret, err = makeEncoder(v, inner, scratch); ret != nil {
+			ret = &explicitEncoder{v, ret}
+		}
+		return ret, err
+	}
+	if p := applyType(v.Type(), params); p.Tag != params.Tag {
+		params = p
 		defer func() {
 			if ret != nil {
-				ret = &explicitEncoder{v, ret}
+				ret = implicitEncoder{ret, params.Tag}
 			}
 		}()
-		params.Explicit = false
 	}
 
 	// If v is a named type and is addressable, start with its address, so that if
@@ -286,9 +565,20 @@ func makeEncoder(v reflect.Value, params internal.FieldParameters) (ret BerEncod
 		v = v.Addr()
 	}
 	for (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) && !v.IsNil() {
+		if v.Kind() == reflect.Pointer && registeredEncoder(v.Type().Elem()) != nil {
+			// registered codecs take precedence over methods
+			v = v.Elem()
+			break
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok && params.Text {
+			return textMarshalerCodec{v, m}, nil
+		}
 		switch vv := v.Interface().(type) {
 		case BerEncoder:
 			return vv, nil
+		case time.Time, *time.Time, netip.Addr, *netip.Addr, netip.Prefix, *netip.Prefix:
+			// These types implement encoding.BinaryMarshaler but are handled by
+			// codecFor.
 		case encoding.BinaryMarshaler:
 			return binaryMarshalerCodec{v, vv}, nil
 		}
@@ -305,55 +595,141 @@ func makeEncoder(v reflect.Value, params internal.FieldParameters) (ret BerEncod
 	}
 
 	vif := v.Interface()
-	if z, ok := vif.(interface{ IsZero() bool }); (ok && z.IsZero()) || (!ok && v.IsZero()) {
+	if z, ok := vif.(interface{ IsZero() bool }); (v.Kind() == reflect.Pointer && v.IsNil()) || (ok && z.IsZero()) || (!ok && v.IsZero()) {
 		if params.OmitZero {
 			return nil, nil
 		} else if params.Nullable {
 			return nullCodec{ref: v}, nil
 		}
 	}
+	if params.OmitEmpty && internal.IsEmpty(v) {
+		return nil, nil
+	}
+	if internal.IsNullable(v.Type()) {
+		if !v.Field(1).Bool() {
+			return nullCodec{ref: v}, nil
+		}
+		return makeEncoder(v.Field(0), params, scratch)
+	}
+	if internal.IsOptional(v.Type()) {
+		// a present value is encoded even if it is the zero value
+		params.OmitZero = false
+		return makeEncoder(v.Field(0), params, scratch)
+	}
 	if v.Kind() == reflect.Interface || (v.Kind() == reflect.Pointer && v.IsNil()) {
 		return nil, &UnsupportedTypeError{Type: nil}
 	}
+	if err = internal.CheckConstraints(v, params); err != nil {
+		return nil, &EncodeError{Value: v, Err: err}
+	}
+	if params.Choice && v.Kind() == reflect.Struct {
+		// only the chosen alternative is encoded
+		if params.Tag != 0 {
+			return nil, &EncodeError{Value: v, Err: errImplicitChoice}
+		}
+		alt, altParams, err := internal.ChoiceAlternative(v)
+		if err != nil {
+			return nil, &EncodeError{Value: v, Err: err}
+		}
+		if ret, err = makeEncoder(alt, altParams, scratch); ret != nil && altParams.Tag != 0 {
+			// the tag of the alternative replaces the tag of the CHOICE
+			ret = implicitEncoder{ret, altParams.Tag}
+		}
+		return ret, err
+	}
 
+	if enc := registeredEncoder(v.Type()); enc != nil {
+		return enc(v), nil
+	}
+	if m, ok := vif.(encoding.TextMarshaler); ok && params.Text {
+		return textMarshalerCodec{v, m}, nil
+	}
+	if params.Enum != "" && v.Kind() == reflect.String {
+		return enumStringCodec{v, params.Enum}, nil
+	}
 	switch vv := vif.(type) {
 	case BerEncoder:
 		return vv, nil
+	case time.Time, netip.Addr, netip.Prefix:
+		// handled by codecFor
 	case encoding.BinaryMarshaler:
 		return binaryMarshalerCodec{v, vv}, nil
 	}
 	if vv, ok := vif.(BerEncoder); ok {
 		return vv, nil
 	}
-	enc := codecFor(v, vif, params.Tag)
+	enc := codecFor(v, adjustTime(vif, params), params.Tag, scratch)
 	if enc != nil {
 		return enc, nil
 	}
 	switch v.Kind() {
 	case reflect.Struct:
-		e := &Sequence{}
-		for field, params := range internal.StructFields(v) {
-			if err = e.append(field, params); err != nil {
-				return nil, err
-			}
+		e, err := structEncoder(v)
+		if err != nil {
+			return nil, err
 		}
 		return e, nil
 	case reflect.Slice, reflect.Array:
 		if v.Type().Elem().Kind() == reflect.Uint8 {
 			return bytesCodec{ref: v}, nil
 		}
+		if params.Type == asn1.TagSet {
+			return setSliceEncoder{v}, nil
+		}
 		e := &Sequence{}
 		for i := range v.Len() {
 			if err = e.append(v.Index(i), internal.FieldParameters{}); err != nil {
-				return nil, err
+				return nil, withPath(err, "["+strconv.Itoa(i)+"]")
 			}
 		}
 		return e, nil
+	case reflect.Func:
+		if isSeq(v.Type()) {
+			return seqEncoder{v}, nil
+		}
+		return nil, &UnsupportedTypeError{Type: v.Type()}
 	default:
 		return nil, &UnsupportedTypeError{Type: v.Type()}
 	}
 }
 
+// structEncoder returns a [Sequence] encoding the fields of the struct v. It
+// is separate from makeEncoder because the range-over-func loop would move the
+// results of makeEncoder to the heap.
+func structEncoder(v reflect.Value) (*Sequence, error) {
+	e := &Sequence{}
+	for field, params := range internal.StructFields(v) {
+		if field.Type() == internal.ExtensibleType {
+			continue
+		} else if field.Type() == internal.ExtensibleDataType {
+			for i, b := range field.Interface().(asn1.ExtensibleData).Extensions {
+				path := params.Field + "[" + strconv.Itoa(i) + "]"
+				var rv RawElement
+				err := Unmarshal(b, &rv)
+				if err == nil {
+					err = e.append(reflect.ValueOf(rv), internal.FieldParameters{Field: path})
+				}
+				if err != nil {
+					return nil, &EncodeError{Value: field, Path: path, Err: err}
+				}
+			}
+			continue
+		}
+		if params.Rest && field.Kind() == reflect.Slice {
+			for i := range field.Len() {
+				if err := e.append(field.Index(i), internal.FieldParameters{Field: params.Field + "[" + strconv.Itoa(i) + "]"}); err != nil {
+					return nil, withPath(withPath(err, "["+strconv.Itoa(i)+"]"), params.Field)
+				}
+			}
+			continue
+		}
+		if err := e.append(field, params); err != nil {
+			return nil, withPath(err, params.Field)
+		}
+	}
+	return e, nil
+}
+
 // encodeValue begins encoding enc. This is the first step of the 2-step
 // encoding process. The second step is implemented by writeValue.
 //
@@ -367,16 +743,20 @@ func encodeValue(v reflect.Value, enc BerEncoder, params internal.FieldParameter
 		if errors.As(err, new(*EncodeError)) {
 			return h, wt, err
 		}
-		return h, wt, &EncodeError{v, err}
+		return h, wt, &EncodeError{Value: v, Err: err}
 	}
 	if h.Length == LengthIndefinite && !h.Constructed {
-		return h, nil, &EncodeError{v, errors.New("primitive, indefinite length encoding")}
+		return h, nil, &EncodeError{Value: v, Err: errors.New("primitive, indefinite length encoding")}
 	}
-	if params.Tag != 0 {
+	if params.Tag != 0 && params.Tag != h.Tag {
 		h.Tag = params.Tag
+		if raw, ok := wt.(rawEncoding); ok {
+			// the original encoding cannot be used with a different tag
+			wt = bytes.NewReader(raw.content)
+		}
 	}
 	if h.Tag == 0 {
-		return h, wt, &EncodeError{v, errors.New("missing class or tag")}
+		return h, wt, &EncodeError{Value: v, Err: errors.New("missing class or tag")}
 	}
 	return h, wt, nil
 }
@@ -395,27 +775,50 @@ func writeValue(v reflect.Value, w io.Writer, h Header, wt io.WriterTo) (n int64
 	if h.Length == LengthIndefinite && !h.Constructed {
 		panic("primitive, indefinite length encoding")
 	}
+	if sw := stateWriterOf(w); sw != nil {
+		sw.depth++
+		defer func() { sw.depth-- }()
+		if sw.stats != nil {
+			sw.stats.value(sw.depth)
+		}
+		for _, hook := range sw.hooks {
+			if err = hook(h, sw.path); err != nil {
+				return 0, &EncodeError{Value: v, Err: err}
+			}
+		}
+	}
+	if raw, ok := wt.(rawEncoding); ok {
+		n2, err := w.Write(raw.full)
+		return int64(n2), err
+	}
 	n, err = h.writeTo(w.(io.ByteWriter))
 	if err != nil {
 		return n, err
 	}
-	ew := &limitWriter{w, h.Length, 0}
+	if c, ok := wt.(*inlineContent); ok && len(c.b) == h.Length {
+		// fast path that avoids allocating a limitWriter
+		n2, err := w.Write(c.b)
+		return n + int64(n2), err
+	}
+	remaining := h.Length
 	if wt != nil {
+		ew := &limitWriter{w, h.Length, 0, stateWriterOf(w)}
 		n2, err := wt.WriteTo(ew)
 		n += n2
 		if err != nil {
 			return n, err
 		}
 		if n2 != ew.C {
-			return n - n2 + ew.C, &EncodeError{v, io.ErrShortWrite}
+			return n - n2 + ew.C, &EncodeError{Value: v, Err: io.ErrShortWrite}
 		}
+		remaining = ew.Len()
 	}
 	if h.Length == LengthIndefinite {
 		var n2 int
 		n2, err = w.Write([]byte{0x00, 0x00})
 		n += int64(n2)
-	} else if ew.Len() != 0 {
-		err = &EncodeError{v, errors.New("BerEncode did not write all its bytes")}
+	} else if remaining != 0 {
+		err = &EncodeError{Value: v, Err: errors.New("BerEncode did not write all its bytes")}
 	}
 	return n, err
 }
@@ -429,9 +832,10 @@ func writeValue(v reflect.Value, w io.Writer, h Header, wt io.WriterTo) (n int64
 //
 // Setting N to [LengthIndefinite] disables the write limiter.
 type limitWriter struct {
-	W io.Writer
-	N int   // remaining bytes
-	C int64 // bytes written
+	W  io.Writer
+	N  int          // remaining bytes
+	C  int64        // bytes written
+	sw *stateWriter // stateWriter of W, if any
 }
 
 // Len returns the number of bytes remaining in w. Writing more than Len() bytes
@@ -484,8 +888,14 @@ func (w *limitWriter) WriteByte(b byte) (err error) {
 //
 // To create a new Encoder, use the [NewEncoder] function.
 type Encoder struct {
-	w   io.Writer
-	buf *bufio.Writer
+	w     io.Writer
+	buf   *bufio.Writer
+	opts  EncoderOptions
+	hooks []ValueHook
+	stats stats
+	sw    stateWriter // reused by each call to EncodeWithParams
+
+	codecs inlineCodecs // reused by each call to EncodeWithParams
 }
 
 // NewEncoder creates a new [Encoder]. Writing BER data requires single-byte
@@ -495,13 +905,19 @@ type Encoder struct {
 // in [Encoder.Encode] or [Encoder.EncodeWithParams].
 func NewEncoder(w io.Writer) *Encoder {
 	if _, ok := w.(io.Writer); ok {
-		return &Encoder{w, nil}
+		return &Encoder{w: w}
 	}
 	e := &Encoder{buf: bufio.NewWriterSize(w, 512)}
 	e.w = e.buf
 	return e
 }
 
+// SetOptions configures the output of e for subsequent calls to [Encoder.Encode]
+// and [Encoder.EncodeWithParams].
+func (e *Encoder) SetOptions(opts EncoderOptions) {
+	e.opts = opts
+}
+
 // Encode writes the BER-encoding of val to its underlying writer. If encoding
 // fails, an error is returned. If a value fails validation before encoding, an
 // [EncodeError] will be returned.
@@ -512,32 +928,146 @@ func (e *Encoder) Encode(val any) error {
 // EncodeWithParams writes the BER-encoding of val to its underlying writer. The
 // format for params is described in the asn1 package. Using the `asn1:"-"`
 // option has no effect here.
-func (e *Encoder) EncodeWithParams(val any, params string) (err error) {
+func (e *Encoder) EncodeWithParams(val any, params string) error {
+	n, err := e.encode(val, params)
+	e.stats.bytes.Add(n)
+	e.stats.error(err)
+	return err
+}
+
+// encode implements [Encoder.EncodeWithParams] and returns the number of bytes
+// written to the underlying writer of e.
+func (e *Encoder) encode(val any, params string) (n int64, err error) {
 	fp := internal.ParseFieldParameters(params)
 	v := reflect.ValueOf(val)
-	enc, err := makeEncoder(v, fp)
-	if err != nil {
-		return err
-	}
-	if enc == nil {
-		return nil
+	enc, err := makeEncoder(v, fp, &e.codecs)
+	if err != nil || enc == nil {
+		return 0, err
 	}
 	h, wt, err := encodeValue(v, enc, fp)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	var w io.Writer = e.w
+	var scratch *bytes.Buffer
+	if e.opts.Verify {
+		scratch = getBuffer()
+		defer putBuffer(scratch)
+		w = scratch
+	}
+	e.sw = stateWriter{W: w, hooks: e.hooks, stats: &e.stats}
+	if e.opts.UseIndefiniteLength {
+		n, err = writeIndefiniteValue(v, &e.sw, h, wt)
+	} else {
+		n, err = writeValue(v, &e.sw, h, wt)
+	}
+	if err == nil && scratch != nil {
+		n = 0
+		if err = verifyEncoding(v, scratch.Bytes(), fp); err == nil {
+			var n2 int
+			n2, err = e.w.Write(scratch.Bytes())
+			n = int64(n2)
+		}
 	}
-	_, err = writeValue(v, e.w, h, wt)
 	if e.buf == nil {
-		return err
+		return n, err
 	}
 	if fErr := e.buf.Flush(); err == nil {
 		err = fErr
 	}
-	return err
+	return n, err
+}
+
+// BufferedLength encodes a constructed data value whose contents are written
+// by f. The data values written to the [Encoder] passed to f are buffered in
+// memory so that the definite-length encoding can be used even if the length of
+// the contents is not known in advance. This is required for DER output of
+// dynamically built values. Nested data values retain their own length
+// encoding.
+//
+// The returned header uses the tag [asn1.TagSequence]. Callers may change the
+// tag of the header before returning it. If f returns an error, that error is
+// returned by BufferedLength.
+//
+// BufferedLength is intended to be used in implementations of [BerEncoder]:
+//
+//	func (v *T) BerEncode() (ber.Header, io.WriterTo, error) {
+//		return ber.BufferedLength(func(e *ber.Encoder) error {
+//			for _, x := range v.items {
+//				if err := e.Encode(x); err != nil {
+//					return err
+//				}
+//			}
+//			return nil
+//		})
+//	}
+func BufferedLength(f func(*Encoder) error) (Header, io.WriterTo, error) {
+	var buf bytes.Buffer
+	if err := f(NewEncoder(&buf)); err != nil {
+		return Header{}, nil, err
+	}
+	return Header{
+		Tag:         asn1.TagSequence,
+		Length:      buf.Len(),
+		Constructed: true,
+	}, bytes.NewReader(buf.Bytes()), nil
+}
+
+// writeIndefiniteValue writes the data value encoding of h and wt to w using
+// the indefinite-length encoding. See [EncoderOptions.UseIndefiniteLength] for
+// details. The encoding is buffered in memory.
+func writeIndefiniteValue(v reflect.Value, w io.Writer, h Header, wt io.WriterTo) (int64, error) {
+	def, buf := getBuffer(), getBuffer()
+	defer putBuffer(def)
+	defer putBuffer(buf)
+	var dw io.Writer = def
+	if sw := stateWriterOf(w); sw != nil {
+		dw = &stateWriter{W: def, hooks: sw.hooks, stats: sw.stats, path: sw.path, depth: sw.depth}
+	}
+	if _, err := writeValue(v, dw, h, wt); err != nil {
+		return 0, err
+	}
+	d := NewDecoder(bytes.NewReader(def.Bytes()))
+	h, r, err := d.Next()
+	if err != nil {
+		return 0, err
+	}
+	if err = writeIndefinite(buf, h, r); err == nil {
+		err = r.Close()
+	}
+	if err != nil {
+		return 0, &EncodeError{Value: v, Err: err}
+	}
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
 }
 
 //endregion
 
+// bufferPool holds scratch buffers for encoding. Reusing buffers reduces the
+// number of allocations when many values are encoded.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxPooledBuffer is the capacity above which buffers are not returned to
+// bufferPool. This prevents a single large encoding from retaining memory.
+const maxPooledBuffer = 64 << 10
+
+// getBuffer returns an empty scratch buffer from bufferPool.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to bufferPool. The caller must not use buf afterward.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
 // Marshal returns the BER-encoding of val or an error if encoding fails.
 func Marshal(val any) ([]byte, error) {
 	return MarshalWithParams(val, "")
@@ -547,23 +1077,66 @@ func Marshal(val any) ([]byte, error) {
 // returns it. The format of the params is described in the asn1 package. Using
 // the `asn1:"-"` option has no effect here.
 func MarshalWithParams(val any, params string) ([]byte, error) {
+	return marshalAppend(nil, val, internal.ParseFieldParameters(params))
+}
+
+// MarshalAppend appends the BER-encoding of val to dst and returns the extended
+// slice. If encoding fails, dst is returned unmodified together with the error.
+// MarshalAppend reuses internal scratch buffers, so that encoding many values
+// into the same slice causes few allocations:
+//
+//	buf := make([]byte, 0, 1024)
+//	for _, v := range values {
+//		buf, err = ber.MarshalAppend(buf[:0], v)
+//		...
+//	}
+func MarshalAppend(dst []byte, val any) ([]byte, error) {
+	return marshalAppend(dst, val, internal.FieldParameters{})
+}
+
+// EncodedLength returns the number of bytes of the BER-encoding of val using
+// the specified params. Only the first step of the encoding process is
+// performed, so no content octets are produced. This can be used to allocate
+// buffers of the exact size or to reject oversized values before encoding them.
+//
+// If the encoding of val uses the indefinite-length format, its length cannot be
+// determined in advance and EncodedLength returns [LengthIndefinite].
+func EncodedLength(val any, params string) (int, error) {
 	fp := internal.ParseFieldParameters(params)
 	v := reflect.ValueOf(val)
-	enc, err := makeEncoder(v, fp)
+	enc, err := makeEncoder(v, fp, nil)
+	if err != nil || enc == nil {
+		return 0, err
+	}
+	h, wt, err := encodeValue(v, enc, fp)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	if enc == nil {
-		return nil, nil
+	return encodedLength(h, wt), nil
+}
+
+// marshalAppend implements [MarshalAppend] and [MarshalWithParams]. The value
+// is encoded into a scratch buffer first so that dst is only extended if
+// encoding succeeds.
+func marshalAppend(dst []byte, val any, fp internal.FieldParameters) ([]byte, error) {
+	v := reflect.ValueOf(val)
+	scratch := codecsPool.Get().(*inlineCodecs)
+	defer codecsPool.Put(scratch)
+	enc, err := makeEncoder(v, fp, scratch)
+	if err != nil || enc == nil {
+		return dst, err
 	}
 	h, wt, err := encodeValue(v, enc, fp)
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
-	var buf bytes.Buffer
-	if h.Length != LengthIndefinite {
-		buf.Grow(h.Length)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if l := encodedLength(h, wt); l != LengthIndefinite {
+		buf.Grow(l)
+	}
+	if _, err = writeValue(v, buf, h, wt); err != nil {
+		return dst, err
 	}
-	_, err = writeValue(v, &buf, h, wt)
-	return buf.Bytes(), err
+	return append(dst, buf.Bytes()...), nil
 }
@@ -6,7 +6,16 @@ package ber
 
 import (
 	"bytes"
+	stdasn1 "encoding/asn1"
+	"errors"
+	"io"
+	"iter"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"codello.dev/asn1"
 )
 
 func TestMarshal(t *testing.T) {
@@ -31,6 +40,18 @@ func TestMarshal(t *testing.T) {
 			B *int   `asn1:"nullable"`
 			C int    `asn1:"nullable,omitzero"`
 		}{"", nil, 5}, []byte{0x30, 0x07, 0x05, 0x00, 0x05, 0x00, 0x02, 0x01, 0x05}},
+		"Extensible": {struct {
+			A int
+			asn1.Extensible
+		}{A: 5}, []byte{0x30, 0x03, 0x02, 0x01, 0x05}},
+		"Presence": {struct {
+			A int
+			asn1.Presence
+		}{A: 5}, []byte{0x30, 0x03, 0x02, 0x01, 0x05}},
+		"ExtensibleData": {struct {
+			A int
+			asn1.ExtensibleData
+		}{5, asn1.ExtensibleData{Extensions: [][]byte{{0x04, 0x01, 0xFF}}}}, []byte{0x30, 0x06, 0x02, 0x01, 0x05, 0x04, 0x01, 0xFF}},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -44,3 +65,446 @@ func TestMarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshal_Constraints(t *testing.T) {
+	type inner struct {
+		A int `asn1:"range:0..10"`
+	}
+	tests := map[string]struct {
+		val      any
+		wantPath string
+		wantErr  error
+	}{
+		"Range": {struct {
+			A int `asn1:"range:0..10"`
+		}{20}, "A", ErrRange},
+		"Size": {struct {
+			A string `asn1:"size:1..3"`
+		}{"abcd"}, "A", ErrSize},
+		"Nested": {struct{ Values []inner }{[]inner{{1}, {11}}}, "Values[1].A", ErrRange},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Marshal(tt.val)
+			var encErr *EncodeError
+			if !errors.As(err, &encErr) {
+				t.Fatalf("Marshal() error = %v, want *EncodeError", err)
+			}
+			if encErr.Path != tt.wantPath {
+				t.Errorf("Marshal() error path = %q, want %q", encErr.Path, tt.wantPath)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Marshal() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// bufferedList is a BerEncoder that writes its values via BufferedLength.
+type bufferedList []any
+
+func (l bufferedList) BerEncode() (Header, io.WriterTo, error) {
+	return BufferedLength(func(e *Encoder) error {
+		for _, v := range l {
+			if err := e.Encode(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestMarshalAppend(t *testing.T) {
+	dst := []byte{0xAB}
+	got, err := MarshalAppend(dst, struct{ A, B int }{1, 2})
+	if err != nil {
+		t.Fatalf("MarshalAppend() error = %v", err)
+	}
+	want := []byte{0xAB, 0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}
+	if !bytes.Equal(got, want) {
+		t.Errorf("MarshalAppend() = % X, want % X", got, want)
+	}
+
+	got, err = MarshalAppend(dst, asn1.ObjectIdentifier{3})
+	if err == nil || !bytes.Equal(got, dst) {
+		t.Errorf("MarshalAppend() = % X, %v, want % X and an error", got, err, dst)
+	}
+
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		if buf, err = MarshalAppend(buf[:0], 1000); err != nil {
+			t.Fatalf("MarshalAppend() error = %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("MarshalAppend() allocs = %v, want 0", allocs)
+	}
+}
+
+func TestEncodedLength(t *testing.T) {
+	tests := map[string]struct {
+		val     any
+		params  string
+		want    int
+		wantErr bool
+	}{
+		"Int":        {1000, "", 4, false},
+		"Struct":     {struct{ A, B int }{1, 2}, "", 8, false},
+		"Tagged":     {true, "tag:200", 5, false},
+		"LongLength": {make([]byte, 200), "", 203, false},
+		"Seq":        {slices.Values([]int{1, 2}), "", LengthIndefinite, false},
+		"Error":      {asn1.ObjectIdentifier{3}, "", 0, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := EncodedLength(tt.val, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodedLength() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EncodedLength() = %d, want %d", got, tt.want)
+			}
+			if tt.wantErr || got == LengthIndefinite {
+				return
+			}
+			b, err := MarshalWithParams(tt.val, tt.params)
+			if err != nil {
+				t.Fatalf("MarshalWithParams() error = %v", err)
+			}
+			if len(b) != got {
+				t.Errorf("len(MarshalWithParams()) = %d, want %d", len(b), got)
+			}
+		})
+	}
+}
+
+func TestBufferedLength(t *testing.T) {
+	tests := map[string]struct {
+		val     any
+		want    []byte
+		wantErr bool
+	}{
+		"Empty":  {bufferedList{}, []byte{0x30, 0x00}, false},
+		"Values": {bufferedList{1, true}, []byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x01, 0x01, 0xFF}, false},
+		"Tagged": {struct {
+			L bufferedList `asn1:"tag:1"`
+		}{bufferedList{1}}, []byte{0x30, 0x05, 0xA1, 0x03, 0x02, 0x01, 0x01}, false},
+		"Error": {bufferedList{make(chan int)}, nil, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Marshal(tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal() = % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequence_Modify(t *testing.T) {
+	s := &Sequence{}
+	if err := s.Append(1, 3); err != nil {
+		t.Fatalf("s.Append() error = %v", err)
+	}
+	if err := s.Insert(1, 2); err != nil {
+		t.Fatalf("s.Insert() error = %v", err)
+	}
+	if err := s.AppendRaw(RawValue{Tag: asn1.TagNull}); err != nil {
+		t.Fatalf("s.AppendRaw() error = %v", err)
+	}
+	if err := s.Insert(0, 0); err != nil {
+		t.Fatalf("s.Insert() error = %v", err)
+	}
+	s.Remove(2)
+	if s.Len() != 4 {
+		t.Errorf("s.Len() = %d, want 4", s.Len())
+	}
+	got, err := Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := []byte{0x30, 0x0B, 0x02, 0x01, 0x00, 0x02, 0x01, 0x01, 0x02, 0x01, 0x03, 0x05, 0x00}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal() = % X, want % X", got, want)
+	}
+}
+
+func TestEncoder_UseIndefiniteLength(t *testing.T) {
+	type record struct {
+		A int
+		B []int
+	}
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.SetOptions(EncoderOptions{UseIndefiniteLength: true})
+	if err := e.Encode(record{1, []int{2}}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	want := []byte{0x30, 0x80, 0x02, 0x01, 0x01, 0x30, 0x80, 0x02, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Encode() = % X, want % X", buf.Bytes(), want)
+	}
+
+	t.Run("Chunked", func(t *testing.T) {
+		val := struct {
+			Octets []byte
+			Bits   asn1.BitString
+		}{bytes.Repeat([]byte{0xAB}, 2500), asn1.BitString{Bytes: bytes.Repeat([]byte{0xF0}, 1500), BitLength: 1500*8 - 4}}
+		buf.Reset()
+		if err := e.Encode(val); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		prefix := []byte{0x30, 0x80, 0x24, 0x80, 0x04, 0x82, 0x03, 0xE8, 0xAB}
+		if !bytes.HasPrefix(buf.Bytes(), prefix) {
+			t.Errorf("Encode() = % X..., want prefix % X", buf.Bytes()[:len(prefix)], prefix)
+		}
+		got := val
+		got.Octets, got.Bits = nil, asn1.BitString{}
+		if err := Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if !bytes.Equal(got.Octets, val.Octets) || !bytes.Equal(got.Bits.Bytes, val.Bits.Bytes) || got.Bits.BitLength != val.Bits.BitLength {
+			t.Errorf("Unmarshal() did not round-trip chunked strings")
+		}
+	})
+}
+
+func TestEncoder_Verify(t *testing.T) {
+	type record struct {
+		Name string
+		When time.Time `asn1:"utc"`
+		Tags []int
+	}
+	when := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	tests := map[string]struct {
+		val       record
+		wantDiffs []string
+	}{
+		"Equal":      {record{"a", when, nil}, nil},
+		"EmptySlice": {record{"a", when, []int{}}, nil},
+		"Lossy":      {record{"a", when.Add(500 * time.Millisecond), []int{1}}, []string{"When"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.SetOptions(EncoderOptions{Verify: true})
+			err := e.Encode(tt.val)
+			if tt.wantDiffs == nil {
+				if err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+				if buf.Len() == 0 {
+					t.Errorf("Encode() wrote no data")
+				}
+				return
+			}
+			var verr *VerifyError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Encode() error = %v, want *VerifyError", err)
+			}
+			if len(verr.Diffs) != len(tt.wantDiffs) {
+				t.Fatalf("Encode() diffs = %q, want %d", verr.Diffs, len(tt.wantDiffs))
+			}
+			for i, d := range verr.Diffs {
+				if !strings.HasPrefix(d, tt.wantDiffs[i]+": ") {
+					t.Errorf("Encode() diffs[%d] = %q, want path %q", i, d, tt.wantDiffs[i])
+				}
+			}
+			if buf.Len() != 0 {
+				t.Errorf("Encode() wrote % X, want nothing", buf.Bytes())
+			}
+		})
+	}
+}
+
+func TestEncoder_Allocs(t *testing.T) {
+	// The codecs of primitive types are reused by the encoder and their content
+	// octets are stored inline in the codec, so encoding does not allocate.
+	tests := map[string]struct {
+		val  any
+		want []byte
+	}{
+		"BOOLEAN":     {true, []byte{0x01, 0x01, 0xFF}},
+		"INTEGER":     {1000, []byte{0x02, 0x02, 0x03, 0xE8}},
+		"ENUMERATED":  {testEnum(3), []byte{0x0A, 0x01, 0x03}},
+		"NULL":        {asn1.Null{}, []byte{0x05, 0x00}},
+		"OID":         {asn1.ObjectIdentifier{1, 2, 840, 113549}, []byte{0x06, 0x06, 0x2A, 0x86, 0x48, 0x86, 0xF7, 0x0D}},
+		"RelativeOID": {asn1.RelativeOID{8571, 3, 2}, []byte{0x0D, 0x04, 0xC2, 0x7B, 0x03, 0x02}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			buf.Grow(64)
+			e := NewEncoder(&buf)
+			allocs := testing.AllocsPerRun(100, func() {
+				buf.Reset()
+				if err := e.Encode(tt.val); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+			})
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("Encode() = % X, want % X", buf.Bytes(), tt.want)
+			}
+			if allocs != 0 {
+				t.Errorf("Encode() allocs = %v, want 0", allocs)
+			}
+		})
+	}
+}
+
+func TestMarshal_Seq(t *testing.T) {
+	tests := map[string]struct {
+		val     any
+		want    []byte
+		wantErr bool
+	}{
+		"Values": {slices.Values([]int{1, 2}), []byte{0x30, 0x80, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x00, 0x00}, false},
+		"Nil":    {iter.Seq[int](nil), []byte{0x30, 0x80, 0x00, 0x00}, false},
+		"Field": {struct {
+			A iter.Seq[bool]
+		}{slices.Values([]bool{true})}, []byte{0x30, 0x80, 0x30, 0x80, 0x01, 0x01, 0xFF, 0x00, 0x00, 0x00, 0x00}, false},
+		"OmitZero": {struct {
+			A iter.Seq[bool] `asn1:"omitzero"`
+		}{}, []byte{0x30, 0x00}, false},
+		"Error": {slices.Values([]any{1, make(chan int)}), nil, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Marshal(tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal() = % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshal_NestedPointers(t *testing.T) {
+	i := 5
+	pi := &i
+	got, err := Marshal(struct{ A **int }{&pi})
+	if want := []byte{0x30, 0x03, 0x02, 0x01, 0x05}; err != nil || !bytes.Equal(got, want) {
+		t.Errorf("Marshal() = % X, %v, want % X, <nil>", got, err, want)
+	}
+	pi = nil
+	_, err = Marshal(struct{ A **int }{&pi})
+	var typeErr *UnsupportedTypeError
+	if !errors.As(err, &typeErr) || err.Error() == "" {
+		t.Errorf("Marshal() error = %q, want *UnsupportedTypeError with message", err)
+	}
+}
+
+func TestMarshal_OmitEmpty(t *testing.T) {
+	type S struct {
+		A []int  `asn1:"optional,omitempty"`
+		B string `asn1:"optional,omitempty"`
+		C []int  `asn1:"optional,omitzero"`
+	}
+	tests := map[string]struct {
+		val  S
+		want []byte
+	}{
+		"Nil":      {S{}, []byte{0x30, 0x00}},
+		"Empty":    {S{A: []int{}, C: []int{}}, []byte{0x30, 0x02, 0x30, 0x00}},
+		"NonEmpty": {S{A: []int{1}, B: "a"}, []byte{0x30, 0x08, 0x30, 0x03, 0x02, 0x01, 0x01, 0x0C, 0x01, 'a'}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Marshal(tt.val)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal() = % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshal_SetSlice(t *testing.T) {
+	type S struct {
+		A []int   `asn1:"set"`
+		B [2]bool `asn1:"set,tag:0"`
+	}
+	tests := map[string]struct {
+		val  S
+		data []byte
+	}{
+		"Sorted": {S{[]int{1, 3, 256}, [2]bool{false, true}}, []byte{0x30, 0x14,
+			0x31, 0x0A, 0x02, 0x01, 0x01, 0x02, 0x01, 0x03, 0x02, 0x02, 0x01, 0x00,
+			0xA0, 0x06, 0x01, 0x01, 0x00, 0x01, 0x01, 0xFF}},
+		"Empty": {S{nil, [2]bool{}}, []byte{0x30, 0x0A, 0x31, 0x00,
+			0xA0, 0x06, 0x01, 0x01, 0x00, 0x01, 0x01, 0x00}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Marshal(tt.val)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("Marshal() = % X, want % X", got, tt.data)
+			}
+		})
+	}
+
+	val := S{[]int{256, 3, 1}, [2]bool{true, false}}
+	got, err := Marshal(val)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := tests["Sorted"].data; !bytes.Equal(got, want) {
+		t.Errorf("Marshal() = % X, want % X", got, want)
+	}
+
+	var dec S
+	data := []byte{0x30, 0x14,
+		0x31, 0x0A, 0x02, 0x02, 0x01, 0x00, 0x02, 0x01, 0x03, 0x02, 0x01, 0x01,
+		0xA0, 0x06, 0x01, 0x01, 0xFF, 0x01, 0x01, 0x00}
+	if err = Unmarshal(data, &dec); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !slices.Equal(dec.A, val.A) || dec.B != val.B {
+		t.Errorf("Unmarshal() = %v, want %v", dec, val)
+	}
+}
+
+func TestMarshal_StdlibTags(t *testing.T) {
+	type S struct {
+		A string    `asn1:"ia5"`
+		B string    `asn1:"printable"`
+		C string    `asn1:"numeric"`
+		D string    `asn1:"utf8"`
+		E time.Time `asn1:"utc"`
+		F time.Time `asn1:"generalized"`
+		G []int     `asn1:"set"`
+		H int       `asn1:"optional,explicit,tag:0"`
+		I []int     `asn1:"optional,omitempty"`
+		J int       `asn1:"any"`
+	}
+	val := S{"a@b", "Ab", "12", "", time.Date(2014, 3, 12, 13, 31, 42, 0, time.UTC), time.Date(2054, 3, 12, 13, 31, 42, 0, time.UTC), []int{1, 2}, 3, nil, 4}
+	want, err := stdasn1.Marshal(val)
+	if err != nil {
+		t.Fatalf("encoding/asn1.Marshal() error = %v", err)
+	}
+	got, err := Marshal(val)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal() = % X, want % X", got, want)
+	}
+	var dec S
+	if err = Unmarshal(want, &dec); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !dec.E.Equal(val.E) || !dec.F.Equal(val.F) || dec.A != val.A || !slices.Equal(dec.G, val.G) || dec.H != val.H || dec.J != val.J {
+		t.Errorf("Unmarshal() = %v, want %v", dec, val)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"errors"
+	"io"
+
+	"codello.dev/asn1/tlv"
+)
+
+// Feeder is a push-style decoder for a stream of BER-encoded data values.
+// Instead of reading from an [io.Reader], a Feeder is written to as data
+// arrives, for example from a non-blocking socket or an event loop. Whenever
+// the data written so far contains a complete top-level data value, it is
+// passed to the callback of the Feeder. This avoids the need for a goroutine
+// per connection that blocks in a read call:
+//
+//	f := ber.NewFeeder(func(rv ber.RawElement) error {
+//		var msg Message
+//		if err := ber.Unmarshal(rv.FullBytes, &msg); err != nil {
+//			return err
+//		}
+//		return handle(msg)
+//	})
+//	// whenever data is available
+//	if _, err := f.Write(chunk); err != nil {
+//		// close the connection
+//	}
+//
+// The [RawElement] passed to the callback references the internal buffer of the
+// Feeder. It is only valid until the callback returns.
+//
+// If the syntax of a data value is invalid or the callback returns an error,
+// the Feeder stops and the error is returned by all subsequent calls to
+// [Feeder.Write]. Offsets reported in syntax errors are relative to the start
+// of the data value encoding containing the error.
+//
+// A Feeder is not safe for concurrent use.
+type Feeder struct {
+	fn  func(RawElement) error
+	buf []byte
+	max int
+	err error
+}
+
+// NewFeeder creates a new [Feeder] that calls fn for each complete top-level
+// data value written to it.
+func NewFeeder(fn func(RawElement) error) *Feeder {
+	return &Feeder{fn: fn}
+}
+
+// SetMaxSize limits the size of a single data value encoding to n bytes. If
+// more than n bytes have been written without completing a data value, the
+// Feeder stops with a [SyntaxError] wrapping [ErrValueTooLarge]. This protects
+// against peers that send an unbounded amount of data. A value of 0 (the
+// default) disables the limit.
+func (f *Feeder) SetMaxSize(n int) {
+	f.max = max(n, 0)
+}
+
+// Write implements the [io.Writer] interface. It appends p to the internal
+// buffer of f and calls the callback of f for all data values that are
+// complete. The bytes of an incomplete data value are retained until more
+// data is written. Write always consumes all of p unless f has stopped
+// previously.
+func (f *Feeder) Write(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.buf = append(f.buf, p...)
+	start := 0
+	for f.err == nil && start < len(f.buf) {
+		n, _, err := tlv.ScanValues(f.buf[start:], false)
+		if err != nil {
+			// Parsing the encoding gives a more detailed error.
+			if _, _, f.err = parseRawElement(f.buf[start:]); f.err == nil || f.err == io.EOF {
+				var se *tlv.SyntaxError
+				if errors.As(err, &se) {
+					err = se.Err
+				}
+				f.err = &SyntaxError{Err: err}
+			}
+		} else if n == 0 {
+			if f.max > 0 && len(f.buf)-start > f.max {
+				f.err = &SyntaxError{Err: ErrValueTooLarge}
+			}
+			break
+		} else if f.max > 0 && n > f.max {
+			f.err = &SyntaxError{Err: ErrValueTooLarge}
+		} else {
+			var rv RawElement
+			if rv, _, f.err = parseRawElement(f.buf[start : start+n]); f.err == nil {
+				f.err = f.fn(rv)
+			}
+			start += n
+		}
+	}
+	// Retain the incomplete data value at the start of the buffer.
+	f.buf = f.buf[:copy(f.buf, f.buf[start:])]
+	return len(p), f.err
+}
+
+// Buffered returns the number of bytes of an incomplete data value that have
+// been written to f but not yet passed to the callback.
+func (f *Feeder) Buffered() int {
+	return len(f.buf)
+}
+
+// Close notifies f that no more data will be written. If an incomplete data
+// value has been written to f, a [SyntaxError] wrapping [ErrTruncated] is
+// returned. If f has stopped with an error, that error is returned instead.
+func (f *Feeder) Close() error {
+	if f.err == nil && len(f.buf) > 0 {
+		f.err = &SyntaxError{Err: ErrTruncated}
+	}
+	return f.err
+}
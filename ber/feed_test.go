@@ -0,0 +1,96 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFeeder(t *testing.T) {
+	// INTEGER 1, SEQUENCE { INTEGER 2 } (indefinite), OCTET STRING 0x03
+	data := []byte{0x02, 0x01, 0x01, 0x30, 0x80, 0x02, 0x01, 0x02, 0x00, 0x00, 0x04, 0x01, 0x03}
+	tests := map[string]struct {
+		data     []byte
+		chunk    int // size of the chunks written to the Feeder
+		max      int
+		want     [][]byte
+		wantErr  error
+		buffered int
+	}{
+		"Single":       {data, len(data), 0, [][]byte{data[:3], data[3:10], data[10:]}, nil, 0},
+		"Bytewise":     {data, 1, 0, [][]byte{data[:3], data[3:10], data[10:]}, nil, 0},
+		"Chunks":       {data, 4, 0, [][]byte{data[:3], data[3:10], data[10:]}, nil, 0},
+		"Incomplete":   {data[:8], 3, 0, [][]byte{data[:3]}, nil, 5},
+		"MaxSize":      {data, 2, 4, [][]byte{data[:3]}, ErrValueTooLarge, 0},
+		"MaxSizeChunk": {data, len(data), 4, [][]byte{data[:3]}, ErrValueTooLarge, 0},
+		"Invalid":      {[]byte{0x02, 0x01, 0x01, 0x00, 0x00}, 5, 0, [][]byte{{0x02, 0x01, 0x01}}, &SyntaxError{}, 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got [][]byte
+			f := NewFeeder(func(rv RawElement) error {
+				got = append(got, slices.Clone(rv.FullBytes))
+				return nil
+			})
+			f.SetMaxSize(tt.max)
+			var err error
+			for b := range slices.Chunk(tt.data, tt.chunk) {
+				if _, err = f.Write(b); err != nil {
+					break
+				}
+			}
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("f.Write() error = %v, want nil", err)
+				}
+			case *SyntaxError:
+				if !errors.As(err, &want) {
+					t.Fatalf("f.Write() error = %v, want %T", err, want)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("f.Write() error = %v, want %v", err, want)
+				}
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("callback values = % X, want % X", got, tt.want)
+			}
+			if err == nil && f.Buffered() != tt.buffered {
+				t.Errorf("f.Buffered() = %d, want %d", f.Buffered(), tt.buffered)
+			}
+		})
+	}
+
+	t.Run("Close", func(t *testing.T) {
+		f := NewFeeder(func(rv RawElement) error { return nil })
+		if _, err := f.Write(data[:5]); err != nil {
+			t.Fatalf("f.Write() error = %v", err)
+		}
+		if err := f.Close(); !errors.Is(err, ErrTruncated) {
+			t.Errorf("f.Close() error = %v, want %v", err, ErrTruncated)
+		}
+	})
+
+	t.Run("CallbackError", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		f := NewFeeder(func(rv RawElement) error {
+			calls++
+			return errStop
+		})
+		if _, err := f.Write(data); err != errStop {
+			t.Errorf("f.Write() error = %v, want %v", err, errStop)
+		}
+		if _, err := f.Write(data); err != errStop {
+			t.Errorf("f.Write() error = %v, want %v", err, errStop)
+		}
+		if calls != 1 {
+			t.Errorf("callback called %d times, want %d", calls, 1)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"errors"
+	"fmt"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/tlv"
+)
+
+// tagInitialContextToken is the tag of the GSS-API InitialContextToken.
+const tagInitialContextToken = asn1.ClassApplication | 0
+
+// AppendInitialContextToken appends the GSS-API InitialContextToken framing
+// defined in Section 3.1 of [RFC 2743] to dst and returns the extended buffer.
+// The framing consists of an [APPLICATION 0] header containing the DER
+// encoding of the mechanism identifier mech, followed by the mechanism-specific
+// inner token. The inner token is appended as-is. It does not have to be an
+// ASN.1 encoding.
+//
+// [RFC 2743]: https://www.rfc-editor.org/rfc/rfc2743
+func AppendInitialContextToken(dst []byte, mech asn1.ObjectIdentifier, token []byte) ([]byte, error) {
+	oid, err := Marshal(mech)
+	if err != nil {
+		return dst, err
+	}
+	h := tlv.Header{Tag: tagInitialContextToken, Constructed: true, Length: len(oid) + len(token)}
+	dst = h.AppendTo(dst)
+	dst = append(dst, oid...)
+	return append(dst, token...), nil
+}
+
+// ParseInitialContextToken parses the GSS-API InitialContextToken framing
+// defined in Section 3.1 of [RFC 2743]. It returns the mechanism identifier and
+// the inner token. The inner token references b instead of copying it. If b
+// does not contain exactly one InitialContextToken, an error is returned.
+//
+// [RFC 2743]: https://www.rfc-editor.org/rfc/rfc2743
+func ParseInitialContextToken(b []byte) (mech asn1.ObjectIdentifier, token []byte, err error) {
+	h, n, err := tlv.ParseHeader(b)
+	if err != nil {
+		return nil, nil, &SyntaxError{Err: err}
+	}
+	if h.Tag != tagInitialContextToken || !h.Constructed {
+		return nil, nil, &StructuralError{Tag: h.Tag, Err: fmt.Errorf("%w: expected InitialContextToken", ErrTagMismatch)}
+	}
+	if h.Length == tlv.LengthIndefinite {
+		return nil, nil, &SyntaxError{Tag: h.Tag, Err: errors.New("indefinite-length InitialContextToken")}
+	}
+	if len(b)-n < h.Length {
+		return nil, nil, &SyntaxError{Tag: h.Tag, Err: ErrTruncated}
+	}
+	if len(b)-n > h.Length {
+		return nil, nil, &SyntaxError{Tag: h.Tag, Err: fmt.Errorf("%w after InitialContextToken", ErrExtraData)}
+	}
+	rv, token, err := parseRawElement(b[n:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if rv.Tag != asn1.TagOID {
+		return nil, nil, &StructuralError{Tag: rv.Tag, Err: fmt.Errorf("%w: expected mechanism OBJECT IDENTIFIER", ErrTagMismatch)}
+	}
+	if err = Unmarshal(rv.FullBytes, &mech); err != nil {
+		return nil, nil, err
+	}
+	return mech, token, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"codello.dev/asn1"
+)
+
+func TestInitialContextToken(t *testing.T) {
+	krb5 := asn1.ObjectIdentifier{1, 2, 840, 113554, 1, 2, 2}
+	oid := []byte{0x06, 0x09, 0x2A, 0x86, 0x48, 0x86, 0xF7, 0x12, 0x01, 0x02, 0x02}
+	long := bytes.Repeat([]byte{0xAB}, 200)
+	tests := map[string]struct {
+		token []byte
+		want  []byte
+	}{
+		"Empty": {nil, append([]byte{0x60, 0x0B}, oid...)},
+		"Short": {[]byte{0x01, 0x00, 'a'}, append(append([]byte{0x60, 0x0E}, oid...), 0x01, 0x00, 'a')},
+		"Long":  {long, append(append([]byte{0x60, 0x81, 0xD3}, oid...), long...)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := AppendInitialContextToken(nil, krb5, tt.token)
+			if err != nil {
+				t.Fatalf("AppendInitialContextToken() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("AppendInitialContextToken() = % X, want % X", got, tt.want)
+			}
+			mech, token, err := ParseInitialContextToken(got)
+			if err != nil {
+				t.Fatalf("ParseInitialContextToken() error = %v", err)
+			}
+			if !mech.Equal(krb5) || !bytes.Equal(token, tt.token) {
+				t.Errorf("ParseInitialContextToken() = %v, % X, want %v, % X", mech, token, krb5, tt.token)
+			}
+		})
+	}
+
+	errTests := map[string]struct {
+		data    []byte
+		wantErr error
+	}{
+		"WrongTag":   {append([]byte{0x30, 0x0B}, oid...), ErrTagMismatch},
+		"NoOID":      {[]byte{0x60, 0x03, 0x02, 0x01, 0x00}, ErrTagMismatch},
+		"Truncated":  {append([]byte{0x60, 0x0C}, oid...), ErrTruncated},
+		"ExtraData":  {append(append([]byte{0x60, 0x0B}, oid...), 0x00), ErrExtraData},
+		"Indefinite": {append([]byte{0x60, 0x80}, oid...), nil},
+	}
+	for name, tt := range errTests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ParseInitialContextToken(tt.data)
+			if err == nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseInitialContextToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
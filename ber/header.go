@@ -8,9 +8,10 @@ import (
 	"errors"
 	"io"
 	"math"
-	"math/bits"
 
 	"codello.dev/asn1"
+	"codello.dev/asn1/tlv"
+	"codello.dev/asn1/vlq"
 )
 
 // LengthIndefinite when used as a magic number for the length of a [Header]
@@ -47,73 +48,28 @@ type Header struct {
 	Constructed bool
 }
 
+// tlv converts h into the equivalent [tlv.Header].
+func (h Header) tlv() tlv.Header {
+	return tlv.Header{Tag: h.Tag, Constructed: h.Constructed, Length: h.Length}
+}
+
 // numBytes computes the number of bytes required to BER-encode h. The encode
 // method will write this exact number of bytes.
 func (h Header) numBytes() int {
-	l := 1 // class, constructed, tag
-	if h.Tag.Number() >= 31 {
-		// tag does not fit
-		l += base128IntLength(h.Tag.Number())
-	}
-	l++ // length
-	if h.Length == LengthIndefinite || h.Length < 128 {
-		return l
-	}
-	// multi-byte length
-	l++
-	for hl := h.Length; hl > 255; hl >>= 8 {
-		l++
-	}
-	return l
+	return h.tlv().EncodedLen()
 }
 
 // writeTo writes the BER-encoding of h to w. It returns the number of bytes
 // written as well as any error that occurs during writing.
 func (h Header) writeTo(w io.ByteWriter) (n int64, err error) {
-	b := uint8(h.Tag.Class() >> 8)
-	if h.Constructed {
-		b |= 0x20
-	}
-	if h.Tag.Number() < 31 {
-		b |= uint8(h.Tag.Number())
+	var buf [14]byte
+	for _, b := range h.tlv().AppendTo(buf[:0]) {
 		if err = w.WriteByte(b); err != nil {
 			return n, err
 		}
 		n++
-	} else {
-		b |= 0x1f
-		if err = w.WriteByte(b); err != nil {
-			return n, err
-		}
-		n, err = writeBase128Int(w, h.Tag.Number())
-		n++
-		if err != nil {
-			return n, err
-		}
-	}
-
-	if h.Length == LengthIndefinite {
-		err = w.WriteByte(0x80)
-	} else if h.Length >= 128 {
-		numBytes := 1
-		l := h.Length
-		for l > 255 {
-			numBytes++
-			l >>= 8
-		}
-		err = w.WriteByte(0x80 | byte(numBytes))
-		for ; numBytes > 0 && err == nil; numBytes-- {
-			n++
-			err = w.WriteByte(byte(h.Length >> uint((numBytes-1)*8)))
-		}
-	} else {
-		err = w.WriteByte(byte(h.Length))
-	}
-	if err == nil {
-		n++
 	}
-
-	return n, err
+	return n, nil
 }
 
 // decodeHeader reads the identifier and length octets of a data value encoding
@@ -124,9 +80,18 @@ func (h Header) writeTo(w io.ByteWriter) (n int64, err error) {
 // well. If r produces a valid BER-encoded header, this method will not read any
 // bytes past the header.
 func decodeHeader(r io.ByteReader) (h Header, err error) {
+	h, _, err = parseHeader(r)
+	return h, err
+}
+
+// parseHeader works like decodeHeader but additionally reports whether the
+// length octets use the minimal encoding as required by DER. The length
+// octets are minimal if they use the short form for lengths less than 128 and
+// have no leading zero octets.
+func parseHeader(r io.ByteReader) (h Header, minimal bool, err error) {
 	b, err := r.ReadByte()
 	if err != nil {
-		return Header{}, err
+		return Header{}, false, err
 	}
 	h = Header{
 		Tag:         asn1.Tag(b>>6)<<14 | asn1.Tag(b&0x1f),
@@ -137,14 +102,14 @@ func decodeHeader(r io.ByteReader) (h Header, err error) {
 	// encoded afterward
 	if b&0x1f == 0x1f {
 		var n uint
-		n, err = decodeBase128(r)
+		n, err = vlq.ReadMinimal[uint](r)
 		// FIXME: Check overflow
 		h.Tag = h.Tag.Class() | (asn1.Tag(n) &^ (0b11 << 14))
 		if err != nil {
 			if err == io.EOF {
 				err = io.ErrUnexpectedEOF
 			}
-			return h, err
+			return h, false, err
 		}
 	}
 
@@ -152,8 +117,9 @@ func decodeHeader(r io.ByteReader) (h Header, err error) {
 		if err == io.EOF {
 			err = io.ErrUnexpectedEOF
 		}
-		return h, err
+		return h, false, err
 	}
+	minimal = true
 	if b&0x80 == 0 {
 		// The length is encoded in the bottom 7 bits.
 		h.Length = int(b & 0x7f)
@@ -168,93 +134,22 @@ func decodeHeader(r io.ByteReader) (h Header, err error) {
 				if err == io.EOF {
 					err = io.ErrUnexpectedEOF
 				}
-				return h, err
+				return h, false, err
 			}
 			if h.Length >= 1<<23 {
 				// We can't shift h.length up without overflowing.
 				err = errors.New("length too large")
 				continue
 			}
+			if i == 0 && b == 0 {
+				minimal = false
+			}
 			h.Length <<= 8
 			h.Length |= int(b)
 		}
-	}
-	return h, err
-}
-
-// decodeBase128 reads and parses a base-128 encoded uint from r. The maximum
-// supported value is limited by the size of an uint.
-//
-// If r produces a valid base-128 integer, only the bytes belonging to the
-// encoded value will be read from r. If r returns io.EOF on the first read, the
-// returned error will be io.EOF as well.
-func decodeBase128(r io.ByteReader) (uint, error) {
-	b, err := r.ReadByte()
-	if err != nil {
-		// io.EOF stays io.EOF
-		return 0, err
-	}
-	var syntaxError error
-	if b == 0x80 {
-		// integers should be minimally encoded, so the leading octet
-		// should never be 0x80
-		syntaxError = errors.New("base 128 integer is not minimally encoded")
-	}
-	ret := uint(b & 0x7f)
-	numBits := bits.Len8(b & 0x7f)
-
-	for b&0x80 != 0 {
-		b, err = r.ReadByte()
-		if err != nil {
-			break
-		}
-		ret <<= 7
-		ret |= uint(b & 0x7f)
-		if numBits == 0 {
-			numBits = bits.Len8(b & 0x7f)
-		} else {
-			numBits += 7
-		}
-		if numBits > bits.UintSize {
-			syntaxError = errors.New("base 128 integer too large")
+		if h.Length < 0x80 {
+			minimal = false
 		}
 	}
-	if err == io.EOF {
-		err = io.ErrUnexpectedEOF
-	}
-	if syntaxError != nil {
-		err = syntaxError
-	}
-	return ret, err
-}
-
-// base128IntLength returns the number of bytes needed to encode n as a base 128
-// integer.
-func base128IntLength(n uint) int {
-	if n == 0 {
-		return 1
-	}
-	l := 0
-	for i := n; i > 0; i >>= 7 {
-		l++
-	}
-	return l
-}
-
-// writeBase128Int encodes i as a base 128 integer into w. Any error returned by
-// w is returned by this function.
-func writeBase128Int(w io.ByteWriter, i uint) (n int64, err error) {
-	l := base128IntLength(i)
-
-	j := l - 1
-	for ; j >= 0 && err == nil; j-- {
-		b := byte(i >> (j * 7))
-		b &= 0x7f
-		if j != 0 {
-			b |= 0x80
-		}
-		err = w.WriteByte(b)
-	}
-
-	return int64(l - 1 - j), err
+	return h, minimal, err
 }
@@ -0,0 +1,154 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"io"
+	"strconv"
+)
+
+// A ValueHook is invoked by an [Encoder] or [Decoder] for each data value it
+// processes. The hook receives the header of the data value and the path of
+// struct fields and indices leading to the Go value, in the same format as the
+// Path of a [SyntaxError]. The path of a top-level data value is empty.
+//
+// Hooks enable cross-cutting concerns such as metrics, logging or policy
+// checks without implementing custom codecs. If a hook returns an error,
+// encoding or decoding stops and the error is returned wrapped in an
+// [EncodeError] or [StructuralError]:
+//
+//	d.OnValue(func(h ber.Header, path string) error {
+//		if h.Tag.Class() == asn1.ClassPrivate {
+//			return errors.New("private tags are not permitted")
+//		}
+//		return nil
+//	})
+type ValueHook func(h Header, path string) error
+
+// OnValue registers hook to be invoked for each data value decoded by d. Hooks
+// are invoked in the order of their registration before a data value is decoded
+// into a Go value. Data values that are decoded as a whole, such as the
+// contents of a [RawValue], are reported once and their nested data values are
+// not reported. If d reads from a [Reader] passed to [NewDecoder], hooks have
+// no effect.
+func (d *Decoder) OnValue(hook ValueHook) {
+	d.hooks = append(d.hooks, hook)
+}
+
+// OnValue registers hook to be invoked for each data value encoded by e. Hooks
+// are invoked in the order of their registration before the data value is
+// written. Data values written by custom [BerEncoder] implementations without
+// using the [Sequence] type are not reported. If
+// [EncoderOptions.UseIndefiniteLength] is set, hooks receive the headers of the
+// definite-length encoding.
+func (e *Encoder) OnValue(hook ValueHook) {
+	e.hooks = append(e.hooks, hook)
+}
+
+// appendPath appends elem to path. The elem is either the name of a struct
+// field or an index enclosed in square brackets.
+func appendPath(path, elem string) string {
+	if elem != "" && elem[0] == '[' {
+		return path + elem
+	}
+	return fieldPath(path, elem)
+}
+
+// setPath sets the path of er, a data value read from r, to the path of r
+// joined with the struct field name and the index i. An empty field or a
+// negative i is omitted from the path. If the Decoder that created er has no
+// hooks, setPath does nothing.
+func setPath(er Reader, r Reader, field string, i int) {
+	child, ok := er.(*reader)
+	if !ok || child.hooks == nil || len(*child.hooks) == 0 {
+		return
+	}
+	var path string
+	if parent, ok := r.(*reader); ok {
+		path = parent.path
+	}
+	if field != "" {
+		path = fieldPath(path, field)
+	}
+	if i >= 0 {
+		path += "[" + strconv.Itoa(i) + "]"
+	}
+	child.path = path
+}
+
+// runHooks invokes the hooks of the Decoder that created r for the data value
+// read by r. Hooks are invoked at most once for each data value.
+func runHooks(r Reader) error {
+	er, ok := r.(*reader)
+	if !ok || er.hooks == nil || er.hooked {
+		return nil
+	}
+	er.hooked = true
+	for _, hook := range *er.hooks {
+		if err := hook(er.H, er.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stateWriter is the underlying writer of an [Encoder]. Nested writers created
+// by writeValue reference the stateWriter so that hooks can be invoked and
+// statistics can be recorded for nested data values. The nesting depth and, if
+// there are hooks, the path of the data value currently being written are
+// tracked as well.
+type stateWriter struct {
+	W     io.Writer
+	hooks []ValueHook
+	stats *stats
+	path  string
+	depth int
+}
+
+func (w *stateWriter) Write(p []byte) (int, error) {
+	return w.W.Write(p)
+}
+
+func (w *stateWriter) WriteByte(b byte) error {
+	if bw, ok := w.W.(io.ByteWriter); ok {
+		return bw.WriteByte(b)
+	}
+	_, err := w.W.Write([]byte{b})
+	return err
+}
+
+// stateWriterOf returns the stateWriter that w writes to or nil, if w is not
+// writing to a stateWriter.
+func stateWriterOf(w io.Writer) *stateWriter {
+	switch w := w.(type) {
+	case *stateWriter:
+		return w
+	case *limitWriter:
+		return w.sw
+	}
+	return nil
+}
+
+// enterElem sets the path of the stateWriter of w, if any, to the path of the
+// child identified by elem, or by the index i if elem is empty. The returned
+// function restores the previous path.
+func enterElem(w io.Writer, elem string, i int) (restore func()) {
+	sw := stateWriterOf(w)
+	if sw == nil || len(sw.hooks) == 0 {
+		return func() {}
+	}
+	parent := sw.path
+	sw.path = appendPath(parent, elemName(elem, i))
+	return func() { sw.path = parent }
+}
+
+// elemName returns elem or, if elem is empty, the index i enclosed in square
+// brackets.
+func elemName(elem string, i int) string {
+	if elem == "" {
+		return "[" + strconv.Itoa(i) + "]"
+	}
+	return elem
+}
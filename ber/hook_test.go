@@ -0,0 +1,88 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"codello.dev/asn1"
+)
+
+func TestValueHook(t *testing.T) {
+	type inner struct {
+		Flag bool `asn1:"private,tag:3"`
+	}
+	type outer struct {
+		ID    int
+		Items []inner
+		Note  string `asn1:"explicit,tag:1"`
+		Rest  []int  `asn1:"rest"`
+	}
+	val := outer{ID: 1, Items: []inner{{true}, {false}}, Note: "x", Rest: []int{5}}
+	want := []string{
+		"[UNIVERSAL 16] ",
+		"[UNIVERSAL 2] ID",
+		"[UNIVERSAL 16] Items",
+		"[UNIVERSAL 16] Items[0]",
+		"[PRIVATE 3] Items[0].Flag",
+		"[UNIVERSAL 16] Items[1]",
+		"[PRIVATE 3] Items[1].Flag",
+		"[1] Note",
+		"[UNIVERSAL 12] Note",
+		"[UNIVERSAL 2] Rest[0]",
+	}
+	record := func(got *[]string) ValueHook {
+		return func(h Header, path string) error {
+			*got = append(*got, h.Tag.String()+" "+path)
+			return nil
+		}
+	}
+
+	var buf bytes.Buffer
+	var encoded []string
+	e := NewEncoder(&buf)
+	e.OnValue(record(&encoded))
+	if err := e.Encode(val); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !slices.Equal(encoded, want) {
+		t.Errorf("Encoder hook got %q, want %q", encoded, want)
+	}
+
+	var decoded []string
+	var got outer
+	d := NewDecoder(bytes.NewReader(buf.Bytes()))
+	d.OnValue(record(&decoded))
+	if err := d.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !slices.Equal(decoded, want) {
+		t.Errorf("Decoder hook got %q, want %q", decoded, want)
+	}
+
+	errPrivate := errors.New("private tag")
+	rejectPrivate := func(h Header, path string) error {
+		if h.Tag.Class() == asn1.ClassPrivate {
+			return fmt.Errorf("%s: %w", path, errPrivate)
+		}
+		return nil
+	}
+	e = NewEncoder(new(bytes.Buffer))
+	e.OnValue(rejectPrivate)
+	var ee *EncodeError
+	if err := e.Encode(val); !errors.As(err, &ee) || !errors.Is(err, errPrivate) || ee.Path != "Items[0].Flag" {
+		t.Errorf("Encode() error = %v, want EncodeError in Items[0].Flag", err)
+	}
+	d = NewDecoder(bytes.NewReader(buf.Bytes()))
+	d.OnValue(rejectPrivate)
+	var se *StructuralError
+	if err := d.Decode(&got); !errors.As(err, &se) || !errors.Is(err, errPrivate) || se.Path != "Items[0].Flag" {
+		t.Errorf("Decode() error = %v, want StructuralError in Items[0].Flag", err)
+	}
+}
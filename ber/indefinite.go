@@ -0,0 +1,98 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"io"
+
+	"codello.dev/asn1"
+)
+
+// chunkSize is the maximum number of content octets of a segment of a
+// constructed string encoding written when [EncoderOptions.UseIndefiniteLength]
+// is set. This is the segment size used by CER.
+const chunkSize = 1000
+
+// EncoderOptions configure the output of an [Encoder]. The zero value
+// represents the default options.
+type EncoderOptions struct {
+	// UseIndefiniteLength causes all constructed data values to be written using
+	// the indefinite-length encoding. Additionally, primitive encodings of
+	// universal string types with more than 1000 content octets are split into
+	// segments of a constructed encoding. Each segment is a primitive encoding
+	// with the same tag as the string.
+	UseIndefiniteLength bool
+
+	// Verify causes the Encoder to decode each encoding into a new value of the
+	// same type before it is written and to compare the result to the encoded
+	// value field by field. If the values differ, nothing is written and a
+	// [VerifyError] describing the differences is returned. This is intended for
+	// tests that check custom [BerEncoder] and [BerDecoder] implementations for
+	// consistency. Values must be decodable into their own type, so types such
+	// as iterators cannot be verified.
+	Verify bool
+}
+
+// writeIndefinite writes the data value with header h read from r into buf.
+// All constructed encodings are written using the indefinite-length encoding.
+// Long primitive encodings of universal string types are split into segments.
+func writeIndefinite(buf *bytes.Buffer, h Header, r Reader) error {
+	if r.Constructed() {
+		h.Length = LengthIndefinite
+		_, _ = h.writeTo(buf)
+		for {
+			ch, er, err := r.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+			if err = writeIndefinite(buf, ch, er); err == nil {
+				err = er.Close()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		buf.Write([]byte{0x00, 0x00})
+		return nil
+	}
+
+	content := make([]byte, r.Len())
+	if _, err := io.ReadFull(r, content); err != nil {
+		return err
+	}
+	if !isStringTag(h.Tag) || len(content) <= chunkSize {
+		_, _ = h.writeTo(buf)
+		buf.Write(content)
+		return nil
+	}
+
+	_, _ = Header{h.Tag, LengthIndefinite, true}.writeTo(buf)
+	if h.Tag == asn1.TagBitString {
+		// every segment starts with the number of unused bits. Only the last
+		// segment may have unused bits.
+		unused, data := content[0], content[1:]
+		for len(data) >= chunkSize {
+			_, _ = Header{h.Tag, chunkSize, false}.writeTo(buf)
+			buf.WriteByte(0)
+			buf.Write(data[:chunkSize-1])
+			data = data[chunkSize-1:]
+		}
+		_, _ = Header{h.Tag, len(data) + 1, false}.writeTo(buf)
+		buf.WriteByte(unused)
+		buf.Write(data)
+	} else {
+		for len(content) > 0 {
+			n := min(len(content), chunkSize)
+			_, _ = Header{h.Tag, n, false}.writeTo(buf)
+			buf.Write(content[:n])
+			content = content[n:]
+		}
+	}
+	buf.Write([]byte{0x00, 0x00})
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/tlv"
+)
+
+// jsonNode is the JSON representation of a data value produced by [ToJSON].
+type jsonNode struct {
+	Tag         asn1.Tag    `json:"tag"`
+	Class       string      `json:"class"`
+	Type        string      `json:"type,omitempty"`
+	Constructed bool        `json:"constructed"`
+	Hex         *string     `json:"hex,omitempty"`
+	Value       any         `json:"value,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Children    []*jsonNode `json:"children,omitempty"`
+}
+
+// classNames contains the names of the tag classes used by [ToJSON].
+var classNames = map[asn1.Class]string{
+	asn1.ClassUniversal:       "universal",
+	asn1.ClassApplication:     "application",
+	asn1.ClassContextSpecific: "context-specific",
+	asn1.ClassPrivate:         "private",
+}
+
+// ToJSON reads BER-encoded data values from r and returns a generic JSON
+// representation of them. ToJSON is intended for logging and for comparing
+// binary messages in test failures. The output format is not stable and may
+// change in future versions.
+//
+// The result is a JSON array with one object for each top-level data value.
+// Each object contains the tag, class and constructed flag of the data value.
+// Constructed data values contain their components as children. Primitive
+// data values contain their content octets in hexadecimal. Primitive values
+// using universal tags are additionally decoded according to their type:
+//
+//	[{"tag":"[UNIVERSAL 2]","class":"universal","type":"INTEGER","constructed":false,"hex":"05","value":5}]
+//
+// ToJSON reads until r returns io.EOF. If the input is not a valid BER
+// encoding, an error is returned.
+func ToJSON(r io.Reader) ([]byte, error) {
+	roots := make([]*jsonNode, 0, 1)
+	var stack []*jsonNode
+	d := tlv.NewDecoder(r)
+	for {
+		h, val, err := d.ReadHeader()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if h == tlv.EndOfContents {
+			continue
+		}
+		node := &jsonNode{
+			Tag:         h.Tag,
+			Class:       classNames[h.Tag.Class()],
+			Constructed: h.Constructed,
+		}
+		if h.Tag.Class() == asn1.ClassUniversal {
+			node.Type = universalTagNames[h.Tag]
+		}
+		if stack = stack[:d.StackDepth()-1]; len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		if val == nil {
+			stack = append(stack, node)
+			continue
+		}
+		content, err := d.ReadValueBytes()
+		if err != nil {
+			return nil, err
+		}
+		s := strings.ToUpper(hex.EncodeToString(content))
+		node.Hex = &s
+		if h.Tag.Class() == asn1.ClassUniversal {
+			if v, err := decodePrimitive(h.Tag, content); err != nil {
+				node.Error = err.Error()
+			} else {
+				node.Value = jsonValue(v)
+			}
+		}
+	}
+	return json.Marshal(roots)
+}
+
+// jsonValue returns the JSON representation of the decoded primitive value v.
+// Byte strings are represented by the hexadecimal content octets only, so nil
+// is returned for them.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil, []byte, RawValue:
+		return nil
+	case bool:
+		return v
+	case *big.Int:
+		return json.Number(v.String())
+	case asn1.BitString:
+		return strconv.Itoa(v.BitLength) + " bits " + dumpHex(v.Bytes, -1)
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.String:
+		return rv.String()
+	}
+	return fmt.Sprint(v)
+}
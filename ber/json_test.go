@@ -0,0 +1,93 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func ExampleToJSON() {
+	data := []byte{
+		0x30, 0x80,
+		0x02, 0x01, 0x05,
+		0x81, 0x01, 0xff,
+		0x00, 0x00,
+	}
+	b, _ := ToJSON(bytes.NewReader(data))
+	var buf bytes.Buffer
+	_ = json.Indent(&buf, b, "", "  ")
+	fmt.Println(buf.String())
+	// Output:
+	// [
+	//   {
+	//     "tag": "[UNIVERSAL 16]",
+	//     "class": "universal",
+	//     "type": "SEQUENCE",
+	//     "constructed": true,
+	//     "children": [
+	//       {
+	//         "tag": "[UNIVERSAL 2]",
+	//         "class": "universal",
+	//         "type": "INTEGER",
+	//         "constructed": false,
+	//         "hex": "05",
+	//         "value": 5
+	//       },
+	//       {
+	//         "tag": "[1]",
+	//         "class": "context-specific",
+	//         "constructed": false,
+	//         "hex": "FF"
+	//       }
+	//     ]
+	//   }
+	// ]
+}
+
+func TestToJSON(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		"Empty": {nil, `[]`, false},
+		"Null": {[]byte{0x05, 0x00},
+			`[{"tag":"[UNIVERSAL 5]","class":"universal","type":"NULL","constructed":false,"hex":""}]`, false},
+		"Boolean": {[]byte{0x01, 0x01, 0xff},
+			`[{"tag":"[UNIVERSAL 1]","class":"universal","type":"BOOLEAN","constructed":false,"hex":"FF","value":true}]`, false},
+		"OID": {[]byte{0x06, 0x03, 0x55, 0x04, 0x03},
+			`[{"tag":"[UNIVERSAL 6]","class":"universal","type":"OBJECT IDENTIFIER","constructed":false,"hex":"550403","value":"2.5.4.3"}]`, false},
+		"OctetString": {[]byte{0x04, 0x02, 0xab, 0xcd},
+			`[{"tag":"[UNIVERSAL 4]","class":"universal","type":"OCTET STRING","constructed":false,"hex":"ABCD"}]`, false},
+		"Application": {[]byte{0x61, 0x00},
+			`[{"tag":"[APPLICATION 1]","class":"application","constructed":true}]`, false},
+		"Multiple": {[]byte{0x05, 0x00, 0xc2, 0x00},
+			`[{"tag":"[UNIVERSAL 5]","class":"universal","type":"NULL","constructed":false,"hex":""},` +
+				`{"tag":"[PRIVATE 2]","class":"private","constructed":false,"hex":""}]`, false},
+		"InvalidValue": {[]byte{0x01, 0x02, 0x00, 0x00},
+			`[{"tag":"[UNIVERSAL 1]","class":"universal","type":"BOOLEAN","constructed":false,"hex":"0000","error":`, false},
+
+		"Truncated":  {[]byte{0x30, 0x80, 0x05, 0x00}, "", true},
+		"Invalid":    {[]byte{0x04, 0x05, 0x01}, "", true},
+		"HugeLength": {[]byte{0x04, 0x88, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, "", true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ToJSON(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.HasPrefix(got, []byte(tt.want)) {
+				t.Errorf("ToJSON() = %s, want %s", got, tt.want)
+			}
+			if err == nil && !json.Valid(got) {
+				t.Errorf("ToJSON() = %s, want valid JSON", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+)
+
+// Lazy defers the decoding of a data value until its value is requested. When
+// a Lazy is decoded, the data value encoding is validated syntactically and
+// retained. It is decoded into a value of type T when [Lazy.Value] is first
+// called. This avoids the cost of decoding data values that are never
+// accessed:
+//
+//	type Message struct {
+//		Header Header
+//		Body   ber.Lazy[Body]
+//	}
+//
+// When decoding a data value into an optional field of type Lazy[T], the tag of
+// the data value is matched against the type T. When a Lazy is encoded, the
+// retained encoding is written as-is unless a value has been set via
+// [Lazy.Set]. As with [RawElement], the retained encoding may reference the
+// input of [Unmarshal].
+//
+// Lazy is not safe for concurrent use.
+type Lazy[T any] struct {
+	raw RawElement
+	val T
+	err error
+
+	// decoded indicates that val and err are the result of decoding raw or that
+	// val has been set.
+	decoded bool
+}
+
+// NewLazy returns a Lazy holding the value v.
+func NewLazy[T any](v T) Lazy[T] {
+	return Lazy[T]{val: v, decoded: true}
+}
+
+// Value returns the value of l. If l holds an encoding that has not been
+// decoded yet, it is decoded into a value of type T. The result is cached, so
+// the encoding is decoded at most once. If decoding fails, the error is
+// returned by this and every subsequent call.
+func (l *Lazy[T]) Value() (T, error) {
+	if l.decoded || l.raw.FullBytes == nil {
+		return l.val, l.err
+	}
+	l.decoded = true
+	r := bytes.NewReader(l.raw.FullBytes)
+	d := NewDecoder(r)
+	d.r.(*reader).src = &source{l.raw.FullBytes, r}
+	h, er, err := d.Next()
+	if err == nil {
+		// the tag has already been matched when l was decoded
+		err = decodeValue(h.Tag, er, reflect.ValueOf(&l.val).Elem(), internal.FieldParameters{Tag: h.Tag})
+	}
+	if err == nil {
+		err = er.Close()
+	}
+	l.err = err
+	return l.val, l.err
+}
+
+// Set sets the value of l to v, discarding any retained encoding.
+func (l *Lazy[T]) Set(v T) {
+	*l = NewLazy(v)
+}
+
+// Raw returns the retained encoding of l. If l has not been decoded from a data
+// value encoding or a value has been set via [Lazy.Set], the zero RawValue is
+// returned.
+func (l Lazy[T]) Raw() RawValue {
+	return l.raw.RawValue
+}
+
+// IsZero reports whether l neither holds an encoding nor a non-zero value.
+func (l Lazy[T]) IsZero() bool {
+	return l.raw.FullBytes == nil && reflect.ValueOf(&l.val).Elem().IsZero()
+}
+
+// BerEncode writes the retained encoding of l. If l does not hold an encoding,
+// its value is encoded.
+func (l Lazy[T]) BerEncode() (Header, io.WriterTo, error) {
+	if l.raw.FullBytes != nil {
+		return rawElementCodec{val: l.raw}.BerEncode()
+	}
+	v := reflect.ValueOf(&l.val).Elem()
+	enc, err := makeEncoder(v, internal.FieldParameters{}, nil)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	return encodeValue(v, enc, internal.FieldParameters{})
+}
+
+// BerMatch reports whether a data value with the given tag can be decoded into
+// a value of type T.
+func (l Lazy[T]) BerMatch(tag asn1.Tag) bool {
+	_, err := makeDecoder(tag, reflect.New(reflect.TypeFor[T]()).Elem(), internal.FieldParameters{})
+	return err == nil
+}
+
+// BerDecode retains the data value encoding read from r. The encoding is
+// decoded when [Lazy.Value] is called.
+func (l *Lazy[T]) BerDecode(tag asn1.Tag, r Reader) error {
+	raw, err := decodeRawElement(tag, r, true)
+	if err != nil {
+		return err
+	}
+	*l = Lazy[T]{raw: raw}
+	return nil
+}
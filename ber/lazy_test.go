@@ -0,0 +1,76 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	type body struct{ X, Y int }
+	type message struct {
+		A int
+		B Lazy[body]
+		C Lazy[string] `asn1:"optional,omitzero"`
+		D int
+	}
+	// B uses a non-minimal length encoding that is retained
+	data := []byte{0x30, 0x0F, 0x02, 0x01, 0x01,
+		0x30, 0x81, 0x06, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03,
+		0x02, 0x01, 0x04}
+
+	var got message
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.A != 1 || got.D != 4 {
+		t.Errorf("Unmarshal() got A = %d, D = %d, want 1, 4", got.A, got.D)
+	}
+	if raw := got.B.Raw(); !bytes.Equal(raw.Bytes, data[8:14]) {
+		t.Errorf("B.Raw() = % X, want % X", raw.Bytes, data[8:14])
+	}
+	if !got.C.IsZero() {
+		t.Errorf("C.IsZero() = false, want true")
+	}
+	if b, err := got.B.Value(); err != nil || b != (body{2, 3}) {
+		t.Errorf("B.Value() = %v, %v, want %v, nil", b, err, body{2, 3})
+	}
+
+	enc, err := Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.Equal(enc, data) {
+		t.Errorf("Marshal() = % X, want % X", enc, data)
+	}
+
+	got.B.Set(body{5, 6})
+	got.C.Set("a")
+	enc, err = Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := []byte{0x30, 0x11, 0x02, 0x01, 0x01,
+		0x30, 0x06, 0x02, 0x01, 0x05, 0x02, 0x01, 0x06,
+		0x0C, 0x01, 'a',
+		0x02, 0x01, 0x04}
+	if !bytes.Equal(enc, want) {
+		t.Errorf("Marshal() = % X, want % X", enc, want)
+	}
+}
+
+func TestLazy_ValueError(t *testing.T) {
+	var l Lazy[bool]
+	if err := Unmarshal([]byte{0x01, 0x02, 0xFF, 0xFF}, &l); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if _, err := l.Value(); err == nil {
+		t.Errorf("Value() error = nil, want error")
+	}
+	if _, err := l.Value(); err == nil {
+		t.Errorf("Value() error = nil on second call, want error")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"runtime"
+	"strconv"
+
+	"codello.dev/asn1/tlv"
+)
+
+// parallelResult is the result of decoding a single data value in
+// [DecodeAllParallel].
+type parallelResult[T any] struct {
+	val T
+	err error
+}
+
+// parallelJob is a single data value encoding to be decoded by a worker of
+// [DecodeAllParallel].
+type parallelJob[T any] struct {
+	i    int
+	data []byte
+	res  chan<- parallelResult[T]
+}
+
+// DecodeAllParallel returns an iterator over the top-level data values read
+// from r. Each data value is decoded into a new value of type T as if by
+// [Unmarshal]. This is useful for inputs consisting of a large number of
+// concatenated data values, such as dumps of certificate logs.
+//
+// The input is split into data value encodings sequentially. The encodings are
+// then decoded concurrently by the given number of worker goroutines. If
+// workers is less than 1, runtime.GOMAXPROCS(0) workers are used. Regardless
+// of the order in which decoding completes, values are yielded in the order in
+// which they appear in the input. The number of data values held in memory at
+// once is bounded by a small multiple of workers.
+//
+// If an error occurs, it is yielded together with the zero value of T and
+// iteration stops. If iteration stops early, the remaining input is not read.
+// A read from r that is already in progress is not interrupted, but the
+// goroutines started by DecodeAllParallel exit once it completes.
+func DecodeAllParallel[T any](r io.Reader, workers int) iter.Seq2[T, error] {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return func(yield func(T, error) bool) {
+		done := make(chan struct{})
+		jobs := make(chan parallelJob[T])
+		pending := make(chan chan parallelResult[T], 2*workers)
+		defer close(done)
+
+		for range workers {
+			go func() {
+				for job := range jobs {
+					var res parallelResult[T]
+					if res.err = Unmarshal(job.data, &res.val); res.err != nil {
+						res.err = withPath(res.err, "["+strconv.Itoa(job.i)+"]")
+					}
+					job.res <- res
+				}
+			}()
+		}
+
+		go func() {
+			defer close(pending)
+			defer close(jobs)
+			d := tlv.NewDecoder(r)
+			var buf bytes.Buffer
+			e := tlv.NewEncoder(&buf)
+			for i := 0; ; i++ {
+				buf.Reset()
+				res := make(chan parallelResult[T], 1)
+				err := e.CopyFrom(d)
+				if err == io.EOF {
+					return
+				} else if err != nil {
+					res <- parallelResult[T]{err: err}
+				} else {
+					select {
+					case jobs <- parallelJob[T]{i, bytes.Clone(buf.Bytes()), res}:
+					case <-done:
+						return
+					}
+				}
+				select {
+				case pending <- res:
+				case <-done:
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+
+		for res := range pending {
+			r := <-res
+			if r.err != nil {
+				var zero T
+				yield(zero, r.err)
+				return
+			}
+			if !yield(r.val, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestDecodeAllParallel(t *testing.T) {
+	var input []byte
+	var want []int
+	for i := range 1000 {
+		b, err := Marshal(struct{ N int }{i % 100})
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		input = append(input, b...)
+		want = append(want, i%100)
+	}
+	tests := map[string]struct {
+		input   []byte
+		workers int
+		limit   int
+		want    []int
+		wantErr bool
+	}{
+		"Sequential": {input, 1, 0, want, false},
+		"Parallel":   {input, 8, 0, want, false},
+		"Default":    {input, 0, 0, want, false},
+		"Empty":      {nil, 4, 0, nil, false},
+		"Stop":       {input, 4, 10, want[:10], false},
+		"Truncated":  {input[:len(input)-1], 4, 0, want[:999], true},
+		"Mismatch":   {append(slices.Clip(input[:10]), 0x02, 0x01, 0x00), 4, 0, want[:2], true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []int
+			var err error
+			for v, verr := range DecodeAllParallel[struct{ N int }](bytes.NewReader(tt.input), tt.workers) {
+				if verr != nil {
+					err = verr
+					break
+				}
+				got = append(got, v.N)
+				if len(got) == tt.limit {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeAllParallel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DecodeAllParallel() got %d values, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// UnmarshalPEM finds the first PEM block of type blockType in data and decodes
+// its contents into val as if by [Unmarshal]. Blocks of other types are
+// skipped. If blockType is empty, the first PEM block is decoded regardless of
+// its type.
+//
+// The remainder of data after the decoded block is returned in rest. This can
+// be used to decode multiple consecutive blocks, for example a certificate
+// chain. If no matching PEM block is found, an error is returned.
+func UnmarshalPEM(data []byte, blockType string, val any) (rest []byte, err error) {
+	var block *pem.Block
+	rest = data
+	for {
+		if block, rest = pem.Decode(rest); block == nil {
+			if blockType == "" {
+				return data, errors.New("no PEM block found")
+			}
+			return data, fmt.Errorf("no %q PEM block found", blockType)
+		}
+		if blockType == "" || block.Type == blockType {
+			return rest, Unmarshal(block.Bytes, val)
+		}
+	}
+}
+
+// MarshalPEM returns the BER encoding of val wrapped in a PEM block of type
+// blockType. See [Marshal] for details on the encoding.
+func MarshalPEM(blockType string, val any) ([]byte, error) {
+	b, err := Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUnmarshalPEM(t *testing.T) {
+	data := []byte(`-----BEGIN FOO-----
+AgEF
+-----END FOO-----
+-----BEGIN BAR-----
+AgEH
+-----END BAR-----
+`)
+	tests := map[string]struct {
+		blockType string
+		want      int
+		wantRest  bool
+		wantErr   bool
+	}{
+		"First":    {"FOO", 5, true, false},
+		"Skip":     {"BAR", 7, false, false},
+		"Any":      {"", 5, true, false},
+		"NotFound": {"BAZ", 0, false, true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got int
+			rest, err := UnmarshalPEM(data, tc.blockType, &got)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("UnmarshalPEM() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("UnmarshalPEM() = %d, want %d", got, tc.want)
+			}
+			if hasRest := len(bytes.TrimSpace(rest)) > 0; !tc.wantErr && hasRest != tc.wantRest {
+				t.Errorf("UnmarshalPEM() rest = %q", rest)
+			}
+		})
+	}
+}
+
+func TestMarshalPEM(t *testing.T) {
+	got, err := MarshalPEM("FOO", 5)
+	if err != nil {
+		t.Fatalf("MarshalPEM() error = %v", err)
+	}
+	want := "-----BEGIN FOO-----\nAgEF\n-----END FOO-----\n"
+	if string(got) != want {
+		t.Errorf("MarshalPEM() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"fmt"
+	"strconv"
+
+	"codello.dev/asn1"
+)
+
+// A Selection references a data value within a BER encoding. Selections are
+// created by [Query] and refined by selecting components of constructed
+// encodings. This makes it possible to extract nested data values without
+// defining Go types for the enclosing structures:
+//
+//	serial, err := ber.Query(cert).At(0).Tag(asn1.ClassContextSpecific | 0).Bytes()
+//
+// Errors are sticky. If a selection fails, all subsequent selections fail with
+// the same error, which is reported by the methods returning the selected data
+// value. The byte slices of a selection reference the input of [Query].
+type Selection struct {
+	rv   RawElement
+	path string // for error messages
+	err  error
+}
+
+// Query returns a Selection of the data value encoded in b. If b does not
+// contain exactly one valid data value encoding, the returned Selection
+// reports an error.
+func Query(b []byte) Selection {
+	var rv RawElement
+	if err := Unmarshal(b, &rv); err != nil {
+		return Selection{err: err}
+	}
+	return Selection{rv: rv}
+}
+
+// components returns the data values encoded in the content octets of s. The
+// returned values reference the input of s.
+func (s Selection) components() ([]RawElement, error) {
+	if !s.rv.Constructed {
+		return nil, fmt.Errorf("ber: query %s: %s is not constructed", s.location(), s.rv.Tag)
+	}
+	var ret []RawElement
+	for b := s.rv.Bytes; len(b) > 0; {
+		rv, rest, err := parseRawElement(b)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, rv)
+		b = rest
+	}
+	return ret, nil
+}
+
+// location returns a description of s for error messages.
+func (s Selection) location() string {
+	if s.path == "" {
+		return "root"
+	}
+	return s.path
+}
+
+// At selects the i-th component of the constructed data value of s, starting
+// at index 0.
+func (s Selection) At(i int) Selection {
+	if s.err != nil {
+		return s
+	}
+	cs, err := s.components()
+	if err == nil && (i < 0 || i >= len(cs)) {
+		err = fmt.Errorf("ber: query %s: index %d out of range with %d components", s.location(), i, len(cs))
+	}
+	if err != nil {
+		return Selection{err: err}
+	}
+	return Selection{rv: cs[i], path: s.path + "[" + strconv.Itoa(i) + "]"}
+}
+
+// Tag selects the first component of the constructed data value of s that has
+// the given tag.
+func (s Selection) Tag(tag asn1.Tag) Selection {
+	if s.err != nil {
+		return s
+	}
+	cs, err := s.components()
+	if err != nil {
+		return Selection{err: err}
+	}
+	for _, rv := range cs {
+		if rv.Tag == tag {
+			return Selection{rv: rv, path: s.path + "{" + tag.String() + "}"}
+		}
+	}
+	return Selection{err: fmt.Errorf("ber: query %s: no component with tag %s", s.location(), tag)}
+}
+
+// Err returns the error that occurred while selecting s, if any.
+func (s Selection) Err() error {
+	return s.err
+}
+
+// RawValue returns the selected data value.
+func (s Selection) RawValue() (RawValue, error) {
+	return s.rv.RawValue, s.err
+}
+
+// Bytes returns the content octets of the selected data value. If the
+// indefinite-length encoding was used, the end-of-contents marker is not
+// included.
+func (s Selection) Bytes() ([]byte, error) {
+	return s.rv.Bytes, s.err
+}
+
+// FullBytes returns the complete encoding of the selected data value,
+// including the identifier and length octets.
+func (s Selection) FullBytes() ([]byte, error) {
+	return s.rv.FullBytes, s.err
+}
+
+// Decode decodes the selected data value into the value pointed to by val as
+// if by [Unmarshal].
+func (s Selection) Decode(val any) error {
+	if s.err != nil {
+		return s.err
+	}
+	return Unmarshal(s.rv.FullBytes, val)
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"testing"
+
+	"codello.dev/asn1"
+)
+
+func TestQuery(t *testing.T) {
+	// SEQUENCE { SEQUENCE { [0] { INTEGER 2 }, INTEGER 7 }, BOOLEAN TRUE }
+	data := []byte{0x30, 0x80, 0x30, 0x08, 0xA0, 0x03, 0x02, 0x01, 0x02, 0x02, 0x01, 0x07, 0x01, 0x01, 0xFF, 0x00, 0x00}
+	tests := map[string]struct {
+		sel     Selection
+		want    []byte
+		wantErr bool
+	}{
+		"Root":        {Query(data), data[2:15], false},
+		"At":          {Query(data).At(1), []byte{0xFF}, false},
+		"Nested":      {Query(data).At(0).At(1), []byte{0x07}, false},
+		"Tag":         {Query(data).At(0).Tag(asn1.ClassContextSpecific | 0).At(0), []byte{0x02}, false},
+		"OutOfRange":  {Query(data).At(2), nil, true},
+		"Negative":    {Query(data).At(-1), nil, true},
+		"NoTag":       {Query(data).Tag(asn1.TagInteger), nil, true},
+		"Primitive":   {Query(data).At(1).At(0), nil, true},
+		"Sticky":      {Query(data).At(5).At(0), nil, true},
+		"InvalidData": {Query(data[:5]), nil, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tt.sel.Bytes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Bytes() = % X, want % X", got, tt.want)
+			}
+		})
+	}
+
+	var i int
+	if err := Query(data).At(0).At(1).Decode(&i); err != nil || i != 7 {
+		t.Errorf("Decode() = %d, %v, want 7, <nil>", i, err)
+	}
+	full, _ := Query(data).At(0).FullBytes()
+	if &full[0] != &data[2] {
+		t.Errorf("FullBytes() does not reference the input")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+
+	"codello.dev/asn1/internal"
+)
+
+// registeredCodec holds the functions registered via [RegisterCodec].
+type registeredCodec struct {
+	enc func(v reflect.Value) BerEncoder
+	dec func(v reflect.Value) BerDecoder
+}
+
+// codecs maps types to the registeredCodec values registered via
+// [RegisterCodec].
+var codecs sync.Map
+
+// RegisterCodec registers custom encoding and decoding logic for values of
+// type t. This makes it possible to encode types that cannot be modified to
+// implement [BerEncoder] and [BerDecoder], such as types of third-party
+// packages, without introducing wrapper types.
+//
+// When a value of type t is encoded, enc is called with the value and the
+// returned [BerEncoder] is used to encode it. When a data value is decoded into
+// a value of type t, dec is called with a settable value of type t and the
+// returned [BerDecoder] is used to decode the data value. The returned encoders
+// and decoders are subject to the same rules as types implementing the
+// interfaces directly. In particular, a decoder may implement [BerMatcher] to
+// support optional fields. Either of enc and dec may be nil, in which case
+// values of type t are encoded or decoded as usual. Registered codecs take
+// precedence over the methods of t.
+//
+// RegisterCodec panics if t is a pointer or interface type or if a codec has
+// already been registered for t. It is safe to call RegisterCodec concurrently
+// with encoding and decoding, but registration is typically done during
+// program initialization.
+func RegisterCodec(t reflect.Type, enc func(v reflect.Value) BerEncoder, dec func(v reflect.Value) BerDecoder) {
+	if t == nil {
+		panic("ber: RegisterCodec of nil type")
+	} else if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface {
+		panic("ber: RegisterCodec of pointer or interface type " + t.String())
+	}
+	if _, dup := codecs.LoadOrStore(t, registeredCodec{enc, dec}); dup {
+		panic("ber: RegisterCodec of duplicate type " + t.String())
+	}
+}
+
+// registeredEncoder returns the encoder function registered for t, if any.
+func registeredEncoder(t reflect.Type) func(v reflect.Value) BerEncoder {
+	c, _ := codecs.Load(t)
+	rc, _ := c.(registeredCodec)
+	return rc.enc
+}
+
+// registeredDecoder returns the decoder function registered for t, if any.
+func registeredDecoder(t reflect.Type) func(v reflect.Value) BerDecoder {
+	c, _ := codecs.Load(t)
+	rc, _ := c.(registeredCodec)
+	return rc.dec
+}
+
+// TypeOptions configure the encoding and decoding of a struct type without
+// modifying its declaration. See [RegisterTypeOptions] for details.
+type TypeOptions struct {
+	// FieldParams maps Go field names to field parameters. The format of the
+	// parameters is the same as the format of `asn1` struct tags, which is
+	// described in the asn1 package. The parameters of a field replace its
+	// struct tag. Fields not present in the map retain their struct tags.
+	FieldParams map[string]string
+}
+
+// RegisterTypeOptions registers opts for the struct type t. This makes it
+// possible to supply field parameters for struct types whose struct tags cannot
+// be edited, such as generated types or types of third-party packages. Field
+// parameters are shared by all encoding rules of this module, so the
+// registered options also apply to other packages such as jer or per.
+//
+// RegisterTypeOptions panics if t is not a struct type, if opts reference a
+// field that t does not declare, or if options have already been registered
+// for t. Registration is typically done during program initialization, before
+// any values of type t are encoded or decoded.
+func RegisterTypeOptions(t reflect.Type, opts TypeOptions) {
+	if t == nil {
+		panic("ber: RegisterTypeOptions of nil type")
+	} else if t.Kind() != reflect.Struct {
+		panic("ber: RegisterTypeOptions of non-struct type " + t.String())
+	}
+	for name := range opts.FieldParams {
+		if f, ok := t.FieldByName(name); !ok || len(f.Index) > 1 {
+			panic("ber: RegisterTypeOptions of unknown field " + t.String() + "." + name)
+		}
+	}
+	if !internal.SetFieldTags(t, maps.Clone(opts.FieldParams)) {
+		panic("ber: RegisterTypeOptions of duplicate type " + t.String())
+	}
+}
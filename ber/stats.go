@@ -0,0 +1,119 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// Stats contains statistics about the data values processed by an [Encoder] or
+// [Decoder]. The statistics are cumulative over the lifetime of the Encoder or
+// Decoder. Stats is a plain struct so that it can be published using packages
+// such as expvar or exported to a metrics system:
+//
+//	expvar.Publish("ldap.decoder", expvar.Func(func() any { return d.Stats() }))
+type Stats struct {
+	Values   int64 // number of data values encoded or decoded, including nested ones
+	Bytes    int64 // number of bytes written or read
+	MaxDepth int   // deepest nesting level encountered. Top-level data values have depth 1.
+
+	// The number of errors returned from encoding or decoding, by type. Errors
+	// of other types, such as I/O errors, are counted as OtherErrors.
+	SyntaxErrors     int64 // [*SyntaxError]
+	StructuralErrors int64 // [*StructuralError]
+	EncodeErrors     int64 // [*EncodeError]
+	OtherErrors      int64
+}
+
+// stats records the statistics of an [Encoder] or [Decoder]. Its fields are
+// updated atomically so that Stats can be called concurrently with encoding or
+// decoding.
+type stats struct {
+	values   atomic.Int64
+	bytes    atomic.Int64
+	maxDepth atomic.Int64
+
+	syntaxErrors     atomic.Int64
+	structuralErrors atomic.Int64
+	encodeErrors     atomic.Int64
+	otherErrors      atomic.Int64
+}
+
+// value records a data value at the given nesting depth.
+func (s *stats) value(depth int) {
+	s.values.Add(1)
+	for {
+		m := s.maxDepth.Load()
+		if int64(depth) <= m || s.maxDepth.CompareAndSwap(m, int64(depth)) {
+			return
+		}
+	}
+}
+
+// error records err, unless it is nil or io.EOF.
+func (s *stats) error(err error) {
+	if err == nil || err == io.EOF {
+		return
+	}
+	var syn *SyntaxError
+	var se *StructuralError
+	var ee *EncodeError
+	switch {
+	case errors.As(err, &syn):
+		s.syntaxErrors.Add(1)
+	case errors.As(err, &se):
+		s.structuralErrors.Add(1)
+	case errors.As(err, &ee):
+		s.encodeErrors.Add(1)
+	default:
+		s.otherErrors.Add(1)
+	}
+}
+
+// snapshot returns the current values of s.
+func (s *stats) snapshot() Stats {
+	return Stats{
+		Values:           s.values.Load(),
+		Bytes:            s.bytes.Load(),
+		MaxDepth:         int(s.maxDepth.Load()),
+		SyntaxErrors:     s.syntaxErrors.Load(),
+		StructuralErrors: s.structuralErrors.Load(),
+		EncodeErrors:     s.encodeErrors.Load(),
+		OtherErrors:      s.otherErrors.Load(),
+	}
+}
+
+// Stats returns statistics about the data values decoded by d. Errors are
+// counted if they are returned by [Decoder.Decode], [Decoder.DecodeWithParams]
+// or [Decoder.DecodeAll]. If d reads from a [Reader] passed to [NewDecoder],
+// only errors are counted. Stats may be called concurrently with other methods
+// of d.
+func (d *Decoder) Stats() Stats {
+	return d.stats.snapshot()
+}
+
+// Stats returns statistics about the data values encoded by e. Data values
+// written by custom [BerEncoder] implementations without using the [Sequence]
+// type are not counted. Stats may be called concurrently with other methods of
+// e.
+func (e *Encoder) Stats() Stats {
+	return e.stats.snapshot()
+}
+
+// record updates the statistics of d after decoding a data value. The err is
+// the error returned to the caller, if any.
+func (d *Decoder) record(err error) {
+	d.stats.error(err)
+	d.updateBytes()
+}
+
+// updateBytes sets the number of bytes read by d.
+func (d *Decoder) updateBytes() {
+	if er, ok := d.r.(*reader); ok && er.in != nil {
+		d.stats.bytes.Store(*er.in.N)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	type inner struct {
+		A int
+		B []bool
+	}
+	type outer struct {
+		X inner
+		Y string
+	}
+	val := outer{inner{1, []bool{true, false}}, "abc"}
+	// SEQUENCE { SEQUENCE { INTEGER, SEQUENCE { BOOLEAN, BOOLEAN } }, UTF8String }
+	want := Stats{Values: 7, Bytes: 20, MaxDepth: 4}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	if err := e.Encode(val); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if got := e.Stats(); got != want {
+		t.Errorf("Encoder.Stats() = %+v, want %+v", got, want)
+	}
+	if err := e.Encode(make(chan int)); err == nil {
+		t.Fatalf("Encode() error = nil, want error")
+	}
+	if got := e.Stats().OtherErrors; got != 1 {
+		t.Errorf("Encoder.Stats().OtherErrors = %d, want 1", got)
+	}
+
+	data := buf.Bytes()
+	d := NewDecoder(bytes.NewReader(append(data, data[:len(data)-1]...)))
+	var got outer
+	if err := d.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got := d.Stats(); got != want {
+		t.Errorf("Decoder.Stats() = %+v, want %+v", got, want)
+	}
+	if err := d.Decode(&got); err == nil {
+		t.Fatalf("Decode() error = nil, want error")
+	}
+	if got := d.Stats(); got.SyntaxErrors != 1 || got.Bytes != 2*want.Bytes-1 {
+		t.Errorf("Decoder.Stats() = %+v, want 1 syntax error and %d bytes", got, 2*want.Bytes-1)
+	}
+
+	d = NewDecoder(bytes.NewReader(data))
+	var s string
+	if err := d.Decode(&s); err == nil {
+		t.Fatalf("Decode() error = nil, want error")
+	}
+	if got := d.Stats().StructuralErrors; got != 1 {
+		t.Errorf("Decoder.Stats().StructuralErrors = %d, want 1", got)
+	}
+}
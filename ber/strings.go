@@ -67,7 +67,7 @@ func (r *StringReader) next() (er Reader, err error) {
 				return er, err
 			}
 			if h.Tag != r.t {
-				return er, &SyntaxError{r.t, errors.New("non-matching encoding " + h.Tag.String() + " in constructed string")}
+				return er, &SyntaxError{Tag: r.t, Err: errors.New("non-matching encoding " + h.Tag.String() + " in constructed string")}
 			}
 			if !er.Constructed() {
 				r.currLeaf = er
@@ -85,7 +85,7 @@ func (r *StringReader) next() (er Reader, err error) {
 			return nil, err
 		}
 		if h.Tag != r.t {
-			return er, &SyntaxError{r.t, errors.New("non-matching encoding " + h.Tag.String() + " in constructed string")}
+			return er, &SyntaxError{Tag: r.t, Err: errors.New("non-matching encoding " + h.Tag.String() + " in constructed string")}
 		}
 	}
 	return r.currLeaf, nil
@@ -0,0 +1,315 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"codello.dev/asn1/tlv"
+)
+
+//region type tlvReader
+
+// NewDecoderFromTLV creates a new [Decoder] reading data values from td. The
+// returned Decoder reads directly from td without additional buffering. This
+// makes it possible to mix the low-level header processing of a [tlv.Decoder]
+// with the reflection-based decoding of this package on the same stream.
+//
+// The returned Decoder reads data values at the current nesting level of td.
+// For example, after reading the header of a constructed data value using
+// [tlv.Decoder.ReadHeader], [Decoder.Decode] decodes the components of that
+// data value one at a time. When the end of the constructed data value is
+// reached, [Decoder.Next] returns io.EOF without consuming the end-of-contents
+// marker, so that it can be read from td.
+//
+// Any errors that occur are returned by the Decoder and leave td in an
+// undefined state. The options of the returned Decoder have no effect.
+func NewDecoderFromTLV(td *tlv.Decoder) *Decoder {
+	return &Decoder{r: &tlvReader{d: td, h: Header{Constructed: true, Length: LengthIndefinite}, root: true}}
+}
+
+// tlvReader implements [Reader] on top of a [tlv.Decoder]. Like the reader
+// type, a tlvReader operates either in primitive or constructed mode as
+// indicated by h.
+type tlvReader struct {
+	d *tlv.Decoder
+	h Header
+
+	// val reads the content octets of a primitive data value. It is nil after
+	// val has been closed.
+	val io.ReadCloser
+
+	// level is the stack level of d of the data value read by r.
+	level int
+	// curr is the last reader returned by Next.
+	curr *tlvReader
+	// done indicates that the end of the constructed data value has been read.
+	done bool
+	// root indicates that r reads at the current level of d. A root reader
+	// does not consume the end-of-contents marker of its parent.
+	root bool
+}
+
+// Constructed reports whether r is operating on a constructed or primitive
+// encoding.
+func (r *tlvReader) Constructed() bool {
+	return r.h.Constructed
+}
+
+// More reports whether r might have more data. For constructed encodings the
+// last reader returned by Next is closed and the next header is peeked from the
+// underlying decoder.
+func (r *tlvReader) More() bool {
+	if !r.Constructed() {
+		return r.Len() > 0
+	}
+	if r.curr != nil {
+		err := r.curr.Close()
+		r.curr = nil
+		if err != nil {
+			return false
+		}
+	}
+	if r.done {
+		return false
+	}
+	h, err := r.d.PeekHeader()
+	return err == nil && h.Tag != tlv.TagEndOfContents
+}
+
+// Len returns the number of bytes remaining in r or -1 if the number of bytes
+// is not known.
+func (r *tlvReader) Len() int {
+	if r.done || !r.Constructed() && r.val == nil {
+		return 0
+	}
+	if r.root {
+		return r.d.Remaining(r.d.StackDepth())
+	}
+	return r.d.Remaining(r.level)
+}
+
+// Next reads the next data value from the underlying decoder of r. See
+// [Reader.Next] for details.
+func (r *tlvReader) Next() (Header, Reader, error) {
+	if !r.Constructed() {
+		return Header{}, nil, &SyntaxError{Tag: r.h.Tag, Err: errors.New("primitive encoding")}
+	}
+	if r.curr != nil {
+		// Unread bytes of r.curr are discarded. Syntax errors of the discarded
+		// bytes are detected by the underlying decoder.
+		err := r.curr.Close()
+		r.curr = nil
+		if err != nil {
+			return Header{}, nil, err
+		}
+	}
+	if r.done {
+		return Header{}, nil, io.EOF
+	}
+	if r.root {
+		// The end-of-contents marker belongs to the caller.
+		if th, err := r.d.PeekHeader(); err != nil {
+			return Header{}, nil, err
+		} else if th.Tag == tlv.TagEndOfContents {
+			return Header{}, nil, io.EOF
+		}
+	}
+	th, val, err := r.d.ReadHeader()
+	if err != nil {
+		if err == io.EOF && !r.root {
+			err = &SyntaxError{Tag: r.h.Tag, Err: fmt.Errorf("decoding child: %w", ErrTruncated)}
+		}
+		return Header{}, nil, err
+	}
+	if th.Tag == tlv.TagEndOfContents {
+		r.done = true
+		return Header{}, nil, io.EOF
+	}
+	h := Header{Tag: th.Tag, Length: th.Length, Constructed: th.Constructed}
+	r.curr = &tlvReader{d: r.d, h: h, val: val, level: r.d.StackDepth()}
+	return h, r.curr, nil
+}
+
+// Close discards any unread bytes of r. If r is constructed, the syntax of
+// the discarded data values is validated by the underlying decoder.
+func (r *tlvReader) Close() error {
+	if !r.Constructed() {
+		if r.val == nil {
+			return nil
+		}
+		err := r.val.Close()
+		r.val = nil
+		return err
+	}
+	for {
+		if _, _, err := r.Next(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Read implements the io.Reader interface. If r is using the constructed
+// encoding, this method returns an error.
+func (r *tlvReader) Read(p []byte) (int, error) {
+	if r.Constructed() {
+		return 0, &SyntaxError{Tag: r.h.Tag, Err: errors.New("constructed encoding")}
+	}
+	if r.val == nil {
+		return 0, io.EOF
+	}
+	return r.val.Read(p)
+}
+
+// ReadByte implements the io.ByteReader interface. If r is using the
+// constructed encoding, this method returns an error.
+func (r *tlvReader) ReadByte() (byte, error) {
+	if r.Constructed() {
+		return 0, &SyntaxError{Tag: r.h.Tag, Err: errors.New("constructed encoding")}
+	}
+	if r.val == nil {
+		return 0, io.EOF
+	}
+	return r.val.(io.ByteReader).ReadByte()
+}
+
+//endregion
+
+//region type tlvWriter
+
+// NewEncoderFromTLV creates a new [Encoder] writing data values to te. The
+// encodings produced by the returned Encoder are written to te as a sequence
+// of headers and values without additional buffering. This makes it possible
+// to mix the low-level header processing of a [tlv.Encoder] with the
+// reflection-based encoding of this package on the same stream.
+//
+// Data values are written at the current nesting level of te. For example,
+// after writing the header of a constructed data value using
+// [tlv.Encoder.WriteHeader], [Encoder.Encode] can be used to write its
+// components. The end-of-contents marker must then be written to te.
+//
+// Any errors that occur are returned by the Encoder and leave te in an
+// undefined state.
+func NewEncoderFromTLV(te *tlv.Encoder) *Encoder {
+	return &Encoder{w: &tlvWriter{e: te}}
+}
+
+// tlvWriter parses the BER-encoded data values written to it and writes them
+// to a [tlv.Encoder]. Headers are accumulated in hdr until they are complete.
+// The content octets of primitive data values are passed through to the
+// encoder.
+type tlvWriter struct {
+	e *tlv.Encoder
+
+	hdr []byte
+	buf [14]byte // initial storage for hdr
+
+	// val writes the content octets of the current primitive data value and n
+	// is the number of content octets that remain to be written.
+	val io.WriteCloser
+	n   int
+
+	// stack holds the number of remaining bytes of the enclosing constructed
+	// data values, or LengthIndefinite.
+	stack []int
+}
+
+// Write implements the io.Writer interface.
+func (w *tlvWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if w.val != nil {
+			k := min(len(p), w.n)
+			k, err = w.val.Write(p[:k])
+			n += k
+			p = p[k:]
+			w.consume(k)
+			if err != nil {
+				return n, err
+			}
+			if w.n -= k; w.n == 0 {
+				err = w.val.Close()
+				w.val = nil
+				if err == nil {
+					err = w.endValues()
+				}
+				if err != nil {
+					return n, err
+				}
+			}
+			continue
+		}
+
+		if w.hdr == nil {
+			w.hdr = w.buf[:0]
+		}
+		w.hdr = append(w.hdr, p[0])
+		n++
+		p = p[1:]
+		th, size, err := tlv.ParseHeader(w.hdr)
+		if err == io.ErrUnexpectedEOF {
+			continue
+		} else if err != nil {
+			return n, err
+		}
+		w.hdr = w.hdr[:0]
+		w.consume(size)
+		if th.Tag == tlv.TagEndOfContents {
+			if len(w.stack) == 0 || w.stack[len(w.stack)-1] != LengthIndefinite {
+				return n, &SyntaxError{Tag: th.Tag, Err: errors.New("unexpected end of contents")}
+			}
+			w.stack = w.stack[:len(w.stack)-1]
+		}
+		if w.val, err = w.e.WriteHeader(th); err != nil {
+			return n, err
+		}
+		if th.Constructed {
+			w.stack = append(w.stack, th.Length)
+		} else if th.Tag != tlv.TagEndOfContents {
+			w.n = th.Length
+			if w.n == 0 {
+				err = w.val.Close()
+				w.val = nil
+			}
+		}
+		if err == nil {
+			err = w.endValues()
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// consume marks k bytes as written within the enclosing definite-length data
+// values.
+func (w *tlvWriter) consume(k int) {
+	for i, l := range w.stack {
+		if l != LengthIndefinite {
+			w.stack[i] = l - k
+		}
+	}
+}
+
+// endValues writes the end-of-contents markers of all definite-length data
+// values that have been written completely.
+func (w *tlvWriter) endValues() error {
+	if w.val != nil {
+		return nil
+	}
+	for len(w.stack) > 0 && w.stack[len(w.stack)-1] == 0 {
+		if _, err := w.e.WriteHeader(tlv.EndOfContents); err != nil {
+			return err
+		}
+		w.stack = w.stack[:len(w.stack)-1]
+	}
+	return nil
+}
+
+//endregion
@@ -0,0 +1,120 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/tlv"
+)
+
+func TestNewDecoderFromTLV(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	tests := map[string]struct {
+		data    []byte
+		want    []point
+		wantErr bool
+	}{
+		"Definite":   {[]byte{0x30, 0x10, 0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x30, 0x06, 0x02, 0x01, 0x03, 0x02, 0x01, 0x04}, []point{{1, 2}, {3, 4}}, false},
+		"Indefinite": {[]byte{0x30, 0x80, 0x30, 0x80, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00}, []point{{1, 2}}, false},
+		"Empty":      {[]byte{0x30, 0x00}, nil, false},
+		"Mismatch":   {[]byte{0x30, 0x05, 0x31, 0x03, 0x02, 0x01, 0x01}, nil, true},
+		"Truncated":  {[]byte{0x30, 0x05, 0x30, 0x03, 0x02, 0x01}, nil, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := tlv.NewDecoder(bytes.NewReader(tt.data))
+			if _, _, err := td.ReadHeader(); err != nil {
+				t.Fatalf("td.ReadHeader() error = %v", err)
+			}
+			d := NewDecoderFromTLV(td)
+			var got []point
+			var err error
+			for d.More() {
+				var p point
+				if err = d.Decode(&p); err != nil {
+					break
+				}
+				got = append(got, p)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("d.Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("d.Decode() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("d.Decode() = %v, want %v", got, tt.want)
+				}
+			}
+			if _, _, err = d.Next(); err != io.EOF {
+				t.Errorf("d.Next() error = %v, want io.EOF", err)
+			}
+			if h, _, err := td.ReadHeader(); err != nil || h.Tag != tlv.TagEndOfContents {
+				t.Errorf("td.ReadHeader() = %v, %v, want end of contents", h, err)
+			}
+		})
+	}
+}
+
+func TestNewEncoderFromTLV(t *testing.T) {
+	type inner struct {
+		S string
+		B []byte
+	}
+	type outer struct {
+		A int
+		B inner
+		C []int
+	}
+	tests := map[string]struct {
+		val        any
+		indefinite bool
+	}{
+		"Integer":    {42, false},
+		"Empty":      {[]int{}, false},
+		"Nested":     {outer{1, inner{"hello", []byte{0xFF}}, []int{1, 2, 3}}, false},
+		"Indefinite": {outer{1, inner{"hello", nil}, []int{1, 2, 3}}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var want bytes.Buffer
+			e := NewEncoder(&want)
+			e.SetOptions(EncoderOptions{UseIndefiniteLength: tt.indefinite})
+			if err := e.Encode(tt.val); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			var got bytes.Buffer
+			te := tlv.NewEncoder(&got)
+			if _, err := te.WriteHeader(tlv.Header{Tag: asn1.TagSequence, Length: LengthIndefinite, Constructed: true}); err != nil {
+				t.Fatalf("te.WriteHeader() error = %v", err)
+			}
+			e = NewEncoderFromTLV(te)
+			e.SetOptions(EncoderOptions{UseIndefiniteLength: tt.indefinite})
+			if err := e.Encode(tt.val); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if _, err := te.WriteHeader(tlv.EndOfContents); err != nil {
+				t.Fatalf("te.WriteHeader() error = %v", err)
+			}
+
+			wantBytes := append([]byte{0x30, 0x80}, want.Bytes()...)
+			wantBytes = append(wantBytes, 0x00, 0x00)
+			if !bytes.Equal(got.Bytes(), wantBytes) {
+				t.Errorf("Encode() = % X, want % X", got.Bytes(), wantBytes)
+			}
+		})
+	}
+}
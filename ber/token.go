@@ -0,0 +1,87 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import "io"
+
+// A Token is an interface holding one of the token types: [StartConstructed],
+// [EndConstructed] or [Primitive].
+type Token any
+
+// StartConstructed indicates the beginning of a constructed data value
+// encoding. The components of the data value are returned as subsequent
+// tokens, followed by a matching [EndConstructed].
+type StartConstructed struct {
+	Header Header
+}
+
+// EndConstructed indicates the end of a constructed data value encoding. The
+// Header is the same as in the matching [StartConstructed].
+type EndConstructed struct {
+	Header Header
+}
+
+// Primitive is a data value using the primitive encoding. Bytes contains the
+// content octets of the data value.
+type Primitive struct {
+	Header Header
+	Bytes  []byte
+}
+
+// openToken is a constructed data value encoding that has been started by
+// [Decoder.Token] but not yet ended.
+type openToken struct {
+	h Header
+	r Reader
+}
+
+// Token returns the next BER token in the input stream. At the end of the input
+// stream, Token returns nil, io.EOF.
+//
+// Token implements a streaming API that does not require the caller to manage
+// the lifetime of nested [Reader] values. For each constructed data value
+// encoding, a [StartConstructed] token is returned, followed by the tokens of
+// its components and a matching [EndConstructed] token. A data value using the
+// primitive encoding is returned as a [Primitive] token. The token sequence is
+// properly nested, even if the indefinite-length encoding is used. The syntax
+// of the input is validated as the tokens are read.
+//
+// Token must not be mixed with [Decoder.Next], [Decoder.Decode] or related
+// methods while a constructed data value started by Token has not been ended.
+func (d *Decoder) Token() (Token, error) {
+	var (
+		h   Header
+		er  Reader
+		err error
+	)
+	if len(d.tokens) == 0 {
+		h, er, err = d.Next()
+	} else {
+		top := d.tokens[len(d.tokens)-1]
+		h, er, err = top.r.Next()
+		if err == io.EOF {
+			d.tokens = d.tokens[:len(d.tokens)-1]
+			if err = top.r.Close(); err != nil {
+				return nil, err
+			}
+			return EndConstructed{top.h}, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if er.Constructed() {
+		d.tokens = append(d.tokens, openToken{h, er})
+		return StartConstructed{h}, nil
+	}
+	b, err := io.ReadAll(er)
+	if err == nil {
+		err = er.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Primitive{h, b}, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_Token(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		want    []string
+		wantErr bool
+	}{
+		"Primitive": {[]byte{0x02, 0x01, 0x05, 0x05, 0x00},
+			[]string{"[UNIVERSAL 2]=05", "[UNIVERSAL 5]="}, false},
+		"Nested": {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x30, 0x03, 0x01, 0x01, 0xFF, 0x00, 0x00, 0x05, 0x00},
+			[]string{"start [UNIVERSAL 16]", "[UNIVERSAL 2]=05", "start [UNIVERSAL 16]", "[UNIVERSAL 1]=FF", "end [UNIVERSAL 16]", "end [UNIVERSAL 16]", "[UNIVERSAL 5]="}, false},
+		"Empty": {[]byte{0x30, 0x00},
+			[]string{"start [UNIVERSAL 16]", "end [UNIVERSAL 16]"}, false},
+		"Truncated": {[]byte{0x30, 0x05, 0x02, 0x01, 0x05},
+			[]string{"start [UNIVERSAL 16]", "[UNIVERSAL 2]=05"}, true},
+	}
+	for name, tt := range tests {
+		for _, r := range []io.Reader{bytes.NewReader(tt.data), iotest.OneByteReader(bytes.NewReader(tt.data))} {
+			t.Run(fmt.Sprintf("%s/%T", name, r), func(t *testing.T) {
+				d := NewDecoder(r)
+				var got []string
+				var err error
+				for {
+					var tok Token
+					if tok, err = d.Token(); err != nil {
+						break
+					}
+					switch tok := tok.(type) {
+					case StartConstructed:
+						got = append(got, "start "+tok.Header.Tag.String())
+					case EndConstructed:
+						got = append(got, "end "+tok.Header.Tag.String())
+					case Primitive:
+						got = append(got, fmt.Sprintf("%s=%X", tok.Header.Tag, tok.Bytes))
+					}
+				}
+				if (err != io.EOF) != tt.wantErr {
+					t.Errorf("Token() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("Token() returned %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
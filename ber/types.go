@@ -7,13 +7,14 @@ package ber
 import (
 	"bytes"
 	"encoding"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/big"
 	"math/bits"
+	"net"
+	"net/netip"
 	"reflect"
 	"slices"
 	"strconv"
@@ -24,6 +25,7 @@ import (
 
 	"codello.dev/asn1"
 	"codello.dev/asn1/internal"
+	"codello.dev/asn1/vlq"
 )
 
 // berCodec is a helper type that combines the [BerEncoder] and [BerDecoder] types.
@@ -45,24 +47,25 @@ type codec[T any] struct {
 
 // codecFor returns a codec value that can encode or decode the value in v. If
 // vif is provided, it is assumed to be the result of calling v.Interface().
+// Codecs of primitive types are taken from scratch, see [inlineCodecs].
 //
 // The codec is selected mainly based on the type of vif. If vif is nil or an
 // unknown type the codec is selected based on the provided tag or the
 // underlying type of v.
-func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
+func codecFor(v reflect.Value, vif any, tag asn1.Tag, scratch *inlineCodecs) berCodec {
 	switch vv := vif.(type) {
 	case asn1.BitString:
 		return bitStringCodec{v, vv}
 	case int, int8, int16, int32, int64:
-		return intCodec{codec: codec[any]{v, v.Int()}}
+		return scratch.newInt(v, false)
 	case uint, uint8, uint16, uint32, uint64:
-		return intCodec{codec: codec[any]{v, v.Uint()}}
+		return scratch.newInt(v, false)
 	case big.Int:
 		return bigIntCodec{v, vv}
 	case asn1.Null:
-		return nullCodec{v, vv}
+		return scratch.newNull(v)
 	case asn1.ObjectIdentifier:
-		return oidCodec{v, vv}
+		return scratch.newOID(v, vv)
 	case float32:
 		return floatCodec{v, float64(vv)}
 	case float64:
@@ -75,9 +78,13 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 			codec: codec[asn1.UTF8String]{v, vv},
 		}
 	case asn1.RelativeOID:
-		return relativeOIDCodec{v, vv}
+		return scratch.newRelativeOID(v, vv)
 	case asn1.Time:
 		return timeCodec{v, vv}
+	case asn1.TimeInterval:
+		return timeIntervalCodec{v, vv}
+	case asn1.RecurringInterval:
+		return recurringIntervalCodec{v, vv}
 	case asn1.NumericString:
 		return stringCodec[asn1.NumericString]{
 			tag:   asn1.TagNumericString,
@@ -104,6 +111,8 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 		return generalizedTimeCodec{v, vv}
 	case time.Time:
 		switch tag {
+		case 0:
+			return x509TimeCodec{v, vv}
 		case asn1.TagTime:
 			return timeCodec{v, asn1.Time(vv)}
 		case asn1.TagUTCTime:
@@ -132,10 +141,20 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 		return durationCodec{v, vv}
 	case time.Duration:
 		return durationCodec{v, asn1.Duration(vv)}
+	case asn1.ISODuration:
+		return isoDurationCodec{v, vv}
+	case netip.Addr:
+		return ipAddrCodec{v, vv}
+	case netip.Prefix:
+		return ipPrefixCodec{v, vv}
+	case net.IP:
+		return ipCodec{v, vv}
 	case Flag:
 		return flagCodec{v, vv}
 	case RawValue:
 		return rawValueCodec{v, vv}
+	case RawElement:
+		return rawElementCodec{v, vv}
 	}
 
 	// s holds v.String() if v is a string
@@ -143,11 +162,11 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 
 	switch v.Kind() {
 	case reflect.Bool:
-		return boolCodec{v, v.Bool()}
+		return scratch.newBool(v, v.Bool())
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return intCodec{true, codec[any]{v, v.Int()}}
+		return scratch.newInt(v, true)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return intCodec{true, codec[any]{v, v.Uint()}}
+		return scratch.newInt(v, true)
 	case reflect.Float32, reflect.Float64:
 		return floatCodec{v, v.Float()}
 	case reflect.String:
@@ -168,9 +187,9 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 		// This case is only reached when decoding
 		switch tag {
 		case asn1.TagBoolean:
-			return boolCodec{ref: v}
+			return &boolCodec{codec: codec[bool]{ref: v}}
 		case asn1.TagInteger:
-			return intCodec{false, codec[any]{ref: v}}
+			return &intCodec{codec: codec[any]{ref: v}}
 		case asn1.TagBitString:
 			return bitStringCodec{ref: v}
 		case asn1.TagOctetString:
@@ -178,18 +197,18 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 		case asn1.TagNull:
 			return nullCodec{ref: v}
 		case asn1.TagOID:
-			return oidCodec{ref: v}
+			return &oidCodec{codec: codec[asn1.ObjectIdentifier]{ref: v}}
 		case asn1.TagReal:
 			return floatCodec{ref: v}
 		case asn1.TagEnumerated:
-			return intCodec{true, codec[any]{ref: v}}
+			return &intCodec{enum: true, codec: codec[any]{ref: v}}
 		case asn1.TagUTF8String:
 			return stringCodec[asn1.UTF8String]{
 				tag:   asn1.TagUTF8String,
 				codec: codec[asn1.UTF8String]{v, asn1.UTF8String(s)},
 			}
 		case asn1.TagRelativeOID:
-			return relativeOIDCodec{ref: v}
+			return &relativeOIDCodec{codec: codec[asn1.RelativeOID]{ref: v}}
 		case asn1.TagTime:
 			return timeCodec{ref: v}
 		case asn1.TagNumericString:
@@ -239,6 +258,10 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 		if v.Type().Elem() == emptyStructType {
 			return setCodec{v, vif}
 		}
+	case reflect.Struct:
+		if internal.IsSetOf(v.Type()) {
+			return setOfCodec{v, vif}
+		}
 	default:
 	}
 	return nil
@@ -247,25 +270,114 @@ func codecFor(v reflect.Value, vif any, tag asn1.Tag) berCodec {
 // emptyStructType is used to identify the [asn1.Set] type.
 var emptyStructType = reflect.TypeFor[struct{}]()
 
+// adjustTime applies the "toutc" and "precision" parameters to vif if it is a
+// time value. Other values are returned unchanged.
+func adjustTime(vif any, params internal.FieldParameters) any {
+	if !params.UTC && !params.HasPrecision {
+		return vif
+	}
+	adjust := func(t time.Time) time.Time {
+		if params.UTC {
+			t = t.UTC()
+		}
+		if params.HasPrecision {
+			d := time.Second
+			for range params.Precision {
+				d /= 10
+			}
+			t = t.Truncate(d)
+		}
+		return t
+	}
+	switch vv := vif.(type) {
+	case time.Time:
+		return adjust(vv)
+	case asn1.Time:
+		return asn1.Time(adjust(time.Time(vv)))
+	case asn1.UTCTime:
+		return asn1.UTCTime(adjust(time.Time(vv)))
+	case asn1.GeneralizedTime:
+		return asn1.GeneralizedTime(adjust(time.Time(vv)))
+	}
+	return vif
+}
+
+// berTaggerType and taggerType are the types of the [BerTagger] and
+// [asn1.Tagger] interfaces.
+var (
+	berTaggerType = reflect.TypeFor[BerTagger]()
+	taggerType    = reflect.TypeFor[asn1.Tagger]()
+)
+
+// intrinsicTag returns the tag declared by t via the [BerTagger] or
+// [asn1.Tagger] interfaces. If t implements both, BerTagger takes precedence.
+// If t does not declare a tag, 0 is returned.
+func intrinsicTag(t reflect.Type) asn1.Tag {
+	if t.Kind() == reflect.Interface {
+		return 0
+	}
+	if t.Implements(berTaggerType) {
+		return reflect.Zero(t).Interface().(BerTagger).BerTag()
+	} else if t.Implements(taggerType) {
+		return reflect.Zero(t).Interface().(asn1.Tagger).AsnTag()
+	}
+	return 0
+}
+
+// applyType returns params with the tag implied by the type t applied, if no
+// other tag is specified. If t declares an intrinsic tag via [BerTagger] or
+// [asn1.Tagger], that tag is used. Otherwise, the universal type selected by
+// the struct tags of the encoding/asn1 package is used, if that type is
+// applicable to values of type t.
+func applyType(t reflect.Type, params internal.FieldParameters) internal.FieldParameters {
+	if params.Tag != 0 {
+		return params
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if tag := intrinsicTag(t); tag != 0 {
+		params.Tag = tag
+		return params
+	}
+	if params.Type == 0 {
+		return params
+	}
+	switch params.Type {
+	case asn1.TagUTCTime, asn1.TagGeneralizedTime:
+		if t != reflect.TypeFor[time.Time]() {
+			return params
+		}
+	case asn1.TagSet:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array || t.Elem().Kind() == reflect.Uint8 {
+			return params
+		}
+	default:
+		if t.Kind() != reflect.String {
+			return params
+		}
+	}
+	params.Tag = params.Type
+	return params
+}
+
 //region [UNIVERSAL 1] BOOLEAN
 
 // boolCodec implements encoding and decoding of the ASN.1 BOOLEAN type. The
 // value false is encoded as 0x00. Any other single byte value corresponds to
 // true.
-type boolCodec codec[bool]
+type boolCodec struct {
+	codec[bool]
+	content inlineContent
+}
 
-func (c boolCodec) BerEncode() (h Header, w io.WriterTo, err error) {
-	var bs []byte
-	if c.ref.Bool() {
-		bs = []byte{0xff}
-	} else {
-		bs = []byte{0x00}
-	}
+func (c *boolCodec) BerEncode() (h Header, w io.WriterTo, err error) {
+	c.content.b = AppendBoolean(c.content.buf[:0], c.ref.Bool())
 	return Header{
 			Tag:         asn1.TagBoolean,
 			Length:      1,
 			Constructed: false},
-		bytes.NewReader(bs), nil
+		&c.content, nil
 }
 
 func (c boolCodec) BerMatch(tag asn1.Tag) bool {
@@ -274,13 +386,16 @@ func (c boolCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c boolCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Len() != 1 {
-		return &SyntaxError{tag, errors.New("invalid boolean")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid boolean")}
 	}
 
 	bt, err := r.ReadByte()
 	if err != nil {
 		return err
 	}
+	if opts := optionsOf(r); opts != nil && opts.RequireCanonicalBoolean && bt != 0x00 && bt != 0xFF {
+		return &SyntaxError{Tag: tag, Err: ErrNonCanonicalBoolean}
+	}
 	if c.ref.Kind() == reflect.Bool {
 		c.ref.SetBool(bt != 0)
 	} else {
@@ -305,10 +420,14 @@ func (c boolCodec) BerDecode(tag asn1.Tag, r Reader) error {
 type intCodec struct {
 	enum bool
 	codec[any]
+	content inlineContent
 }
 
-func (c intCodec) BerEncode() (h Header, w io.WriterTo, err error) {
+func (c *intCodec) BerEncode() (h Header, w io.WriterTo, err error) {
 	if c.enum && c.ref.Kind() != reflect.Interface {
+		if err = asn1.ValidateEnum(c.ref.Interface()); err != nil {
+			return h, nil, err
+		}
 		if vv, ok := c.ref.Interface().(interface{ IsValid() bool }); ok && !vv.IsValid() {
 			return h, nil, errors.New("invalid value for type " + c.ref.Type().String())
 		}
@@ -326,28 +445,16 @@ func (c intCodec) BerEncode() (h Header, w io.WriterTo, err error) {
 		panic("unreachable")
 	}
 
-	var bs [9]byte
-	binary.BigEndian.PutUint64(bs[1:], u64)
-	l := (bits.Len64(u64) + 8 - 1) / 8
-	if l == 0 {
-		l = 1
-	}
-	if u64&(1<<63) != 0 {
-		if signed {
-			l -= bits.LeadingZeros64(^u64) / 8
-		} else {
-			l++
-		}
-	}
+	c.content.b = appendInteger(c.content.buf[:0], u64, signed)
 	tag := asn1.TagInteger
 	if c.enum {
 		tag = asn1.TagEnumerated
 	}
 	return Header{
 			Tag:         tag,
-			Length:      l,
+			Length:      len(c.content.b),
 			Constructed: false},
-		bytes.NewReader(bs[9-l:]), nil
+		&c.content, nil
 }
 
 func (c intCodec) BerMatch(tag asn1.Tag) bool {
@@ -362,7 +469,7 @@ func (c intCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c intCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Len() == 0 {
-		return &SyntaxError{tag, errors.New("empty integer")}
+		return &SyntaxError{Tag: tag, Err: errors.New("empty integer")}
 	}
 	size := int(c.ref.Type().Size())
 	var signed bool
@@ -372,7 +479,7 @@ func (c intCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		signed = false
 	case reflect.Interface:
-		size = bits.UintSize
+		size = bits.UintSize / 8
 		signed = true
 	default:
 		panic("unreachable")
@@ -385,7 +492,7 @@ func (c intCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	neg := b&0x80 != 0
 	val := uint64(b)
 	if neg && !signed {
-		return &StructuralError{tag, c.ref.Type(), errors.New("integer is signed")}
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("integer is signed")}
 	}
 	read := 1
 	for r.More() && read < size {
@@ -397,8 +504,8 @@ func (c intCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		val <<= 8
 		val |= uint64(b)
 
-		if read == 2 && (val&0xff80 == 0) || (val&0xff80 == 0xff80) {
-			return &SyntaxError{tag, errors.New("integer not minimally-encoded")}
+		if read == 2 && (val&0xff80 == 0 || val&0xff80 == 0xff80) {
+			return &SyntaxError{Tag: tag, Err: errors.New("integer not minimally-encoded")}
 		} else if read == 2 && (val&0xff80 == 0x0080) && !signed {
 			// Pretend our integer is larger than it is because
 			// we do not need to store the leading 0x00 byte.
@@ -406,7 +513,7 @@ func (c intCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		}
 	}
 	if r.More() {
-		return &StructuralError{tag, c.ref.Type(), errors.New("integer too large")}
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("integer too large")}
 	}
 
 	if signed {
@@ -424,9 +531,67 @@ func (c intCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	} else {
 		c.ref.SetUint(val)
 	}
+	if err := asn1.ValidateEnum(c.ref.Interface()); err != nil {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: err}
+	}
 	if vv, ok := c.ref.Interface().(interface{ IsValid() bool }); ok && !vv.IsValid() {
-		return &StructuralError{tag, c.ref.Type(), errors.New("invalid value")}
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("invalid value")}
+	}
+	if opts := optionsOf(r); opts != nil && opts.ValidateIntegers {
+		if err := internal.Validate(c.ref); err != nil {
+			return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: err}
+		}
+	}
+	return nil
+}
+
+// enumStringCodec implements encoding and decoding of the ASN.1 ENUMERATED type
+// from and to Go strings. The string holds the name of a value of the
+// enumeration defined by an [asn1.EnumDef]. It is used for fields with the
+// "enum" struct tag.
+type enumStringCodec struct {
+	ref  reflect.Value
+	enum string // the name of the enum type
+}
+
+// enumType returns the Go type of the enumeration.
+func (c enumStringCodec) enumType() (reflect.Type, error) {
+	if t := asn1.EnumType(c.enum); t != nil {
+		return t, nil
+	}
+	return nil, errors.New("unknown enum type " + c.enum)
+}
+
+func (c enumStringCodec) BerEncode() (Header, io.WriterTo, error) {
+	t, err := c.enumType()
+	if err != nil {
+		return Header{}, nil, err
+	}
+	i, err := asn1.EnumValue(t, c.ref.String())
+	if err != nil {
+		return Header{}, nil, err
+	}
+	return (&intCodec{enum: true, codec: codec[any]{ref: reflect.ValueOf(asn1.Enumerated(i))}}).BerEncode()
+}
+
+func (enumStringCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagEnumerated
+}
+
+func (c enumStringCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	v := reflect.New(reflect.TypeFor[asn1.Enumerated]()).Elem()
+	if err := (intCodec{enum: true, codec: codec[any]{ref: v}}).BerDecode(tag, r); err != nil {
+		return err
 	}
+	t, err := c.enumType()
+	if err != nil {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: err}
+	}
+	name, err := asn1.EnumName(t, int(v.Int()))
+	if err != nil {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: err}
+	}
+	c.ref.SetString(name)
 	return nil
 }
 
@@ -504,10 +669,10 @@ func (c bigIntCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c bigIntCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Len() == 0 {
-		return &SyntaxError{tag, errors.New("empty integer")}
+		return &SyntaxError{Tag: tag, Err: errors.New("empty integer")}
 	}
 	if r.Constructed() {
-		return &SyntaxError{tag, errors.New("constructed INTEGER")}
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed INTEGER")}
 	}
 	bs := make([]byte, r.Len())
 	if _, err := io.ReadFull(r, bs); err != nil {
@@ -515,7 +680,7 @@ func (c bigIntCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	}
 	// set to zero
 	if len(bs) > 1 && ((bs[0] == 0x00 && bs[1]&0x80 == 0x00) || (bs[0] == 0xFF && bs[1]&0x80 == 0x80)) {
-		return &SyntaxError{tag, errors.New("integer not minimally-encoded")}
+		return &SyntaxError{Tag: tag, Err: errors.New("integer not minimally-encoded")}
 	}
 	i := new(big.Int)
 	if bs[0]&0x80 == 0x80 {
@@ -590,11 +755,11 @@ func (c bitStringCodec) BerDecode(tag asn1.Tag, r Reader) error {
 			break
 		}
 		if padding != 0 {
-			err = &SyntaxError{tag, errors.New("non-zero padding in constructed BIT STRING")}
+			err = &SyntaxError{Tag: tag, Err: errors.New("non-zero padding in constructed BIT STRING")}
 			break
 		}
 		if er.Len() == 0 {
-			err = &SyntaxError{tag, errors.New("zero length BIT STRING")}
+			err = &SyntaxError{Tag: tag, Err: errors.New("zero length BIT STRING")}
 			break
 		}
 		padding, err = er.ReadByte()
@@ -602,7 +767,7 @@ func (c bitStringCodec) BerDecode(tag asn1.Tag, r Reader) error {
 			return err
 		}
 		if padding > 7 || er.Len() == 0 && padding > 0 {
-			err = &SyntaxError{tag, errors.New("invalid padding bits in BIT STRING")}
+			err = &SyntaxError{Tag: tag, Err: errors.New("invalid padding bits in BIT STRING")}
 			break
 		}
 		if _, err = buf.ReadFrom(er); err != nil {
@@ -659,6 +824,47 @@ func (c binaryUnmarshalerCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	return c.val.UnmarshalBinary(buf)
 }
 
+// textMarshalerCodec implements encoding of Go values that implement
+// [encoding.TextMarshaler] into an ASN.1 UTF8String. It is used for fields with
+// the "text" struct tag.
+type textMarshalerCodec codec[encoding.TextMarshaler]
+
+func (c textMarshalerCodec) BerEncode() (Header, io.WriterTo, error) {
+	buf, err := c.val.MarshalText()
+	if err != nil {
+		return Header{}, nil, fmt.Errorf("marshal text: %w", err)
+	}
+	if !utf8.Valid(buf) {
+		return Header{}, nil, errors.New("marshal text: invalid UTF-8")
+	}
+	return Header{
+		Tag:         asn1.TagUTF8String,
+		Length:      len(buf),
+		Constructed: false,
+	}, bytes.NewReader(buf), nil
+}
+
+// textUnmarshalerCodec implements decoding of an ASN.1 UTF8String into Go
+// values that implement [encoding.TextUnmarshaler]. It is used for fields with
+// the "text" struct tag. The entire data value encoding is buffered into memory
+// before the unmarshaler is invoked.
+type textUnmarshalerCodec codec[encoding.TextUnmarshaler]
+
+func (textUnmarshalerCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagUTF8String
+}
+
+func (c textUnmarshalerCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	buf, err := NewStringReader(tag, r).Bytes()
+	if err != nil {
+		return err
+	}
+	if !utf8.Valid(buf) {
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid UTF-8")}
+	}
+	return c.val.UnmarshalText(buf)
+}
+
 // bytesCodec implements encoding and decoding of the ASN.1 OCTET STRING type.
 // Encoding and decoding can be done from and to byte slices and byte arrays.
 // Pre-allocated byte slices are resliced and then reused.
@@ -709,9 +915,9 @@ func (c bytesCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		copy(c.ref.Interface().([]byte), bs)
 	} else if c.ref.Kind() == reflect.Array {
 		if len(bs) > c.ref.Len() {
-			return &StructuralError{tag, c.ref.Type(), errors.New("too many bytes")}
+			return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("too many bytes")}
 		} else if len(bs) < c.ref.Len() {
-			return &StructuralError{tag, c.ref.Type(), errors.New("not enough bytes")}
+			return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("not enough bytes")}
 		}
 		copy(c.ref.Slice(0, c.ref.Len()).Interface().([]byte), bs)
 	} else {
@@ -743,12 +949,27 @@ func (c nullCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c nullCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Constructed() || r.Len() > 0 {
-		return &SyntaxError{tag, errors.New("invalid NULL value")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid NULL value")}
 	}
 	c.ref.Set(reflect.Zero(c.ref.Type()))
 	return nil
 }
 
+// flagDecoder decodes a data value into the value of an [asn1.Nullable] or
+// [asn1.Optional] and sets its Valid or Present flag.
+type flagDecoder struct {
+	dec  BerDecoder
+	flag reflect.Value // the Valid or Present field
+}
+
+func (d flagDecoder) BerDecode(tag asn1.Tag, r Reader) error {
+	if err := d.dec.BerDecode(tag, r); err != nil {
+		return err
+	}
+	d.flag.SetBool(true)
+	return nil
+}
+
 //endregion
 
 //region [UNIVERSAL 6] OBJECT IDENTIFIER
@@ -756,33 +977,22 @@ func (c nullCodec) BerDecode(tag asn1.Tag, r Reader) error {
 // oidCodec implements encoding and decoding of the ASN.1 OBJECT IDENTIFIER
 // type. The first two components of the OID are encoded into a single byte.
 // Subsequent components use a variable-length base128 encoding.
-type oidCodec codec[asn1.ObjectIdentifier]
+type oidCodec struct {
+	codec[asn1.ObjectIdentifier]
+	content inlineContent
+}
 
-func (c oidCodec) BerEncode() (Header, io.WriterTo, error) {
-	if len(c.val) < 2 || c.val[0] > 2 || (c.val[0] < 2 && c.val[1] > 40) {
-		return Header{}, nil, errors.New("invalid asn1.ObjectIdentifier")
-	}
-	rel := relativeOIDCodec{val: asn1.RelativeOID(c.val[2:])}
-	l := base128IntLength(c.val[0]*40 + c.val[1])
-	h, wt, err := rel.BerEncode()
+func (c *oidCodec) BerEncode() (Header, io.WriterTo, error) {
+	b, err := AppendOID(c.content.buf[:0], c.val)
 	if err != nil {
 		return Header{}, nil, err
 	}
-	h2 := Header{
+	c.content.b = b
+	return Header{
 		Tag:         asn1.TagOID,
-		Length:      l + h.Length,
+		Length:      len(b),
 		Constructed: false,
-	}
-	return h2, writerFunc(func(w io.Writer) (n int64, err error) {
-		bw := w.(io.ByteWriter)
-		n, err = writeBase128Int(bw, c.val[0]*40+c.val[1])
-		if err != nil {
-			return n, err
-		}
-		n0, err := wt.WriteTo(w)
-		n += n0
-		return n, err
-	}), err
+	}, &c.content, nil
 }
 
 func (oidCodec) BerMatch(tag asn1.Tag) bool {
@@ -791,14 +1001,14 @@ func (oidCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c oidCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Len() == 0 {
-		return &SyntaxError{tag, errors.New("zero length OBJECT IDENTIFIER")}
+		return &SyntaxError{Tag: tag, Err: errors.New("zero length OBJECT IDENTIFIER")}
 	}
 
 	// The first varint is 40*value1 + value2:
 	// According to this packing, value1 can take the values 0, 1 and 2 only.
 	// When value1 = 0 or value1 = 1, then value2 is <= 39. When value1 = 2,
 	// then there are no restrictions on value2.
-	v, err := decodeBase128(r)
+	v, err := vlq.ReadMinimal[uint](r)
 	if err != nil {
 		return err
 	}
@@ -919,7 +1129,7 @@ func (c floatCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 			// negative 0
 			ret = math.Copysign(0, -1)
 		default:
-			return &SyntaxError{tag, errors.New("invalid special value")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid special value")}
 		}
 		goto done
 	} else if b&0x80 == 0x80 {
@@ -955,7 +1165,7 @@ func (c floatCodec) parseBinary(tag asn1.Tag, b byte, r Reader) (ret float64, er
 				m >>= 8
 				e += 8
 			} else {
-				return 0, &SyntaxError{tag, errors.New("mantissa too large")}
+				return 0, &SyntaxError{Tag: tag, Err: errors.New("mantissa too large")}
 			}
 		}
 		m = m<<8 | uint64(b)
@@ -964,7 +1174,7 @@ func (c floatCodec) parseBinary(tag asn1.Tag, b byte, r Reader) (ret float64, er
 		return 0, err
 	}
 	if m == 0 {
-		return 0, &SyntaxError{tag, errors.New("zero mantissa")}
+		return 0, &SyntaxError{Tag: tag, Err: errors.New("zero mantissa")}
 	}
 	zeros := bits.LeadingZeros64(m)
 	if zeros >= 11 {
@@ -973,7 +1183,7 @@ func (c floatCodec) parseBinary(tag asn1.Tag, b byte, r Reader) (ret float64, er
 		// can shift without loss in precision
 		m >>= 11 - zeros
 	} else {
-		return 0, &SyntaxError{tag, errors.New("not enough precision")}
+		return 0, &SyntaxError{Tag: tag, Err: errors.New("not enough precision")}
 	}
 	e += int64(11 - zeros)
 	// At this point m is normalized to 52 bits plus a leading 1 in the 53rd least significant bit.
@@ -982,12 +1192,12 @@ func (c floatCodec) parseBinary(tag asn1.Tag, b byte, r Reader) (ret float64, er
 
 	e += 52
 	if e < -1022 || e > 1023 {
-		return 0, &SyntaxError{tag, errors.New("not enough precision")}
+		return 0, &SyntaxError{Tag: tag, Err: errors.New("not enough precision")}
 	}
 	e += 1023
 	val := math.Float64frombits((uint64(s) << 63) | uint64(e)<<52 | m&^(1<<52))
-	if c.ref.OverflowFloat(val) {
-		return 0, &SyntaxError{tag, errors.New("float32 overflow")}
+	if c.ref.Kind() != reflect.Interface && c.ref.OverflowFloat(val) {
+		return 0, &SyntaxError{Tag: tag, Err: errors.New("float32 overflow")}
 	}
 	return val, nil
 }
@@ -1002,7 +1212,7 @@ func parseRealExp(tag asn1.Tag, b byte, r Reader) (s byte, e int64, err error) {
 	base := (b & 0x30) >> 4 // bit 6 and 5 of b
 	// we keep the binary code of the base for simpler computations later on
 	if base > 2 {
-		return s, e, &SyntaxError{tag, errors.New("invalid base")}
+		return s, e, &SyntaxError{Tag: tag, Err: errors.New("invalid base")}
 	}
 	f := (b & 0x0C) >> 2 // bit 4 and 3 of b
 	es := 1 + (b & 0x03) // bit 2 and 1 of b
@@ -1011,20 +1221,20 @@ func parseRealExp(tag asn1.Tag, b byte, r Reader) (s byte, e int64, err error) {
 			return s, e, err
 		}
 		if b == 0 {
-			return s, e, &SyntaxError{tag, errors.New("invalid exponent size")}
+			return s, e, &SyntaxError{Tag: tag, Err: errors.New("invalid exponent size")}
 		}
 		es = 3 + b
 	}
 	for i := byte(0); i < es; i++ {
 		if i == 8 {
-			return s, e, &SyntaxError{tag, errors.New("exponent too large")}
+			return s, e, &SyntaxError{Tag: tag, Err: errors.New("exponent too large")}
 		}
 		if b, err = r.ReadByte(); err != nil {
 			return s, e, err
 		}
 		e = e<<8 | int64(b)
 		if i == 1 && (e&0xFF80 == 0xFF80 || e&0xFF80 == 0x0000) {
-			return s, e, &SyntaxError{tag, errors.New("non-minimal exponent")}
+			return s, e, &SyntaxError{Tag: tag, Err: errors.New("non-minimal exponent")}
 		}
 	}
 	// Shift up and down in order to sign extend the exponent.
@@ -1049,7 +1259,7 @@ func (c floatCodec) parseDecimal(tag asn1.Tag, b byte, r Reader) (float64, error
 	}
 	nr := b & 0x3F
 	if nr == 0 || nr > 3 {
-		return 0, &SyntaxError{tag, errors.New("invalid decimal number representation")}
+		return 0, &SyntaxError{Tag: tag, Err: errors.New("invalid decimal number representation")}
 	}
 	s := unsafe.String(unsafe.SliceData(bs), len(bs))
 	s = strings.TrimLeft(s, " ")
@@ -1057,12 +1267,12 @@ func (c floatCodec) parseDecimal(tag asn1.Tag, b byte, r Reader) (float64, error
 	// strconv.ParseFloat accepts number that we don't so we do syntax validation
 	ok := validateDecimalReal(s, nr)
 	if !ok {
-		return 0, &SyntaxError{tag, errors.New("invalid decimal number")}
+		return 0, &SyntaxError{Tag: tag, Err: errors.New("invalid decimal number")}
 	}
 
 	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
-		return 0, &SyntaxError{tag, err}
+		return 0, &SyntaxError{Tag: tag, Err: err}
 	}
 	return f, nil
 }
@@ -1243,7 +1453,7 @@ func (c bigFloatCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 			// negative 0
 			ret = big.NewFloat(math.Copysign(0, -1))
 		default:
-			return &SyntaxError{tag, errors.New("invalid special value")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid special value")}
 		}
 	} else if b&0x80 == 0x80 {
 		ret, err = c.parseBinary(tag, b, r)
@@ -1264,7 +1474,7 @@ func (c bigFloatCodec) parseBinary(tag asn1.Tag, b byte, r Reader) (*big.Float,
 		return nil, err
 	}
 	if int64(int(e)) != e {
-		return nil, &SyntaxError{tag, errors.New("exponent too large")}
+		return nil, &SyntaxError{Tag: tag, Err: errors.New("exponent too large")}
 	}
 
 	mbs := make([]byte, r.Len())
@@ -1273,7 +1483,7 @@ func (c bigFloatCodec) parseBinary(tag asn1.Tag, b byte, r Reader) (*big.Float,
 	}
 	m := new(big.Int).SetBytes(mbs)
 	if m.Sign() == 0 {
-		return nil, &SyntaxError{tag, errors.New("zero mantissa")}
+		return nil, &SyntaxError{Tag: tag, Err: errors.New("zero mantissa")}
 	}
 	ret := new(big.Float).SetMantExp(new(big.Float).SetInt(m), int(e))
 	if s != 0 {
@@ -1291,7 +1501,7 @@ func (c bigFloatCodec) parseDecimal(tag asn1.Tag, b byte, r Reader) (*big.Float,
 	}
 	nr := b & 0x3F
 	if nr == 0 || nr > 3 {
-		return nil, &SyntaxError{tag, errors.New("invalid decimal number representation")}
+		return nil, &SyntaxError{Tag: tag, Err: errors.New("invalid decimal number representation")}
 	}
 	s := unsafe.String(unsafe.SliceData(bs), len(bs))
 	s = strings.TrimLeft(s, " ")
@@ -1299,12 +1509,12 @@ func (c bigFloatCodec) parseDecimal(tag asn1.Tag, b byte, r Reader) (*big.Float,
 	// strconv.ParseFloat accepts number that we don't so we do syntax validation
 	ok := validateDecimalReal(s, nr)
 	if !ok {
-		return nil, &SyntaxError{tag, errors.New("invalid decimal number")}
+		return nil, &SyntaxError{Tag: tag, Err: errors.New("invalid decimal number")}
 	}
 
 	f, _, err := new(big.Float).SetPrec(128).Parse(s, 10)
 	if err != nil {
-		return nil, &SyntaxError{tag, err}
+		return nil, &SyntaxError{Tag: tag, Err: err}
 	}
 	return f, nil
 }
@@ -1360,7 +1570,7 @@ func (c stringCodec[T]) BerDecode(tag asn1.Tag, r Reader) error {
 			return err
 		}
 		if !T(buf).IsValid() {
-			return &SyntaxError{tag, errors.New("UTF8String contains invalid characters")}
+			return &SyntaxError{Tag: tag, Err: errors.New("UTF8String contains invalid characters")}
 		}
 		sb.Write(buf)
 	}
@@ -1379,27 +1589,18 @@ func (c stringCodec[T]) BerDecode(tag asn1.Tag, r Reader) error {
 // relativeOIDCodec implements encoding und decoding of the ASN.1 RELATIVE-OID
 // type. Every component is encoded as a variable-length base128 integer. See
 // also the oidCodec type.
-type relativeOIDCodec codec[asn1.RelativeOID]
+type relativeOIDCodec struct {
+	codec[asn1.RelativeOID]
+	content inlineContent
+}
 
-func (c relativeOIDCodec) BerEncode() (Header, io.WriterTo, error) {
-	l := 0
-	for _, n := range c.val {
-		l += base128IntLength(n)
-	}
-	h := Header{
+func (c *relativeOIDCodec) BerEncode() (Header, io.WriterTo, error) {
+	c.content.b = AppendRelativeOID(c.content.buf[:0], c.val)
+	return Header{
 		Tag:         asn1.TagRelativeOID,
-		Length:      l,
+		Length:      len(c.content.b),
 		Constructed: false,
-	}
-	return h, writerFunc(func(w io.Writer) (n int64, err error) {
-		bw := w.(io.ByteWriter)
-		var n2 int64
-		for i := 0; i < len(c.val) && err == nil; i++ {
-			n2, err = writeBase128Int(bw, c.val[i])
-			n += n2
-		}
-		return n, nil
-	}), nil
+	}, &c.content, nil
 }
 
 func (relativeOIDCodec) BerMatch(tag asn1.Tag) bool {
@@ -1408,7 +1609,7 @@ func (relativeOIDCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c relativeOIDCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 	if r.Constructed() {
-		return &SyntaxError{tag, errors.New("primitive encoding")}
+		return &SyntaxError{Tag: tag, Err: errors.New("primitive encoding")}
 	}
 	var s []uint
 	if c.val != nil && len(c.val) >= r.Len() {
@@ -1428,7 +1629,7 @@ func (c relativeOIDCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 func decodeRelativeOID(r io.ByteReader, buf []uint) (i int, err error) {
 	var v uint
 	for {
-		v, err = decodeBase128(r)
+		v, err = vlq.ReadMinimal[uint](r)
 		if err != nil {
 			break
 		}
@@ -1449,7 +1650,8 @@ func decodeRelativeOID(r io.ByteReader, buf []uint) (i int, err error) {
 // encoded as their ASN.1 string representations. Sub-nanosecond precision is
 // silently discarded.
 //
-// Currently only a subset of representable dates can be decoded.
+// Calendar dates, ordinal dates and week dates can be decoded. Currently only a
+// subset of representable values can be decoded.
 type timeCodec codec[asn1.Time]
 
 func (c timeCodec) BerEncode() (h Header, wt io.WriterTo, err error) {
@@ -1471,41 +1673,70 @@ func (c timeCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c timeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Constructed() {
-		return &SyntaxError{tag, errors.New("constructed encoding")}
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
 	}
 	bs := make([]byte, r.Len())
 	_, err := io.ReadFull(r, bs)
 	if err != nil {
 		return err
 	}
-	var year, day int
+	t, ok := parseTime(unsafe.String(unsafe.SliceData(bs), len(bs)))
+	if !ok {
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid TIME")}
+	}
+	c.ref.Set(reflect.ValueOf(t).Convert(c.ref.Type()))
+	return nil
+}
+
+// parseTime parses s as an ISO 8601 date with an optional time of day. The
+// date can be a calendar date, an ordinal date or a week date. The second
+// return value indicates whether s is valid.
+func parseTime(s string) (time.Time, bool) {
+	var year, day, week int
 	var month time.Month
-	s := unsafe.String(unsafe.SliceData(bs), len(bs))
 	datePart, timePart, hasTime := strings.Cut(s, "T")
-	extended := false
+	extended, ordinal, weekDate := false, false, false
 	switch len(datePart) {
 	case 7:
 		year = atoiN[int](datePart, 4)
 		day = atoiN[int](datePart[4:], 3)
+		ordinal = true
 	case 8:
 		year = atoiN[int](datePart, 4)
-		if datePart[4] == '-' {
+		switch datePart[4] {
+		case '-':
 			day = atoiN[int](datePart[5:], 3)
-			extended = true
-		} else {
+			extended, ordinal = true, true
+		case 'W':
+			weekDate = true
+			week = atoiN[int](datePart[5:], 2)
+			day = atoiN[int](datePart[7:], 1)
+		default:
 			month = atoiN[time.Month](datePart[4:], 2)
 			day = atoiN[int](datePart[6:], 2)
 		}
 	case 10:
 		extended = true
 		year = atoiN[int](datePart, 4)
-		month = atoiN[time.Month](datePart[5:], 2)
-		day = atoiN[int](datePart[8:], 2)
-		if datePart[4] != '-' || datePart[7] != '-' {
-			return &SyntaxError{tag, errors.New("invalid TIME")}
+		if datePart[4] != '-' {
+			return time.Time{}, false
+		}
+		if datePart[5] == 'W' {
+			weekDate = true
+			week = atoiN[int](datePart[6:], 2)
+			day = atoiN[int](datePart[9:], 1)
+			if datePart[8] != '-' {
+				return time.Time{}, false
+			}
+		} else {
+			month = atoiN[time.Month](datePart[5:], 2)
+			day = atoiN[int](datePart[8:], 2)
+			if datePart[7] != '-' {
+				return time.Time{}, false
+			}
 		}
 	default:
-		return &SyntaxError{tag, errors.New("invalid TIME")}
+		return time.Time{}, false
 	}
 	var dur time.Duration
 	loc := time.Local
@@ -1513,17 +1744,34 @@ func (c timeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		var ext, ok bool
 		dur, loc, ext, ok = parseISOTime(timePart)
 		if !ok || extended != ext {
-			return &SyntaxError{tag, errors.New("invalid TIME")}
+			return time.Time{}, false
 		}
 	}
-	ret := time.Date(year, month, day, 0, 0, 0, 0, loc)
-	if ret.Year() != year || ret.Month() != month || ret.Day() != day {
-		return &SyntaxError{tag, errors.New("invalid TIME")}
+	var ret time.Time
+	switch {
+	case weekDate:
+		// week 1 is the week containing January 4th
+		if day < 1 || day > 7 || week < 1 || week > 53 {
+			return time.Time{}, false
+		}
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+		monday := 4 - (int(jan4.Weekday())+6)%7
+		ret = time.Date(year, time.January, monday+7*(week-1)+day-1, 0, 0, 0, 0, loc)
+		if y, w := ret.ISOWeek(); y != year || w != week {
+			return time.Time{}, false
+		}
+	case ordinal:
+		ret = time.Date(year, time.January, day, 0, 0, 0, 0, loc)
+		if day < 1 || ret.Year() != year {
+			return time.Time{}, false
+		}
+	default:
+		ret = time.Date(year, month, day, 0, 0, 0, 0, loc)
+		if ret.Year() != year || ret.Month() != month || ret.Day() != day {
+			return time.Time{}, false
+		}
 	}
-	ret = ret.Add(dur)
-
-	c.ref.Set(reflect.ValueOf(ret).Convert(c.ref.Type()))
-	return nil
+	return ret.Add(dur), true
 }
 
 func parseISOTime(s string) (time.Duration, *time.Location, bool, bool) {
@@ -1606,6 +1854,122 @@ tz:
 	return hour*time.Hour + minute*time.Minute + second*time.Second + nanos, loc, ext, true
 }
 
+// timeIntervalCodec implements encoding and decoding of ASN.1 TIME values that
+// are ISO 8601 time intervals.
+type timeIntervalCodec codec[asn1.TimeInterval]
+
+func (c timeIntervalCodec) BerEncode() (h Header, wt io.WriterTo, err error) {
+	if !c.val.IsValid() {
+		return Header{}, nil, errors.New("invalid time interval")
+	}
+	format := c.val.String()
+	h = Header{
+		Tag:         asn1.TagTime,
+		Length:      len(format),
+		Constructed: false,
+	}
+	return h, writerFunc(func(w io.Writer) (int64, error) {
+		n, err := io.WriteString(w, format)
+		return int64(n), err
+	}), nil
+}
+
+func (c timeIntervalCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagTime
+}
+
+func (c timeIntervalCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	if r.Constructed() {
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
+	}
+	bs := make([]byte, r.Len())
+	_, err := io.ReadFull(r, bs)
+	if err != nil {
+		return err
+	}
+	i, ok := parseTimeInterval(unsafe.String(unsafe.SliceData(bs), len(bs)))
+	if !ok {
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid TIME")}
+	}
+	c.ref.Set(reflect.ValueOf(i).Convert(c.ref.Type()))
+	return nil
+}
+
+// parseTimeInterval parses s as an ISO 8601 time interval. The second return
+// value indicates whether s is valid.
+func parseTimeInterval(s string) (i asn1.TimeInterval, ok bool) {
+	first, second, found := strings.Cut(s, "/")
+	switch {
+	case !found:
+		i.Duration, ok = parseISODuration(s)
+	case strings.HasPrefix(first, "P"):
+		if i.Duration, ok = parseISODuration(first); ok {
+			i.End, ok = parseTime(second)
+		}
+	case strings.HasPrefix(second, "P"):
+		if i.Start, ok = parseTime(first); ok {
+			i.Duration, ok = parseISODuration(second)
+		}
+	default:
+		if i.Start, ok = parseTime(first); ok {
+			i.End, ok = parseTime(second)
+		}
+	}
+	return i, ok && i.IsValid()
+}
+
+// recurringIntervalCodec implements encoding and decoding of ASN.1 TIME values
+// that are ISO 8601 recurring time intervals.
+type recurringIntervalCodec codec[asn1.RecurringInterval]
+
+func (c recurringIntervalCodec) BerEncode() (h Header, wt io.WriterTo, err error) {
+	if !c.val.IsValid() {
+		return Header{}, nil, errors.New("invalid time interval")
+	}
+	format := c.val.String()
+	h = Header{
+		Tag:         asn1.TagTime,
+		Length:      len(format),
+		Constructed: false,
+	}
+	return h, writerFunc(func(w io.Writer) (int64, error) {
+		n, err := io.WriteString(w, format)
+		return int64(n), err
+	}), nil
+}
+
+func (c recurringIntervalCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagTime
+}
+
+func (c recurringIntervalCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	if r.Constructed() {
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
+	}
+	bs := make([]byte, r.Len())
+	_, err := io.ReadFull(r, bs)
+	if err != nil {
+		return err
+	}
+	s := unsafe.String(unsafe.SliceData(bs), len(bs))
+	n, interval, found := strings.Cut(s, "/")
+	if !found || !strings.HasPrefix(n, "R") {
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid TIME")}
+	}
+	ret := asn1.RecurringInterval{Recurrences: -1}
+	if n = n[1:]; n != "" {
+		if ret.Recurrences, err = strconv.Atoi(n); err != nil || n[0] < '0' || '9' < n[0] {
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid TIME")}
+		}
+	}
+	var ok bool
+	if ret.Interval, ok = parseTimeInterval(interval); !ok {
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid TIME")}
+	}
+	c.ref.Set(reflect.ValueOf(ret).Convert(c.ref.Type()))
+	return nil
+}
+
 //endregion
 
 //region [UNIVERSAL 16] SEQUENCE
@@ -1618,16 +1982,29 @@ tz:
 // represented in Go as maps with a value type of struct{}. During decoding the
 // entire map is replaced with the decoded value. Pre-allocated maps are
 // cleared.
+//
+// During encoding the elements are sorted by their encodings as required by
+// DER. This makes the encoding of sets deterministic.
 type setCodec codec[any]
 
 func (c setCodec) BerEncode() (Header, io.WriterTo, error) {
-	seq := Sequence{Tag: asn1.TagSet}
-	for _, key := range c.ref.MapKeys() {
-		if err := seq.append(key, internal.FieldParameters{}); err != nil {
+	return encodeSetElements(c.ref.MapKeys())
+}
+
+// encodeSetElements returns the encoding of a SET OF with the given elements.
+// The elements are sorted by their encodings as required by DER.
+func encodeSetElements(elems []reflect.Value) (Header, io.WriterTo, error) {
+	encodings := make([][]byte, 0, len(elems))
+	for _, elem := range elems {
+		var buf bytes.Buffer
+		if _, err := writeElement(elem, &buf); err != nil {
 			return Header{}, nil, err
 		}
+		encodings = append(encodings, buf.Bytes())
 	}
-	return seq.BerEncode()
+	slices.SortFunc(encodings, bytes.Compare)
+	content := slices.Concat(encodings...)
+	return Header{asn1.TagSet, len(content), true}, bytes.NewReader(content), nil
 }
 
 func (c setCodec) BerMatch(tag asn1.Tag) bool {
@@ -1667,6 +2044,69 @@ func (c setCodec) BerDecode(_ asn1.Tag, r Reader) (err error) {
 	return err
 }
 
+// setSliceEncoder implements encoding of slices and arrays with a `asn1:"set"`
+// struct tag as SET OF. Like setCodec, the elements are sorted by their
+// encodings as required by DER. Decoding is implemented by the SEQUENCE OF
+// decoder, which retains the order of the elements in the input.
+type setSliceEncoder struct {
+	ref reflect.Value
+}
+
+func (e setSliceEncoder) BerEncode() (Header, io.WriterTo, error) {
+	elems := make([]reflect.Value, e.ref.Len())
+	for i := range elems {
+		elems[i] = e.ref.Index(i)
+	}
+	return encodeSetElements(elems)
+}
+
+// setOfCodec implements encoding and decoding of the ASN.1 SET OF type for
+// values of type [asn1.SetOf]. The elements are accessed via the methods of
+// the set. During decoding the set is cleared before decoding the elements,
+// but its equality function is retained. Like setCodec, the elements are sorted
+// by their encodings during encoding.
+type setOfCodec codec[any]
+
+func (c setOfCodec) BerEncode() (Header, io.WriterTo, error) {
+	vals := c.ref.MethodByName("Values").Call(nil)[0]
+	elems := make([]reflect.Value, vals.Len())
+	for i := range elems {
+		elems[i] = vals.Index(i)
+	}
+	return encodeSetElements(elems)
+}
+
+func (c setOfCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagSet
+}
+
+func (c setOfCodec) BerDecode(_ asn1.Tag, r Reader) (err error) {
+	s := c.ref.Addr()
+	s.MethodByName("Clear").Call(nil)
+	add := s.MethodByName("Add")
+	elemType := add.Type().In(0)
+	var (
+		params internal.FieldParameters
+		h      Header
+		er     Reader
+	)
+	for err == nil {
+		if h, er, err = r.Next(); err != nil {
+			break
+		}
+		v := reflect.New(elemType).Elem()
+		if err = decodeValue(h.Tag, er, v, params); err != nil {
+			break
+		}
+		add.Call([]reflect.Value{v})
+		err = er.Close()
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return err
+}
+
 //endregion
 
 //region [UNIVERSAL 23] UTCTime
@@ -1696,12 +2136,16 @@ func (utcTimeCodec) BerMatch(tag asn1.Tag) bool {
 }
 
 func (c utcTimeCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
+	opts := optionsOf(r)
+	if opts != nil && opts.DisableUTCTime {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("UTCTime is disabled")}
+	}
 	s, err := NewStringReader(tag, r).String()
 	if err != nil {
 		return err
 	}
 	if len(s) < 11 || len(s) > 17 {
-		return &SyntaxError{tag, errors.New("invalid UTCTime")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid UTCTime")}
 	}
 	year := atoiN[int](s, 2)
 	month := atoiN[time.Month](s[2:], 2)
@@ -1715,22 +2159,27 @@ func (c utcTimeCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 	} else {
 		second = 0
 	}
+	nsec := 0
+	leap := second == 60 && opts != nil && opts.AllowLeapSeconds
+	if leap {
+		second, nsec = 59, 999999000
+	}
 	loc := parseLocation(s)
 	if loc == nil {
-		return &SyntaxError{tag, errors.New("invalid UTCTime")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid UTCTime")}
 	}
 
-	// UTCTime only encodes times prior to 2050. See https://tools.ietf.org/html/rfc5280#section-4.1.2.5.1
+	// By default UTCTime only encodes times prior to 2050. See https://tools.ietf.org/html/rfc5280#section-4.1.2.5.1
 	if year < 0 {
-		return &SyntaxError{tag, errors.New("invalid UTCTime")}
-	} else if year <= 49 {
-		year += 2000
-	} else {
-		year += 1900
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid UTCTime")}
 	}
-	ret := time.Date(year, month, day, hour, minute, second, 0, loc)
+	year = opts.utcTimeYear(year)
+	ret := time.Date(year, month, day, hour, minute, second, nsec, loc)
 	if ret.Year() != year || ret.Month() != month || ret.Day() != day || ret.Hour() != hour || ret.Minute() != minute || ret.Second() != second {
-		return &SyntaxError{tag, errors.New("invalid UTCTime")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid UTCTime")}
+	}
+	if leap {
+		warn(r, &SyntaxError{Tag: tag, Err: ErrLeapSecond})
 	}
 	c.ref.Set(reflect.ValueOf(ret).Convert(c.ref.Type()))
 	return nil
@@ -1803,14 +2252,14 @@ func (c generalizedTimeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		return err
 	}
 	if len(s) < 10 {
-		return &SyntaxError{tag, errors.New("invalid GeneralizedTime")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid GeneralizedTime")}
 	}
 	year := atoiN[int](s, 4)
 	month := atoiN[time.Month](s[4:], 2)
 	day := atoiN[int](s[6:], 2)
 	hour := atoiN[time.Duration](s[8:], 2)
 	if hour < 0 || 23 < hour {
-		return &SyntaxError{tag, errors.New("invalid GeneralizedTime")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid GeneralizedTime")}
 	}
 	s = s[10:]
 	dur := hour * time.Hour
@@ -1822,17 +2271,22 @@ func (c generalizedTimeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 			unit = time.Minute
 			s = s[2:]
 		} else {
-			return &SyntaxError{tag, errors.New("invalid GeneralizedTime")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid GeneralizedTime")}
 		}
 	}
+	leap := false
 	if len(s) >= 2 && '0' <= s[0] && s[0] <= '9' {
 		second := atoiN[time.Duration](s, 2)
+		if opts := optionsOf(r); second == 60 && opts != nil && opts.AllowLeapSeconds {
+			leap = true
+			second = 59
+		}
 		if 0 <= second && second <= 59 {
 			unit = time.Second
 			dur += second * time.Second
 			s = s[2:]
 		} else {
-			return &SyntaxError{tag, errors.New("invalid GeneralizedTime")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid GeneralizedTime")}
 		}
 	}
 	if len(s) > 0 && (s[0] == '.' || s[0] == ',') {
@@ -1845,23 +2299,29 @@ func (c generalizedTimeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 			dur += time.Duration(s[i]-'0') * unit
 		}
 		if i == 1 {
-			return &SyntaxError{tag, errors.New("invalid GeneralizedTime")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid GeneralizedTime")}
 		}
 		s = s[i:]
 	}
+	if leap {
+		dur = dur.Truncate(time.Minute) + 59*time.Second + 999999*time.Microsecond
+	}
 	var loc *time.Location
 	if len(s) == 0 {
 		loc = time.Local
 	} else {
 		loc = parseLocation(s)
 		if loc == nil {
-			return &SyntaxError{tag, errors.New("invalid GeneralizedTime")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid GeneralizedTime")}
 		}
 	}
 	ret := time.Date(year, month, day, 0, 0, 0, 0, loc)
 	ret = ret.Add(dur)
 	if ret.Year() != year || ret.Month() != month || ret.Day() != day {
-		return &SyntaxError{tag, errors.New("invalid GeneralizedTime")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid GeneralizedTime")}
+	}
+	if leap {
+		warn(r, &SyntaxError{Tag: tag, Err: ErrLeapSecond})
 	}
 	c.ref.Set(reflect.ValueOf(ret).Convert(c.ref.Type()))
 	return nil
@@ -1869,6 +2329,39 @@ func (c generalizedTimeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 
 //endregion
 
+//region X.509 Time
+
+// x509TimeCodec implements encoding and decoding of [time.Time] values without
+// an explicit tag. This corresponds to the Time type of X.509, a CHOICE of
+// UTCTime and GeneralizedTime. Values are encoded in UTC as UTCTime if the year
+// is between 1950 and 2049 and as GeneralizedTime otherwise, as required by
+// DER. Values can be decoded from UTCTime, GeneralizedTime and TIME.
+type x509TimeCodec codec[time.Time]
+
+func (c x509TimeCodec) BerEncode() (Header, io.WriterTo, error) {
+	t := c.val.UTC()
+	if ut := asn1.UTCTime(t); ut.IsValid() {
+		return utcTimeCodec{c.ref, ut}.BerEncode()
+	}
+	return generalizedTimeCodec{c.ref, asn1.GeneralizedTime(t)}.BerEncode()
+}
+
+func (c x509TimeCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagUTCTime || tag == asn1.TagGeneralizedTime || tag == asn1.TagTime
+}
+
+func (c x509TimeCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	switch tag {
+	case asn1.TagUTCTime:
+		return utcTimeCodec{ref: c.ref}.BerDecode(tag, r)
+	case asn1.TagGeneralizedTime:
+		return generalizedTimeCodec{ref: c.ref}.BerDecode(tag, r)
+	}
+	return timeCodec{ref: c.ref}.BerDecode(tag, r)
+}
+
+//endregion
+
 //region [UNIVERSAL 28] UniversalString
 
 // universalStringCodec implements encoding and decoding of the ASN.1
@@ -1912,7 +2405,7 @@ func (c universalStringCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 			return err
 		}
 		if er.Len()%4 != 0 {
-			return &SyntaxError{tag, errors.New("length of UniversalString is no multiple of 4")}
+			return &SyntaxError{Tag: tag, Err: errors.New("length of UniversalString is no multiple of 4")}
 		}
 		sb.Grow(er.Len() / 4)
 		for err == nil {
@@ -1922,7 +2415,7 @@ func (c universalStringCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 			}
 			x := uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])
 			if !utf8.ValidRune(rune(x)) {
-				err = &SyntaxError{tag, errors.New("UniversalString contains invalid characters")}
+				err = &SyntaxError{Tag: tag, Err: errors.New("UniversalString contains invalid characters")}
 				sb.WriteRune(utf8.RuneError)
 			} else {
 				sb.WriteRune(rune(x))
@@ -1986,7 +2479,7 @@ func (c bmpStringCodec) BerDecode(tag asn1.Tag, r Reader) (err error) {
 			return err
 		}
 		if er.Len()%2 != 0 {
-			return &SyntaxError{tag, errors.New("odd-length BMP string")}
+			return &SyntaxError{Tag: tag, Err: errors.New("odd-length BMP string")}
 		}
 		for er.More() {
 			var bs [2]byte
@@ -2034,7 +2527,7 @@ func (c dateCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c dateCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Constructed() {
-		return &SyntaxError{tag, errors.New("constructed encoding")}
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
 	}
 	bs := make([]byte, r.Len())
 	_, err := io.ReadFull(r, bs)
@@ -2058,7 +2551,7 @@ func (c dateCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	}
 	ret := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
 	if !ok || ret.Year() != year || ret.Month() != month || ret.Day() != day {
-		return &SyntaxError{tag, errors.New("invalid DATE")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid DATE")}
 	}
 	c.ref.Set(reflect.ValueOf(ret).Convert(c.ref.Type()))
 	return nil
@@ -2094,7 +2587,7 @@ func (c timeOfDayCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c timeOfDayCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Constructed() {
-		return &SyntaxError{tag, errors.New("constructed encoding")}
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
 	}
 	bs := make([]byte, r.Len())
 	_, err := io.ReadFull(r, bs)
@@ -2115,11 +2608,11 @@ func (c timeOfDayCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		second = atoiN[int](s[6:], 2)
 		ok = s[2] == ':' && s[5] == ':'
 	default:
-		return &SyntaxError{tag, errors.New("invalid TIME-OF-DAY")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid TIME-OF-DAY")}
 	}
 	ret := time.Date(1, 1, 1, hour, minute, second, 0, time.Local)
 	if !ok || ret.Hour() != hour || ret.Minute() != minute || ret.Second() != second {
-		return &SyntaxError{tag, errors.New("invalid TIME-OF-DAY")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid TIME-OF-DAY")}
 	}
 	c.ref.Set(reflect.ValueOf(ret).Convert(c.ref.Type()))
 	return nil
@@ -2155,7 +2648,7 @@ func (c dateTimeCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c dateTimeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Constructed() {
-		return &SyntaxError{tag, errors.New("constructed encoding")}
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
 	}
 	bs := make([]byte, r.Len())
 	_, err := io.ReadFull(r, bs)
@@ -2185,12 +2678,12 @@ func (c dateTimeCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		second = atoiN[int](s[17:], 2)
 		ok = s[4] == '-' && s[7] == '-' && s[10] == 'T' && s[13] == ':' && s[16] == ':'
 	default:
-		return &SyntaxError{tag, errors.New("invalid DATE-TIME")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid DATE-TIME")}
 	}
 
 	ret := time.Date(year, month, day, hour, minute, second, 0, time.Local)
 	if !ok || ret.Year() != year || ret.Month() != month || ret.Day() != day || ret.Hour() != hour || ret.Minute() != minute || ret.Second() != second {
-		return &SyntaxError{tag, errors.New("invalid DATE-TIME")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid DATE-TIME")}
 	}
 	c.ref.Set(reflect.ValueOf(ret).Convert(c.ref.Type()))
 	return nil
@@ -2223,7 +2716,7 @@ func (c durationCodec) BerMatch(tag asn1.Tag) bool {
 
 func (c durationCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	if r.Constructed() {
-		return &SyntaxError{tag, errors.New("constructed encoding")}
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
 	}
 	bs := make([]byte, r.Len())
 	_, err := io.ReadFull(r, bs)
@@ -2233,7 +2726,7 @@ func (c durationCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	s := unsafe.String(unsafe.SliceData(bs), len(bs))
 	var val time.Duration
 	if len(s) == 0 {
-		return &SyntaxError{tag, errors.New("invalid DURATION")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid DURATION")}
 	}
 	sign := time.Duration(1)
 	if s[0] == '+' || s[0] == '-' {
@@ -2241,7 +2734,7 @@ func (c durationCodec) BerDecode(tag asn1.Tag, r Reader) error {
 		s = s[1:]
 	}
 	if !strings.HasPrefix(s, "PT") {
-		return &SyntaxError{tag, errors.New("invalid DURATION")}
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid DURATION")}
 	}
 	s = s[2:]
 	unit := 2 * time.Hour
@@ -2249,7 +2742,7 @@ func (c durationCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	for len(s) > 0 {
 		if frac != "" {
 			// we have content after a fractional unit
-			return &SyntaxError{tag, errors.New("invalid DURATION")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid DURATION")}
 		}
 		var n time.Duration
 		sign := time.Duration(1)
@@ -2273,12 +2766,12 @@ func (c durationCodec) BerDecode(tag asn1.Tag, r Reader) error {
 				}
 			}
 			if j == i {
-				return &SyntaxError{tag, errors.New("invalid DURATION")}
+				return &SyntaxError{Tag: tag, Err: errors.New("invalid DURATION")}
 			}
 			frac = s[j:i]
 		}
 		if i == 0 || i == len(s) {
-			return &SyntaxError{tag, errors.New("invalid DURATION")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid DURATION")}
 		}
 		newUnit := 10 * time.Hour
 		switch s[i] {
@@ -2290,7 +2783,7 @@ func (c durationCodec) BerDecode(tag asn1.Tag, r Reader) error {
 			newUnit = time.Second
 		}
 		if newUnit >= unit {
-			return &SyntaxError{tag, errors.New("invalid DURATION")}
+			return &SyntaxError{Tag: tag, Err: errors.New("invalid DURATION")}
 		}
 		unit = newUnit
 		val += sign * n * unit
@@ -2305,6 +2798,233 @@ func (c durationCodec) BerDecode(tag asn1.Tag, r Reader) error {
 	return nil
 }
 
+// isoDurationCodec implements encoding and decoding of the ASN.1 DURATION type
+// into an [asn1.ISODuration]. A fractional part is only supported for the time
+// components of a duration.
+type isoDurationCodec codec[asn1.ISODuration]
+
+func (c isoDurationCodec) BerEncode() (h Header, wt io.WriterTo, err error) {
+	if !c.val.IsValid() {
+		return Header{}, nil, errors.New("invalid DURATION")
+	}
+	format := c.val.String()
+	h = Header{
+		Tag:         asn1.TagDuration,
+		Length:      len(format),
+		Constructed: false,
+	}
+	return h, writerFunc(func(w io.Writer) (int64, error) {
+		n, err := io.WriteString(w, format)
+		return int64(n), err
+	}), nil
+}
+
+func (c isoDurationCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagDuration
+}
+
+func (c isoDurationCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	if r.Constructed() {
+		return &SyntaxError{Tag: tag, Err: errors.New("constructed encoding")}
+	}
+	bs := make([]byte, r.Len())
+	_, err := io.ReadFull(r, bs)
+	if err != nil {
+		return err
+	}
+	d, ok := parseISODuration(unsafe.String(unsafe.SliceData(bs), len(bs)))
+	if !ok {
+		return &SyntaxError{Tag: tag, Err: errors.New("invalid DURATION")}
+	}
+	c.ref.Set(reflect.ValueOf(d).Convert(c.ref.Type()))
+	return nil
+}
+
+// parseISODuration parses s as an ISO 8601 duration. The second return value
+// indicates whether s is valid.
+func parseISODuration(s string) (d asn1.ISODuration, ok bool) {
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		d.Negative = s[0] == '-'
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return d, false
+	}
+	s = s[1:]
+	designators := "YMWD"
+	fields := []*int{&d.Years, &d.Months, &d.Weeks, &d.Days}
+	next := 0 // index of the next allowed designator
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if designators == "HMS" || len(s) == 1 {
+				return d, false
+			}
+			designators = "HMS"
+			fields = []*int{&d.Hours, &d.Minutes, &d.Seconds}
+			next = 0
+			s = s[1:]
+			continue
+		}
+		i := 0
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return d, false
+		}
+		var frac string
+		if i < len(s) && (s[i] == '.' || s[i] == ',') {
+			j := i + 1
+			for j < len(s) && '0' <= s[j] && s[j] <= '9' {
+				j++
+			}
+			frac, i = s[i+1:j], j
+		}
+		if i == len(s) {
+			return d, false
+		}
+		k := strings.IndexByte(designators[next:], s[i])
+		if k < 0 {
+			return d, false
+		}
+		next += k
+		*fields[next] = n
+		next++
+		s = s[i+1:]
+		if frac == "" {
+			continue
+		}
+		// a fractional part is only allowed in the last time component
+		if len(s) > 0 || designators != "HMS" {
+			return d, false
+		}
+		unit := [...]time.Duration{time.Hour, time.Minute, time.Second}[next-1]
+		var rem time.Duration
+		for _, c := range frac[:min(len(frac), 9)] {
+			unit /= 10
+			rem += time.Duration(c-'0') * unit
+		}
+		d.Minutes += int(rem / time.Minute)
+		d.Seconds += int(rem % time.Minute / time.Second)
+		d.Nanoseconds = int(rem % time.Second)
+	}
+	return d, true
+}
+
+//endregion
+
+//region IP addresses
+
+// ipAddrCodec implements encoding and decoding of [netip.Addr] values as an
+// ASN.1 OCTET STRING. IPv4 addresses use 4 octets, IPv6 addresses use 16
+// octets. Addresses with a zone cannot be encoded.
+type ipAddrCodec codec[netip.Addr]
+
+func (c ipAddrCodec) BerEncode() (Header, io.WriterTo, error) {
+	if !c.val.IsValid() || c.val.Zone() != "" {
+		return Header{}, nil, errors.New("invalid IP address")
+	}
+	b := c.val.AsSlice()
+	return Header{
+		Tag:         asn1.TagOctetString,
+		Length:      len(b),
+		Constructed: false,
+	}, bytes.NewReader(b), nil
+}
+
+func (ipAddrCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagOctetString
+}
+
+func (c ipAddrCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	b, err := NewStringReader(tag, r).Bytes()
+	if err != nil {
+		return err
+	}
+	addr, ok := netip.AddrFromSlice(b)
+	if !ok {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("invalid IP address length")}
+	}
+	c.ref.Set(reflect.ValueOf(addr).Convert(c.ref.Type()))
+	return nil
+}
+
+// ipPrefixCodec implements encoding and decoding of [netip.Prefix] values as
+// an ASN.1 OCTET STRING. The encoding consists of the address followed by the
+// network mask, as used in the iPAddress name constraints of RFC 5280.
+type ipPrefixCodec codec[netip.Prefix]
+
+func (c ipPrefixCodec) BerEncode() (Header, io.WriterTo, error) {
+	if !c.val.IsValid() || c.val.Addr().Zone() != "" {
+		return Header{}, nil, errors.New("invalid IP prefix")
+	}
+	addr := c.val.Addr().AsSlice()
+	b := append(addr, net.CIDRMask(c.val.Bits(), 8*len(addr))...)
+	return Header{
+		Tag:         asn1.TagOctetString,
+		Length:      len(b),
+		Constructed: false,
+	}, bytes.NewReader(b), nil
+}
+
+func (ipPrefixCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagOctetString
+}
+
+func (c ipPrefixCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	b, err := NewStringReader(tag, r).Bytes()
+	if err != nil {
+		return err
+	}
+	if len(b) != 2*net.IPv4len && len(b) != 2*net.IPv6len {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("invalid IP prefix length")}
+	}
+	addr, _ := netip.AddrFromSlice(b[:len(b)/2])
+	ones, bits := net.IPMask(b[len(b)/2:]).Size()
+	if bits == 0 {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("non-contiguous network mask")}
+	}
+	c.ref.Set(reflect.ValueOf(netip.PrefixFrom(addr, ones)).Convert(c.ref.Type()))
+	return nil
+}
+
+// ipCodec implements encoding and decoding of [net.IP] values as an ASN.1
+// OCTET STRING. IPv4 addresses are encoded using 4 octets, even if they are
+// stored in their 16-byte form.
+type ipCodec codec[net.IP]
+
+func (c ipCodec) BerEncode() (Header, io.WriterTo, error) {
+	b := c.val.To4()
+	if b == nil {
+		b = c.val.To16()
+	}
+	if b == nil {
+		return Header{}, nil, errors.New("invalid IP address")
+	}
+	return Header{
+		Tag:         asn1.TagOctetString,
+		Length:      len(b),
+		Constructed: false,
+	}, bytes.NewReader(b), nil
+}
+
+func (ipCodec) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagOctetString
+}
+
+func (c ipCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	b, err := NewStringReader(tag, r).Bytes()
+	if err != nil {
+		return err
+	}
+	if len(b) != net.IPv4len && len(b) != net.IPv6len {
+		return &StructuralError{Tag: tag, Type: c.ref.Type(), Err: errors.New("invalid IP address length")}
+	}
+	c.ref.Set(reflect.ValueOf(net.IP(b)).Convert(c.ref.Type()))
+	return nil
+}
+
 //endregion
 
 // region type Flag
@@ -2343,28 +3063,104 @@ func (c rawValueCodec) BerMatch(tag asn1.Tag) bool {
 }
 
 func (c rawValueCodec) BerDecode(tag asn1.Tag, r Reader) error {
-	rv := RawValue{
+	re, err := decodeRawElement(tag, r, false)
+	c.ref.Set(reflect.ValueOf(re.RawValue))
+	return err
+}
+
+// rawElementCodec implements encoding and decoding of the [RawElement] type.
+// Matching works like for [RawValue]. The exact identifier and length octets
+// are retained in FullBytes so that encoding a decoded value reproduces the
+// original bytes.
+type rawElementCodec codec[RawElement]
+
+func (c rawElementCodec) BerEncode() (Header, io.WriterTo, error) {
+	h := Header{c.val.Tag, len(c.val.Bytes), c.val.Constructed}
+	if c.val.FullBytes == nil {
+		return h, bytes.NewReader(c.val.Bytes), nil
+	}
+	// Only use FullBytes if it still matches the other fields.
+	fh, err := decodeHeader(bytes.NewReader(c.val.FullBytes))
+	if err != nil || fh.Tag != h.Tag || fh.Constructed != h.Constructed {
+		return h, bytes.NewReader(c.val.Bytes), nil
+	}
+	content := c.val.FullBytes[len(c.val.FullBytes)-len(c.val.Bytes):]
+	if fh.Length == LengthIndefinite {
+		content = c.val.FullBytes[max(len(c.val.FullBytes)-len(c.val.Bytes)-2, 0) : len(c.val.FullBytes)-2]
+	}
+	if !bytes.Equal(content, c.val.Bytes) {
+		return h, bytes.NewReader(c.val.Bytes), nil
+	}
+	return h, rawEncoding{c.val.FullBytes, c.val.Bytes}, nil
+}
+
+func (c rawElementCodec) BerMatch(tag asn1.Tag) bool {
+	return rawValueCodec{val: c.val.RawValue}.BerMatch(tag)
+}
+
+func (c rawElementCodec) BerDecode(tag asn1.Tag, r Reader) error {
+	re, err := decodeRawElement(tag, r, true)
+	c.ref.Set(reflect.ValueOf(re))
+	return err
+}
+
+// decodeRawElement reads the data value from r into a [RawElement]. If alias is
+// set and r reads from a byte slice, the returned value references it instead
+// of copying.
+func decodeRawElement(tag asn1.Tag, r Reader, alias bool) (RawElement, error) {
+	re := RawElement{RawValue: RawValue{
 		Tag:         tag,
 		Constructed: r.Constructed(),
+	}}
+	indefinite := r.Len() == LengthIndefinite
+	er, ok := r.(*reader)
+	if ok && alias && er.src != nil {
+		// reference the input instead of copying
+		start := er.src.offset()
+		if r.Constructed() || start+r.Len() <= len(er.src.b) {
+			err := r.Close()
+			end := er.src.offset()
+			re.FullBytes = er.src.b[er.start:end:end]
+			if indefinite && err == nil {
+				end -= 2 // end-of-contents
+			}
+			re.Bytes = er.src.b[start:end:end]
+			return re, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if ok && len(er.header.B) > 0 {
+		buf.Write(er.header.B)
+	} else {
+		_, _ = Header{tag, r.Len(), r.Constructed()}.writeTo(&buf)
 	}
+	headerLen := buf.Len()
 	if !r.Constructed() {
-		rv.Bytes = make([]byte, r.Len())
-		_, err := io.ReadFull(r, rv.Bytes)
-		c.ref.Set(reflect.ValueOf(rv))
-		return err
+		buf.Grow(r.Len())
+		_, err := io.CopyN(&buf, r, int64(r.Len()))
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		re.FullBytes = buf.Bytes()
+		re.Bytes = re.FullBytes[headerLen:]
+		return re, err
 	}
-	buf := bytes.Buffer{}
-	if r.Len() != LengthIndefinite {
+	if !indefinite {
 		buf.Grow(r.Len())
 	}
-	lr := r.(*reader).R
-	r.(*reader).R = &limitReader{io.TeeReader(lr, &buf), lr.N}
+	lr := er.R
+	er.R = &limitReader{io.TeeReader(lr, &buf), lr.N}
 
 	// Validate the syntax and read the content octets
 	err := r.Close()
-	rv.Bytes = buf.Bytes()
-	c.ref.Set(reflect.ValueOf(rv))
-	return err
+	re.FullBytes = buf.Bytes()
+	end := len(re.FullBytes)
+	if indefinite && err == nil {
+		end -= 2 // end-of-contents
+	}
+	re.Bytes = re.FullBytes[headerLen:end:end]
+	return re, err
 }
 
 // endregion
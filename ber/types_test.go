@@ -10,8 +10,13 @@ import (
 	"errors"
 	"math"
 	"math/big"
+	"net"
+	"net/netip"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"codello.dev/asn1"
@@ -142,10 +147,14 @@ func TestBoolCodec(t *testing.T) {
 func TestIntCodec(t *testing.T) {
 	testCodec(t, map[string]testCase[int]{
 		// Marshal & Unmarshal
-		"Zero":          {val: 0, data: []byte{0x02, 0x01, 0x00}},
-		"Positive":      {val: 723, data: []byte{0x02, 0x02, 0x02, 0xD3}},
-		"Negative":      {val: -2, data: []byte{0x02, 0x01, 0xFE}},
-		"LargeNegative": {val: -258, data: []byte{0x02, 0x02, 0xFE, 0xFE}},
+		"Zero":            {val: 0, data: []byte{0x02, 0x01, 0x00}},
+		"Positive":        {val: 723, data: []byte{0x02, 0x02, 0x02, 0xD3}},
+		"Negative":        {val: -2, data: []byte{0x02, 0x01, 0xFE}},
+		"LargeNegative":   {val: -258, data: []byte{0x02, 0x02, 0xFE, 0xFE}},
+		"PositiveHighBit": {val: 128, data: []byte{0x02, 0x02, 0x00, 0x80}},
+		"NegativeHighBit": {val: -129, data: []byte{0x02, 0x02, 0xFF, 0x7F}},
+		"MinInt":          {val: math.MinInt64, data: []byte{0x02, 0x08, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		"ThirdOctet":      {val: 0x01FF80, data: []byte{0x02, 0x03, 0x01, 0xFF, 0x80}},
 	}, nil, map[string]testCase[int]{
 		// Unmarshal
 		"Empty":              {data: []byte{0x02, 0x00}, wantErr: &SyntaxError{}},
@@ -162,6 +171,11 @@ func TestIntCodec(t *testing.T) {
 		"TooLargeUint16": {data: []byte{0x02, 0x03, 0x02, 0x15, 0x51}, wantErr: &StructuralError{}},
 		"SignedUint":     {data: []byte{0x02, 0x02, 0xFF, 0x51}, wantErr: &StructuralError{}},
 	})
+	testCodec(t, nil, nil, map[string]testCase[any]{
+		// Unmarshal
+		"Interface":         {val: 5, data: []byte{0x02, 0x01, 0x05}},
+		"TooLargeInterface": {data: []byte{0x02, 0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, wantErr: &StructuralError{}},
+	})
 }
 
 func TestBigIntCodec(t *testing.T) {
@@ -286,6 +300,34 @@ func TestNullCodec(t *testing.T) {
 	})
 }
 
+func TestNullable(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.Nullable[int]]{
+		"Null":     {val: asn1.Nullable[int]{}, data: []byte{0x05, 0x00}},
+		"Valid":    {val: asn1.NewNullable(5), data: []byte{0x02, 0x01, 0x05}},
+		"Zero":     {val: asn1.NewNullable(0), data: []byte{0x02, 0x01, 0x00}},
+		"Implicit": {val: asn1.NewNullable(5), params: "tag:1", data: []byte{0x81, 0x01, 0x05}},
+	}, map[string]testCase[asn1.Nullable[int]]{
+		"Range": {val: asn1.NewNullable(5), params: "range:0..3", wantErr: &EncodeError{}},
+	}, map[string]testCase[asn1.Nullable[int]]{
+		"Range":    {data: []byte{0x02, 0x01, 0x05}, params: "range:0..3", wantErr: &StructuralError{}},
+		"Mismatch": {data: []byte{0x04, 0x00}, wantErr: &StructuralError{}},
+	})
+}
+
+func TestOptional(t *testing.T) {
+	type seq struct {
+		A asn1.Optional[int]
+		B bool
+	}
+	testCodec(t, map[string]testCase[seq]{
+		"Absent":  {val: seq{B: true}, data: []byte{0x30, 0x03, 0x01, 0x01, 0xFF}},
+		"Present": {val: seq{asn1.NewOptional(5), true}, data: []byte{0x30, 0x06, 0x02, 0x01, 0x05, 0x01, 0x01, 0xFF}},
+		"Zero":    {val: seq{asn1.NewOptional(0), true}, data: []byte{0x30, 0x06, 0x02, 0x01, 0x00, 0x01, 0x01, 0xFF}},
+	}, nil, map[string]testCase[seq]{
+		"Missing": {data: []byte{0x30, 0x00}, wantErr: &StructuralError{}},
+	})
+}
+
 //endregion
 
 //region [UNIVERSAL 6] OBJECT IDENTIFIER
@@ -383,6 +425,49 @@ func TestEnumeratedCodec(t *testing.T) {
 	})
 }
 
+// testEnumDef is a type for testing enumerations defined by an asn1.EnumDef.
+type testEnumDef int
+
+var _ = asn1.NewEnumDef(map[testEnumDef]string{1: "one", 2: "two"})
+
+func TestEnumDefCodec(t *testing.T) {
+	testCodec(t, map[string]testCase[testEnumDef]{
+		"Valid": {val: testEnumDef(2), data: []byte{0x0A, 0x01, 0x02}},
+	}, map[string]testCase[testEnumDef]{
+		"Invalid": {val: testEnumDef(7), wantErr: &EncodeError{}},
+	}, map[string]testCase[testEnumDef]{
+		"Invalid": {data: []byte{0x0A, 0x01, 0x07}, wantErr: &StructuralError{}},
+	})
+
+	var v testEnumDef
+	if err := Unmarshal([]byte{0x0A, 0x01, 0x07}, &v); err == nil || !strings.Contains(err.Error(), "invalid value 7 for testEnumDef") {
+		t.Errorf("Unmarshal() error = %v, want invalid value 7 for testEnumDef", err)
+	}
+}
+
+func TestEnumStringCodec(t *testing.T) {
+	type entry struct {
+		Result string `asn1:"enum:ber.testEnumDef"`
+	}
+	testCodec(t, map[string]testCase[entry]{
+		"Valid": {val: entry{"two"}, data: []byte{0x30, 0x03, 0x0A, 0x01, 0x02}},
+	}, map[string]testCase[entry]{
+		"InvalidName": {val: entry{"three"}, wantErr: &EncodeError{}},
+	}, map[string]testCase[entry]{
+		"InvalidValue": {data: []byte{0x30, 0x03, 0x0A, 0x01, 0x07}, wantErr: &StructuralError{}},
+		"Integer":      {data: []byte{0x30, 0x03, 0x02, 0x01, 0x02}, wantErr: &StructuralError{}},
+	})
+
+	type unknown struct {
+		Result string `asn1:"enum:ber.unknown"`
+	}
+	testCodec(t, nil, map[string]testCase[unknown]{
+		"Unknown": {val: unknown{"one"}, wantErr: &EncodeError{}},
+	}, map[string]testCase[unknown]{
+		"Unknown": {data: []byte{0x30, 0x03, 0x0A, 0x01, 0x01}, wantErr: &StructuralError{}},
+	})
+}
+
 //endregion
 
 //region [UNIVERSAL 12] UTF8String
@@ -407,6 +492,37 @@ func TestUTF8StringCodec(t *testing.T) {
 	})
 }
 
+func TestTextMarshalerCodec(t *testing.T) {
+	addr := netip.MustParseAddr("192.0.2.1")
+	testCodec(t, map[string]testCase[netip.Addr]{
+		// Marshal & Unmarshal
+		"Text":    {val: addr, params: "text", data: append([]byte{0x0C, 0x09}, []byte("192.0.2.1")...)},
+		"Default": {val: addr, data: []byte{0x04, 0x04, 0xC0, 0x00, 0x02, 0x01}},
+		"Tagged":  {val: addr, params: "text,tag:3", data: append([]byte{0x83, 0x09}, []byte("192.0.2.1")...)},
+	}, nil, map[string]testCase[netip.Addr]{
+		// Unmarshal
+		"TagMismatch": {data: append([]byte{0x04, 0x09}, []byte("192.0.2.1")...), params: "text", wantErr: &StructuralError{}},
+	})
+
+	type S struct {
+		A *netip.Addr `asn1:"text,optional,omitzero"`
+	}
+	b, err := Marshal(S{&addr})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got S
+	if err = Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.A == nil || *got.A != addr {
+		t.Errorf("Unmarshal() A = %v, want %v", got.A, addr)
+	}
+	if err = Unmarshal([]byte{0x30, 0x05, 0x0C, 0x03, 'f', 'o', 'o'}, &got); err == nil {
+		t.Errorf("Unmarshal() error = nil, want error for invalid address")
+	}
+}
+
 //endregion
 
 //region [UNIVERSAL 13] RELATIVE-OID
@@ -436,6 +552,50 @@ func TestTimeCodec(t *testing.T) {
 		"MixedFormat": {data: append([]byte{0x0E, 0x19}, []byte("20140312T13:31:42.2+05:00")...), wantErr: &SyntaxError{}},
 		"NoTime":      {data: append([]byte{0x0E, 0x0A}, []byte("2014-03-12")...), val: asn1.Time(time.Date(2014, 3, 12, 0, 0, 0, 0, time.Local))},
 		"Invalid":     {data: append([]byte{0x0E, 0x0A}, []byte("2014-AB-CD")...), wantErr: &SyntaxError{}},
+		"Ordinal":     {data: append([]byte{0x0E, 0x08}, []byte("2014-071")...), val: asn1.Time(time.Date(2014, 3, 12, 0, 0, 0, 0, time.Local))},
+		"OrdinalTime": {data: append([]byte{0x0E, 0x0D}, []byte("2014071T1331Z")...), val: asn1.Time(time.Date(2014, 3, 12, 13, 31, 0, 0, time.UTC))},
+		"OrdinalLeap": {data: append([]byte{0x0E, 0x08}, []byte("2016-366")...), val: asn1.Time(time.Date(2016, 12, 31, 0, 0, 0, 0, time.Local))},
+		"OrdinalOver": {data: append([]byte{0x0E, 0x08}, []byte("2014-366")...), wantErr: &SyntaxError{}},
+		"Week":        {data: append([]byte{0x0E, 0x0A}, []byte("2014-W11-3")...), val: asn1.Time(time.Date(2014, 3, 12, 0, 0, 0, 0, time.Local))},
+		"WeekBasic":   {data: append([]byte{0x0E, 0x08}, []byte("2015W011")...), val: asn1.Time(time.Date(2014, 12, 29, 0, 0, 0, 0, time.Local))},
+		"WeekTime":    {data: append([]byte{0x0E, 0x19}, []byte("2014-W11-3T13:31:42+01:00")...), val: asn1.Time(time.Date(2014, 3, 12, 13, 31, 42, 0, time.FixedZone("", 3600)))},
+		"WeekOver":    {data: append([]byte{0x0E, 0x0A}, []byte("2014-W53-1")...), wantErr: &SyntaxError{}},
+		"WeekDay":     {data: append([]byte{0x0E, 0x0A}, []byte("2014-W11-8")...), wantErr: &SyntaxError{}},
+		"MonthZero":   {data: append([]byte{0x0E, 0x0A}, []byte("2014-00-10")...), wantErr: &SyntaxError{}},
+	})
+}
+
+func TestTimeIntervalCodec(t *testing.T) {
+	start := time.Date(2014, 3, 12, 13, 0, 0, 0, time.UTC)
+	end := time.Date(2014, 3, 14, 12, 0, 0, 0, time.UTC)
+	testCodec(t, map[string]testCase[asn1.TimeInterval]{
+		// Marshal & Unmarshal
+		"StartEnd":      {val: asn1.TimeInterval{Start: start, End: end}, data: append([]byte{0x0E, 0x29}, []byte("2014-03-12T13:00:00Z/2014-03-14T12:00:00Z")...)},
+		"StartDuration": {val: asn1.TimeInterval{Start: start, Duration: asn1.ISODuration{Days: 1}}, data: append([]byte{0x0E, 0x18}, []byte("2014-03-12T13:00:00Z/P1D")...)},
+		"DurationEnd":   {val: asn1.TimeInterval{Duration: asn1.ISODuration{Hours: 2}, End: end}, data: append([]byte{0x0E, 0x19}, []byte("PT2H/2014-03-14T12:00:00Z")...)},
+		"Duration":      {val: asn1.TimeInterval{Duration: asn1.ISODuration{Months: 1}}, data: append([]byte{0x0E, 0x03}, []byte("P1M")...)},
+	}, map[string]testCase[asn1.TimeInterval]{
+		// Marshal
+		"Empty":        {val: asn1.TimeInterval{}, wantErr: &EncodeError{}},
+		"TooManyParts": {val: asn1.TimeInterval{Start: start, End: end, Duration: asn1.ISODuration{Days: 1}}, wantErr: &EncodeError{}},
+	}, map[string]testCase[asn1.TimeInterval]{
+		// Unmarshal
+		"Instant":      {data: append([]byte{0x0E, 0x14}, []byte("2014-03-12T13:00:00Z")...), wantErr: &SyntaxError{}},
+		"TwoDurations": {data: append([]byte{0x0E, 0x07}, []byte("P1D/P2D")...), wantErr: &SyntaxError{}},
+	})
+}
+
+func TestRecurringIntervalCodec(t *testing.T) {
+	start := time.Date(2014, 3, 12, 13, 0, 0, 0, time.UTC)
+	daily := asn1.TimeInterval{Start: start, Duration: asn1.ISODuration{Days: 1}}
+	testCodec(t, map[string]testCase[asn1.RecurringInterval]{
+		// Marshal & Unmarshal
+		"Bounded":   {val: asn1.RecurringInterval{Recurrences: 5, Interval: daily}, data: append([]byte{0x0E, 0x1B}, []byte("R5/2014-03-12T13:00:00Z/P1D")...)},
+		"Unbounded": {val: asn1.RecurringInterval{Recurrences: -1, Interval: daily}, data: append([]byte{0x0E, 0x1A}, []byte("R/2014-03-12T13:00:00Z/P1D")...)},
+	}, nil, map[string]testCase[asn1.RecurringInterval]{
+		// Unmarshal
+		"NoRecurrence": {data: append([]byte{0x0E, 0x18}, []byte("2014-03-12T13:00:00Z/P1D")...), wantErr: &SyntaxError{}},
+		"Negative":     {data: append([]byte{0x0E, 0x1C}, []byte("R-1/2014-03-12T13:00:00Z/P1D")...), wantErr: &SyntaxError{}},
 	})
 }
 
@@ -449,14 +609,58 @@ func TestSetCodec(t *testing.T) {
 		"Empty": {val: asn1.NewSet[int](), data: []byte{0x31, 0x00}},
 		"Single": {val: asn1.NewSet(2), data: []byte{0x31, 0x03,
 			0x02, 0x01, 0x02}},
-	}, map[string]testCase[asn1.Set[int]]{
-		// Marshal
-		"Nil": {val: nil, data: []byte{0x31, 0x00}},
-	}, map[string]testCase[asn1.Set[int]]{
 		"Multi": {val: asn1.NewSet(2, 4), data: []byte{0x31, 0x06,
 			0x02, 0x01, 0x02,
 			0x02, 0x01, 0x04}},
+		"Sorted": {val: asn1.NewSet(256, 3, 1), data: []byte{0x31, 0x0A,
+			0x02, 0x01, 0x01,
+			0x02, 0x01, 0x03,
+			0x02, 0x02, 0x01, 0x00}},
+	}, map[string]testCase[asn1.Set[int]]{
+		// Marshal
+		"Nil": {val: nil, data: []byte{0x31, 0x00}},
+	}, nil)
+}
+
+func TestSetOfCodec(t *testing.T) {
+	oid1, oid2 := asn1.ObjectIdentifier{2, 5, 4, 3}, asn1.ObjectIdentifier{2, 5, 4, 10}
+	testCodec(t, map[string]testCase[asn1.SetOf[asn1.ObjectIdentifier]]{
+		// Marshal & Unmarshal
+		"Empty": {val: asn1.SetOf[asn1.ObjectIdentifier]{}, data: []byte{0x31, 0x00}},
+		"Multi": {val: asn1.NewSetOf(nil, oid1, oid2), data: []byte{0x31, 0x0A,
+			0x06, 0x03, 0x55, 0x04, 0x03,
+			0x06, 0x03, 0x55, 0x04, 0x0A}},
+	}, map[string]testCase[asn1.SetOf[asn1.ObjectIdentifier]]{
+		// Marshal
+		"Sorted": {val: asn1.NewSetOf(nil, oid2, oid1, oid2), data: []byte{0x31, 0x0A,
+			0x06, 0x03, 0x55, 0x04, 0x03,
+			0x06, 0x03, 0x55, 0x04, 0x0A}},
+	}, map[string]testCase[asn1.SetOf[asn1.ObjectIdentifier]]{
+		// Unmarshal
+		"Duplicates": {val: asn1.NewSetOf(nil, oid1), data: []byte{0x31, 0x0A,
+			0x06, 0x03, 0x55, 0x04, 0x03,
+			0x06, 0x03, 0x55, 0x04, 0x03}},
+		"Mismatch": {data: []byte{0x30, 0x00}, wantErr: &StructuralError{}},
 	})
+
+	type rdn struct {
+		Attrs asn1.SetOf[asn1.BitString]
+	}
+	val := rdn{asn1.NewSetOf(asn1.BitString.Equal, asn1.BitString{Bytes: []byte{0x80}, BitLength: 1})}
+	data, err := Marshal(val)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := []byte{0x30, 0x06, 0x31, 0x04, 0x03, 0x02, 0x07, 0x80}; !bytes.Equal(data, want) {
+		t.Errorf("Marshal() = % X, want % X", data, want)
+	}
+	var got rdn
+	if err = Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.Attrs.Len() != 1 || !got.Attrs.Contains(asn1.BitString{Bytes: []byte{0x80}, BitLength: 1}) {
+		t.Errorf("Unmarshal() = %v, want %v", got.Attrs.Values(), val.Attrs.Values())
+	}
 }
 
 //endregion
@@ -691,6 +895,153 @@ func TestDurationCodec(t *testing.T) {
 	})
 }
 
+func TestISODurationCodec(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.ISODuration]{
+		// Marshal & Unmarshal
+		"Zero":     {val: asn1.ISODuration{}, data: append([]byte{0x1F, 0x22, 0x04}, []byte("PT0S")...)},
+		"Months":   {val: asn1.ISODuration{Months: 3}, data: append([]byte{0x1F, 0x22, 0x03}, []byte("P3M")...)},
+		"DateTime": {val: asn1.ISODuration{Days: 1, Hours: 2}, data: append([]byte{0x1F, 0x22, 0x06}, []byte("P1DT2H")...)},
+		"Negative": {val: asn1.ISODuration{Negative: true, Weeks: 2}, data: append([]byte{0x1F, 0x22, 0x04}, []byte("-P2W")...)},
+		"Fraction": {val: asn1.ISODuration{Minutes: 1, Seconds: 2, Nanoseconds: 250000000}, data: append([]byte{0x1F, 0x22, 0x09}, []byte("PT1M2.25S")...)},
+	}, map[string]testCase[asn1.ISODuration]{
+		// Marshal
+		"Invalid": {val: asn1.ISODuration{Days: -1}, wantErr: &EncodeError{}},
+	}, map[string]testCase[asn1.ISODuration]{
+		// Unmarshal
+		"FractionalHour":  {data: append([]byte{0x1F, 0x22, 0x06}, []byte("PT1.5H")...), val: asn1.ISODuration{Hours: 1, Minutes: 30}},
+		"FractionalDay":   {data: append([]byte{0x1F, 0x22, 0x04}, []byte("P1.5D")...), wantErr: &SyntaxError{}},
+		"FractionNotLast": {data: append([]byte{0x1F, 0x22, 0x07}, []byte("PT1.5H2M")...), wantErr: &SyntaxError{}},
+		"WrongOrder":      {data: append([]byte{0x1F, 0x22, 0x04}, []byte("P1D2Y")...), wantErr: &SyntaxError{}},
+		"EmptyTime":       {data: append([]byte{0x1F, 0x22, 0x04}, []byte("P1DT")...), wantErr: &SyntaxError{}},
+		"Empty":           {data: append([]byte{0x1F, 0x22, 0x01}, []byte("P")...), wantErr: &SyntaxError{}},
+	})
+}
+
+//endregion
+
+//region type time.Time
+
+func TestGoTimeCodec(t *testing.T) {
+	tm := time.Date(2014, 3, 12, 13, 31, 42, 0, time.UTC)
+	testCodec(t, map[string]testCase[time.Time]{
+		// Marshal & Unmarshal
+		"Default":         {val: tm, data: append([]byte{0x17, 0x0D}, []byte("140312133142Z")...)},
+		"DefaultLate":     {val: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC), data: append([]byte{0x18, 0x0F}, []byte("20500101000000Z")...)},
+		"DefaultEarly":    {val: time.Date(1949, 12, 31, 23, 59, 59, 0, time.UTC), data: append([]byte{0x18, 0x0F}, []byte("19491231235959Z")...)},
+		"Time":            {val: tm, params: "universal,tag:14", data: append([]byte{0x0E, 0x14}, []byte("2014-03-12T13:31:42Z")...)},
+		"UTCTime":         {val: tm, params: "universal,tag:23", data: append([]byte{0x17, 0x0D}, []byte("140312133142Z")...)},
+		"GeneralizedTime": {val: tm, params: "universal,tag:24", data: append([]byte{0x18, 0x0F}, []byte("20140312133142Z")...)},
+		"Date":            {val: time.Date(2014, 3, 12, 0, 0, 0, 0, time.Local), params: "universal,tag:31", data: append([]byte{0x1F, 0x1F, 0x0A}, []byte("2014-03-12")...)},
+		"Generalized":     {val: tm, params: "generalized", data: append([]byte{0x18, 0x0F}, []byte("20140312133142Z")...)},
+		"UTCTimeTag":      {val: tm, params: "utctime", data: append([]byte{0x17, 0x0D}, []byte("140312133142Z")...)},
+		"UTCTag":          {val: tm, params: "utc", data: append([]byte{0x17, 0x0D}, []byte("140312133142Z")...)},
+	}, map[string]testCase[time.Time]{
+		// Marshal
+		"UTC":           {val: time.Date(2014, 3, 12, 18, 31, 42, 500000000, time.FixedZone("", 5*3600)), params: "universal,tag:24,toutc,precision:0", data: append([]byte{0x18, 0x0F}, []byte("20140312133142Z")...)},
+		"Precision":     {val: time.Date(2014, 3, 12, 13, 31, 42, 123456789, time.UTC), params: "universal,tag:24,precision:3", data: append([]byte{0x18, 0x13}, []byte("20140312133142.123Z")...)},
+		"TrailingZeros": {val: time.Date(2014, 3, 12, 13, 31, 42, 500000000, time.UTC), params: "universal,tag:24,precision:3", data: append([]byte{0x18, 0x11}, []byte("20140312133142.5Z")...)},
+		"LocalUTC":      {val: time.Date(2014, 3, 12, 13, 31, 42, 0, time.Local), params: "universal,tag:23,toutc", data: append([]byte{0x17, 0x0D}, []byte(time.Date(2014, 3, 12, 13, 31, 42, 0, time.Local).UTC().Format("060102150405Z"))...)},
+		"DefaultZone":   {val: time.Date(2014, 3, 12, 18, 31, 42, 0, time.FixedZone("", 5*3600)), data: append([]byte{0x17, 0x0D}, []byte("140312133142Z")...)},
+		"UTCLate":       {val: time.Date(2060, 3, 12, 14, 31, 42, 0, time.FixedZone("", 3600)), params: "toutc", data: append([]byte{0x18, 0x0F}, []byte("20600312133142Z")...)},
+		"UTCTimeLate":   {val: time.Date(2054, 3, 12, 13, 31, 42, 0, time.UTC), params: "utc", wantErr: &EncodeError{}},
+	}, map[string]testCase[time.Time]{
+		// Unmarshal
+		"DefaultFromGeneralizedTime": {val: tm, data: append([]byte{0x18, 0x0F}, []byte("20140312133142Z")...)},
+		"DefaultFromTime":            {val: tm, data: append([]byte{0x0E, 0x14}, []byte("2014-03-12T13:31:42Z")...)},
+		"DefaultMismatch":            {data: []byte{0x02, 0x01, 0x01}, wantErr: &StructuralError{}},
+	})
+}
+
+func TestGoTimePointer(t *testing.T) {
+	type S struct {
+		A *time.Time `asn1:"universal,tag:23"`
+		B *time.Time `asn1:"universal,tag:24,optional,omitzero"`
+	}
+	tm := time.Date(2014, 3, 12, 13, 31, 42, 0, time.UTC)
+	tests := map[string]struct {
+		val  S
+		data []byte
+	}{
+		"Present": {S{&tm, &tm}, slices.Concat([]byte{0x30, 0x20, 0x17, 0x0D}, []byte("140312133142Z"), []byte{0x18, 0x0F}, []byte("20140312133142Z"))},
+		"Absent":  {S{&tm, nil}, slices.Concat([]byte{0x30, 0x0F, 0x17, 0x0D}, []byte("140312133142Z"))},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Marshal(tc.val)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, tc.data) {
+				t.Errorf("Marshal() = %x, want %x", got, tc.data)
+			}
+			var s S
+			if err = Unmarshal(tc.data, &s); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if s.A == nil || !s.A.Equal(*tc.val.A) {
+				t.Errorf("Unmarshal() A = %v, want %v", s.A, tc.val.A)
+			}
+			if (s.B == nil) != (tc.val.B == nil) || s.B != nil && !s.B.Equal(*tc.val.B) {
+				t.Errorf("Unmarshal() B = %v, want %v", s.B, tc.val.B)
+			}
+		})
+	}
+}
+
+//endregion
+
+//region IP addresses
+
+func TestIPAddrCodec(t *testing.T) {
+	testCodec(t, map[string]testCase[netip.Addr]{
+		// Marshal & Unmarshal
+		"IPv4": {val: netip.MustParseAddr("192.0.2.1"), data: []byte{0x04, 0x04, 0xC0, 0x00, 0x02, 0x01}},
+		"IPv6": {val: netip.MustParseAddr("2001:db8::1"), data: []byte{0x04, 0x10,
+			0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}},
+	}, map[string]testCase[netip.Addr]{
+		// Marshal
+		"Zero": {val: netip.Addr{}, wantErr: &EncodeError{}},
+		"Zone": {val: netip.MustParseAddr("fe80::1%eth0"), wantErr: &EncodeError{}},
+	}, map[string]testCase[netip.Addr]{
+		// Unmarshal
+		"InvalidLength": {data: []byte{0x04, 0x03, 0xC0, 0x00, 0x02}, wantErr: &StructuralError{}},
+	})
+}
+
+func TestIPPrefixCodec(t *testing.T) {
+	testCodec(t, map[string]testCase[netip.Prefix]{
+		// Marshal & Unmarshal
+		"IPv4": {val: netip.MustParsePrefix("192.0.2.0/24"), data: []byte{0x04, 0x08,
+			0xC0, 0x00, 0x02, 0x00, 0xFF, 0xFF, 0xFF, 0x00}},
+		"IPv6": {val: netip.MustParsePrefix("2001:db8::/32"), data: []byte{0x04, 0x20,
+			0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	}, map[string]testCase[netip.Prefix]{
+		// Marshal
+		"Zero": {val: netip.Prefix{}, wantErr: &EncodeError{}},
+	}, map[string]testCase[netip.Prefix]{
+		// Unmarshal
+		"InvalidLength": {data: []byte{0x04, 0x05, 0xC0, 0x00, 0x02, 0x00, 0xFF}, wantErr: &StructuralError{}},
+		"InvalidMask":   {data: []byte{0x04, 0x08, 0xC0, 0x00, 0x02, 0x00, 0xFF, 0x00, 0xFF, 0x00}, wantErr: &StructuralError{}},
+	})
+}
+
+func TestIPCodec(t *testing.T) {
+	testCodec(t, map[string]testCase[net.IP]{
+		// Marshal & Unmarshal
+		"IPv4": {val: net.IP{192, 0, 2, 1}, data: []byte{0x04, 0x04, 0xC0, 0x00, 0x02, 0x01}},
+		"IPv6": {val: net.ParseIP("2001:db8::1"), data: []byte{0x04, 0x10,
+			0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}},
+	}, map[string]testCase[net.IP]{
+		// Marshal
+		"IPv4In16": {val: net.ParseIP("192.0.2.1"), data: []byte{0x04, 0x04, 0xC0, 0x00, 0x02, 0x01}},
+		"Invalid":  {val: net.IP{1, 2, 3}, wantErr: &EncodeError{}},
+	}, map[string]testCase[net.IP]{
+		// Unmarshal
+		"InvalidLength": {data: []byte{0x04, 0x03, 0xC0, 0x00, 0x02}, wantErr: &StructuralError{}},
+	})
+}
+
 //endregion
 
 //region type Flag
@@ -733,4 +1084,147 @@ func TestRawValue(t *testing.T) {
 	})
 }
 
+func TestRawValue_Reencode(t *testing.T) {
+	tests := map[string]struct {
+		data []byte
+		want []byte
+	}{
+		"NonMinimalLength": {[]byte{0x04, 0x82, 0x00, 0x02, 0x01, 0x02}, []byte{0x04, 0x02, 0x01, 0x02}},
+		"Indefinite":       {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00}, []byte{0x30, 0x03, 0x02, 0x01, 0x05}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var rv RawValue
+			if err := Unmarshal(tt.data, &rv); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			got, err := Marshal(rv)
+			if err != nil || !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal() = % X, %v, want % X", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawValue_Copies(t *testing.T) {
+	tests := map[string][]byte{
+		"Primitive":   {0x04, 0x02, 0x01, 0x02},
+		"Constructed": {0x30, 0x03, 0x02, 0x01, 0x05},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var rv RawValue
+			if err := Unmarshal(data, &rv); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			want := bytes.Clone(rv.Bytes)
+			clear(data)
+			if !bytes.Equal(rv.Bytes, want) {
+				t.Errorf("RawValue.Bytes = % X after modifying the input, want % X", rv.Bytes, want)
+			}
+		})
+	}
+}
+
+func TestRawElement_Fidelity(t *testing.T) {
+	tests := map[string]struct {
+		data  []byte
+		bytes []byte // expected content octets
+	}{
+		"NonMinimalLength": {[]byte{0x04, 0x82, 0x00, 0x02, 0x01, 0x02}, []byte{0x01, 0x02}},
+		"Indefinite":       {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00}, []byte{0x02, 0x01, 0x05}},
+		"NestedIndefinite": {[]byte{0x30, 0x80, 0x24, 0x80, 0x04, 0x01, 0x05, 0x00, 0x00, 0x00, 0x00}, []byte{0x24, 0x80, 0x04, 0x01, 0x05, 0x00, 0x00}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			decoders := map[string]func(*RawElement) error{
+				"Bytes": func(rv *RawElement) error { return Unmarshal(tc.data, rv) },
+				"Stream": func(rv *RawElement) error {
+					return NewDecoder(iotest.OneByteReader(bytes.NewReader(tc.data))).Decode(rv)
+				},
+			}
+			for mode, decode := range decoders {
+				var rv RawElement
+				if err := decode(&rv); err != nil {
+					t.Fatalf("%s: decode error = %v", mode, err)
+				}
+				if !bytes.Equal(rv.Bytes, tc.bytes) {
+					t.Errorf("%s: RawElement.Bytes = % X, want % X", mode, rv.Bytes, tc.bytes)
+				}
+				got, err := Marshal(rv)
+				if err != nil {
+					t.Fatalf("%s: Marshal() error = %v", mode, err)
+				}
+				if !bytes.Equal(got, tc.data) {
+					t.Errorf("%s: Marshal() = % X, want % X", mode, got, tc.data)
+				}
+			}
+		})
+	}
+
+	t.Run("Modified", func(t *testing.T) {
+		var rv RawElement
+		if err := Unmarshal([]byte{0x04, 0x82, 0x00, 0x02, 0x01, 0x02}, &rv); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		rv.Bytes = []byte{0x03}
+		got, err := Marshal(rv)
+		if want := []byte{0x04, 0x01, 0x03}; err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Marshal() = % X, %v, want % X", got, err, want)
+		}
+	})
+
+	t.Run("Retagged", func(t *testing.T) {
+		var rv RawElement
+		if err := Unmarshal([]byte{0x04, 0x82, 0x00, 0x02, 0x01, 0x02}, &rv); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		got, err := MarshalWithParams(rv, "tag:1")
+		if want := []byte{0x81, 0x02, 0x01, 0x02}; err != nil || !bytes.Equal(got, want) {
+			t.Errorf("MarshalWithParams() = % X, %v, want % X", got, err, want)
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		data := []byte{0x30, 0x09, 0x02, 0x01, 0x05, 0x04, 0x84, 0x00, 0x00, 0x00, 0x00}
+		var val struct {
+			A int
+			B RawElement
+		}
+		if err := Unmarshal(data, &val); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		got, err := Marshal(val)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Marshal() = % X, %v, want % X", got, err, data)
+		}
+	})
+}
+
+func TestRawElement_References(t *testing.T) {
+	data := []byte{0x30, 0x09, 0x02, 0x01, 0x05, 0x64, 0x04, 0x04, 0x02, 0x01, 0x02}
+	var val struct {
+		A int
+		B RawElement
+	}
+	if err := Unmarshal(data, &val); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !bytes.Equal(val.B.FullBytes, data[5:]) || &val.B.FullBytes[0] != &data[5] {
+		t.Errorf("RawElement.FullBytes = % X, want reference to % X", val.B.FullBytes, data[5:])
+	}
+	if !bytes.Equal(val.B.Bytes, data[7:]) || &val.B.Bytes[0] != &data[7] {
+		t.Errorf("RawElement.Bytes = % X, want reference to % X", val.B.Bytes, data[7:])
+	}
+
+	// decoding from a stream copies the encoding
+	var rv RawElement
+	if err := NewDecoder(iotest.HalfReader(bytes.NewReader(data))).Decode(&rv); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !bytes.Equal(rv.FullBytes, data) || !bytes.Equal(rv.Bytes, data[2:]) {
+		t.Errorf("Decode() = %v, want FullBytes = % X", rv, data)
+	}
+}
+
 //endregion
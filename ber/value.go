@@ -0,0 +1,185 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"slices"
+
+	"codello.dev/asn1"
+)
+
+// Value is a generic representation of a data value. A Value holds either the
+// content octets of a primitive encoding or the components of a constructed
+// encoding. Values can be decoded from and encoded into any BER encoding
+// without knowledge of the ASN.1 schema. This makes it possible to inspect and
+// modify encodings without defining Go types for them:
+//
+//	var v ber.Value
+//	err := ber.Unmarshal(b, &v)
+//	v.At(0, 2).Prim = []byte{0x05}
+//	b, err = ber.Marshal(v)
+//
+// When decoding, Prim is always a copy of the input. When encoding, the
+// definite-length encoding is used unless the [Encoder] is configured
+// otherwise. Consequently, decoding and re-encoding a Value does not
+// necessarily reproduce the original bytes. Use [RawValue] if the exact
+// encoding must be retained.
+//
+// Decoding into a Value matches any tag.
+type Value struct {
+	Tag         asn1.Tag
+	Constructed bool
+
+	// Prim contains the content octets of a primitive encoding. It is ignored if
+	// Constructed is true.
+	Prim []byte
+
+	// Children contains the components of a constructed encoding. It is ignored
+	// if Constructed is false.
+	Children []Value
+}
+
+// ValueOf returns the generic representation of the BER encoding of val. It is
+// equivalent to marshalling val and unmarshalling the result into a Value.
+func ValueOf(val any) (Value, error) {
+	var v Value
+	b, err := Marshal(val)
+	if err == nil {
+		err = Unmarshal(b, &v)
+	}
+	return v, err
+}
+
+// Decode decodes v into val. It is equivalent to marshalling v and
+// unmarshalling the result into val.
+func (v Value) Decode(val any) error {
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(b, val)
+}
+
+// At returns the descendant of v identified by path. Each element of path is
+// the index of a component in the Children of the previous value. If path is
+// empty, v is returned. If any index is out of range or refers to a component
+// of a primitive value, At returns nil. The returned pointer can be used to
+// modify the descendant in place.
+func (v *Value) At(path ...int) *Value {
+	for _, i := range path {
+		if !v.Constructed || i < 0 || i >= len(v.Children) {
+			return nil
+		}
+		v = &v.Children[i]
+	}
+	return v
+}
+
+// All returns an iterator over v and all of its descendants in depth-first
+// pre-order. Each value is yielded together with its path as accepted by
+// [Value.At]. The path slice is reused during iteration and must not be
+// retained. The yielded values may be modified, but their Children must not be
+// resized during iteration.
+func (v *Value) All() iter.Seq2[[]int, *Value] {
+	return func(yield func([]int, *Value) bool) {
+		v.all(nil, yield)
+	}
+}
+
+// all implements [Value.All]. It reports whether the iteration should
+// continue.
+func (v *Value) all(path []int, yield func([]int, *Value) bool) bool {
+	if !yield(path, v) {
+		return false
+	}
+	if !v.Constructed {
+		return true
+	}
+	for i := range v.Children {
+		if !v.Children[i].all(append(path, i), yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Insert inserts the components cs at index i of the Children of v, moving
+// subsequent components back. Insert panics if v is not constructed or if i is
+// out of range.
+func (v *Value) Insert(i int, cs ...Value) {
+	if !v.Constructed {
+		panic("ber: Value.Insert on primitive value")
+	}
+	if i < 0 || i > len(v.Children) {
+		panic("ber: Value.Insert index out of range")
+	}
+	v.Children = slices.Insert(v.Children, i, cs...)
+}
+
+// Remove removes the component at index i from the Children of v, moving
+// subsequent components forward. Remove panics if v is not constructed or if
+// i is out of range.
+func (v *Value) Remove(i int) {
+	if !v.Constructed {
+		panic("ber: Value.Remove on primitive value")
+	}
+	if i < 0 || i >= len(v.Children) {
+		panic("ber: Value.Remove index out of range")
+	}
+	v.Children = slices.Delete(v.Children, i, i+1)
+}
+
+// BerEncode encodes v using the primitive or constructed encoding as indicated
+// by v.Constructed.
+func (v Value) BerEncode() (Header, io.WriterTo, error) {
+	if !v.Constructed {
+		return Header{v.Tag, len(v.Prim), false}, bytes.NewReader(v.Prim), nil
+	}
+	s := &Sequence{Tag: v.Tag}
+	for _, c := range v.Children {
+		if err := s.Append(c); err != nil {
+			return Header{}, nil, err
+		}
+	}
+	return s.BerEncode()
+}
+
+// BerMatch reports true for every tag.
+func (v *Value) BerMatch(asn1.Tag) bool {
+	return true
+}
+
+// BerDecode decodes the data value read from r into v, including all of its
+// components.
+func (v *Value) BerDecode(tag asn1.Tag, r Reader) error {
+	*v = Value{Tag: tag, Constructed: r.Constructed()}
+	if !v.Constructed {
+		v.Prim = make([]byte, r.Len())
+		_, err := io.ReadFull(r, v.Prim)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	for {
+		h, er, err := r.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		var c Value
+		if err = c.BerDecode(h.Tag, er); err == nil {
+			err = er.Close()
+		}
+		if err != nil {
+			return err
+		}
+		v.Children = append(v.Children, c)
+	}
+}
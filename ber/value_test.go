@@ -0,0 +1,94 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+
+	"codello.dev/asn1"
+)
+
+func TestValue(t *testing.T) {
+	type record struct {
+		A int
+		B string
+		C []bool
+	}
+	tests := map[string]struct {
+		val  any
+		want Value
+	}{
+		"Primitive": {5, Value{Tag: asn1.TagInteger, Prim: []byte{0x05}}},
+		"Constructed": {record{1, "x", []bool{true}}, Value{Tag: asn1.TagSequence, Constructed: true, Children: []Value{
+			{Tag: asn1.TagInteger, Prim: []byte{0x01}},
+			{Tag: asn1.TagUTF8String, Prim: []byte("x")},
+			{Tag: asn1.TagSequence, Constructed: true, Children: []Value{{Tag: asn1.TagBoolean, Prim: []byte{0xFF}}}},
+		}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			want, err := Marshal(tt.val)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var v Value
+			if err = Unmarshal(want, &v); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got := diffValues("", reflect.ValueOf(tt.want), reflect.ValueOf(v), nil); len(got) > 0 {
+				t.Errorf("Unmarshal() diffs = %q", got)
+			}
+			got, err := Marshal(v)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Marshal() = % X, want % X", got, want)
+			}
+		})
+	}
+}
+
+func TestValue_Modify(t *testing.T) {
+	type record struct {
+		A int
+		B string
+		C []int
+	}
+	v, err := ValueOf(record{1, "x", []int{2, 3}})
+	if err != nil {
+		t.Fatalf("ValueOf() error = %v", err)
+	}
+	if v.At(0, 0) != nil || v.At(3) != nil {
+		t.Errorf("At() returned a value for an invalid path")
+	}
+	v.At(2, 1).Prim = []byte{0x04}
+	n, _ := ValueOf(5)
+	v.At(2).Insert(0, n)
+	v.At(2).Remove(2)
+
+	var got record
+	if err = v.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if want := (record{1, "x", []int{5, 2}}); got.A != want.A || got.B != want.B || !slices.Equal(got.C, want.C) {
+		t.Errorf("Decode() = %v, want %v", got, want)
+	}
+
+	var paths [][]int
+	for path, c := range v.All() {
+		if v.At(path...) != c {
+			t.Errorf("All() yielded %v for path %v", c, path)
+		}
+		paths = append(paths, slices.Clone(path))
+	}
+	want := [][]int{{}, {0}, {1}, {2}, {2, 0}, {2, 1}}
+	if !slices.EqualFunc(paths, want, slices.Equal) {
+		t.Errorf("All() paths = %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"codello.dev/asn1/internal"
+)
+
+// VerifyError is returned by an [Encoder] with [EncoderOptions.Verify] if the
+// encoding of a value does not decode to an equal value. This usually indicates
+// an asymmetry between the [BerEncoder] and [BerDecoder] implementations of a
+// type.
+type VerifyError struct {
+	Value reflect.Value // the value that was encoded
+
+	// Diffs describes the differences between the encoded and the decoded value.
+	// Each entry has the form "path: encoded X, decoded Y", where path is the
+	// path of struct fields and indices to the differing value (maybe empty).
+	Diffs []string
+
+	// Err is the error that occurred while decoding the encoding, if any. If Err
+	// is set, Diffs is empty.
+	Err error
+}
+
+func (e *VerifyError) Error() string {
+	var s strings.Builder
+	s.WriteString("verify error")
+	if e.Value.IsValid() {
+		s.WriteString(" for ")
+		s.WriteString(e.Value.Type().String())
+	}
+	if e.Err != nil {
+		s.WriteString(": decoding failed: ")
+		s.WriteString(e.Err.Error())
+		return s.String()
+	}
+	s.WriteString(": encoding does not round-trip")
+	for _, d := range e.Diffs {
+		s.WriteString("\n\t")
+		s.WriteString(d)
+	}
+	return s.String()
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// verifyEncoding decodes b into a new value of the type of v using params and
+// compares the result to v. If the values differ, a [VerifyError] is returned.
+func verifyEncoding(v reflect.Value, b []byte, params internal.FieldParameters) error {
+	got := reflect.New(v.Type())
+	r := bytes.NewReader(b)
+	d := NewDecoder(r)
+	d.r.(*reader).src = &source{b, r}
+	h, er, err := d.Next()
+	if err == nil {
+		if err = decodeValue(h.Tag, er, got.Elem(), params); err == nil {
+			err = er.Close()
+		}
+	}
+	if err != nil {
+		return &VerifyError{Value: v, Err: err}
+	}
+	if diffs := diffValues("", v, got.Elem(), nil); len(diffs) > 0 {
+		return &VerifyError{Value: v, Diffs: diffs}
+	}
+	return nil
+}
+
+// diffValues appends a description of the differences between want and got to
+// diffs and returns the extended slice. Values are compared field by field.
+// Types with an Equal or Cmp method are compared using that method. Nil and
+// empty slices are considered equal, as are struct fields that are not
+// encoded.
+func diffValues(path string, want, got reflect.Value, diffs []string) []string {
+	diff := func() []string {
+		return append(diffs, fmt.Sprintf("%s: encoded %s, decoded %s", path, formatValue(want), formatValue(got)))
+	}
+	if !want.IsValid() || !got.IsValid() {
+		if want.IsValid() != got.IsValid() {
+			return diff()
+		}
+		return diffs
+	}
+	if want.Type() != got.Type() {
+		return diff()
+	}
+	if eq, ok := equalMethod(want, got); ok {
+		if !eq {
+			return diff()
+		}
+		return diffs
+	}
+	switch want.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				return diff()
+			}
+			return diffs
+		}
+		return diffValues(path, want.Elem(), got.Elem(), diffs)
+	case reflect.Struct:
+		t := want.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() || f.Type == internal.PresenceType || internal.ParseFieldParameters(f.Tag.Get("asn1")).Ignore {
+				continue
+			}
+			diffs = diffValues(fieldPath(path, f.Name), want.Field(i), got.Field(i), diffs)
+		}
+		return diffs
+	case reflect.Slice:
+		if want.Len() == 0 && got.Len() == 0 {
+			return diffs
+		}
+		fallthrough
+	case reflect.Array:
+		if want.Len() != got.Len() {
+			return diff()
+		}
+		for i := range want.Len() {
+			diffs = diffValues(path+"["+strconv.Itoa(i)+"]", want.Index(i), got.Index(i), diffs)
+		}
+		return diffs
+	case reflect.Map, reflect.Func, reflect.Chan:
+		if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+			return diff()
+		}
+		return diffs
+	default:
+		if !want.Equal(got) {
+			return diff()
+		}
+		return diffs
+	}
+}
+
+// fieldPath returns the path of the struct field name of the value at path.
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// equalMethod compares want and got using an Equal(T) bool or Cmp(T) int
+// method of their type T, such as the methods of [time.Time] or
+// [*math/big.Int]. Methods with a pointer receiver are called on copies of
+// want and got. If T has no such method, ok is false.
+func equalMethod(want, got reflect.Value) (eq, ok bool) {
+	switch want.Kind() {
+	case reflect.Interface:
+		return false, false
+	case reflect.Pointer:
+		if want.IsNil() || got.IsNil() {
+			return false, false
+		}
+		return callEqual(want, got)
+	}
+	if eq, ok = callEqual(want, got); ok {
+		return eq, ok
+	}
+	pt := reflect.PointerTo(want.Type())
+	_, hasEqual := pt.MethodByName("Equal")
+	_, hasCmp := pt.MethodByName("Cmp")
+	if !hasEqual && !hasCmp {
+		return false, false
+	}
+	w, g := reflect.New(want.Type()), reflect.New(want.Type())
+	w.Elem().Set(want)
+	g.Elem().Set(got)
+	if eq, ok = callEqual(w, g); ok {
+		return eq, ok
+	}
+	return callEqual(w, g.Elem())
+}
+
+// callEqual calls the Equal or Cmp method of recv with the argument arg.
+func callEqual(recv, arg reflect.Value) (eq, ok bool) {
+	if m := recv.MethodByName("Equal"); m.IsValid() && m.Type().NumIn() == 1 && m.Type().In(0) == arg.Type() &&
+		m.Type().NumOut() == 1 && m.Type().Out(0).Kind() == reflect.Bool {
+		return m.Call([]reflect.Value{arg})[0].Bool(), true
+	}
+	if m := recv.MethodByName("Cmp"); m.IsValid() && m.Type().NumIn() == 1 && m.Type().In(0) == arg.Type() &&
+		m.Type().NumOut() == 1 && m.Type().Out(0).Kind() == reflect.Int {
+		return m.Call([]reflect.Value{arg})[0].Int() == 0, true
+	}
+	return false, false
+}
+
+// formatValue returns a short representation of v for a difference reported
+// by diffValues.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	if !v.CanInterface() {
+		return v.Type().String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"errors"
+	"io"
+)
+
+// SkipValue can be returned by a [WalkFunc] to indicate that the components of
+// the current constructed data value should not be visited. It is not returned
+// as an error by [Walk].
+var SkipValue = errors.New("skip this value")
+
+// SkipAll can be returned by a [WalkFunc] to indicate that all remaining data
+// values should be skipped. It is not returned as an error by [Walk].
+var SkipAll = errors.New("skip everything and stop the walk")
+
+// WalkFunc is the type of the function called by [Walk] to visit each data
+// value.
+//
+// The path contains the headers of all data values enclosing the visited data
+// value, starting with a top-level data value. The last element of path is the
+// header of the visited data value itself. The depth is the nesting level of
+// the data value, with 0 being a top-level data value. It is always equal to
+// len(path)-1. The path slice is reused by Walk and must not be retained after
+// the function returns.
+//
+// If the data value uses the primitive encoding, the function may read its
+// content octets from value. If the data value is constructed, the function
+// must not call value.Next. Instead, the components are visited by Walk after
+// the function returns, unless it returns [SkipValue].
+//
+// If the function returns an error other than SkipValue or [SkipAll], Walk
+// stops and returns that error.
+type WalkFunc func(path []Header, depth int, value Reader) error
+
+// Walk reads BER-encoded data values from r and calls fn for each data value,
+// including all nested components of constructed encodings, in the order in
+// which they appear in the input. This enables generic processing of encodings
+// such as collecting statistics or searching for specific values without
+// knowledge of the ASN.1 schema.
+//
+// Walk reads until r returns io.EOF. The syntax of the entire input is
+// validated, including the content octets of data values that were skipped. If
+// the input is not a valid BER encoding, Walk returns an error after visiting
+// all data values preceding the error.
+func Walk(r io.Reader, fn WalkFunc) error {
+	d := NewDecoder(r)
+	var path []Header
+	for {
+		h, er, err := d.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if path, err = walk(path[:0], h, er, fn); err == SkipAll {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// walk visits the data value with header h read from r and all of its
+// components. The path contains the headers of the enclosing data values. The
+// returned slice is the path, possibly reallocated.
+func walk(path []Header, h Header, r Reader, fn WalkFunc) ([]Header, error) {
+	path = append(path, h)
+	err := fn(path, len(path)-1, r)
+	if err == nil && r.Constructed() {
+		for {
+			ch, er, cerr := r.Next()
+			if cerr == io.EOF {
+				break
+			} else if cerr != nil {
+				return path, cerr
+			}
+			if path, err = walk(path, ch, er, fn); err != nil {
+				return path, err
+			}
+		}
+	} else if err != nil && err != SkipValue {
+		return path, err
+	}
+	return path[:len(path)-1], r.Close()
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"codello.dev/asn1"
+)
+
+func TestWalk(t *testing.T) {
+	// SEQUENCE { INTEGER 5, SEQUENCE { BOOLEAN TRUE } } NULL
+	data := []byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x30, 0x03, 0x01, 0x01, 0xFF, 0x00, 0x00, 0x05, 0x00}
+	tests := map[string]struct {
+		skip    asn1.Tag // tag for which SkipValue is returned
+		stop    asn1.Tag // tag for which SkipAll is returned
+		want    []string
+		wantErr bool
+	}{
+		"All": {want: []string{"0:[UNIVERSAL 16]", "1:[UNIVERSAL 2]=05", "1:[UNIVERSAL 16]", "2:[UNIVERSAL 1]=FF", "0:[UNIVERSAL 5]="}},
+		"SkipValue": {skip: asn1.TagSequence,
+			want: []string{"0:[UNIVERSAL 16]", "0:[UNIVERSAL 5]="}},
+		"SkipAll": {stop: asn1.TagBoolean,
+			want: []string{"0:[UNIVERSAL 16]", "1:[UNIVERSAL 2]=05", "1:[UNIVERSAL 16]", "2:[UNIVERSAL 1]=FF"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			err := Walk(bytes.NewReader(data), func(path []Header, depth int, value Reader) error {
+				h := path[len(path)-1]
+				s := fmt.Sprintf("%d:%s", depth, h.Tag)
+				if !value.Constructed() {
+					b, _ := io.ReadAll(value)
+					s += fmt.Sprintf("=%X", b)
+				}
+				got = append(got, s)
+				switch h.Tag {
+				case tt.skip:
+					return SkipValue
+				case tt.stop:
+					return SkipAll
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Walk() visited %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		errTest := errors.New("test")
+		n := 0
+		err := Walk(bytes.NewReader(data), func([]Header, int, Reader) error {
+			n++
+			if n == 2 {
+				return errTest
+			}
+			return nil
+		})
+		if err != errTest || n != 2 {
+			t.Errorf("Walk() = %v after %d values, want %v after 2 values", err, n, errTest)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		n := 0
+		err := Walk(bytes.NewReader(data[:8]), func([]Header, int, Reader) error {
+			n++
+			return nil
+		})
+		if err == nil || n != 3 {
+			t.Errorf("Walk() = %v after %d values, want error after 3 values", err, n)
+		}
+	})
+}
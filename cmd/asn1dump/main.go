@@ -0,0 +1,158 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Asn1dump prints the structure of BER-encoded data.
+//
+// Usage:
+//
+//	asn1dump [flags] [file ...]
+//
+// Asn1dump reads BER or DER encoded data from the named files or from standard
+// input if no files are given. PEM-armored input is detected automatically and
+// each PEM block is printed separately. For each data value asn1dump prints its
+// tag, length and, for primitive values with universal tags, its decoded value.
+// Known object identifiers are printed with their names.
+//
+// The flags are:
+//
+//	-offsets
+//		print the offset of each data value in the input
+//	-hex
+//		print the content octets of primitive values in hexadecimal
+//	-max n
+//		limit hexadecimal output to n bytes per value (-1 for no limit)
+//	-oids file
+//		read additional object identifier names from file. Each line of the
+//		file contains an object identifier in dot notation followed by
+//		whitespace and its name. Empty lines and lines starting with # are
+//		ignored.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"codello.dev/asn1/ber"
+)
+
+var (
+	offsets  = flag.Bool("offsets", false, "print offsets of data values")
+	hexView  = flag.Bool("hex", false, "print content octets in hexadecimal")
+	maxBytes = flag.Int("max", 0, "limit hexadecimal output to `n` bytes per value (-1 for no limit)")
+	oidFile  = flag.String("oids", "", "read additional object identifier names from `file`")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: asn1dump [flags] [file ...]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	opts := &ber.DumpOptions{
+		Offsets:  *offsets,
+		Hex:      *hexView,
+		MaxBytes: *maxBytes,
+		OIDNames: oidNames,
+	}
+	if *oidFile != "" {
+		if err := readOIDNames(*oidFile, opts.OIDNames); err != nil {
+			fatal(err)
+		}
+	}
+
+	if flag.NArg() == 0 {
+		if err := dump(os.Stdout, os.Stdin, opts); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	status := 0
+	for i, name := range flag.Args() {
+		if flag.NArg() > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s:\n", name)
+		}
+		f, err := os.Open(name)
+		if err == nil {
+			err = dump(os.Stdout, f, opts)
+			_ = f.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "asn1dump: %s: %v\n", name, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// dump writes the structure of the data read from r to w. If the data is
+// PEM-armored, each PEM block is dumped separately.
+func dump(w io.Writer, r io.Reader, opts *ber.DumpOptions) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(data, []byte("-----BEGIN ")) {
+		return ber.Dump(w, bytes.NewReader(data), opts)
+	}
+	var block *pem.Block
+	for n := 0; ; n++ {
+		block, data = pem.Decode(data)
+		if block == nil {
+			if n == 0 {
+				return fmt.Errorf("no valid PEM block found")
+			}
+			return nil
+		}
+		if n > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "-----BEGIN %s-----\n", block.Type)
+		if err = ber.Dump(w, bytes.NewReader(block.Bytes), opts); err != nil {
+			return err
+		}
+	}
+}
+
+// readOIDNames reads object identifier names from the named file into m.
+func readOIDNames(name string, m map[string]string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		oid, desc, ok := strings.Cut(text, " ")
+		if !ok {
+			oid, desc, ok = strings.Cut(text, "\t")
+		}
+		if !ok {
+			return fmt.Errorf("%s:%d: missing name", name, line)
+		}
+		m[oid] = strings.TrimSpace(desc)
+	}
+	return s.Err()
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "asn1dump: %v\n", err)
+	os.Exit(1)
+}
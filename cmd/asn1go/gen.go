@@ -0,0 +1,473 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/schema"
+)
+
+// builtinTypes maps universal tags of built-in types to their Go types.
+// Built-in types not contained in the map are represented by ber.RawValue.
+var builtinTypes = map[asn1.Tag]string{
+	asn1.TagBoolean:         "bool",
+	asn1.TagBitString:       "asn1.BitString",
+	asn1.TagOctetString:     "[]byte",
+	asn1.TagNull:            "asn1.Null",
+	asn1.TagOID:             "asn1.ObjectIdentifier",
+	asn1.TagReal:            "float64",
+	asn1.TagUTF8String:      "string",
+	asn1.TagRelativeOID:     "asn1.RelativeOID",
+	asn1.TagTime:            "asn1.Time",
+	asn1.TagNumericString:   "asn1.NumericString",
+	asn1.TagPrintableString: "asn1.PrintableString",
+	asn1.TagIA5String:       "asn1.IA5String",
+	asn1.TagUTCTime:         "asn1.UTCTime",
+	asn1.TagGeneralizedTime: "asn1.GeneralizedTime",
+	asn1.TagVisibleString:   "asn1.VisibleString",
+	asn1.TagUniversalString: "asn1.UniversalString",
+	asn1.TagBMPString:       "asn1.BMPString",
+	asn1.TagDate:            "asn1.Date",
+	asn1.TagTimeOfDay:       "asn1.TimeOfDay",
+	asn1.TagDateTime:        "asn1.DateTime",
+	asn1.TagDuration:        "asn1.Duration",
+}
+
+// generator generates Go declarations for the types of a module.
+type generator struct {
+	m       *schema.Module
+	decls   []string        // generated declarations in output order
+	names   map[string]bool // Go names of generated declarations
+	choices map[string]bool // Go names of types generated for CHOICE types
+	imports map[string]bool // imported packages
+}
+
+// generate returns formatted Go source code declaring the types of m in
+// package pkg. If pkg is empty, the lower case module name is used. The name
+// of the input is mentioned in the header of the generated code.
+func generate(m *schema.Module, pkg, input string) ([]byte, error) {
+	if pkg == "" {
+		pkg = strings.Map(func(r rune) rune {
+			if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return -1
+			}
+			return unicode.ToLower(r)
+		}, m.Name)
+	}
+	g := &generator{m: m, names: make(map[string]bool), choices: make(map[string]bool), imports: make(map[string]bool)}
+	for _, name := range m.TypeNames {
+		g.assignment(name, m.Types[name])
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by asn1go from %s; DO NOT EDIT.\n\n", input)
+	fmt.Fprintf(&b, "// Package %s contains the types of the ASN.1 module %s.\n", pkg, m.Name)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		// standard library imports are grouped before other imports
+		imports := slices.SortedFunc(maps.Keys(g.imports), func(a, b string) int {
+			if isStd(a) != isStd(b) {
+				if isStd(a) {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a, b)
+		})
+		b.WriteString("import (\n")
+		for i, imp := range imports {
+			if i > 0 && !isStd(imp) && isStd(imports[i-1]) {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "\t%q\n", imp)
+		}
+		b.WriteString(")\n\n")
+	}
+	for _, d := range g.decls {
+		b.WriteString(d)
+		b.WriteString("\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+// isStd reports whether path is the import path of a standard library
+// package.
+func isStd(path string) bool {
+	elem, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(elem, ".")
+}
+
+// goName converts the ASN.1 identifier or type reference s into an exported Go
+// identifier.
+func goName(s string) string {
+	var b strings.Builder
+	for part := range strings.SplitSeq(s, "-") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+// declare reserves a slot for a declaration of the Go type name and returns
+// its index in g.decls. Reserving the slot before generating nested types
+// keeps declarations in the order of the module.
+func (g *generator) declare(name string) int {
+	g.names[name] = true
+	g.decls = append(g.decls, "")
+	return len(g.decls) - 1
+}
+
+// unique returns name or, if name is already declared, name with a numeric
+// suffix.
+func (g *generator) unique(name string) string {
+	if !g.names[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if n := name + strconv.Itoa(i); !g.names[n] {
+			return n
+		}
+	}
+}
+
+// stripTags returns the type of t without its tags.
+func stripTags(t *schema.Type) *schema.Type {
+	for t.Kind == schema.Tagged {
+		t = t.Elem
+	}
+	return t
+}
+
+// isNamed reports whether the type t is represented by a defined Go type
+// rather than a type alias.
+func isNamed(t *schema.Type) bool {
+	switch t.Kind {
+	case schema.Sequence, schema.Set, schema.Choice, schema.SequenceOf, schema.SetOf:
+		return true
+	case schema.Builtin:
+		return t.Tag == asn1.TagEnumerated
+	}
+	return false
+}
+
+// assignment generates the declaration of the type assignment name ::= t.
+func (g *generator) assignment(name string, t *schema.Type) {
+	base := stripTags(t)
+	if isNamed(base) {
+		g.namedType(goName(name), name, base)
+		return
+	}
+	gn := goName(name)
+	i := g.declare(gn)
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s corresponds to the ASN.1 type %s.\n", gn, name)
+	typ, _ := g.field(t, gn)
+	fmt.Fprintf(&b, "type %s = %s\n", gn, typ)
+	g.decls[i] = b.String()
+}
+
+// namedType generates a defined Go type with the given name for t, which must
+// be a type for which isNamed returns true. The ASN.1 name is used in the
+// documentation of the type.
+func (g *generator) namedType(gn, name string, t *schema.Type) {
+	i := g.declare(gn)
+	if t.Kind == schema.Choice {
+		g.choices[gn] = true
+	}
+	var b strings.Builder
+	if name != "" {
+		fmt.Fprintf(&b, "// %s corresponds to the ASN.1 type %s.\n", gn, name)
+	}
+	if t.Kind == schema.Choice {
+		if name == "" {
+			fmt.Fprintf(&b, "// %s is an ASN.1 CHOICE type.\n", gn)
+		}
+		b.WriteString("// Exactly one field, the chosen alternative, must be non-zero.\n")
+	}
+	switch t.Kind {
+	case schema.Sequence, schema.Set:
+		fmt.Fprintf(&b, "type %s struct {\n", gn)
+		for _, c := range t.Components {
+			g.component(&b, gn, c, c.Optional || c.Extension)
+		}
+		if t.Extensible {
+			g.imports["codello.dev/asn1"] = true
+			b.WriteString("\n\tasn1.Extensible\n")
+		}
+		b.WriteString("}\n")
+	case schema.Choice:
+		fmt.Fprintf(&b, "type %s struct {\n", gn)
+		for _, c := range t.Components {
+			g.component(&b, gn, c, false)
+		}
+		b.WriteString("}\n")
+	case schema.SequenceOf, schema.SetOf:
+		typ, opts := g.field(t.Elem, gn+"Item")
+		if len(opts) > 0 {
+			// element tags cannot be expressed for a slice type
+			g.imports["codello.dev/asn1/ber"] = true
+			typ = "ber.RawValue"
+		}
+		fmt.Fprintf(&b, "type %s []%s\n", gn, typ)
+	case schema.Builtin: // ENUMERATED
+		fmt.Fprintf(&b, "type %s int\n", gn)
+		consts := g.constants(&b, gn, gn, t)
+		if len(consts) > 0 {
+			g.enumDef(&b, gn, consts, t)
+		}
+	}
+	if t.Kind == schema.Set || t.Kind == schema.SetOf {
+		g.imports["codello.dev/asn1"] = true
+		fmt.Fprintf(&b, "\n// AsnTag returns the tag of the ASN.1 SET type.\n")
+		fmt.Fprintf(&b, "func (%s) AsnTag() asn1.Tag { return asn1.TagSet }\n", gn)
+	}
+	g.decls[i] = b.String()
+}
+
+// constants writes constants for the named numbers of t and returns their
+// names. The constants are prefixed with prefix. If typ is not empty, the
+// constants have that type.
+func (g *generator) constants(b *strings.Builder, prefix, typ string, t *schema.Type) []string {
+	if len(t.NamedNumbers) == 0 {
+		return nil
+	}
+	var names []string
+	b.WriteString("\nconst (\n")
+	for _, n := range t.NamedNumbers {
+		name := g.unique(prefix + goName(n.Name))
+		g.names[name] = true
+		names = append(names, name)
+		if typ != "" {
+			fmt.Fprintf(b, "\t%s %s = %d\n", name, typ, n.Value)
+		} else {
+			fmt.Fprintf(b, "\t%s = %d\n", name, n.Value)
+		}
+	}
+	b.WriteString(")\n")
+	return names
+}
+
+// enumDef writes the [asn1.EnumDef] of the ENUMERATED type gn with the
+// constants consts for the named numbers of t, as well as the String and
+// IsValid methods of gn. The EnumDef provides the identifiers of the values to
+// the encoding rules.
+func (g *generator) enumDef(b *strings.Builder, gn string, consts []string, t *schema.Type) {
+	g.imports["codello.dev/asn1"] = true
+	def := g.unique(strings.ToLower(gn[:1]) + gn[1:] + "Def")
+	g.names[def] = true
+	fmt.Fprintf(b, "\nvar %s = asn1.NewEnumDef(map[%s]string{\n", def, gn)
+	for i, n := range t.NamedNumbers {
+		fmt.Fprintf(b, "\t%s: %q,\n", consts[i], n.Name)
+	}
+	b.WriteString("})\n")
+	fmt.Fprintf(b, "\n// String returns the ASN.1 identifier of v.\n")
+	fmt.Fprintf(b, "func (v %s) String() string { return %s.String(v) }\n", gn, def)
+	if !t.Extensible {
+		fmt.Fprintf(b, "\n// IsValid reports whether v is one of the enumeration items of %s.\n", gn)
+		fmt.Fprintf(b, "func (v %s) IsValid() bool { return %s.IsValid(v) }\n", gn, def)
+	}
+}
+
+// component writes the struct field for the component c of the type parent.
+// If optional is set, the field is marked as optional.
+func (g *generator) component(b *strings.Builder, parent string, c schema.Component, optional bool) {
+	name := goName(c.Name)
+	typ, opts := g.field(c.Type, parent+name)
+	if optional {
+		opts = append(opts, "optional", "omitzero")
+	}
+	if g.choices[parent] && !strings.HasPrefix(typ, "[]") {
+		// alternatives are pointers so that absent alternatives are nil
+		typ = "*" + typ
+	}
+	if id := strings.ToLower(name[:1]) + name[1:]; id != c.Name {
+		opts = append(opts, "name:"+c.Name)
+	}
+	fmt.Fprintf(b, "\t%s %s", name, typ)
+	if len(opts) > 0 {
+		fmt.Fprintf(b, " `asn1:%q`", strings.Join(opts, ","))
+	}
+	if c.Default != "" {
+		fmt.Fprintf(b, " // DEFAULT %s", c.Default)
+	}
+	b.WriteString("\n")
+}
+
+// layer is a tag of a tagged type.
+type layer struct {
+	tag      asn1.Tag
+	explicit bool
+}
+
+// field returns the Go type and struct tag options of a field of type t. If t
+// requires the generation of a nested type, hint is used as its name.
+func (g *generator) field(t *schema.Type, hint string) (typ string, opts []string) {
+	var tags []layer
+	var rng, size *schema.Range
+	constrain := func(t *schema.Type) {
+		rng = cmpOr(rng, t.Range)
+		size = cmpOr(size, t.Size)
+	}
+	var base *schema.Type
+	for base == nil {
+		constrain(t)
+		switch t.Kind {
+		case schema.Tagged:
+			tags = append(tags, layer{t.Tag, t.Explicit})
+			t = t.Elem
+		case schema.Reference:
+			target := g.m.Types[t.Name]
+			if isNamed(stripTags(target)) {
+				for ; target.Kind == schema.Tagged; target = target.Elem {
+					tags = append(tags, layer{target.Tag, target.Explicit})
+				}
+				typ, base = goName(t.Name), target
+				constrain(base)
+			} else {
+				if typ == "" {
+					typ = goName(t.Name)
+				}
+				t = target
+			}
+		default:
+			base = t
+		}
+	}
+	inline := typ == ""
+	if inline {
+		typ = g.inlineType(base, hint, rng)
+	}
+
+	// An implicit tag replaces the outermost tag of the tagged type. Implicit
+	// tags of CHOICE and ANY types are explicit.
+	var layers []layer
+	for i := len(tags) - 1; i >= 0; i-- {
+		l := tags[i]
+		if l.explicit || len(layers) == 0 {
+			if len(layers) == 0 && (base.Kind == schema.Choice || base.Kind == schema.Any) {
+				l.explicit = true
+			}
+			layers = append(layers, l)
+		} else {
+			layers[len(layers)-1].tag = l.tag
+		}
+	}
+	if len(layers) > 1 {
+		// nested tags cannot be expressed by struct tags
+		g.imports["codello.dev/asn1/ber"] = true
+		typ = "ber.RawValue"
+		layers = layers[len(layers)-1:]
+		layers[0].explicit = true
+		base = &schema.Type{Kind: schema.Any}
+		rng, size = nil, nil
+	}
+	if len(layers) == 1 {
+		l := layers[0]
+		switch l.tag.Class() {
+		case asn1.ClassUniversal:
+			opts = append(opts, "universal")
+		case asn1.ClassApplication:
+			opts = append(opts, "application")
+		case asn1.ClassPrivate:
+			opts = append(opts, "private")
+		}
+		if l.explicit {
+			opts = append(opts, "explicit")
+		}
+		opts = append(opts, "tag:"+strconv.FormatUint(uint64(l.tag.Number()), 10))
+	}
+	switch {
+	case base.Kind == schema.Choice:
+		opts = append(opts, "choice")
+	case base.Kind == schema.SetOf && inline:
+		opts = append(opts, "set")
+	}
+	if rng != nil && !rng.Extensible && (base.Kind == schema.Builtin && base.Tag == asn1.TagInteger) {
+		opts = append(opts, "range:"+bounds(rng))
+	}
+	if size != nil && !size.Extensible {
+		opts = append(opts, "size:"+bounds(size))
+	}
+	return typ, opts
+}
+
+// cmpOr returns a if it is not nil and b otherwise.
+func cmpOr(a, b *schema.Range) *schema.Range {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
+// bounds formats r in the notation of the "range" and "size" struct tags.
+func bounds(r *schema.Range) string {
+	lo, hi := "MIN", "MAX"
+	if r.Min != math.MinInt64 {
+		lo = strconv.FormatInt(r.Min, 10)
+	}
+	if r.Max != math.MaxInt64 {
+		hi = strconv.FormatInt(r.Max, 10)
+	}
+	return lo + ".." + hi
+}
+
+// inlineType returns the Go type of the untagged type t that is not a
+// reference. Nested types are generated using hint as their name. If t is an
+// INTEGER, rng is its value constraint.
+func (g *generator) inlineType(t *schema.Type, hint string, rng *schema.Range) string {
+	switch t.Kind {
+	case schema.Any:
+		g.imports["codello.dev/asn1/ber"] = true
+		return "ber.RawValue"
+	case schema.SequenceOf, schema.SetOf:
+		typ, opts := g.field(t.Elem, hint+"Item")
+		if len(opts) > 0 {
+			g.imports["codello.dev/asn1/ber"] = true
+			typ = "ber.RawValue"
+		}
+		return "[]" + typ
+	case schema.Builtin:
+		if t.Tag != asn1.TagEnumerated && len(t.NamedNumbers) > 0 {
+			var b strings.Builder
+			g.constants(&b, hint, "", t)
+			g.decls = append(g.decls, b.String()[1:])
+		}
+		if t.Tag == asn1.TagInteger {
+			if rng == nil && len(t.NamedNumbers) == 0 {
+				g.imports["math/big"] = true
+				return "*big.Int"
+			}
+			return "int64"
+		}
+		if t.Tag == asn1.TagEnumerated {
+			break
+		}
+		typ, ok := builtinTypes[t.Tag]
+		if !ok {
+			g.imports["codello.dev/asn1/ber"] = true
+			return "ber.RawValue"
+		}
+		if strings.HasPrefix(typ, "asn1.") {
+			g.imports["codello.dev/asn1"] = true
+		}
+		return typ
+	}
+	name := g.unique(hint)
+	g.namedType(name, "", t)
+	return name
+}
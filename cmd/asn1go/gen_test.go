@@ -0,0 +1,95 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"codello.dev/asn1/schema"
+)
+
+func TestGenerate(t *testing.T) {
+	src := `
+Test DEFINITIONS IMPLICIT TAGS ::= BEGIN
+	Message ::= SEQUENCE {
+		message-id INTEGER (0..65535),
+		body       CHOICE { request Request, extra [1] EXPLICIT [2] INTEGER },
+		names      SET OF IA5String,
+		...
+	}
+	Request ::= [APPLICATION 0] SEQUENCE {
+		mode   ENUMERATED { simple(0), sasl(3) } DEFAULT simple,
+		params ANY OPTIONAL
+	}
+	Names ::= SET SIZE (1..4) OF UTF8String
+	Serial ::= INTEGER
+END`
+	m, err := schema.Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	code, err := generate(m, "", "test.asn")
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if _, err = parser.ParseFile(token.NewFileSet(), "test.go", code, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	for _, want := range []string{
+		"// Code generated by asn1go from test.asn; DO NOT EDIT.",
+		"package test\n",
+		"\t\"math/big\"\n\n\t\"codello.dev/asn1\"\n",
+		"MessageId int64 `asn1:\"range:0..65535,name:message-id\"`",
+		"Body MessageBody `asn1:\"choice\"`",
+		"Names []asn1.IA5String `asn1:\"set\"`",
+		"asn1.Extensible",
+		"Request *Request `asn1:\"application,tag:0\"`",
+		"Extra *ber.RawValue `asn1:\"explicit,tag:1\"`",
+		"Mode RequestMode `asn1:\"optional,omitzero\"` // DEFAULT simple",
+		"Params ber.RawValue `asn1:\"optional,omitzero\"`",
+		"RequestModeSasl RequestMode = 3",
+		"func (v RequestMode) IsValid() bool",
+		"var requestModeDef = asn1.NewEnumDef(map[RequestMode]string{",
+		"RequestModeSasl: \"sasl\",",
+		"// MessageBody is an ASN.1 CHOICE type.",
+		"type Names []string",
+		"func (Names) AsnTag() asn1.Tag { return asn1.TagSet }",
+		"type Serial = *big.Int",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(string(code)), " "), strings.Join(strings.Fields(want), " ")) {
+			t.Errorf("generated code does not contain %q\n%s", want, code)
+		}
+	}
+}
+
+// TestGenerateExample checks that the generated code in the example package,
+// which is tested with all encoding rules, is up to date.
+func TestGenerateExample(t *testing.T) {
+	src, err := os.ReadFile("internal/example/example.asn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("internal/example/example.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := schema.Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got, err := generate(m, "", "example.asn")
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from internal/example/example.go, run go generate\n%s", got)
+	}
+}
@@ -0,0 +1,45 @@
+// Code generated by asn1go from example.asn; DO NOT EDIT.
+
+// Package example contains the types of the ASN.1 module Example.
+package example
+
+import (
+	"codello.dev/asn1"
+)
+
+// Message corresponds to the ASN.1 type Message.
+type Message struct {
+	MessageId int64            `asn1:"tag:0,range:0..65535,name:message-id"`
+	Priority  Priority         `asn1:"tag:1"`
+	Body      MessageBody      `asn1:"explicit,tag:2,choice"`
+	Labels    []asn1.IA5String `asn1:"tag:3,size:0..8,optional,omitzero"`
+}
+
+// MessageBody is an ASN.1 CHOICE type.
+// Exactly one field, the chosen alternative, must be non-zero.
+type MessageBody struct {
+	Text *string `asn1:"tag:0"`
+	Data []byte  `asn1:"tag:1"`
+	Ack  *bool   `asn1:"tag:2"`
+}
+
+// Priority corresponds to the ASN.1 type Priority.
+type Priority int
+
+const (
+	PriorityLow    Priority = 0
+	PriorityNormal Priority = 1
+	PriorityHigh   Priority = 5
+)
+
+var priorityDef = asn1.NewEnumDef(map[Priority]string{
+	PriorityLow:    "low",
+	PriorityNormal: "normal",
+	PriorityHigh:   "high",
+})
+
+// String returns the ASN.1 identifier of v.
+func (v Priority) String() string { return priorityDef.String(v) }
+
+// IsValid reports whether v is one of the enumeration items of Priority.
+func (v Priority) IsValid() bool { return priorityDef.IsValid(v) }
@@ -0,0 +1,75 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:generate go run codello.dev/asn1/cmd/asn1go -o example.go example.asn
+
+package example
+
+import (
+	"reflect"
+	"testing"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+	"codello.dev/asn1/jer"
+	"codello.dev/asn1/oer"
+	"codello.dev/asn1/per"
+	"codello.dev/asn1/xer"
+)
+
+// codecs contains the encoding rules the generated types are tested with.
+var codecs = map[string]struct {
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte, any) error
+}{
+	"BER": {ber.Marshal, ber.Unmarshal},
+	"JER": {jer.Marshal, jer.Unmarshal},
+	"XER": {xer.Marshal, xer.Unmarshal},
+	"PER": {per.Marshal, per.Unmarshal},
+	"OER": {oer.Marshal, oer.Unmarshal},
+}
+
+func TestRoundTrip(t *testing.T) {
+	text := "hello"
+	ack := true
+	tests := map[string]Message{
+		"Text": {MessageId: 1, Priority: PriorityHigh, Body: MessageBody{Text: &text},
+			Labels: []asn1.IA5String{"a", "b"}},
+		"Data": {MessageId: 65535, Priority: PriorityLow, Body: MessageBody{Data: []byte{1, 2, 3}}},
+		"Ack":  {MessageId: 0, Priority: PriorityNormal, Body: MessageBody{Ack: &ack}},
+	}
+	for cname, c := range codecs {
+		for name, want := range tests {
+			t.Run(cname+"/"+name, func(t *testing.T) {
+				data, err := c.marshal(want)
+				if err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+				var got Message
+				if err = c.unmarshal(data, &got); err != nil {
+					t.Fatalf("Unmarshal(%q) error = %v", data, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Unmarshal(%q) = %+v, want %+v", data, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestEnum(t *testing.T) {
+	if got := PriorityHigh.String(); got != "high" {
+		t.Errorf("PriorityHigh.String() = %q, want %q", got, "high")
+	}
+	if Priority(2).IsValid() {
+		t.Errorf("Priority(2).IsValid() = true, want false")
+	}
+	for cname, c := range codecs {
+		t.Run(cname, func(t *testing.T) {
+			if _, err := c.marshal(Message{Priority: 2, Body: MessageBody{Data: []byte{}}}); err == nil {
+				t.Errorf("Marshal() error = nil, want non-nil")
+			}
+		})
+	}
+}
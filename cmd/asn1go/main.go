@@ -0,0 +1,105 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Asn1go generates Go type definitions from an ASN.1 module.
+//
+// Usage:
+//
+//	asn1go [flags] [file]
+//
+// Asn1go reads an ASN.1 module definition from the named file or from standard
+// input and writes Go source code containing a type for each type assignment
+// of the module. The supported notation is described in the documentation of
+// the [codello.dev/asn1/schema] package. The generated types use struct tags
+// as documented in the [codello.dev/asn1] package:
+//
+//   - SEQUENCE and SET types are translated into structs. Extensible types
+//     embed [codello.dev/asn1.Extensible].
+//   - CHOICE types are translated into structs whose fields are the
+//     alternatives. Fields of such types use the "choice" struct tag.
+//   - ENUMERATED types are translated into integer types with a constant for
+//     each enumeration item. The items are registered with an
+//     [codello.dev/asn1.EnumDef], so that encoding rules can use their
+//     identifiers.
+//   - SET and SET OF types implement [codello.dev/asn1.Tagger].
+//   - Other types are translated into type aliases. Named numbers and named
+//     bits are translated into constants.
+//
+// Tags and constraints of referenced types are applied to the struct tags of
+// the fields using them. Data values that require multiple tags are
+// represented by [codello.dev/asn1/ber.RawValue].
+//
+// The flags are:
+//
+//	-p name
+//		the package name of the generated code. Defaults to the module name
+//		in lower case.
+//	-o file
+//		write the generated code to file instead of standard output
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"codello.dev/asn1/schema"
+)
+
+var (
+	pkgName = flag.String("p", "", "package `name` of the generated code")
+	output  = flag.String("o", "", "write the generated code to `file`")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: asn1go [flags] [file]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() > 1 {
+		usage()
+	}
+
+	src, name, err := readInput()
+	if err != nil {
+		fatal(err)
+	}
+	m, err := schema.Parse(src)
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", name, err))
+	}
+	code, err := generate(m, *pkgName, name)
+	if err != nil {
+		fatal(err)
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(code)
+	} else {
+		err = os.WriteFile(*output, code, 0o666)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// readInput returns the contents of the input file or standard input and a
+// name for the input.
+func readInput() ([]byte, string, error) {
+	if flag.NArg() == 0 {
+		b, err := io.ReadAll(os.Stdin)
+		return b, "<stdin>", err
+	}
+	b, err := os.ReadFile(flag.Arg(0))
+	return b, flag.Arg(0), err
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "asn1go: %v\n", err)
+	os.Exit(1)
+}
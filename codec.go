@@ -0,0 +1,40 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asn1
+
+import "io"
+
+// Codec is implemented by packages that provide a set of encoding rules. It
+// allows code to be parameterized by the encoding rules at runtime, for
+// example if the encoding rules are negotiated per connection. The methods
+// correspond to the functions of the same name provided by the packages
+// implementing encoding rules, such as codello.dev/asn1/ber.
+type Codec interface {
+	// Marshal returns the encoding of val.
+	Marshal(val any) ([]byte, error)
+
+	// Unmarshal decodes the data value in b and stores the result in the value
+	// pointed to by val.
+	Unmarshal(b []byte, val any) error
+
+	// NewEncoder returns an Encoder that writes to w.
+	NewEncoder(w io.Writer) Encoder
+
+	// NewDecoder returns a Decoder that reads from r.
+	NewDecoder(r io.Reader) Decoder
+}
+
+// An Encoder writes encoded data values to an output stream.
+type Encoder interface {
+	// Encode writes the encoding of val to the stream.
+	Encode(val any) error
+}
+
+// A Decoder reads encoded data values from an input stream.
+type Decoder interface {
+	// Decode reads the next data value from the stream and stores it in the
+	// value pointed to by val.
+	Decode(val any) error
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"codello.dev/asn1"
+)
+
+// Bounds represents a constraint of the form lb..ub as used by the "range" and
+// "size" struct tags. The zero value is unconstrained (MIN..MAX).
+type Bounds struct {
+	Lower, Upper       int64
+	HasLower, HasUpper bool
+}
+
+// Fixed reports whether b contains exactly one value.
+func (b Bounds) Fixed() bool {
+	return b.HasLower && b.HasUpper && b.Lower == b.Upper
+}
+
+// Contains reports whether n satisfies the constraint b.
+func (b Bounds) Contains(n int64) bool {
+	return (!b.HasLower || n >= b.Lower) && (!b.HasUpper || n <= b.Upper)
+}
+
+// parseBounds parses a constraint of the form "lb..ub" or "n". The lower bound
+// may be MIN and the upper bound may be MAX to indicate the absence of a bound.
+func parseBounds(s string) (b Bounds, ok bool) {
+	lb, ub, found := strings.Cut(s, "..")
+	if !found {
+		ub = lb
+	}
+	if lb != "MIN" {
+		n, err := strconv.ParseInt(lb, 10, 64)
+		if err != nil {
+			return b, false
+		}
+		b.Lower, b.HasLower = n, true
+	}
+	if ub != "MAX" {
+		n, err := strconv.ParseInt(ub, 10, 64)
+		if err != nil {
+			return b, false
+		}
+		b.Upper, b.HasUpper = n, true
+	}
+	if b.HasLower && b.HasUpper && b.Lower > b.Upper {
+		return Bounds{}, false
+	}
+	return b, true
+}
+
+// String returns the constraint in the form lb..ub.
+func (b Bounds) String() string {
+	lb, ub := "MIN", "MAX"
+	if b.HasLower {
+		lb = strconv.FormatInt(b.Lower, 10)
+	}
+	if b.HasUpper {
+		ub = strconv.FormatInt(b.Upper, 10)
+	}
+	return lb + ".." + ub
+}
+
+// Errors returned by CheckConstraints. The returned errors wrap these values.
+var (
+	ErrRange = errors.New("value violates range constraint")
+	ErrSize  = errors.New("value violates size constraint")
+)
+
+// CheckConstraints validates that v satisfies the range and size constraints
+// specified in params. Pointers and interfaces are dereferenced. A nil value
+// satisfies all constraints. The range constraint applies to integer types and
+// [big.Int]. The size constraint applies to strings (counting characters),
+// [asn1.BitString] (counting bits), as well as slices, arrays and maps
+// (counting elements). Constraints that do not apply to the type of v are
+// ignored.
+func CheckConstraints(v reflect.Value, params FieldParameters) error {
+	if params.Range == (Bounds{}) && params.Size == (Bounds{}) {
+		return nil
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if IsNullable(v.Type()) || IsOptional(v.Type()) {
+		if !v.Field(1).Bool() {
+			return nil
+		}
+		v = v.Field(0)
+	}
+	if r := params.Range; r != (Bounds{}) {
+		switch vv := v.Interface().(type) {
+		case big.Int:
+			if (r.HasLower && vv.Cmp(big.NewInt(r.Lower)) < 0) || (r.HasUpper && vv.Cmp(big.NewInt(r.Upper)) > 0) {
+				return fmt.Errorf("%w: %s not in %s", ErrRange, vv.String(), r)
+			}
+		default:
+			switch v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				if !r.Contains(v.Int()) {
+					return fmt.Errorf("%w: %d not in %s", ErrRange, v.Int(), r)
+				}
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				if u := v.Uint(); (u > math.MaxInt64 && r.HasUpper) || (u <= math.MaxInt64 && !r.Contains(int64(u))) {
+					return fmt.Errorf("%w: %d not in %s", ErrRange, u, r)
+				}
+			}
+		}
+	}
+	if s := params.Size; s != (Bounds{}) {
+		n := -1
+		if bs, ok := v.Interface().(asn1.BitString); ok {
+			n = bs.BitLength
+		} else {
+			switch v.Kind() {
+			case reflect.String:
+				n = utf8.RuneCountInString(v.String())
+			case reflect.Slice, reflect.Array, reflect.Map:
+				n = v.Len()
+			}
+		}
+		if n >= 0 && !s.Contains(int64(n)) {
+			return fmt.Errorf("%w: size %d not in %s", ErrSize, n, s)
+		}
+	}
+	return nil
+}
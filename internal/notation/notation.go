@@ -0,0 +1,116 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package notation implements formatting and parsing of ASN.1 time types using
+// their ASN.1 value notation. Text-based encoding rules such as JER and XER
+// represent these types by their value notation. The value notation is
+// identical to the content octets of the BER encoding, so the ber package is
+// used to implement the conversion.
+package notation
+
+import (
+	"math/big"
+	"reflect"
+	"time"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/tlv"
+)
+
+// defaultTags contains the universal tags of types that are represented by
+// their value notation.
+var defaultTags = map[reflect.Type]asn1.Tag{
+	reflect.TypeFor[asn1.Time]():              asn1.TagTime,
+	reflect.TypeFor[asn1.TimeInterval]():      asn1.TagTime,
+	reflect.TypeFor[asn1.RecurringInterval](): asn1.TagTime,
+	reflect.TypeFor[asn1.UTCTime]():           asn1.TagUTCTime,
+	reflect.TypeFor[asn1.GeneralizedTime]():   asn1.TagGeneralizedTime,
+	reflect.TypeFor[asn1.Date]():              asn1.TagDate,
+	reflect.TypeFor[asn1.TimeOfDay]():         asn1.TagTimeOfDay,
+	reflect.TypeFor[asn1.DateTime]():          asn1.TagDateTime,
+	reflect.TypeFor[asn1.Duration]():          asn1.TagDuration,
+	reflect.TypeFor[asn1.ISODuration]():       asn1.TagDuration,
+	reflect.TypeFor[time.Time]():              asn1.TagTime,
+	reflect.TypeFor[time.Duration]():          asn1.TagDuration,
+}
+
+// types maps the tags in defaultTags to the types from the asn1 package that
+// implement the respective value notation.
+var types = map[asn1.Tag]reflect.Type{
+	asn1.TagTime:            reflect.TypeFor[asn1.Time](),
+	asn1.TagUTCTime:         reflect.TypeFor[asn1.UTCTime](),
+	asn1.TagGeneralizedTime: reflect.TypeFor[asn1.GeneralizedTime](),
+	asn1.TagDate:            reflect.TypeFor[asn1.Date](),
+	asn1.TagTimeOfDay:       reflect.TypeFor[asn1.TimeOfDay](),
+	asn1.TagDateTime:        reflect.TypeFor[asn1.DateTime](),
+	asn1.TagDuration:        reflect.TypeFor[asn1.Duration](),
+}
+
+// Tag returns the tag of the ASN.1 type used to format or parse values of type
+// t. If params specify the universal tag of a compatible type, it takes
+// precedence over the default for t. This allows [time.Time] values to use any
+// of the ASN.1 time types. If t is not represented by its value notation, ok
+// is false.
+func Tag(t reflect.Type, params internal.FieldParameters) (tag asn1.Tag, ok bool) {
+	tag, ok = defaultTags[t]
+	if ok && params.Tag != 0 && types[params.Tag] != nil && t.ConvertibleTo(types[params.Tag]) {
+		tag = params.Tag
+	}
+	return tag, ok
+}
+
+// IsKnownType reports whether t has a predefined representation in text-based
+// encoding rules. Known types are encoded according to their ASN.1 type even if
+// they implement interfaces such as [encoding.BinaryMarshaler], as is the case
+// for [time.Time] and [big.Int].
+func IsKnownType(t reflect.Type) bool {
+	if _, ok := defaultTags[t]; ok {
+		return true
+	}
+	switch t {
+	case reflect.TypeFor[asn1.BitString](),
+		reflect.TypeFor[asn1.Null](),
+		reflect.TypeFor[asn1.ObjectIdentifier](),
+		reflect.TypeFor[asn1.RelativeOID](),
+		reflect.TypeFor[big.Int](),
+		reflect.TypeFor[big.Float]():
+		return true
+	}
+	return false
+}
+
+// Format returns the value notation of v using the ASN.1 type identified by
+// tag. The tag must have been obtained via [Tag].
+func Format(v reflect.Value, tag asn1.Tag) (string, error) {
+	if v.Type().ConvertibleTo(types[tag]) {
+		v = v.Convert(types[tag])
+	}
+	b, err := ber.Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+	_, n, err := tlv.ParseHeader(b)
+	if err != nil {
+		return "", err
+	}
+	return string(b[n:]), nil
+}
+
+// Parse parses s as a value of the ASN.1 type identified by tag and stores the
+// result in v. The tag must have been obtained via [Tag].
+func Parse(s string, v reflect.Value, tag asn1.Tag) error {
+	b := tlv.AppendHeader(nil, tlv.Header{Tag: tag, Length: len(s)})
+	t := types[tag]
+	if !v.Type().ConvertibleTo(t) {
+		t = v.Type()
+	}
+	val := reflect.New(t)
+	if err := ber.Unmarshal(append(b, s...), val.Interface()); err != nil {
+		return err
+	}
+	v.Set(val.Elem().Convert(v.Type()))
+	return nil
+}
@@ -5,11 +5,15 @@
 package internal
 
 import (
+	"errors"
 	"iter"
 	"math/bits"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"codello.dev/asn1"
 )
@@ -17,12 +21,33 @@ import (
 // FieldParameters is the parsed representation of tag string from a struct
 // field.
 type FieldParameters struct {
-	Ignore   bool     // true iff this field should be ignored
-	Tag      asn1.Tag // the EXPLICIT or IMPLICIT class and tag number (maybe nil).
-	Optional bool     // true iff the field is OPTIONAL
-	Explicit bool     // true iff an EXPLICIT tag is in use.
-	OmitZero bool     // true iff this should be omitted if zero when marshaling.
-	Nullable bool     // true iff this can encode to and decode from null.
+	Ignore     bool     // true iff this field should be ignored
+	Tag        asn1.Tag // the EXPLICIT or IMPLICIT class and tag number (maybe nil).
+	Optional   bool     // true iff the field is OPTIONAL
+	Explicit   bool     // true iff an EXPLICIT tag is in use.
+	OmitZero   bool     // true iff this should be omitted if zero when marshaling.
+	OmitEmpty  bool     // true iff this should be omitted if it is an empty slice, map or string when marshaling.
+	Nullable   bool     // true iff this can encode to and decode from null.
+	Name       string   // the ASN.1 identifier of the field (maybe empty).
+	Field      string   // the name of the Go struct field (maybe empty).
+	Choice     bool     // true iff the field is a CHOICE type.
+	Text       bool     // true iff encoding.TextMarshaler is used for the field.
+	Rest       bool     // true iff the field collects the remaining values of a SEQUENCE.
+	Inline     bool     // true iff the fields of a struct field are treated as fields of the parent.
+	Components bool     // true iff the field is included using COMPONENTS OF.
+	DefinedBy  string   // the name of the Go struct field identifying the type of the field (maybe empty).
+	Enum       string   // the name of the enum type whose names a string field holds (maybe empty).
+	Range      Bounds   // the value range constraint of the field.
+	Size       Bounds   // the size constraint of the field.
+	Any        bool     // true iff the field accepts data values with any tag.
+
+	// Type is the universal type selected by the tags of the encoding/asn1
+	// package, such as "ia5" or "generalized" (maybe 0).
+	Type asn1.Tag
+
+	UTC          bool // true iff time values are converted to UTC when marshaling.
+	Precision    int  // the number of fractional second digits of time values.
+	HasPrecision bool // true iff Precision is set.
 }
 
 // ParseFieldParameters will parse a given tag string into a FieldParameters
@@ -60,6 +85,55 @@ func ParseFieldParameters(str string) (ret FieldParameters) {
 			ret.OmitZero = true
 		case part == "nullable":
 			ret.Nullable = true
+		case strings.HasPrefix(part, "name:"):
+			ret.Name = part[5:]
+		case part == "choice":
+			ret.Choice = true
+		case part == "text":
+			ret.Text = true
+		case part == "rest":
+			ret.Rest = true
+		case part == "inline":
+			ret.Inline = true
+		case part == "components":
+			ret.Components = true
+		case strings.HasPrefix(part, "definedby:"):
+			ret.DefinedBy = part[10:]
+		case strings.HasPrefix(part, "enum:"):
+			ret.Enum = part[5:]
+		case strings.HasPrefix(part, "range:"):
+			if b, ok := parseBounds(part[6:]); ok {
+				ret.Range = b
+			}
+		case part == "toutc":
+			ret.UTC = true
+		case part == "utc", part == "utctime":
+			ret.Type = asn1.TagUTCTime
+		case part == "generalized":
+			ret.Type = asn1.TagGeneralizedTime
+		case part == "ia5":
+			ret.Type = asn1.TagIA5String
+		case part == "printable":
+			ret.Type = asn1.TagPrintableString
+		case part == "utf8":
+			ret.Type = asn1.TagUTF8String
+		case part == "numeric":
+			ret.Type = asn1.TagNumericString
+		case part == "set":
+			ret.Type = asn1.TagSet
+		case part == "omitempty":
+			ret.OmitEmpty = true
+		case part == "any":
+			ret.Any = true
+		case strings.HasPrefix(part, "precision:"):
+			if p, err := strconv.Atoi(part[10:]); err == nil && 0 <= p && p <= 9 {
+				ret.Precision = p
+				ret.HasPrecision = true
+			}
+		case strings.HasPrefix(part, "size:"):
+			if b, ok := parseBounds(part[5:]); ok && (!b.HasLower || b.Lower >= 0) {
+				ret.Size = b
+			}
 		}
 	}
 	return ret
@@ -68,31 +142,208 @@ func ParseFieldParameters(str string) (ret FieldParameters) {
 // ExtensibleType is the type of asn1.Extensible.
 var ExtensibleType = reflect.TypeFor[asn1.Extensible]()
 
+// ExtensibleDataType is the type of asn1.ExtensibleData.
+var ExtensibleDataType = reflect.TypeFor[asn1.ExtensibleData]()
+
+// PresenceType is the type of asn1.Presence.
+var PresenceType = reflect.TypeFor[asn1.Presence]()
+
+// PresenceOf returns the asn1.Presence embedded in the struct v. If v does not
+// embed an asn1.Presence or v is not addressable, nil is returned.
+func PresenceOf(v reflect.Value) *asn1.Presence {
+	if !v.CanAddr() {
+		return nil
+	}
+	t := v.Type()
+	for i := range t.NumField() {
+		if f := t.Field(i); f.Anonymous && f.Type == PresenceType {
+			return v.Field(i).Addr().Interface().(*asn1.Presence)
+		}
+	}
+	return nil
+}
+
+// IsNullable reports whether t is an instantiation of the generic type
+// asn1.Nullable. The value of a Nullable is its first field and the validity
+// flag is its second field.
+func IsNullable(t reflect.Type) bool {
+	return isGeneric(t, "Nullable")
+}
+
+// IsOptional reports whether t is an instantiation of the generic type
+// asn1.Optional. The value of an Optional is its first field and the presence
+// flag is its second field.
+func IsOptional(t reflect.Type) bool {
+	return isGeneric(t, "Optional")
+}
+
+// IsSetOf reports whether t is an instantiation of the generic type
+// asn1.SetOf. The elements of a SetOf are accessed via its Values and Add
+// methods.
+func IsSetOf(t reflect.Type) bool {
+	return isGeneric(t, "SetOf")
+}
+
+// isGeneric reports whether t is an instantiation of the generic struct type
+// with the given name in the asn1 package.
+func isGeneric(t reflect.Type, name string) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == PresenceType.PkgPath() && strings.HasPrefix(t.Name(), name+"[")
+}
+
+// IsEmpty reports whether v is a slice, map or string of length zero. Fields
+// with an `asn1:"omitempty"` tag are omitted if they are empty.
+func IsEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	}
+	return false
+}
+
+// IsExtensible reports whether t is one of the types marking a struct as
+// extensible.
+func IsExtensible(t reflect.Type) bool {
+	return t == ExtensibleType || t == ExtensibleDataType
+}
+
 // StructFields returns a sequence that iterates over the fields of the struct
 // identified by v. Struct fields with a `asn1:"-"` tag are ignored, as are
-// non-exported struct fields. Fields of embedded structs returned as if they
-// were fields of the containing struct, except for fields of type
-// asn1.Extensible.
+// non-exported struct fields. Fields of embedded structs and of struct fields
+// with a `asn1:"inline"` tag are returned as if they were fields of the
+// containing struct, except for fields of type asn1.Extensible and
+// asn1.ExtensibleData. The same applies to struct fields with a
+// `asn1:"components"` tag, but the extension marker of such a struct, that is
+// a field of type asn1.Extensible or asn1.ExtensibleData, is omitted.
+//
+// If a field does not specify a name via struct tags, the Name of the returned
+// FieldParameters is set to the field name with its first letter converted to
+// lower case. Fields of type asn1.Optional are always optional and omitzero.
+// Field parameters registered via SetFieldTags replace the struct tags of the
+// respective fields.
 func StructFields(v reflect.Value) iter.Seq2[reflect.Value, FieldParameters] {
 	return func(yield func(reflect.Value, FieldParameters) bool) {
 		t := v.Type()
 		for i := range t.NumField() {
 			field := t.Field(i)
-			params := ParseFieldParameters(field.Tag.Get("asn1"))
+			params := ParseFieldParameters(fieldTag(t, field))
 			if params.Ignore || !field.IsExported() {
 				continue
 			}
-			if field.Anonymous && params.Tag == 0 && field.Type.Kind() == reflect.Struct && field.Type != ExtensibleType {
+			if (field.Anonymous && params.Tag == 0 || params.Inline || params.Components) && field.Type.Kind() == reflect.Struct && !IsExtensible(field.Type) {
+				components := params.Components
 				for vv, params := range StructFields(v.Field(i)) {
+					if components && IsExtensible(vv.Type()) {
+						continue
+					}
 					if !yield(vv, params) {
 						return
 					}
 				}
 				continue
 			}
+			if params.Name == "" {
+				params.Name = identifier(field.Name)
+			}
+			params.Field = field.Name
+			if IsOptional(field.Type) {
+				params.Optional = true
+				params.OmitZero = true
+			}
 			if !yield(v.Field(i), params) {
 				return
 			}
 		}
 	}
 }
+
+// ChoiceAlternative returns the chosen alternative of the struct v, which
+// represents a value of a CHOICE type. Every field of v except an extension
+// marker is an alternative. The chosen alternative is the only field that is
+// not zero. If no field or multiple fields are non-zero, an error is returned.
+// The returned parameters are not optional.
+func ChoiceAlternative(v reflect.Value) (alt reflect.Value, params FieldParameters, err error) {
+	for field, fieldParams := range StructFields(v) {
+		if IsExtensible(field.Type()) || isZero(field) {
+			continue
+		}
+		if alt.IsValid() {
+			return reflect.Value{}, params, errors.New("multiple CHOICE alternatives present")
+		}
+		alt, params = field, fieldParams
+	}
+	if !alt.IsValid() {
+		return alt, params, errors.New("no CHOICE alternative present")
+	}
+	params.Optional, params.OmitZero, params.OmitEmpty = false, false, false
+	return alt, params, nil
+}
+
+// isZero reports whether v is a nil pointer or interface, or the zero value of
+// its type. Types with an IsZero method define their own zero values.
+func isZero(v reflect.Value) bool {
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
+// fieldTags maps struct types to the field tags registered via SetFieldTags.
+var fieldTags sync.Map
+
+// SetFieldTags registers tags as the field parameters of the fields of the
+// struct type t. The keys of tags are Go field names. The values replace the
+// `asn1` struct tags of the respective fields. SetFieldTags reports false if
+// tags have already been registered for t.
+func SetFieldTags(t reflect.Type, tags map[string]string) bool {
+	_, dup := fieldTags.LoadOrStore(t, tags)
+	return !dup
+}
+
+// fieldTag returns the field parameters of the field of the struct type t. If
+// parameters have been registered via SetFieldTags they are returned,
+// otherwise the `asn1` struct tag of field is returned.
+func fieldTag(t reflect.Type, field reflect.StructField) string {
+	if tags, ok := fieldTags.Load(t); ok {
+		if tag, ok := tags.(map[string]string)[field.Name]; ok {
+			return tag
+		}
+	}
+	return field.Tag.Get("asn1")
+}
+
+// SetAbsent prepares the struct field v for an absent optional value. Values of
+// type asn1.Optional are set to their zero value. If a pointer to v implements
+// asn1.Defaulter, its SetASN1Defaults method is called.
+func SetAbsent(v reflect.Value) {
+	if IsOptional(v.Type()) {
+		v.SetZero()
+	} else if v.CanAddr() {
+		if d, ok := v.Addr().Interface().(asn1.Defaulter); ok {
+			d.SetASN1Defaults()
+		}
+	}
+}
+
+// Validate calls the ValidateASN1 method of v if v or a pointer to v implements
+// asn1.Validator. Otherwise, nil is returned.
+func Validate(v reflect.Value) error {
+	if v.CanAddr() {
+		if val, ok := v.Addr().Interface().(asn1.Validator); ok {
+			return val.ValidateASN1()
+		}
+	}
+	if val, ok := v.Interface().(asn1.Validator); ok {
+		return val.ValidateASN1()
+	}
+	return nil
+}
+
+// identifier converts the Go field name into an ASN.1 identifier by converting
+// the first letter to lower case.
+func identifier(name string) string {
+	r, n := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[n:]
+}
@@ -6,7 +6,10 @@ package internal
 
 import (
 	"reflect"
+	"slices"
 	"testing"
+
+	"codello.dev/asn1"
 )
 
 func Test_structFields(t *testing.T) {
@@ -27,6 +30,22 @@ func Test_structFields(t *testing.T) {
 				Embedded
 			}{}, 3,
 		},
+		"Inline": {
+			struct {
+				X string
+				Y Embedded `asn1:"inline"`
+				Z Embedded
+			}{}, 4,
+		},
+		"ComponentsOf": {
+			struct {
+				X string
+				Y struct {
+					A int
+					asn1.Extensible
+				} `asn1:"components"`
+			}{}, 2,
+		},
 		"NonExported": {
 			struct {
 				a int
@@ -46,3 +65,76 @@ func Test_structFields(t *testing.T) {
 		})
 	}
 }
+
+func Test_structFieldNames(t *testing.T) {
+	v := struct {
+		Num    int
+		Str    string `asn1:"optional,name:text"`
+		ÄBC    bool
+		Nested struct{ ID int }
+	}{}
+	want := []string{"num", "text", "äBC", "nested"}
+	var got []string
+	for _, params := range StructFields(reflect.ValueOf(v)) {
+		got = append(got, params.Name)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("StructFields() names = %v, want %v", got, want)
+	}
+}
+
+func TestParseFieldParameters_constraints(t *testing.T) {
+	tests := map[string]struct {
+		str  string
+		rng  Bounds
+		size Bounds
+	}{
+		"Range":       {"range:0..255", Bounds{0, 255, true, true}, Bounds{}},
+		"Negative":    {"range:-5..-1", Bounds{-5, -1, true, true}, Bounds{}},
+		"SemiRange":   {"range:1..MAX", Bounds{1, 0, true, false}, Bounds{}},
+		"UpperRange":  {"range:MIN..7", Bounds{0, 7, false, true}, Bounds{}},
+		"FixedSize":   {"size:4", Bounds{}, Bounds{4, 4, true, true}},
+		"Size":        {"optional,size:1..32", Bounds{}, Bounds{1, 32, true, true}},
+		"InvalidSize": {"size:-1..4", Bounds{}, Bounds{}},
+		"Reversed":    {"range:5..1", Bounds{}, Bounds{}},
+		"Invalid":     {"range:a..b", Bounds{}, Bounds{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ParseFieldParameters(tt.str)
+			if got.Range != tt.rng {
+				t.Errorf("ParseFieldParameters(%q).Range = %v, want %v", tt.str, got.Range, tt.rng)
+			}
+			if got.Size != tt.size {
+				t.Errorf("ParseFieldParameters(%q).Size = %v, want %v", tt.str, got.Size, tt.size)
+			}
+		})
+	}
+}
+
+func TestParseFieldParameters_time(t *testing.T) {
+	tests := map[string]struct {
+		str          string
+		utc          bool
+		precision    int
+		hasPrecision bool
+	}{
+		"None":      {"", false, 0, false},
+		"UTC":       {"toutc", true, 0, false},
+		"Stdlib":    {"utc", false, 0, false},
+		"Seconds":   {"toutc,precision:0", true, 0, true},
+		"Millis":    {"precision:3", false, 3, true},
+		"TooLarge":  {"precision:10", false, 0, false},
+		"Negative":  {"precision:-1", false, 0, false},
+		"NotNumber": {"precision:x", false, 0, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ParseFieldParameters(tt.str)
+			if got.UTC != tt.utc || got.Precision != tt.precision || got.HasPrecision != tt.hasPrecision {
+				t.Errorf("ParseFieldParameters(%q) = {UTC: %v, Precision: %v, HasPrecision: %v}, want {%v, %v, %v}",
+					tt.str, got.UTC, got.Precision, got.HasPrecision, tt.utc, tt.precision, tt.hasPrecision)
+			}
+		})
+	}
+}
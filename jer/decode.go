@@ -0,0 +1,459 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jer
+
+import (
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+)
+
+// Unmarshal parses the JER-encoded data and stores the result in the value
+// pointed to by val. If val is nil or not a pointer, Unmarshal returns an
+// error.
+//
+// Syntax errors in the JSON data are reported using the error types of the
+// [encoding/json] package. If the JSON data does not match the Go type of val,
+// a [StructuralError] is returned.
+func Unmarshal(data []byte, val any) error {
+	return UnmarshalWithParams(data, val, "")
+}
+
+// UnmarshalWithParams allows field parameters to be specified for the top-level
+// value. The form of the params is the same as the field tags.
+func UnmarshalWithParams(data []byte, val any, params string) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return &InvalidDecodeError{v}
+	}
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return decodeValue(raw, v.Elem(), internal.ParseFieldParameters(params))
+}
+
+// decodeValue decodes the JSON value data into v. v must be settable.
+func decodeValue(data json.RawMessage, v reflect.Value, params internal.FieldParameters) error {
+	isNull := string(data) == "null"
+	if isNull && params.Nullable {
+		v.SetZero()
+		return nil
+	}
+	if internal.IsNullable(v.Type()) {
+		if isNull {
+			v.SetZero()
+			return nil
+		}
+		if err := decodeValue(data, v.Field(0), params); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+	if internal.IsOptional(v.Type()) {
+		if err := decodeValue(data, v.Field(0), params); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+
+	// If v is a named type and is addressable, start with its address, so that if
+	// the type has pointer methods, we find them.
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.Kind() == reflect.Interface {
+			if e := v.Elem(); e.Kind() == reflect.Pointer && !e.IsNil() {
+				v = e
+				continue
+			}
+			if v.NumMethod() > 0 {
+				return &InvalidDecodeError{v}
+			}
+			// v has type interface{}
+			var val any
+			if err := json.Unmarshal(data, &val); err != nil {
+				return err
+			}
+			if val == nil {
+				v.SetZero()
+			} else {
+				v.Set(reflect.ValueOf(val))
+			}
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if notation.IsKnownType(v.Type().Elem()) {
+			v = v.Elem()
+			continue
+		}
+		switch vv := v.Interface().(type) {
+		case json.Unmarshaler:
+			return vv.UnmarshalJSON(data)
+		case encoding.BinaryUnmarshaler:
+			b, err := decodeHex(data, v.Type())
+			if err != nil {
+				return err
+			}
+			return vv.UnmarshalBinary(b)
+		}
+		v = v.Elem()
+	}
+	if !v.CanSet() {
+		return &InvalidDecodeError{v}
+	}
+
+	switch vp := v.Addr().Interface().(type) {
+	case *asn1.BitString:
+		return decodeBitString(data, vp)
+	case *asn1.Null:
+		if !isNull {
+			return &StructuralError{v.Type(), errors.New("expected null")}
+		}
+		return nil
+	case *asn1.ObjectIdentifier:
+		oid, err := decodeOID(data, v.Type())
+		*vp = oid
+		return err
+	case *asn1.RelativeOID:
+		oid, err := decodeOID(data, v.Type())
+		*vp = asn1.RelativeOID(oid)
+		return err
+	case *big.Int:
+		if _, ok := vp.SetString(string(data), 10); !ok {
+			return &StructuralError{v.Type(), errors.New("invalid INTEGER")}
+		}
+		return nil
+	case *big.Float:
+		return decodeBigFloat(data, vp)
+	}
+	if isNull {
+		return &StructuralError{v.Type(), errors.New("unexpected null")}
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		var s string
+		if err := unmarshal(data, &s, v.Type()); err != nil {
+			return err
+		}
+		if err := notation.Parse(s, v, tag); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		var b bool
+		if err := unmarshal(data, &b, v.Type()); err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if len(data) > 0 && data[0] == '"' {
+			var name string
+			if err := unmarshal(data, &name, v.Type()); err != nil {
+				return err
+			}
+			i, err := asn1.EnumValue(v.Type(), name)
+			if err != nil {
+				return &StructuralError{v.Type(), err}
+			}
+			v.SetInt(int64(i))
+			return nil
+		}
+		i, err := strconv.ParseInt(string(data), 10, v.Type().Bits())
+		if err != nil {
+			return &StructuralError{v.Type(), errors.New("invalid INTEGER")}
+		}
+		v.SetInt(i)
+		if err = asn1.ValidateEnum(v.Interface()); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(string(data), 10, v.Type().Bits())
+		if err != nil {
+			return &StructuralError{v.Type(), errors.New("invalid INTEGER")}
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := decodeFloat(data, v.Type())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		var s string
+		if err := unmarshal(data, &s, v.Type()); err != nil {
+			return err
+		}
+		v.SetString(s)
+		if vv, ok := v.Interface().(interface{ IsValid() bool }); ok && !vv.IsValid() {
+			return &StructuralError{v.Type(), errors.New("invalid characters")}
+		}
+	case reflect.Struct:
+		decode := decodeStruct
+		if params.Choice {
+			decode = decodeChoice
+		}
+		if err := decode(data, v); err != nil {
+			return err
+		}
+		if err := internal.Validate(v); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := decodeHex(data, v.Type())
+			if err != nil {
+				return err
+			}
+			if v.Kind() == reflect.Slice {
+				v.SetBytes(b)
+			} else if reflect.Copy(v, reflect.ValueOf(b)) != len(b) || len(b) != v.Len() {
+				return &StructuralError{v.Type(), errors.New("wrong number of bytes")}
+			}
+			return nil
+		}
+		var elems []json.RawMessage
+		if err := unmarshal(data, &elems, v.Type()); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+		} else if len(elems) != v.Len() {
+			return &StructuralError{v.Type(), errors.New("wrong number of values")}
+		}
+		for i, elem := range elems {
+			if err := decodeValue(elem, v.Index(i), internal.FieldParameters{}); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &InvalidDecodeError{v}
+		}
+		var elems []json.RawMessage
+		if err := unmarshal(data, &elems, v.Type()); err != nil {
+			return err
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(elems)))
+		empty := reflect.ValueOf(struct{}{})
+		for _, elem := range elems {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := decodeValue(elem, key, internal.FieldParameters{}); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, empty)
+		}
+	default:
+		return &InvalidDecodeError{v}
+	}
+	return nil
+}
+
+// decodeStruct decodes the JSON object data into the fields of the struct v.
+// Members that do not correspond to a field are only accepted if the struct is
+// extensible.
+func decodeStruct(data json.RawMessage, v reflect.Value) error {
+	var members map[string]json.RawMessage
+	if err := unmarshal(data, &members, v.Type()); err != nil {
+		return err
+	}
+	extensible := false
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			extensible = true
+			continue
+		}
+		raw, ok := members[params.Name]
+		if !ok {
+			if !params.Optional {
+				return &StructuralError{v.Type(), fmt.Errorf("missing member %q", params.Name)}
+			}
+			internal.SetAbsent(field)
+			continue
+		}
+		delete(members, params.Name)
+		if err := decodeValue(raw, field, params); err != nil {
+			return err
+		}
+	}
+	if len(members) > 0 && !extensible {
+		names := slices.Sorted(maps.Keys(members))
+		return &StructuralError{v.Type(), fmt.Errorf("unknown member %q", names[0])}
+	}
+	return nil
+}
+
+// decodeChoice decodes the JSON object data into the CHOICE struct v. The
+// object must have a single member named after the chosen alternative. All
+// other fields of v are set to their zero value.
+func decodeChoice(data json.RawMessage, v reflect.Value) error {
+	var members map[string]json.RawMessage
+	if err := unmarshal(data, &members, v.Type()); err != nil {
+		return err
+	}
+	if len(members) != 1 {
+		return &StructuralError{v.Type(), errors.New("CHOICE value must have exactly one member")}
+	}
+	v.SetZero()
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			continue
+		}
+		if raw, ok := members[params.Name]; ok {
+			return decodeValue(raw, field, params)
+		}
+	}
+	name := slices.Collect(maps.Keys(members))[0]
+	return &StructuralError{v.Type(), fmt.Errorf("unknown CHOICE alternative %q", name)}
+}
+
+// unmarshal decodes data into val using [json.Unmarshal]. Type errors are
+// reported as a [StructuralError] for type t.
+func unmarshal(data json.RawMessage, val any, t reflect.Type) error {
+	if err := json.Unmarshal(data, val); err != nil {
+		return &StructuralError{t, err}
+	}
+	return nil
+}
+
+// decodeHex decodes a JSON string containing hexadecimal digits.
+func decodeHex(data json.RawMessage, t reflect.Type) ([]byte, error) {
+	var s string
+	if err := unmarshal(data, &s, t); err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, &StructuralError{t, err}
+	}
+	return b, nil
+}
+
+// decodeBitString decodes a JSON object with "value" and "length" members into
+// bs. If the length is absent, all bits of the value are used.
+func decodeBitString(data json.RawMessage, bs *asn1.BitString) error {
+	t := reflect.TypeFor[asn1.BitString]()
+	var obj struct {
+		Value  *string `json:"value"`
+		Length *int    `json:"length"`
+	}
+	if err := unmarshal(data, &obj, t); err != nil {
+		return err
+	}
+	if obj.Value == nil {
+		return &StructuralError{t, errors.New(`missing member "value"`)}
+	}
+	b, err := hex.DecodeString(*obj.Value)
+	if err != nil {
+		return &StructuralError{t, err}
+	}
+	ret := asn1.BitString{Bytes: b, BitLength: len(b) * 8}
+	if obj.Length != nil {
+		ret.BitLength = *obj.Length
+	}
+	if ret.BitLength < 0 || !ret.IsValid() {
+		return &StructuralError{t, errors.New("invalid BIT STRING length")}
+	}
+	*bs = ret
+	return nil
+}
+
+// decodeOID decodes a JSON string containing an object identifier in dot
+// notation.
+func decodeOID(data json.RawMessage, t reflect.Type) (asn1.ObjectIdentifier, error) {
+	var s string
+	if err := unmarshal(data, &s, t); err != nil {
+		return nil, err
+	}
+	if s == "" {
+		return nil, &StructuralError{t, errors.New("empty object identifier")}
+	}
+	var oid asn1.ObjectIdentifier
+	for part := range strings.SplitSeq(s, ".") {
+		n, err := strconv.ParseUint(part, 10, strconv.IntSize)
+		if err != nil {
+			return nil, &StructuralError{t, fmt.Errorf("invalid object identifier %q", s)}
+		}
+		oid = append(oid, uint(n))
+	}
+	return oid, nil
+}
+
+// decodeFloat decodes a REAL value. Special values are represented as strings.
+func decodeFloat(data json.RawMessage, t reflect.Type) (float64, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := unmarshal(data, &s, t); err != nil {
+			return 0, err
+		}
+		switch s {
+		case "INF":
+			return math.Inf(1), nil
+		case "-INF":
+			return math.Inf(-1), nil
+		case "NaN":
+			return math.NaN(), nil
+		case "0":
+			return 0, nil
+		case "-0":
+			return math.Copysign(0, -1), nil
+		}
+		return 0, &StructuralError{t, fmt.Errorf("invalid REAL %q", s)}
+	}
+	f, err := strconv.ParseFloat(string(data), t.Bits())
+	if err != nil {
+		return 0, &StructuralError{t, errors.New("invalid REAL")}
+	}
+	return f, nil
+}
+
+// decodeBigFloat decodes a REAL value into f. NaN values cannot be represented
+// by [big.Float].
+func decodeBigFloat(data json.RawMessage, f *big.Float) error {
+	t := reflect.TypeFor[big.Float]()
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := unmarshal(data, &s, t); err != nil {
+			return err
+		}
+		switch s {
+		case "INF":
+			f.SetInf(false)
+		case "-INF":
+			f.SetInf(true)
+		case "0":
+			f.SetInt64(0)
+		case "-0":
+			f.Neg(f.SetInt64(0))
+		default:
+			return &StructuralError{t, fmt.Errorf("invalid REAL %q", s)}
+		}
+		return nil
+	}
+	if _, ok := f.SetString(string(data)); !ok {
+		return &StructuralError{t, errors.New("invalid REAL")}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+)
+
+// emptyStructType is used to identify the [asn1.Set] type.
+var emptyStructType = reflect.TypeFor[struct{}]()
+
+//region main encoding functions
+
+// Marshal returns the JER encoding of val or an error if encoding fails.
+func Marshal(val any) ([]byte, error) {
+	return MarshalWithParams(val, "")
+}
+
+// MarshalWithParams returns the JER encoding of val. The format of the params
+// is described in the asn1 package.
+func MarshalWithParams(val any, params string) ([]byte, error) {
+	var e encodeState
+	if err := e.encode(reflect.ValueOf(val), internal.ParseFieldParameters(params)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// encodeState holds the JSON output of an encoding process.
+type encodeState struct {
+	bytes.Buffer
+}
+
+// encode writes the JER encoding of v to e.
+func (e *encodeState) encode(v reflect.Value, params internal.FieldParameters) error {
+	if !v.IsValid() {
+		return &UnsupportedTypeError{Type: nil}
+	}
+	if internal.IsNullable(v.Type()) {
+		if !v.Field(1).Bool() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.encode(v.Field(0), params)
+	}
+	if internal.IsOptional(v.Type()) {
+		return e.encode(v.Field(0), params)
+	}
+
+	// If v is a named type and is addressable, start with its address, so that if
+	// the type has pointer methods, we find them.
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if params.Nullable {
+				e.WriteString("null")
+				return nil
+			}
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		if m, ok := v.Interface().(json.Marshaler); ok && !notation.IsKnownType(v.Type().Elem()) {
+			return e.marshalJSON(v, m)
+		}
+		v = v.Elem()
+	}
+
+	vif := v.Interface()
+	if params.Nullable {
+		if z, ok := vif.(interface{ IsZero() bool }); (ok && z.IsZero()) || (!ok && v.IsZero()) {
+			e.WriteString("null")
+			return nil
+		}
+	}
+
+	switch vv := vif.(type) {
+	case asn1.BitString:
+		if !vv.IsValid() {
+			return &EncodeError{v, errors.New("invalid BIT STRING")}
+		}
+		e.WriteString(`{"value":"`)
+		e.WriteString(strings.ToUpper(hex.EncodeToString(vv.Bytes)))
+		e.WriteString(`","length":`)
+		e.WriteString(strconv.Itoa(vv.BitLength))
+		e.WriteByte('}')
+		return nil
+	case asn1.Null:
+		e.WriteString("null")
+		return nil
+	case asn1.ObjectIdentifier:
+		e.writeString(vv.String())
+		return nil
+	case asn1.RelativeOID:
+		e.writeString(vv.String())
+		return nil
+	case big.Int:
+		e.WriteString(vv.String())
+		return nil
+	case big.Float:
+		switch {
+		case vv.IsInf() && vv.Signbit():
+			e.writeString("-INF")
+		case vv.IsInf():
+			e.writeString("INF")
+		case vv.Sign() == 0 && vv.Signbit():
+			e.writeString("-0")
+		default:
+			e.WriteString(vv.Text('g', -1))
+		}
+		return nil
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		s, err := notation.Format(v, tag)
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		e.writeString(s)
+		return nil
+	}
+	switch vv := vif.(type) {
+	case json.Marshaler:
+		return e.marshalJSON(v, vv)
+	case encoding.BinaryMarshaler:
+		b, err := vv.MarshalBinary()
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		e.writeHex(b)
+		return nil
+	}
+	if err := asn1.ValidateEnum(vif); err != nil {
+		return &EncodeError{v, err}
+	}
+	if vv, ok := vif.(interface{ IsValid() bool }); ok && !vv.IsValid() {
+		return &EncodeError{v, errors.New("invalid value")}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if name, err := asn1.EnumName(v.Type(), int(v.Int())); err == nil {
+			e.writeString(name)
+		} else {
+			e.WriteString(strconv.FormatInt(v.Int(), 10))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		e.writeFloat(v.Float(), v.Type().Bits())
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Struct:
+		if params.Choice {
+			return e.encodeChoice(v)
+		}
+		return e.encodeStruct(v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.writeHex(b)
+			return nil
+		}
+		e.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			if err := e.encode(v.Index(i), internal.FieldParameters{}); err != nil {
+				return err
+			}
+		}
+		e.WriteByte(']')
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		return e.encodeSet(v)
+	default:
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	return nil
+}
+
+// encodeStruct writes the fields of the struct v as a JSON object.
+func (e *encodeState) encodeStruct(v reflect.Value) error {
+	e.WriteByte('{')
+	first := true
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			continue
+		}
+		if params.OmitEmpty && internal.IsEmpty(field) {
+			continue
+		}
+		if params.OmitZero {
+			if z, ok := field.Interface().(interface{ IsZero() bool }); (ok && z.IsZero()) || (!ok && field.IsZero()) {
+				continue
+			}
+		}
+		if !first {
+			e.WriteByte(',')
+		}
+		first = false
+		e.writeString(params.Name)
+		e.WriteByte(':')
+		if err := e.encode(field, params); err != nil {
+			return err
+		}
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+// encodeChoice writes the CHOICE value v as a JSON object with a single member
+// for the chosen alternative.
+func (e *encodeState) encodeChoice(v reflect.Value) error {
+	alt, params, err := internal.ChoiceAlternative(v)
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	e.WriteByte('{')
+	e.writeString(params.Name)
+	e.WriteByte(':')
+	if err = e.encode(alt, params); err != nil {
+		return err
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+// encodeSet writes the elements of the set v as a JSON array. The elements are
+// sorted by their encoding to produce a deterministic output.
+func (e *encodeState) encodeSet(v reflect.Value) error {
+	elems := make([][]byte, 0, v.Len())
+	var es encodeState
+	for _, key := range v.MapKeys() {
+		es.Reset()
+		if err := es.encode(key, internal.FieldParameters{}); err != nil {
+			return err
+		}
+		elems = append(elems, bytes.Clone(es.Bytes()))
+	}
+	slices.SortFunc(elems, bytes.Compare)
+	e.WriteByte('[')
+	e.Write(bytes.Join(elems, []byte{','}))
+	e.WriteByte(']')
+	return nil
+}
+
+// marshalJSON writes the output of m to e after validating it.
+func (e *encodeState) marshalJSON(v reflect.Value, m json.Marshaler) error {
+	b, err := m.MarshalJSON()
+	if err == nil && !json.Valid(b) {
+		err = errors.New("MarshalJSON returned invalid JSON")
+	}
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	return json.Compact(&e.Buffer, b)
+}
+
+// writeString writes s as a quoted JSON string.
+func (e *encodeState) writeString(s string) {
+	b, _ := json.Marshal(s)
+	e.Write(b)
+}
+
+// writeHex writes b as a JSON string containing hexadecimal digits.
+func (e *encodeState) writeHex(b []byte) {
+	e.WriteByte('"')
+	e.WriteString(strings.ToUpper(hex.EncodeToString(b)))
+	e.WriteByte('"')
+}
+
+// writeFloat writes the REAL value f. Special values are written as strings.
+func (e *encodeState) writeFloat(f float64, bitSize int) {
+	switch {
+	case math.IsInf(f, 1):
+		e.writeString("INF")
+	case math.IsInf(f, -1):
+		e.writeString("-INF")
+	case math.IsNaN(f):
+		e.writeString("NaN")
+	case f == 0 && math.Signbit(f):
+		e.writeString("-0")
+	default:
+		e.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+}
+
+//endregion
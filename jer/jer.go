@@ -0,0 +1,146 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jer implements the ASN.1 JSON Encoding Rules (JER). The JSON Encoding
+// Rules are defined in [Rec. ITU-T X.697].
+//
+// See the package documentation of the asn1 package for details how Go types
+// translate to ASN.1 types. Types following that specification can be encoded
+// into and decoded from JSON using this package. The same struct types can be
+// used with the ber package. ASN.1 types are represented in JSON as follows:
+//
+//   - BOOLEAN values are JSON booleans.
+//   - INTEGER values are JSON numbers.
+//   - ENUMERATED values are JSON strings containing the identifier of the
+//     value. The identifiers are taken from the [asn1.EnumDef] registered for
+//     the Go type.
+//   - REAL values are JSON numbers. The special values are represented by the
+//     strings "INF", "-INF", "NaN" and "-0".
+//   - BIT STRING values are JSON objects with a "value" member containing the
+//     bits as hexadecimal string and a "length" member containing the number of
+//     bits.
+//   - OCTET STRING values are JSON strings containing the hexadecimal
+//     representation of the bytes.
+//   - NULL values are represented by the JSON null value.
+//   - OBJECT IDENTIFIER and RELATIVE-OID values are JSON strings using the dot
+//     notation.
+//   - Character string and time types are JSON strings. Time types use the same
+//     format as their ASN.1 value notation.
+//   - SEQUENCE types are JSON objects with one member per present component.
+//     The member names are the ASN.1 identifiers of the struct fields (see the
+//     asn1 package).
+//   - CHOICE types (see the "choice" struct tag) are JSON objects with a single
+//     member for the chosen alternative.
+//   - SEQUENCE OF and SET OF types are JSON arrays.
+//
+// ASN.1 tags do not have any effect on the JSON encoding. Types that implement
+// [encoding/json.Marshaler] or [encoding/json.Unmarshaler] can customize their
+// JER encoding.
+//
+// The following limitations apply:
+//
+//   - ENUMERATED values of types without an [asn1.EnumDef] are encoded by their
+//     numerical value because their identifiers are not known. The numerical
+//     value is also accepted when decoding.
+//   - When decoding into an interface{} the value is decoded as if by
+//     [encoding/json.Unmarshal] because the ASN.1 type cannot be determined from
+//     the JSON value alone.
+//   - When decoding into a byte array or a Go array, the number of elements must
+//     match the length of the array exactly.
+//
+// [Rec. ITU-T X.697]: https://www.itu.int/rec/T-REC-X.697
+package jer
+
+import (
+	"reflect"
+	"strings"
+)
+
+//region error types
+
+// UnsupportedTypeError indicates that a value was passed to Marshal that cannot
+// be encoded to JER.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "cannot marshal nil value"
+	}
+	if e.Type.Kind() == reflect.Pointer {
+		return "cannot marshal nil pointer of type: " + e.Type.String()
+	} else if e.Type.Kind() == reflect.Interface {
+		return "cannot marshal nil interface of type: " + e.Type.String()
+	}
+	return "cannot marshal value of type " + e.Type.String() + ": unsupported Go type"
+}
+
+// EncodeError indicates that a value failed validation during encoding.
+type EncodeError struct {
+	Value reflect.Value
+	Err   error
+}
+
+func (e *EncodeError) Error() string {
+	var s strings.Builder
+	s.WriteString("encode error")
+	if e.Value.IsValid() {
+		s.WriteString(" for ")
+		s.WriteString(e.Value.Type().String())
+	}
+	s.WriteString(": ")
+	s.WriteString(e.Err.Error())
+	return s.String()
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidDecodeError indicates that an invalid value was passed to Unmarshal.
+// The invalid value might be nested within the passed value.
+type InvalidDecodeError struct {
+	Value reflect.Value
+}
+
+func (e *InvalidDecodeError) Error() string {
+	if !e.Value.IsValid() {
+		return "cannot decode into nil value"
+	}
+	if e.Value.Kind() == reflect.Pointer && e.Value.IsNil() {
+		return "cannot decode into nil pointer of type " + e.Value.Type().String()
+	} else if e.Value.Kind() != reflect.Pointer && !e.Value.CanAddr() {
+		return "cannot decode into non-pointer type " + e.Value.Type().String()
+	}
+	return "unsupported Go type: " + e.Value.Type().String()
+}
+
+// A StructuralError suggests that the JSON data is valid, but the Go type which
+// is receiving it doesn't match or can't fit the data. Invalid JSON syntax is
+// reported using the error types of the [encoding/json] package.
+type StructuralError struct {
+	Type reflect.Type
+	Err  error
+}
+
+func (e *StructuralError) Error() string {
+	var s strings.Builder
+	s.WriteString("structural error")
+	if e.Type != nil {
+		s.WriteString(" decoding into ")
+		s.WriteString(e.Type.String())
+	}
+	if e.Err != nil {
+		s.WriteString(": ")
+		s.WriteString(e.Err.Error())
+	}
+	return s.String()
+}
+
+func (e *StructuralError) Unwrap() error {
+	return e.Err
+}
+
+//endregion
@@ -0,0 +1,403 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jer
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"codello.dev/asn1"
+)
+
+type testCase[T any] struct {
+	val     T
+	data    string
+	params  string
+	wantErr error
+}
+
+// otherError is used to indicate that any non-nil error is expected.
+var otherError = errors.New("other error")
+
+// testCodec runs marshal and unmarshal tests for the type T. Test cases in
+// common are run in both directions.
+func testCodec[T any](t *testing.T, common, marshal, unmarshal map[string]testCase[T]) {
+	t.Helper()
+	runMarshal := func(name string, tc testCase[T]) {
+		t.Run("Marshal/"+name, func(t *testing.T) {
+			got, err := MarshalWithParams(tc.val, tc.params)
+			checkError(t, "MarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && string(got) != tc.data {
+				t.Errorf("MarshalWithParams() = %s, want %s", got, tc.data)
+			}
+		})
+	}
+	runUnmarshal := func(name string, tc testCase[T]) {
+		t.Run("Unmarshal/"+name, func(t *testing.T) {
+			var got T
+			err := UnmarshalWithParams([]byte(tc.data), &got, tc.params)
+			checkError(t, "UnmarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && !reflect.DeepEqual(got, tc.val) {
+				t.Errorf("UnmarshalWithParams() = %v, want %v", got, tc.val)
+			}
+		})
+	}
+	for name, tc := range common {
+		runMarshal(name, tc)
+		runUnmarshal(name, tc)
+	}
+	for name, tc := range marshal {
+		runMarshal(name, tc)
+	}
+	for name, tc := range unmarshal {
+		runUnmarshal(name, tc)
+	}
+}
+
+func checkError(t *testing.T, fn string, err, wantErr error) {
+	t.Helper()
+	if wantErr == nil && err != nil {
+		t.Fatalf("%s error = %v, want nil", fn, err)
+	}
+	if wantErr == otherError && err == nil {
+		t.Fatalf("%s error = nil, want non-nil", fn)
+	}
+	if wantErr != nil && wantErr != otherError {
+		if target := reflect.New(reflect.TypeOf(wantErr)); !errors.As(err, target.Interface()) {
+			t.Fatalf("%s error = %v, want %T", fn, err, wantErr)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	testCodec(t, map[string]testCase[bool]{
+		"True":  {val: true, data: "true"},
+		"False": {val: false, data: "false"},
+	}, nil, map[string]testCase[bool]{
+		"Number": {data: "1", wantErr: &StructuralError{}},
+		"Null":   {data: "null", wantErr: &StructuralError{}},
+	})
+}
+
+type color int
+
+var colors = asn1.NewEnumDef(map[color]string{0: "red", 1: "green"})
+
+func TestInteger(t *testing.T) {
+	testCodec(t, map[string]testCase[int8]{
+		"Zero":     {val: 0, data: "0"},
+		"Positive": {val: 127, data: "127"},
+		"Negative": {val: -128, data: "-128"},
+	}, nil, map[string]testCase[int8]{
+		"Overflow": {data: "128", wantErr: &StructuralError{}},
+		"Fraction": {data: "1.5", wantErr: &StructuralError{}},
+		"String":   {data: `"1"`, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[color]{
+		"Identifier": {val: 1, data: `"green"`},
+	}, map[string]testCase[color]{
+		"InvalidValue": {val: 7, wantErr: &EncodeError{}},
+	}, map[string]testCase[color]{
+		"Number":            {val: 1, data: "1"},
+		"InvalidIdentifier": {data: `"blue"`, wantErr: &StructuralError{}},
+	})
+	big1, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	testCodec(t, map[string]testCase[*big.Int]{
+		"Big": {val: big1, data: "123456789012345678901234567890"},
+	}, nil, nil)
+}
+
+func TestReal(t *testing.T) {
+	testCodec(t, map[string]testCase[float64]{
+		"Number":   {val: 1.5, data: "1.5"},
+		"Exponent": {val: 1e100, data: "1e+100"},
+		"Inf":      {val: math.Inf(1), data: `"INF"`},
+		"NegInf":   {val: math.Inf(-1), data: `"-INF"`},
+		"NegZero":  {val: math.Copysign(0, -1), data: `"-0"`},
+	}, nil, map[string]testCase[float64]{
+		"Invalid": {data: `"1.5"`, wantErr: &StructuralError{}},
+	})
+	t.Run("NaN", func(t *testing.T) {
+		got, err := Marshal(math.NaN())
+		if err != nil || string(got) != `"NaN"` {
+			t.Fatalf("Marshal(NaN) = %s, %v", got, err)
+		}
+		var f float32
+		if err = Unmarshal(got, &f); err != nil || !math.IsNaN(float64(f)) {
+			t.Errorf("Unmarshal() = %v, %v", f, err)
+		}
+	})
+}
+
+func TestBitString(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.BitString]{
+		"Empty":   {val: asn1.BitString{Bytes: []byte{}}, data: `{"value":"","length":0}`},
+		"Partial": {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, data: `{"value":"A0","length":3}`},
+	}, map[string]testCase[asn1.BitString]{
+		"Invalid": {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 9}, wantErr: &EncodeError{}},
+	}, map[string]testCase[asn1.BitString]{
+		"NoLength":  {val: asn1.BitString{Bytes: []byte{0xFF}, BitLength: 8}, data: `{"value":"ff"}`},
+		"NoValue":   {data: `{"length":3}`, wantErr: &StructuralError{}},
+		"TooLong":   {data: `{"value":"FF","length":9}`, wantErr: &StructuralError{}},
+		"NotObject": {data: `"FF"`, wantErr: &StructuralError{}},
+	})
+}
+
+func TestOctetString(t *testing.T) {
+	testCodec(t, map[string]testCase[[]byte]{
+		"Empty": {val: []byte{}, data: `""`},
+		"Bytes": {val: []byte{0x01, 0xAB}, data: `"01AB"`},
+	}, nil, map[string]testCase[[]byte]{
+		"Lowercase":  {val: []byte{0xAB}, data: `"ab"`},
+		"InvalidHex": {data: `"0"`, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[[2]byte]{
+		"Array": {val: [2]byte{0x01, 0x02}, data: `"0102"`},
+	}, nil, map[string]testCase[[2]byte]{
+		"WrongLength": {data: `"01"`, wantErr: &StructuralError{}},
+	})
+}
+
+func TestNull(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.Null]{
+		"Null": {val: asn1.Null{}, data: "null"},
+	}, nil, map[string]testCase[asn1.Null]{
+		"NotNull": {data: "0", wantErr: &StructuralError{}},
+	})
+}
+
+func TestNullable(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.Nullable[string]]{
+		"Null":  {val: asn1.Nullable[string]{}, data: "null"},
+		"Valid": {val: asn1.NewNullable("a"), data: `"a"`},
+		"Empty": {val: asn1.NewNullable(""), data: `""`},
+	}, nil, map[string]testCase[asn1.Nullable[string]]{
+		"Mismatch": {data: "0", wantErr: &StructuralError{}},
+	})
+}
+
+func TestOptional(t *testing.T) {
+	type seq struct {
+		A asn1.Optional[int]
+	}
+	testCodec(t, map[string]testCase[seq]{
+		"Absent":  {val: seq{}, data: `{}`},
+		"Present": {val: seq{asn1.NewOptional(0)}, data: `{"a":0}`},
+	}, nil, nil)
+}
+
+func TestChoice(t *testing.T) {
+	type body struct {
+		A *string
+		B *bool
+	}
+	type msg struct {
+		Body body `asn1:"choice"`
+	}
+	b := true
+	s := "x"
+	testCodec(t, map[string]testCase[msg]{
+		"First":  {val: msg{body{A: &s}}, data: `{"body":{"a":"x"}}`},
+		"Second": {val: msg{body{B: &b}}, data: `{"body":{"b":true}}`},
+	}, map[string]testCase[msg]{
+		"None":     {val: msg{}, wantErr: &EncodeError{}},
+		"Multiple": {val: msg{body{A: &s, B: &b}}, wantErr: &EncodeError{}},
+	}, map[string]testCase[msg]{
+		"Empty":    {data: `{"body":{}}`, wantErr: &StructuralError{}},
+		"Multiple": {data: `{"body":{"a":"x","b":true}}`, wantErr: &StructuralError{}},
+		"Unknown":  {data: `{"body":{"c":1}}`, wantErr: &StructuralError{}},
+	})
+}
+
+func TestObjectIdentifier(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.ObjectIdentifier]{
+		"OID": {val: asn1.ObjectIdentifier{1, 2, 840, 113549}, data: `"1.2.840.113549"`},
+	}, nil, map[string]testCase[asn1.ObjectIdentifier]{
+		"Empty":   {data: `""`, wantErr: &StructuralError{}},
+		"Invalid": {data: `"1..2"`, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[asn1.RelativeOID]{
+		"RelativeOID": {val: asn1.RelativeOID{8571, 3, 2}, data: `"8571.3.2"`},
+	}, nil, nil)
+}
+
+func TestStrings(t *testing.T) {
+	testCodec(t, map[string]testCase[string]{
+		"UTF8": {val: "héllo \"world\"", data: `"héllo \"world\""`},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.PrintableString]{
+		"Printable": {val: "Hello", data: `"Hello"`},
+	}, map[string]testCase[asn1.PrintableString]{
+		"Invalid": {val: "Hello!", wantErr: &EncodeError{}},
+	}, map[string]testCase[asn1.PrintableString]{
+		"Invalid": {data: `"Hello!"`, wantErr: &StructuralError{}},
+	})
+}
+
+func TestTime(t *testing.T) {
+	date := time.Date(1991, 5, 6, 23, 45, 40, 0, time.UTC)
+	testCodec(t, map[string]testCase[asn1.UTCTime]{
+		"UTCTime": {val: asn1.UTCTime(date), data: `"910506234540Z"`},
+	}, nil, map[string]testCase[asn1.UTCTime]{
+		"Invalid": {data: `"1991-05-06"`, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[time.Time]{
+		"GeneralizedTime": {val: date, data: `"19910506234540Z"`, params: "universal,tag:24"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[time.Duration]{
+		"Duration": {val: 90 * time.Minute, data: `"PT1H30M"`},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.ISODuration]{
+		"ISODuration": {val: asn1.ISODuration{Days: 1, Hours: 2}, data: `"P1DT2H"`},
+	}, nil, nil)
+}
+
+func TestSequence(t *testing.T) {
+	type inner struct {
+		A int
+	}
+	type seq struct {
+		Num   int
+		Str   string `asn1:"optional,omitzero,name:text"`
+		Data  []byte `asn1:"application,tag:5"`
+		Inner *inner `asn1:"optional,omitzero"`
+		Ptr   *int   `asn1:"nullable"`
+		Skip  int    `asn1:"-"`
+	}
+	testCodec(t, map[string]testCase[seq]{
+		"Full": {val: seq{1, "a", []byte{0x0F}, &inner{2}, nil, 0},
+			data: `{"num":1,"text":"a","data":"0F","inner":{"a":2},"ptr":null}`},
+		"Omitted": {val: seq{Num: 1, Data: []byte{}},
+			data: `{"num":1,"data":"","ptr":null}`},
+	}, nil, map[string]testCase[seq]{
+		"Reordered": {val: seq{Num: 1, Data: []byte{}},
+			data: `{"ptr":null,"data":"","num":1}`},
+		"Missing":       {data: `{"num":1}`, wantErr: &StructuralError{}},
+		"UnknownMember": {data: `{"num":1,"data":"","ptr":null,"foo":1}`, wantErr: &StructuralError{}},
+		"NotObject":     {data: `[1]`, wantErr: &StructuralError{}},
+		"Syntax":        {data: `{"num":`, wantErr: otherError},
+	})
+
+	type omitEmpty struct {
+		A []int  `asn1:"optional,omitempty"`
+		B string `asn1:"optional,omitempty"`
+	}
+	testCodec(t, map[string]testCase[omitEmpty]{
+		"OmitEmpty": {val: omitEmpty{}, data: `{}`},
+	}, map[string]testCase[omitEmpty]{
+		"OmitEmptySlice": {val: omitEmpty{A: []int{}}, data: `{}`},
+	}, nil)
+
+	type extensible struct {
+		A int
+		asn1.Extensible
+	}
+	testCodec(t, map[string]testCase[extensible]{
+		"Extensible": {val: extensible{A: 1}, data: `{"a":1}`},
+	}, nil, map[string]testCase[extensible]{
+		"Extension": {val: extensible{A: 1}, data: `{"a":1,"b":2}`},
+	})
+}
+
+// testRange implements asn1.Validator.
+type testRange struct {
+	Low, High int
+}
+
+func (r *testRange) ValidateASN1() error {
+	if r.Low > r.High {
+		return errors.New("low exceeds high")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	testCodec(t, map[string]testCase[testRange]{
+		"Valid": {val: testRange{1, 2}, data: `{"low":1,"high":2}`},
+	}, nil, map[string]testCase[testRange]{
+		"Invalid": {data: `{"low":2,"high":1}`, wantErr: &StructuralError{}},
+	})
+}
+
+func TestSequenceOf(t *testing.T) {
+	testCodec(t, map[string]testCase[[]int]{
+		"Slice": {val: []int{1, 2, 3}, data: "[1,2,3]"},
+		"Empty": {val: []int{}, data: "[]"},
+	}, nil, map[string]testCase[[]int]{
+		"Invalid": {data: `[1,"2"]`, wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[[2]int]{
+		"Array": {val: [2]int{1, 2}, data: "[1,2]"},
+	}, nil, map[string]testCase[[2]int]{
+		"WrongLength": {data: "[1]", wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[asn1.Set[int]]{
+		"Set": {val: asn1.NewSet(3, 1, 2), data: "[1,2,3]"},
+	}, nil, nil)
+}
+
+func TestUnmarshal_Any(t *testing.T) {
+	var got any
+	if err := Unmarshal([]byte(`{"a":[1,"b"]}`), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := map[string]any{"a": []any{1.0, "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %v, want %v", got, want)
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	var i int
+	if err := Unmarshal([]byte("1"), i); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(non-pointer) error = %v, want InvalidDecodeError", err)
+	}
+	if err := Unmarshal([]byte("1"), nil); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(nil) error = %v, want InvalidDecodeError", err)
+	}
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+	tests := map[string]any{
+		"Nil":        nil,
+		"NilPointer": (*int)(nil),
+		"Map":        map[string]int{},
+		"Chan":       make(chan int),
+	}
+	for name, val := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Marshal(val); !errors.As(err, new(*UnsupportedTypeError)) {
+				t.Errorf("Marshal() error = %v, want UnsupportedTypeError", err)
+			}
+		})
+	}
+}
+
+// jsonValue implements json.Marshaler and json.Unmarshaler.
+type jsonValue struct{ s string }
+
+func (v jsonValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"custom:` + v.s + `"`), nil
+}
+
+func (v *jsonValue) UnmarshalJSON(b []byte) error {
+	v.s = string(b)
+	return nil
+}
+
+func TestCustom(t *testing.T) {
+	type custom struct {
+		V jsonValue
+	}
+	testCodec(t, nil, map[string]testCase[custom]{
+		"Marshal": {val: custom{jsonValue{"x"}}, data: `{"v":"custom:x"}`},
+	}, map[string]testCase[custom]{
+		"Unmarshal": {val: custom{jsonValue{`"x"`}}, data: `{"v":"x"}`},
+	})
+}
@@ -0,0 +1,544 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oer
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"unicode/utf8"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+	"codello.dev/asn1/tlv"
+	"codello.dev/asn1/vlq"
+)
+
+// Unmarshal parses the OER-encoded data and stores the result in the value
+// pointed to by val. If val is nil or not a pointer, Unmarshal returns an
+// error.
+//
+// Malformed data is reported using a [SyntaxError]. If the data does not match
+// the Go type of val, a [StructuralError] is returned. Because OER does not
+// include type information in the encoding, data that does not match the Go
+// type of val may not always be detected.
+func Unmarshal(data []byte, val any) error {
+	return UnmarshalWithParams(data, val, "")
+}
+
+// UnmarshalWithParams allows field parameters to be specified for the top-level
+// value. The form of the params is the same as the field tags.
+func UnmarshalWithParams(data []byte, val any, params string) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return &InvalidDecodeError{v}
+	}
+	d := &decodeState{data: data}
+	if err := d.decode(v.Elem(), internal.ParseFieldParameters(params)); err != nil {
+		return err
+	}
+	if d.off < len(d.data) {
+		return &SyntaxError{d.off, errors.New("extra data after top-level value")}
+	}
+	return nil
+}
+
+// decodeState holds the input of a decoding process.
+type decodeState struct {
+	data []byte
+	off  int // current read offset in data
+}
+
+// ReadByte implements [io.ByteReader].
+func (d *decodeState) ReadByte() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, &SyntaxError{d.off, io.ErrUnexpectedEOF}
+	}
+	d.off++
+	return d.data[d.off-1], nil
+}
+
+// readBytes reads the next n bytes. The returned slice references the input.
+func (d *decodeState) readBytes(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.off {
+		return nil, &SyntaxError{d.off, io.ErrUnexpectedEOF}
+	}
+	d.off += n
+	return d.data[d.off-n : d.off], nil
+}
+
+// decode decodes a value into v, using the constraints in params.
+func (d *decodeState) decode(v reflect.Value, params internal.FieldParameters) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.Kind() == reflect.Interface {
+			if e := v.Elem(); e.Kind() == reflect.Pointer && !e.IsNil() {
+				v = e
+				continue
+			}
+			return &InvalidDecodeError{v}
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if !v.CanSet() {
+		return &InvalidDecodeError{v}
+	}
+	if internal.IsOptional(v.Type()) {
+		if err := d.decode(v.Field(0), params); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+	if params.Choice {
+		return d.decodeChoice(v)
+	}
+
+	switch vp := v.Addr().Interface().(type) {
+	case *asn1.BitString:
+		return d.decodeBitString(vp, params.Size)
+	case *asn1.Null:
+		return nil
+	case *asn1.ObjectIdentifier:
+		return d.decodeBER(v, asn1.TagOID)
+	case *asn1.RelativeOID:
+		return d.decodeBER(v, asn1.TagRelativeOID)
+	case *big.Float:
+		return d.decodeBER(v, asn1.TagReal)
+	case *big.Int:
+		x, err := d.readInteger(params.Range)
+		if err != nil {
+			return err
+		}
+		vp.Set(x)
+		return nil
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		b, err := d.readOctets(1, internal.Bounds{}, v.Type())
+		if err != nil {
+			return err
+		}
+		if err = notation.Parse(string(b), v, tag); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+		return nil
+	}
+	if vv, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+		b, err := d.readOctets(1, params.Size, v.Type())
+		if err != nil {
+			return err
+		}
+		return vv.UnmarshalBinary(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := d.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != 0x00 && b != 0xff {
+			return &SyntaxError{d.off - 1, errors.New("invalid BOOLEAN")}
+		}
+		v.SetBool(b == 0xff)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := d.readIntegerOrEnumerated(v.Type(), params.Range)
+		if err != nil {
+			return err
+		}
+		if !x.IsInt64() || v.OverflowInt(x.Int64()) {
+			return &StructuralError{v.Type(), errors.New("integer too large")}
+		}
+		v.SetInt(x.Int64())
+		if err = asn1.ValidateEnum(v.Interface()); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := d.readIntegerOrEnumerated(v.Type(), params.Range)
+		if err != nil {
+			return err
+		}
+		if !x.IsUint64() || v.OverflowUint(x.Uint64()) {
+			return &StructuralError{v.Type(), errors.New("integer out of range")}
+		}
+		v.SetUint(x.Uint64())
+	case reflect.Float32, reflect.Float64:
+		return d.decodeBER(v, asn1.TagReal)
+	case reflect.String:
+		var s string
+		switch size, ok := charSizes[v.Type()]; {
+		case ok:
+			b, err := d.readOctets(size, params.Size, v.Type())
+			if err != nil {
+				return err
+			}
+			if size == 1 {
+				s = string(b)
+				break
+			}
+			rs := make([]rune, 0, len(b)/size)
+			for i := 0; i < len(b); i += size {
+				if size == 2 {
+					rs = append(rs, rune(binary.BigEndian.Uint16(b[i:])))
+				} else {
+					rs = append(rs, rune(binary.BigEndian.Uint32(b[i:])))
+				}
+			}
+			s = string(rs)
+		default:
+			b, err := d.readOctets(1, internal.Bounds{}, v.Type())
+			if err != nil {
+				return err
+			}
+			if !utf8.Valid(b) {
+				return &StructuralError{v.Type(), errors.New("invalid UTF-8 string")}
+			}
+			s = string(b)
+		}
+		v.SetString(s)
+		if vv, ok := v.Interface().(interface{ IsValid() bool }); ok && !vv.IsValid() {
+			return &StructuralError{v.Type(), errors.New("invalid characters")}
+		}
+	case reflect.Struct:
+		if err := d.decodeSequence(v); err != nil {
+			return err
+		}
+		if err := internal.Validate(v); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.readOctets(1, params.Size, v.Type())
+			if err != nil {
+				return err
+			}
+			if v.Kind() == reflect.Slice {
+				v.SetBytes(append([]byte{}, b...))
+			} else if len(b) != v.Len() {
+				return &StructuralError{v.Type(), errors.New("wrong number of bytes")}
+			} else {
+				reflect.Copy(v, reflect.ValueOf(b))
+			}
+			return nil
+		}
+		n, err := d.readQuantity(params.Size, v.Type())
+		if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		} else if n != v.Len() {
+			return &StructuralError{v.Type(), errors.New("wrong number of values")}
+		}
+		for i := range n {
+			if err = d.decode(v.Index(i), internal.FieldParameters{}); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &InvalidDecodeError{v}
+		}
+		n, err := d.readQuantity(params.Size, v.Type())
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		empty := reflect.ValueOf(struct{}{})
+		for range n {
+			elem := reflect.New(v.Type().Key()).Elem()
+			if err = d.decode(elem, internal.FieldParameters{}); err != nil {
+				return err
+			}
+			v.SetMapIndex(elem, empty)
+		}
+	default:
+		return &InvalidDecodeError{v}
+	}
+	return nil
+}
+
+// decodeBitString decodes a BIT STRING value into bs.
+func (d *decodeState) decodeBitString(bs *asn1.BitString, size internal.Bounds) error {
+	t := reflect.TypeFor[asn1.BitString]()
+	if size.Fixed() {
+		b, err := d.readBytes(int((size.Upper + 7) / 8))
+		if err != nil {
+			return err
+		}
+		*bs = asn1.BitString{Bytes: append([]byte{}, b...), BitLength: int(size.Upper)}
+		return nil
+	}
+	b, err := d.readOctets(1, internal.Bounds{}, t)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 || b[0] > 7 || (len(b) == 1 && b[0] != 0) {
+		return &SyntaxError{d.off - len(b), errors.New("invalid BIT STRING")}
+	}
+	n := 8*(len(b)-1) - int(b[0])
+	if !size.Contains(int64(n)) {
+		return &StructuralError{t, errSize}
+	}
+	*bs = asn1.BitString{Bytes: append([]byte{}, b[1:]...), BitLength: n}
+	return nil
+}
+
+// decodeSequence decodes the components of the struct v. Absent OPTIONAL
+// components are left unmodified. Extension additions are skipped.
+func (d *decodeState) decodeSequence(v reflect.Value) error {
+	type component struct {
+		value  reflect.Value
+		params internal.FieldParameters
+	}
+	var components []component
+	n := 0 // number of bits in the preamble
+	extensible := false
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			extensible = true
+			n++
+			continue
+		}
+		if params.Optional {
+			n++
+		}
+		components = append(components, component{field, params})
+	}
+	preamble, err := d.readBytes((n + 7) / 8)
+	if err != nil {
+		return err
+	}
+	bit := func(i int) bool { return preamble[i/8]&(0x80>>(i%8)) != 0 }
+	i := 0
+	if extensible {
+		i++
+	}
+	for _, c := range components {
+		if c.params.Optional {
+			i++
+			if !bit(i - 1) {
+				internal.SetAbsent(c.value)
+				continue
+			}
+		}
+		if err = d.decode(c.value, c.params); err != nil {
+			return err
+		}
+	}
+	if extensible && bit(0) {
+		return d.skipExtensions()
+	}
+	return nil
+}
+
+// skipExtensions skips over the extension additions of a SEQUENCE.
+func (d *decodeState) skipExtensions() error {
+	b, err := d.readOctets(1, internal.Bounds{}, nil)
+	if err != nil {
+		return err
+	}
+	if len(b) < 2 || b[0] > 7 {
+		return &SyntaxError{d.off - len(b), errors.New("invalid extension bitmap")}
+	}
+	for i := range 8*(len(b)-1) - int(b[0]) {
+		if b[1+i/8]&(0x80>>(i%8)) == 0 {
+			continue
+		}
+		if _, err = d.readOctets(1, internal.Bounds{}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeChoice decodes the CHOICE value v. All fields of the struct v except
+// the chosen alternative are set to their zero value.
+func (d *decodeState) decodeChoice(v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return &InvalidDecodeError{v}
+	}
+	tag, err := d.readTag()
+	if err != nil {
+		return err
+	}
+	i := 0
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			continue
+		}
+		if alternativeTag(i, params) == tag {
+			v.SetZero()
+			return d.decode(field, params)
+		}
+		i++
+	}
+	return &StructuralError{v.Type(), errors.New("unknown CHOICE alternative " + tag.String())}
+}
+
+// decodeBER decodes content octets of the BER encoding, preceded by a length
+// determinant, into v. The content octets are interpreted as an ASN.1 value
+// with the specified tag.
+func (d *decodeState) decodeBER(v reflect.Value, tag asn1.Tag) error {
+	b, err := d.readOctets(1, internal.Bounds{}, v.Type())
+	if err != nil {
+		return err
+	}
+	data := append(tlv.AppendHeader(nil, tlv.Header{Tag: tag, Length: len(b)}), b...)
+	if err = ber.Unmarshal(data, v.Addr().Interface()); err != nil {
+		return &StructuralError{v.Type(), err}
+	}
+	return nil
+}
+
+// readLength reads a length determinant.
+func (d *decodeState) readLength() (int, error) {
+	offset := d.off
+	b, err := d.ReadByte()
+	if err != nil || b < 0x80 {
+		return int(b), err
+	}
+	l, err := d.readBytes(int(b & 0x7f))
+	if err != nil {
+		return 0, err
+	}
+	if len(l) == 0 || len(l) > 8 {
+		return 0, &SyntaxError{offset, errors.New("invalid length")}
+	}
+	var n uint64
+	for _, c := range l {
+		n = n<<8 | uint64(c)
+	}
+	if n > uint64(len(d.data)-d.off) {
+		return 0, &SyntaxError{d.off, io.ErrUnexpectedEOF}
+	}
+	return int(n), nil
+}
+
+// readOctets reads octets preceded by a length determinant. If size specifies
+// a fixed size, no length determinant is read. The size of the value is its
+// length divided by unit. The type t is used for error reporting.
+func (d *decodeState) readOctets(unit int, size internal.Bounds, t reflect.Type) ([]byte, error) {
+	if size.Fixed() {
+		return d.readBytes(unit * int(size.Upper))
+	}
+	n, err := d.readLength()
+	if err != nil {
+		return nil, err
+	}
+	if n%unit != 0 {
+		return nil, &SyntaxError{d.off, errors.New("invalid length")}
+	}
+	if !size.Contains(int64(n / unit)) {
+		return nil, &StructuralError{t, errSize}
+	}
+	return d.readBytes(n)
+}
+
+// readQuantity reads the number of elements of a SEQUENCE OF or SET OF.
+func (d *decodeState) readQuantity(size internal.Bounds, t reflect.Type) (int, error) {
+	offset := d.off
+	x, err := d.readInteger(internal.Bounds{HasLower: true})
+	if err != nil {
+		return 0, err
+	}
+	// each element occupies at least one bit
+	if !x.IsInt64() || x.Int64() > 8*int64(len(d.data)) {
+		return 0, &SyntaxError{offset, errors.New("invalid quantity")}
+	}
+	if !size.Contains(x.Int64()) {
+		return 0, &StructuralError{t, errSize}
+	}
+	return int(x.Int64()), nil
+}
+
+// readTag reads the tag of a CHOICE alternative.
+func (d *decodeState) readTag() (asn1.Tag, error) {
+	b, err := d.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	class := asn1.Tag(b&0xc0) << 8
+	if b&0x3f != 0x3f {
+		return class | asn1.Tag(b&0x3f), nil
+	}
+	offset := d.off
+	n, err := vlq.Read[uint](d)
+	if err != nil || n > asn1.MaxTag {
+		return 0, &SyntaxError{offset, errors.New("invalid tag")}
+	}
+	return class | asn1.Tag(n), nil
+}
+
+// readIntegerOrEnumerated reads an INTEGER or ENUMERATED value depending on
+// the Go type t.
+func (d *decodeState) readIntegerOrEnumerated(t reflect.Type, r internal.Bounds) (*big.Int, error) {
+	if integerTypes[t] {
+		return d.readInteger(r)
+	}
+	b, err := d.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	x := big.NewInt(int64(b))
+	if b&0x80 != 0 {
+		bs, err := d.readBytes(int(b & 0x7f))
+		if err != nil {
+			return nil, err
+		}
+		if len(bs) == 0 {
+			return nil, &SyntaxError{d.off - 1, errors.New("invalid ENUMERATED")}
+		}
+		x = fromTwosComplement(bs)
+	}
+	if !x.IsInt64() || !r.Contains(x.Int64()) {
+		return nil, &StructuralError{t, errRange}
+	}
+	return x, nil
+}
+
+// readInteger reads an INTEGER value. See writeInteger for details.
+func (d *decodeState) readInteger(r internal.Bounds) (*big.Int, error) {
+	size, unsigned := integerSize(r)
+	var b []byte
+	var err error
+	if size > 0 {
+		b, err = d.readBytes(size)
+	} else {
+		b, err = d.readOctets(1, internal.Bounds{}, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, &SyntaxError{d.off, errors.New("empty integer")}
+	}
+	var x *big.Int
+	if unsigned {
+		x = new(big.Int).SetBytes(b)
+	} else {
+		x = fromTwosComplement(b)
+	}
+	if (r.HasLower && x.Cmp(big.NewInt(r.Lower)) < 0) || (r.HasUpper && x.Cmp(big.NewInt(r.Upper)) > 0) {
+		return nil, &StructuralError{nil, errRange}
+	}
+	return x, nil
+}
+
+// fromTwosComplement interprets b as a two's complement integer.
+func fromTwosComplement(b []byte) *big.Int {
+	x := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return x
+}
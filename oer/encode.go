@@ -0,0 +1,425 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+	"reflect"
+	"slices"
+	"unicode/utf8"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+	"codello.dev/asn1/tlv"
+	"codello.dev/asn1/vlq"
+)
+
+// Marshal returns the canonical OER encoding of val or an error if encoding
+// fails.
+func Marshal(val any) ([]byte, error) {
+	return MarshalWithParams(val, "")
+}
+
+// MarshalWithParams allows field parameters to be specified for the top-level
+// value. The form of the params is the same as the field tags.
+func MarshalWithParams(val any, params string) ([]byte, error) {
+	var e encodeState
+	if err := e.encode(reflect.ValueOf(val), internal.ParseFieldParameters(params)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// encodeState holds the output of an encoding process.
+type encodeState struct {
+	bytes.Buffer
+}
+
+var (
+	errRange = errors.New("value violates range constraint")
+	errSize  = errors.New("value violates size constraint")
+)
+
+// encode writes the OER encoding of v to e, using the constraints in params.
+func (e *encodeState) encode(v reflect.Value, params internal.FieldParameters) error {
+	if !v.IsValid() {
+		return &UnsupportedTypeError{Type: nil}
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		v = v.Elem()
+	}
+	if internal.IsOptional(v.Type()) {
+		return e.encode(v.Field(0), params)
+	}
+	if params.Choice {
+		return e.encodeChoice(v)
+	}
+
+	vif := v.Interface()
+	switch vv := vif.(type) {
+	case asn1.BitString:
+		if !vv.IsValid() {
+			return &EncodeError{v, errors.New("invalid BIT STRING")}
+		}
+		if !params.Size.Contains(int64(vv.BitLength)) {
+			return &EncodeError{v, errSize}
+		}
+		b := vv.Bytes[:(vv.BitLength+7)/8]
+		if !params.Size.Fixed() {
+			e.writeLength(len(b) + 1)
+			e.WriteByte(byte(len(b)*8 - vv.BitLength))
+		}
+		e.Write(b[:max(len(b)-1, 0)])
+		if len(b) > 0 {
+			// unused bits must be zero
+			e.WriteByte(b[len(b)-1] &^ (0xff >> (vv.BitLength - 8*(len(b)-1))))
+		}
+		return nil
+	case asn1.Null:
+		return nil
+	case asn1.ObjectIdentifier, asn1.RelativeOID, float32, float64, big.Float:
+		return e.encodeBER(v)
+	case big.Int:
+		if err := e.writeInteger(&vv, params.Range); err != nil {
+			return &EncodeError{v, err}
+		}
+		return nil
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		s, err := notation.Format(v, tag)
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		e.writeLength(len(s))
+		e.WriteString(s)
+		return nil
+	}
+	if vv, ok := vif.(encoding.BinaryMarshaler); ok {
+		b, err := vv.MarshalBinary()
+		if err == nil {
+			err = e.writeOctets(b, 1, params.Size)
+		}
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		return nil
+	}
+	if err := asn1.ValidateEnum(vif); err != nil {
+		return &EncodeError{v, err}
+	}
+	if vv, ok := vif.(interface{ IsValid() bool }); ok && !vv.IsValid() {
+		return &EncodeError{v, errors.New("invalid value")}
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.WriteByte(0xff)
+		} else {
+			e.WriteByte(0x00)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if integerTypes[v.Type()] {
+			err = e.writeInteger(big.NewInt(v.Int()), params.Range)
+		} else {
+			err = e.writeEnumerated(big.NewInt(v.Int()), params.Range)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if integerTypes[v.Type()] {
+			err = e.writeInteger(new(big.Int).SetUint64(v.Uint()), params.Range)
+		} else {
+			err = e.writeEnumerated(new(big.Int).SetUint64(v.Uint()), params.Range)
+		}
+	case reflect.Float32, reflect.Float64:
+		return e.encodeBER(v)
+	case reflect.String:
+		s := v.String()
+		switch size, ok := charSizes[v.Type()]; {
+		case ok && size == 1:
+			err = e.writeOctets([]byte(s), 1, params.Size)
+		case ok:
+			b := make([]byte, 0, size*len(s))
+			for _, r := range s {
+				if size == 2 {
+					b = binary.BigEndian.AppendUint16(b, uint16(r))
+				} else {
+					b = binary.BigEndian.AppendUint32(b, uint32(r))
+				}
+			}
+			err = e.writeOctets(b, size, params.Size)
+		case !utf8.ValidString(s):
+			err = errors.New("invalid UTF-8 string")
+		default:
+			e.writeLength(len(s))
+			e.WriteString(s)
+		}
+	case reflect.Struct:
+		return e.encodeSequence(v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			err = e.writeOctets(b, 1, params.Size)
+			break
+		}
+		if !params.Size.Contains(int64(v.Len())) {
+			err = errSize
+			break
+		}
+		e.writeQuantity(v.Len())
+		for i := range v.Len() {
+			if err := e.encode(v.Index(i), internal.FieldParameters{}); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		return e.encodeSet(v, params)
+	default:
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	return nil
+}
+
+// encodeSequence writes the components of the struct v. The encoding starts
+// with a bitmap indicating the presence of OPTIONAL components.
+func (e *encodeState) encodeSequence(v reflect.Value) error {
+	type component struct {
+		value   reflect.Value
+		params  internal.FieldParameters
+		present bool
+	}
+	var components []component
+	var preamble []bool
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			// the extension bit is the first bit of the preamble
+			preamble = slices.Insert(preamble, 0, false)
+			continue
+		}
+		present := !params.Optional || !(params.OmitZero && isZero(field) || params.OmitEmpty && internal.IsEmpty(field))
+		if params.Optional {
+			preamble = append(preamble, present)
+		}
+		components = append(components, component{field, params, present})
+	}
+	e.writeBits(preamble)
+	for _, c := range components {
+		if !c.present {
+			continue
+		}
+		if err := e.encode(c.value, c.params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeSet writes the elements of the set v. The elements are sorted by their
+// encoding to produce the canonical encoding.
+func (e *encodeState) encodeSet(v reflect.Value, params internal.FieldParameters) error {
+	if !params.Size.Contains(int64(v.Len())) {
+		return &EncodeError{v, errSize}
+	}
+	elems := make([][]byte, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		var es encodeState
+		if err := es.encode(key, internal.FieldParameters{}); err != nil {
+			return err
+		}
+		elems = append(elems, es.Bytes())
+	}
+	slices.SortFunc(elems, bytes.Compare)
+	e.writeQuantity(len(elems))
+	e.Write(slices.Concat(elems...))
+	return nil
+}
+
+// encodeChoice writes the CHOICE value v. Exactly one field of the struct v
+// must be non-zero. That field is the chosen alternative.
+func (e *encodeState) encodeChoice(v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	var (
+		tag    asn1.Tag
+		value  reflect.Value
+		params internal.FieldParameters
+		i      int
+	)
+	for field, fieldParams := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			continue
+		}
+		if !isZero(field) {
+			if value.IsValid() {
+				return &EncodeError{v, errors.New("multiple CHOICE alternatives present")}
+			}
+			tag, value, params = alternativeTag(i, fieldParams), field, fieldParams
+		}
+		i++
+	}
+	if !value.IsValid() {
+		return &EncodeError{v, errors.New("no CHOICE alternative present")}
+	}
+	e.writeTag(tag)
+	return e.encode(value, params)
+}
+
+// encodeBER writes the content octets of the BER encoding of v, preceded by a
+// length determinant.
+func (e *encodeState) encodeBER(v reflect.Value) error {
+	b, err := ber.Marshal(v.Interface())
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	_, n, err := tlv.ParseHeader(b)
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	e.writeLength(len(b) - n)
+	e.Write(b[n:])
+	return nil
+}
+
+// writeLength writes the length determinant n.
+func (e *encodeState) writeLength(n int) {
+	if n < 0x80 {
+		e.WriteByte(byte(n))
+		return
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(n))
+	l := (bits.Len(uint(n)) + 7) / 8
+	e.WriteByte(0x80 | byte(l))
+	e.Write(b[8-l:])
+}
+
+// writeQuantity writes the number of elements of a SEQUENCE OF or SET OF.
+func (e *encodeState) writeQuantity(n int) {
+	b := new(big.Int).SetUint64(uint64(n)).Bytes()
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	e.writeLength(len(b))
+	e.Write(b)
+}
+
+// writeOctets writes b, preceded by a length determinant unless the size is
+// fixed. The size of b is len(b)/unit.
+func (e *encodeState) writeOctets(b []byte, unit int, size internal.Bounds) error {
+	if !size.Contains(int64(len(b) / unit)) {
+		return errSize
+	}
+	if !size.Fixed() {
+		e.writeLength(len(b))
+	}
+	e.Write(b)
+	return nil
+}
+
+// writeBits writes the bits in bs, padded with zero bits to a multiple of eight.
+func (e *encodeState) writeBits(bs []bool) {
+	b := make([]byte, (len(bs)+7)/8)
+	for i, bit := range bs {
+		if bit {
+			b[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	e.Write(b)
+}
+
+// writeTag writes the tag t of a CHOICE alternative.
+func (e *encodeState) writeTag(t asn1.Tag) {
+	class := byte(t.Class() >> 8)
+	if t.Number() < 0x3f {
+		e.WriteByte(class | byte(t.Number()))
+		return
+	}
+	e.WriteByte(class | 0x3f)
+	_, _ = vlq.Write(e, t.Number())
+}
+
+// writeInteger writes the INTEGER value x. If r specifies a fixed size, x is
+// written using that number of octets. Otherwise x is written using the
+// minimal number of octets, preceded by a length determinant.
+func (e *encodeState) writeInteger(x *big.Int, r internal.Bounds) error {
+	if (r.HasLower && x.Cmp(big.NewInt(r.Lower)) < 0) || (r.HasUpper && x.Cmp(big.NewInt(r.Upper)) > 0) {
+		return errRange
+	}
+	size, unsigned := integerSize(r)
+	var b []byte
+	switch {
+	case size > 0 && unsigned:
+		b = binary.BigEndian.AppendUint64(nil, x.Uint64())
+	case size > 0:
+		b = binary.BigEndian.AppendUint64(nil, uint64(x.Int64()))
+	case unsigned:
+		if b = x.Bytes(); len(b) == 0 {
+			b = []byte{0}
+		}
+	default:
+		b = twosComplement(x)
+	}
+	if size > 0 {
+		e.Write(b[8-size:])
+	} else {
+		e.writeLength(len(b))
+		e.Write(b)
+	}
+	return nil
+}
+
+// writeEnumerated writes the ENUMERATED value x. Values between 0 and 127 are
+// written as a single octet.
+func (e *encodeState) writeEnumerated(x *big.Int, r internal.Bounds) error {
+	if (r.HasLower && x.Cmp(big.NewInt(r.Lower)) < 0) || (r.HasUpper && x.Cmp(big.NewInt(r.Upper)) > 0) {
+		return errRange
+	}
+	if x.Sign() >= 0 && x.Cmp(big.NewInt(0x7f)) <= 0 {
+		e.WriteByte(byte(x.Uint64()))
+		return nil
+	}
+	b := twosComplement(x)
+	e.WriteByte(0x80 | byte(len(b)))
+	e.Write(b)
+	return nil
+}
+
+// twosComplement returns the minimal two's complement representation of x.
+func twosComplement(x *big.Int) []byte {
+	if x.Sign() >= 0 {
+		b := x.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// -x-1 has the same bits as x, inverted
+	b := new(big.Int).Not(x).Bytes()
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	if len(b) == 0 || b[0]&0x80 == 0 {
+		b = append([]byte{0xff}, b...)
+	}
+	return b
+}
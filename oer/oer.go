@@ -0,0 +1,258 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package oer implements the canonical ASN.1 Octet Encoding Rules (OER). The
+// Octet Encoding Rules are defined in [Rec. ITU-T X.696].
+//
+// See the package documentation of the asn1 package for details how Go types
+// translate to ASN.1 types. Types following that specification can be encoded
+// into and decoded from OER using this package. The same struct types can be
+// used with the ber package.
+//
+// OER does not encode tags and makes use of the "range" and "size" constraints
+// of a type (see the asn1 package). Other than PER, all encodings use whole
+// octets. ASN.1 types are encoded as follows:
+//
+//   - BOOLEAN values are encoded as a single octet.
+//   - INTEGER values with a range constraint that fits into 1, 2, 4 or 8 octets
+//     are encoded using that fixed number of octets. Otherwise, INTEGER values
+//     are encoded using the minimal number of octets preceded by a length
+//     determinant.
+//   - ENUMERATED values between 0 and 127 are encoded as a single octet. Other
+//     values are encoded using the minimal number of octets preceded by their
+//     length.
+//   - REAL, OBJECT IDENTIFIER and RELATIVE-OID values use the content octets of
+//     their BER encoding, preceded by a length determinant.
+//   - BIT STRING, OCTET STRING and known-multiplier character string values
+//     are preceded by a length determinant unless their size is fixed by a size
+//     constraint. UTF8String values are always preceded by a length
+//     determinant.
+//   - Time types are encoded as a VisibleString containing their ASN.1 value
+//     notation.
+//   - SEQUENCE values start with a bitmap indicating which OPTIONAL components
+//     are present. A component is absent if it has the "optional" and the
+//     "omitzero" struct tags and its value is the zero value for its type.
+//   - SEQUENCE OF and SET OF values are preceded by the number of elements.
+//   - CHOICE values (see the "choice" struct tag) are encoded as the tag of the
+//     present alternative followed by the encoding of that alternative. If an
+//     alternative does not specify a tag via struct tags, the context-specific
+//     tag with the index of the alternative is used (corresponding to
+//     AUTOMATIC TAGS).
+//
+// An extensible SEQUENCE (see [codello.dev/asn1.Extensible]) uses the first bit
+// of its presence bitmap to indicate the presence of extension additions. When
+// decoding a SEQUENCE, unknown extension additions are skipped.
+//
+// The following limitations apply:
+//
+//   - Extensible constraints are not supported.
+//   - Decoding into an interface{} is not supported because the ASN.1 type
+//     cannot be determined from the encoding.
+//   - Decoding a CHOICE value that uses an unknown alternative results in an
+//     error.
+//
+// [Rec. ITU-T X.696]: https://www.itu.int/rec/T-REC-X.696
+package oer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+)
+
+//region error types
+
+// UnsupportedTypeError indicates that a value was passed to Marshal that cannot
+// be encoded to OER.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "cannot marshal nil value"
+	}
+	if e.Type.Kind() == reflect.Pointer {
+		return "cannot marshal nil pointer of type: " + e.Type.String()
+	} else if e.Type.Kind() == reflect.Interface {
+		return "cannot marshal nil interface of type: " + e.Type.String()
+	}
+	return "cannot marshal value of type " + e.Type.String() + ": unsupported Go type"
+}
+
+// EncodeError indicates that a value failed validation during encoding.
+type EncodeError struct {
+	Value reflect.Value
+	Err   error
+}
+
+func (e *EncodeError) Error() string {
+	var s strings.Builder
+	s.WriteString("encode error")
+	if e.Value.IsValid() {
+		s.WriteString(" for ")
+		s.WriteString(e.Value.Type().String())
+	}
+	s.WriteString(": ")
+	s.WriteString(e.Err.Error())
+	return s.String()
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidDecodeError indicates that an invalid value was passed to Unmarshal.
+// The invalid value might be nested within the passed value.
+type InvalidDecodeError struct {
+	Value reflect.Value
+}
+
+func (e *InvalidDecodeError) Error() string {
+	if !e.Value.IsValid() {
+		return "cannot decode into nil value"
+	}
+	if e.Value.Kind() == reflect.Pointer && e.Value.IsNil() {
+		return "cannot decode into nil pointer of type " + e.Value.Type().String()
+	} else if e.Value.Kind() != reflect.Pointer && !e.Value.CanAddr() {
+		return "cannot decode into non-pointer type " + e.Value.Type().String()
+	}
+	return "unsupported Go type: " + e.Value.Type().String()
+}
+
+// A SyntaxError indicates that the OER data is malformed. Because OER does not
+// use tags, an encoding that does not match the Go type may also result in a
+// SyntaxError.
+type SyntaxError struct {
+	Offset int // byte offset where the syntax error occurred
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	var s strings.Builder
+	s.WriteString("syntax error at offset ")
+	s.WriteString(strconv.Itoa(e.Offset))
+	if e.Err != nil {
+		s.WriteString(": ")
+		s.WriteString(e.Err.Error())
+	}
+	return s.String()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// A StructuralError suggests that the OER data is valid, but the Go type which
+// is receiving it doesn't match or can't fit the data.
+type StructuralError struct {
+	Type reflect.Type
+	Err  error
+}
+
+func (e *StructuralError) Error() string {
+	var s strings.Builder
+	s.WriteString("structural error")
+	if e.Type != nil {
+		s.WriteString(" decoding into ")
+		s.WriteString(e.Type.String())
+	}
+	if e.Err != nil {
+		s.WriteString(": ")
+		s.WriteString(e.Err.Error())
+	}
+	return s.String()
+}
+
+func (e *StructuralError) Unwrap() error {
+	return e.Err
+}
+
+//endregion
+
+//region types
+
+// integerTypes contains the Go types that correspond to the ASN.1 INTEGER type.
+// Any other type with an underlying integer type corresponds to the ENUMERATED
+// type.
+var integerTypes = map[reflect.Type]bool{
+	reflect.TypeFor[int]():    true,
+	reflect.TypeFor[int8]():   true,
+	reflect.TypeFor[int16]():  true,
+	reflect.TypeFor[int32]():  true,
+	reflect.TypeFor[int64]():  true,
+	reflect.TypeFor[uint]():   true,
+	reflect.TypeFor[uint8]():  true,
+	reflect.TypeFor[uint16](): true,
+	reflect.TypeFor[uint32](): true,
+	reflect.TypeFor[uint64](): true,
+}
+
+// charSizes contains the number of octets per character of the known-multiplier
+// character string types. Other string types are encoded as UTF8String.
+var charSizes = map[reflect.Type]int{
+	reflect.TypeFor[asn1.NumericString]():   1,
+	reflect.TypeFor[asn1.PrintableString](): 1,
+	reflect.TypeFor[asn1.IA5String]():       1,
+	reflect.TypeFor[asn1.VisibleString]():   1,
+	reflect.TypeFor[asn1.BMPString]():       2,
+	reflect.TypeFor[asn1.UniversalString](): 4,
+}
+
+// emptyStructType is used to identify the [asn1.Set] type.
+var emptyStructType = reflect.TypeFor[struct{}]()
+
+// isZero reports whether v is the zero value for its type. If v implements
+// IsZero() bool, that method is consulted.
+func isZero(v reflect.Value) bool {
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
+// integerSize returns the number of octets used to encode INTEGER values with
+// the range constraint r. If the values of r do not fit into 8 octets or r is
+// not bounded, size is 0. If r does not permit negative numbers, unsigned is
+// true.
+func integerSize(r internal.Bounds) (size int, unsigned bool) {
+	unsigned = r.HasLower && r.Lower >= 0
+	if !r.HasLower || !r.HasUpper {
+		return 0, unsigned
+	}
+	switch {
+	case unsigned && r.Upper <= 1<<8-1:
+		return 1, true
+	case unsigned && r.Upper <= 1<<16-1:
+		return 2, true
+	case unsigned && r.Upper <= 1<<32-1:
+		return 4, true
+	case unsigned:
+		return 8, true
+	case r.Lower >= -1<<7 && r.Upper <= 1<<7-1:
+		return 1, false
+	case r.Lower >= -1<<15 && r.Upper <= 1<<15-1:
+		return 2, false
+	case r.Lower >= -1<<31 && r.Upper <= 1<<31-1:
+		return 4, false
+	}
+	return 8, false
+}
+
+// alternativeTag returns the tag identifying the CHOICE alternative at index i
+// with the specified params.
+func alternativeTag(i int, params internal.FieldParameters) asn1.Tag {
+	if params.Tag != 0 {
+		return params.Tag
+	}
+	return asn1.ClassContextSpecific | asn1.Tag(i)
+}
+
+//endregion
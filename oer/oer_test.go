@@ -0,0 +1,312 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oer
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"codello.dev/asn1"
+)
+
+type testCase[T any] struct {
+	val     T
+	data    string // hex encoded
+	params  string
+	wantErr error
+}
+
+// otherError is used to indicate that any non-nil error is expected.
+var otherError = errors.New("other error")
+
+// testCodec runs marshal and unmarshal tests for the type T. Test cases in
+// common are run in both directions.
+func testCodec[T any](t *testing.T, common, marshal, unmarshal map[string]testCase[T]) {
+	t.Helper()
+	runMarshal := func(name string, tc testCase[T]) {
+		t.Run("Marshal/"+name, func(t *testing.T) {
+			got, err := MarshalWithParams(tc.val, tc.params)
+			checkError(t, "MarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && hex.EncodeToString(got) != strings.ToLower(tc.data) {
+				t.Errorf("MarshalWithParams() = %X, want %s", got, tc.data)
+			}
+		})
+	}
+	runUnmarshal := func(name string, tc testCase[T]) {
+		t.Run("Unmarshal/"+name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			if err != nil {
+				t.Fatalf("invalid test data: %v", err)
+			}
+			var got T
+			err = UnmarshalWithParams(data, &got, tc.params)
+			checkError(t, "UnmarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && !reflect.DeepEqual(got, tc.val) {
+				t.Errorf("UnmarshalWithParams() = %v, want %v", got, tc.val)
+			}
+		})
+	}
+	for name, tc := range common {
+		runMarshal(name, tc)
+		runUnmarshal(name, tc)
+	}
+	for name, tc := range marshal {
+		runMarshal(name, tc)
+	}
+	for name, tc := range unmarshal {
+		runUnmarshal(name, tc)
+	}
+}
+
+func checkError(t *testing.T, fn string, err, wantErr error) {
+	t.Helper()
+	if wantErr == nil && err != nil {
+		t.Fatalf("%s error = %v, want nil", fn, err)
+	}
+	if wantErr == otherError && err == nil {
+		t.Fatalf("%s error = nil, want non-nil", fn)
+	}
+	if wantErr != nil && wantErr != otherError {
+		if target := reflect.New(reflect.TypeOf(wantErr)); !errors.As(err, target.Interface()) {
+			t.Fatalf("%s error = %v, want %T", fn, err, wantErr)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	testCodec(t, map[string]testCase[bool]{
+		"True":  {val: true, data: "FF"},
+		"False": {val: false, data: "00"},
+	}, nil, map[string]testCase[bool]{
+		"Invalid":   {data: "01", wantErr: &SyntaxError{}},
+		"Empty":     {data: "", wantErr: &SyntaxError{}},
+		"ExtraData": {data: "FF00", wantErr: &SyntaxError{}},
+	})
+}
+
+func TestInteger(t *testing.T) {
+	testCodec(t, map[string]testCase[int]{
+		"Zero":       {val: 0, data: "0100"},
+		"Positive":   {val: 128, data: "020080"},
+		"Negative":   {val: -1, data: "01FF"},
+		"Uint8":      {val: 5, data: "05", params: "range:0..255"},
+		"Uint16":     {val: 300, data: "012C", params: "range:0..65535"},
+		"Uint64":     {val: 1, data: "0000000000000001", params: "range:0..4294967296"},
+		"Int8":       {val: -1, data: "FF", params: "range:-128..127"},
+		"Int16":      {val: -2, data: "FFFE", params: "range:-1000..1000"},
+		"Unsigned":   {val: 256, data: "020100", params: "range:0..MAX"},
+		"SemiSigned": {val: -5, data: "01FB", params: "range:-10..MAX"},
+		"UpperBound": {val: -200, data: "02FF38", params: "range:MIN..0"},
+	}, map[string]testCase[int]{
+		"TooLarge": {val: 256, params: "range:0..255", wantErr: &EncodeError{}},
+		"TooSmall": {val: -1, params: "range:0..MAX", wantErr: &EncodeError{}},
+	}, map[string]testCase[int]{
+		"OutOfRange": {data: "0B", params: "range:0..10", wantErr: &StructuralError{}},
+		"Truncated":  {data: "02FF", wantErr: &SyntaxError{}},
+		"Empty":      {data: "00", wantErr: &SyntaxError{}},
+	})
+	testCodec(t, map[string]testCase[uint8]{
+		"Max": {val: 255, data: "0200FF"},
+	}, nil, map[string]testCase[uint8]{
+		"Overflow": {data: "020100", wantErr: &StructuralError{}},
+		"Negative": {data: "01FF", wantErr: &StructuralError{}},
+	})
+	big1, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	testCodec(t, map[string]testCase[*big.Int]{
+		"Big": {val: big1, data: "0D018EE90FF6C373E0EE4E3F0AD2"},
+	}, nil, nil)
+}
+
+type color int
+
+func TestEnumerated(t *testing.T) {
+	testCodec(t, map[string]testCase[color]{
+		"Short":    {val: 2, data: "02"},
+		"Long":     {val: 200, data: "8200C8"},
+		"Negative": {val: -1, data: "81FF"},
+	}, map[string]testCase[color]{
+		"OutOfRange": {val: 3, params: "range:0..2", wantErr: &EncodeError{}},
+	}, map[string]testCase[color]{
+		"OutOfRange": {data: "03", params: "range:0..2", wantErr: &StructuralError{}},
+		"Empty":      {data: "80", wantErr: &SyntaxError{}},
+	})
+}
+
+func TestReal(t *testing.T) {
+	testCodec(t, map[string]testCase[float64]{
+		"Zero": {val: 0, data: "00"},
+		"One":  {val: 1, data: "03800001"},
+	}, nil, nil)
+}
+
+func TestBitString(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.BitString]{
+		"Empty":   {val: asn1.BitString{Bytes: []byte{}}, data: "0100"},
+		"Partial": {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, data: "0205A0"},
+		"Fixed":   {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, data: "A0", params: "size:3"},
+	}, map[string]testCase[asn1.BitString]{
+		"Invalid":   {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 9}, wantErr: &EncodeError{}},
+		"WrongSize": {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, params: "size:4", wantErr: &EncodeError{}},
+		"Unused":    {val: asn1.BitString{Bytes: []byte{0xFF}, BitLength: 3}, data: "0205E0"},
+	}, map[string]testCase[asn1.BitString]{
+		"InvalidUnused": {data: "0208FF", wantErr: &SyntaxError{}},
+		"TooLong":       {data: "0205A0", params: "size:0..2", wantErr: &StructuralError{}},
+	})
+}
+
+func TestOctetString(t *testing.T) {
+	testCodec(t, map[string]testCase[[]byte]{
+		"Empty": {val: []byte{}, data: "00"},
+		"Bytes": {val: []byte{0x41, 0x42}, data: "024142"},
+		"Fixed": {val: []byte{0x41, 0x42}, data: "4142", params: "size:2"},
+		"Long":  {val: make([]byte, 200), data: "81C8" + strings.Repeat("00", 200)},
+	}, nil, map[string]testCase[[]byte]{
+		"TooShort":      {data: "0141", params: "size:2..MAX", wantErr: &StructuralError{}},
+		"InvalidLength": {data: "80", wantErr: &SyntaxError{}},
+	})
+	testCodec(t, map[string]testCase[[2]byte]{
+		"Array": {val: [2]byte{0x41, 0x42}, data: "024142"},
+	}, nil, map[string]testCase[[2]byte]{
+		"WrongLength": {data: "0141", wantErr: &StructuralError{}},
+	})
+}
+
+func TestNull(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.Null]{
+		"Null": {val: asn1.Null{}, data: ""},
+	}, nil, nil)
+}
+
+func TestObjectIdentifier(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.ObjectIdentifier]{
+		"OID": {val: asn1.ObjectIdentifier{1, 2, 840}, data: "032A8648"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.RelativeOID]{
+		"RelativeOID": {val: asn1.RelativeOID{8571, 3, 2}, data: "04C27B0302"},
+	}, nil, nil)
+}
+
+func TestStrings(t *testing.T) {
+	testCodec(t, map[string]testCase[string]{
+		"UTF8": {val: "é", data: "02C3A9"},
+	}, nil, map[string]testCase[string]{
+		"Invalid": {data: "01FF", wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[asn1.IA5String]{
+		"IA5":   {val: "Hi", data: "024869"},
+		"Fixed": {val: "Hi", data: "4869", params: "size:2"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.BMPString]{
+		"BMP":   {val: "€", data: "0220AC"},
+		"Fixed": {val: "€", data: "20AC", params: "size:1"},
+	}, nil, map[string]testCase[asn1.BMPString]{
+		"OddLength": {data: "0120", wantErr: &SyntaxError{}},
+	})
+	testCodec(t, map[string]testCase[asn1.UniversalString]{
+		"Universal": {val: "€", data: "04000020AC"},
+	}, nil, nil)
+}
+
+func TestTime(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.GeneralizedTime]{
+		"GeneralizedTime": {
+			val:  asn1.GeneralizedTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+			data: "0F32303235303130323033303430355A",
+		},
+	}, nil, nil)
+}
+
+func TestSequence(t *testing.T) {
+	type seq struct {
+		A bool
+		B int  `asn1:"range:0..3"`
+		C *int `asn1:"optional,omitzero"`
+	}
+	five := 5
+	testCodec(t, map[string]testCase[seq]{
+		"Absent":  {val: seq{true, 2, nil}, data: "00FF02"},
+		"Present": {val: seq{true, 2, &five}, data: "80FF020105"},
+	}, nil, map[string]testCase[seq]{
+		"Truncated": {data: "80FF02", wantErr: &SyntaxError{}},
+	})
+
+	type extensible struct {
+		A bool
+		asn1.Extensible
+	}
+	testCodec(t, map[string]testCase[extensible]{
+		"Root": {val: extensible{A: true}, data: "00FF"},
+	}, nil, map[string]testCase[extensible]{
+		"Extension": {val: extensible{A: true}, data: "80FF02078001FF"},
+	})
+}
+
+func TestSequenceOf(t *testing.T) {
+	testCodec(t, map[string]testCase[[]bool]{
+		"Empty":  {val: []bool{}, data: "0100"},
+		"Values": {val: []bool{true, false}, data: "0102FF00"},
+	}, map[string]testCase[[]bool]{
+		"TooLong": {val: []bool{true, false, true}, params: "size:0..2", wantErr: &EncodeError{}},
+	}, nil)
+	testCodec(t, map[string]testCase[asn1.Set[int]]{
+		"Set": {val: asn1.NewSet(2, 1), data: "010201010102"},
+	}, nil, nil)
+}
+
+func TestChoice(t *testing.T) {
+	type choice struct {
+		A *int `asn1:"range:0..15"`
+		B *bool
+		C *int `asn1:"application,tag:5"`
+		D *int `asn1:"tag:100"`
+	}
+	one, yes := 1, true
+	testCodec(t, map[string]testCase[choice]{
+		"First":       {val: choice{A: &one}, data: "8001", params: "choice"},
+		"Second":      {val: choice{B: &yes}, data: "81FF", params: "choice"},
+		"Application": {val: choice{C: &one}, data: "450101", params: "choice"},
+		"LongTag":     {val: choice{D: &one}, data: "BF640101", params: "choice"},
+	}, map[string]testCase[choice]{
+		"None":     {val: choice{}, params: "choice", wantErr: &EncodeError{}},
+		"Multiple": {val: choice{A: &one, B: &yes}, params: "choice", wantErr: &EncodeError{}},
+	}, map[string]testCase[choice]{
+		"Unknown": {data: "8201", params: "choice", wantErr: &StructuralError{}},
+	})
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	if err := Unmarshal([]byte{0xff}, nil); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(nil) error = %v, want InvalidDecodeError", err)
+	}
+	if err := Unmarshal([]byte{0xff}, true); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(non-pointer) error = %v, want InvalidDecodeError", err)
+	}
+	var v any
+	if err := Unmarshal([]byte{0xff}, &v); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(interface) error = %v, want InvalidDecodeError", err)
+	}
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+	tests := map[string]any{
+		"Nil":     nil,
+		"NilPtr":  (*int)(nil),
+		"Map":     map[string]int{},
+		"Chan":    make(chan int),
+		"Complex": complex(1, 2),
+	}
+	for name, val := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Marshal(val); !errors.As(err, new(*UnsupportedTypeError)) {
+				t.Errorf("Marshal() error = %v, want UnsupportedTypeError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package per
+
+import (
+	"io"
+	"math/bits"
+)
+
+// bitWriter implements writing of individual bits. Bits are written starting
+// with the most significant bit of each byte.
+type bitWriter struct {
+	buf []byte
+	n   int // number of bits written
+}
+
+// writeBit writes a single bit.
+func (w *bitWriter) writeBit(b bool) {
+	if w.n%8 == 0 {
+		w.buf = append(w.buf, 0)
+	}
+	if b {
+		w.buf[w.n/8] |= 0x80 >> (w.n % 8)
+	}
+	w.n++
+}
+
+// writeBits writes the n least significant bits of v, starting with the most
+// significant one.
+func (w *bitWriter) writeBits(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.writeBit(v>>i&1 == 1)
+	}
+}
+
+// writeBitField writes the first n bits of b.
+func (w *bitWriter) writeBitField(b []byte, n int) {
+	if w.n%8 != 0 {
+		for i := range n {
+			w.writeBit(b[i/8]&(0x80>>(i%8)) != 0)
+		}
+		return
+	}
+	w.buf = append(w.buf, b[:(n+7)/8]...)
+	w.n += n
+	if n%8 != 0 {
+		w.buf[len(w.buf)-1] &^= 0xff >> (n % 8)
+	}
+}
+
+// writeBytes writes all bits of b.
+func (w *bitWriter) writeBytes(b []byte) {
+	w.writeBitField(b, 8*len(b))
+}
+
+// writeConstrained writes v as a constrained whole number in the range 0..r
+// using the minimal number of bits.
+func (w *bitWriter) writeConstrained(v, r uint64) {
+	w.writeBits(v, bits.Len64(r))
+}
+
+// bitReader implements reading of individual bits from a byte slice.
+type bitReader struct {
+	buf []byte
+	pos int // number of bits read
+}
+
+// error returns a SyntaxError at the current offset of r.
+func (r *bitReader) error(err error) error {
+	return &SyntaxError{Offset: r.pos, Err: err}
+}
+
+// readBit reads a single bit.
+func (r *bitReader) readBit() (bool, error) {
+	if r.pos >= 8*len(r.buf) {
+		return false, r.error(io.ErrUnexpectedEOF)
+	}
+	b := r.buf[r.pos/8]&(0x80>>(r.pos%8)) != 0
+	r.pos++
+	return b, nil
+}
+
+// readBits reads n bits as an unsigned integer. n must not be larger than 64.
+func (r *bitReader) readBits(n int) (uint64, error) {
+	if r.pos+n > 8*len(r.buf) {
+		return 0, r.error(io.ErrUnexpectedEOF)
+	}
+	var v uint64
+	for range n {
+		b, _ := r.readBit()
+		v <<= 1
+		if b {
+			v |= 1
+		}
+	}
+	return v, nil
+}
+
+// readBitField reads n bits into a new byte slice. Unused bits of the last byte
+// are set to zero.
+func (r *bitReader) readBitField(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > 8*len(r.buf) {
+		return nil, r.error(io.ErrUnexpectedEOF)
+	}
+	b := make([]byte, (n+7)/8)
+	if r.pos%8 == 0 {
+		copy(b, r.buf[r.pos/8:])
+		if n%8 != 0 {
+			b[len(b)-1] &^= 0xff >> (n % 8)
+		}
+		r.pos += n
+		return b, nil
+	}
+	for i := range n {
+		if bit, _ := r.readBit(); bit {
+			b[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return b, nil
+}
+
+// readBytes reads n bytes.
+func (r *bitReader) readBytes(n int) ([]byte, error) {
+	return r.readBitField(8 * n)
+}
+
+// readConstrained reads a constrained whole number in the range 0..rng. If the
+// value exceeds rng, an error is returned.
+func (r *bitReader) readConstrained(rng uint64) (uint64, error) {
+	offset := r.pos
+	v, err := r.readBits(bits.Len64(rng))
+	if err == nil && v > rng {
+		err = &SyntaxError{Offset: offset, Err: errConstrained}
+	}
+	return v, err
+}
@@ -0,0 +1,518 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package per
+
+import (
+	"encoding"
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"unicode/utf8"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+	"codello.dev/asn1/tlv"
+)
+
+// Unmarshal parses the unaligned PER-encoded data and stores the result in the
+// value pointed to by val. If val is nil or not a pointer, Unmarshal returns an
+// error.
+//
+// Malformed data is reported using a [SyntaxError]. If the data does not match
+// the Go type of val, a [StructuralError] is returned. Because PER does not
+// include type information in the encoding, data that does not match the Go
+// type of val may not always be detected.
+func Unmarshal(data []byte, val any) error {
+	return UnmarshalWithParams(data, val, "")
+}
+
+// UnmarshalWithParams allows field parameters to be specified for the top-level
+// value. The form of the params is the same as the field tags.
+func UnmarshalWithParams(data []byte, val any, params string) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return &InvalidDecodeError{v}
+	}
+	d := &decodeState{bitReader{buf: data}}
+	if err := d.decode(v.Elem(), internal.ParseFieldParameters(params)); err != nil {
+		return err
+	}
+	if n := max((d.pos+7)/8, 1); len(data) > n {
+		return &SyntaxError{Offset: 8 * n, Err: errors.New("extra data after top-level value")}
+	} else if len(data) < n {
+		return d.error(io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// decodeState holds the input of a decoding process.
+type decodeState struct {
+	bitReader
+}
+
+var errConstrained = errors.New("constrained value out of range")
+
+// decode decodes a value into v, using the constraints in params.
+func (d *decodeState) decode(v reflect.Value, params internal.FieldParameters) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.Kind() == reflect.Interface {
+			if e := v.Elem(); e.Kind() == reflect.Pointer && !e.IsNil() {
+				v = e
+				continue
+			}
+			return &InvalidDecodeError{v}
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if !v.CanSet() {
+		return &InvalidDecodeError{v}
+	}
+	if internal.IsOptional(v.Type()) {
+		if err := d.decode(v.Field(0), params); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+	if params.Choice {
+		return d.decodeChoice(v)
+	}
+
+	switch vp := v.Addr().Interface().(type) {
+	case *asn1.BitString:
+		n, err := d.readLength(params.Size, v.Type())
+		if err != nil {
+			return err
+		}
+		b, err := d.readBitField(n)
+		if err != nil {
+			return err
+		}
+		*vp = asn1.BitString{Bytes: b, BitLength: n}
+		return nil
+	case *asn1.Null:
+		return nil
+	case *asn1.ObjectIdentifier:
+		return d.decodeBER(v, asn1.TagOID)
+	case *asn1.RelativeOID:
+		return d.decodeBER(v, asn1.TagRelativeOID)
+	case *big.Float:
+		return d.decodeBER(v, asn1.TagReal)
+	case *big.Int:
+		x, err := d.readInteger(params.Range)
+		if err != nil {
+			return err
+		}
+		vp.Set(x)
+		return nil
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		s, err := d.readChars(visibleString, internal.Bounds{})
+		if err != nil {
+			return err
+		}
+		if err = notation.Parse(s, v, tag); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+		return nil
+	}
+	if vv, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+		b, err := d.readOctets(params.Size, v.Type())
+		if err != nil {
+			return err
+		}
+		return vv.UnmarshalBinary(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := d.readBit()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var x *big.Int
+		var err error
+		if integerTypes[v.Type()] {
+			x, err = d.readInteger(params.Range)
+		} else {
+			x, err = d.readEnum(v.Type(), params.Range)
+		}
+		if err != nil {
+			return err
+		}
+		if !x.IsInt64() || v.OverflowInt(x.Int64()) {
+			return &StructuralError{v.Type(), errors.New("integer too large")}
+		}
+		v.SetInt(x.Int64())
+		if err = asn1.ValidateEnum(v.Interface()); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var x *big.Int
+		var err error
+		if integerTypes[v.Type()] {
+			x, err = d.readInteger(params.Range)
+		} else {
+			x, err = d.readEnum(v.Type(), params.Range)
+		}
+		if err != nil {
+			return err
+		}
+		if !x.IsUint64() || v.OverflowUint(x.Uint64()) {
+			return &StructuralError{v.Type(), errors.New("integer out of range")}
+		}
+		v.SetUint(x.Uint64())
+	case reflect.Float32, reflect.Float64:
+		return d.decodeBER(v, asn1.TagReal)
+	case reflect.String:
+		var s string
+		if cs, ok := charSets[v.Type()]; ok {
+			var err error
+			if s, err = d.readChars(cs, params.Size); err != nil {
+				return err
+			}
+		} else {
+			b, err := d.readOctets(internal.Bounds{}, v.Type())
+			if err != nil {
+				return err
+			}
+			if !utf8.Valid(b) {
+				return &StructuralError{v.Type(), errors.New("invalid UTF-8 string")}
+			}
+			s = string(b)
+		}
+		v.SetString(s)
+		if vv, ok := v.Interface().(interface{ IsValid() bool }); ok && !vv.IsValid() {
+			return &StructuralError{v.Type(), errors.New("invalid characters")}
+		}
+	case reflect.Struct:
+		if err := d.decodeSequence(v); err != nil {
+			return err
+		}
+		if err := internal.Validate(v); err != nil {
+			return &StructuralError{v.Type(), err}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.readOctets(params.Size, v.Type())
+			if err != nil {
+				return err
+			}
+			if v.Kind() == reflect.Slice {
+				v.SetBytes(b)
+			} else if len(b) != v.Len() {
+				return &StructuralError{v.Type(), errors.New("wrong number of bytes")}
+			} else {
+				reflect.Copy(v, reflect.ValueOf(b))
+			}
+			return nil
+		}
+		n, err := d.readLength(params.Size, v.Type())
+		if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		} else if n != v.Len() {
+			return &StructuralError{v.Type(), errors.New("wrong number of values")}
+		}
+		for i := range n {
+			if err = d.decode(v.Index(i), internal.FieldParameters{}); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &InvalidDecodeError{v}
+		}
+		n, err := d.readLength(params.Size, v.Type())
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		empty := reflect.ValueOf(struct{}{})
+		for range n {
+			elem := reflect.New(v.Type().Key()).Elem()
+			if err = d.decode(elem, internal.FieldParameters{}); err != nil {
+				return err
+			}
+			v.SetMapIndex(elem, empty)
+		}
+	default:
+		return &InvalidDecodeError{v}
+	}
+	return nil
+}
+
+// decodeSequence decodes the components of the struct v. Absent OPTIONAL
+// components are left unmodified. Extension additions are skipped.
+func (d *decodeState) decodeSequence(v reflect.Value) error {
+	type component struct {
+		value  reflect.Value
+		params internal.FieldParameters
+	}
+	var components []component
+	extensible := false
+	optional := 0
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			extensible = true
+			continue
+		}
+		if params.Optional {
+			optional++
+		}
+		components = append(components, component{field, params})
+	}
+	extended := false
+	if extensible {
+		var err error
+		if extended, err = d.readBit(); err != nil {
+			return err
+		}
+	}
+	present := make([]bool, 0, optional)
+	for range optional {
+		b, err := d.readBit()
+		if err != nil {
+			return err
+		}
+		present = append(present, b)
+	}
+	for _, c := range components {
+		if c.params.Optional {
+			p := present[0]
+			present = present[1:]
+			if !p {
+				internal.SetAbsent(c.value)
+				continue
+			}
+		}
+		if err := d.decode(c.value, c.params); err != nil {
+			return err
+		}
+	}
+	if extended {
+		return d.skipExtensions()
+	}
+	return nil
+}
+
+// skipExtensions skips over the extension additions of a SEQUENCE.
+func (d *decodeState) skipExtensions() error {
+	n, err := d.readNormallySmall()
+	if err != nil {
+		return err
+	}
+	present := 0
+	for range n + 1 {
+		b, err := d.readBit()
+		if err != nil {
+			return err
+		}
+		if b {
+			present++
+		}
+	}
+	for range present {
+		if _, err = d.readOctets(internal.Bounds{}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeChoice decodes the CHOICE value v. All fields of the struct v except
+// the chosen alternative are set to their zero value.
+func (d *decodeState) decodeChoice(v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return &InvalidDecodeError{v}
+	}
+	type alternative struct {
+		value  reflect.Value
+		params internal.FieldParameters
+	}
+	var alternatives []alternative
+	extensible := false
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			extensible = true
+			continue
+		}
+		alternatives = append(alternatives, alternative{field, params})
+	}
+	if len(alternatives) == 0 {
+		return &InvalidDecodeError{v}
+	}
+	if extensible {
+		if extended, err := d.readBit(); err != nil {
+			return err
+		} else if extended {
+			return &StructuralError{v.Type(), errors.New("unknown CHOICE alternative")}
+		}
+	}
+	i, err := d.readConstrained(uint64(len(alternatives) - 1))
+	if err != nil {
+		return err
+	}
+	v.SetZero()
+	return d.decode(alternatives[i].value, alternatives[i].params)
+}
+
+// decodeBER decodes content octets of the BER encoding, preceded by a length
+// determinant, into v. The content octets are interpreted as an ASN.1 value
+// with the specified tag.
+func (d *decodeState) decodeBER(v reflect.Value, tag asn1.Tag) error {
+	b, err := d.readOctets(internal.Bounds{}, v.Type())
+	if err != nil {
+		return err
+	}
+	data := append(tlv.AppendHeader(nil, tlv.Header{Tag: tag, Length: len(b)}), b...)
+	if err = ber.Unmarshal(data, v.Addr().Interface()); err != nil {
+		return &StructuralError{v.Type(), err}
+	}
+	return nil
+}
+
+// readLength reads a length determinant. See writeLength for details. The type
+// t is used for error reporting.
+func (d *decodeState) readLength(size internal.Bounds, t reflect.Type) (int, error) {
+	if size.HasUpper && size.Upper < 1<<16 {
+		n, err := d.readConstrained(uint64(size.Upper - size.Lower))
+		return int(size.Lower) + int(n), err
+	}
+	offset := d.pos
+	n, err := d.readBits(8)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case n&0x80 == 0:
+	case n&0xc0 == 0x80:
+		m, err := d.readBits(8)
+		if err != nil {
+			return 0, err
+		}
+		n = (n&0x3f)<<8 | m
+	default:
+		return 0, &SyntaxError{offset, errors.New("fragmented encodings are not supported")}
+	}
+	if !size.Contains(int64(n)) {
+		return 0, &StructuralError{t, errSize}
+	}
+	return int(n), nil
+}
+
+// readNormallySmall reads a normally small non-negative whole number.
+func (d *decodeState) readNormallySmall() (int, error) {
+	large, err := d.readBit()
+	if err != nil {
+		return 0, err
+	}
+	if !large {
+		n, err := d.readBits(6)
+		return int(n), err
+	}
+	x, err := d.readInteger(internal.Bounds{HasLower: true})
+	if err != nil {
+		return 0, err
+	}
+	if !x.IsInt64() || x.Int64() >= 1<<14 {
+		return 0, d.error(errors.New("normally small number too large"))
+	}
+	return int(x.Int64()), nil
+}
+
+// readOctets reads octets preceded by a length determinant.
+func (d *decodeState) readOctets(size internal.Bounds, t reflect.Type) ([]byte, error) {
+	n, err := d.readLength(size, t)
+	if err != nil {
+		return nil, err
+	}
+	return d.readBytes(n)
+}
+
+// readChars reads a character string using the known-multiplier character set
+// cs.
+func (d *decodeState) readChars(cs charSet, size internal.Bounds) (string, error) {
+	n, err := d.readLength(size, nil)
+	if err != nil {
+		return "", err
+	}
+	rs := make([]rune, 0, n)
+	for range n {
+		offset := d.pos
+		c, err := d.readBits(cs.bits)
+		if err != nil {
+			return "", err
+		}
+		if cs.alphabet != "" {
+			if c >= uint64(len(cs.alphabet)) {
+				return "", &SyntaxError{offset, errors.New("invalid character")}
+			}
+			c = uint64(cs.alphabet[c])
+		}
+		rs = append(rs, rune(c))
+	}
+	return string(rs), nil
+}
+
+// readEnum reads an ENUMERATED value of type t. See writeEnum for details.
+func (d *decodeState) readEnum(t reflect.Type, r internal.Bounds) (*big.Int, error) {
+	vs, err := enumValues(t, r)
+	if err != nil {
+		return nil, &StructuralError{t, err}
+	}
+	if vs == nil {
+		return d.readInteger(r)
+	}
+	if len(vs) == 0 {
+		return nil, &StructuralError{t, errors.New("ENUMERATED type without values")}
+	}
+	i, err := d.readConstrained(uint64(len(vs) - 1))
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(vs[i]), nil
+}
+
+// readInteger reads an INTEGER value. See writeInteger for details.
+func (d *decodeState) readInteger(r internal.Bounds) (*big.Int, error) {
+	if r.HasLower && r.HasUpper {
+		n, err := d.readConstrained(uint64(r.Upper) - uint64(r.Lower))
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).Add(big.NewInt(r.Lower), new(big.Int).SetUint64(n)), nil
+	}
+	offset := d.pos
+	b, err := d.readOctets(internal.Bounds{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, &SyntaxError{offset, errors.New("empty integer")}
+	}
+	x := new(big.Int)
+	if r.HasLower {
+		return x.Add(x.SetBytes(b), big.NewInt(r.Lower)), nil
+	}
+	x.SetBytes(b)
+	if b[0]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	if r.HasUpper && x.Cmp(big.NewInt(r.Upper)) > 0 {
+		return nil, &StructuralError{nil, errRange}
+	}
+	return x, nil
+}
@@ -0,0 +1,424 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package per
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"errors"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+	"codello.dev/asn1/tlv"
+)
+
+// Marshal returns the unaligned PER encoding of val or an error if encoding
+// fails.
+func Marshal(val any) ([]byte, error) {
+	return MarshalWithParams(val, "")
+}
+
+// MarshalWithParams allows field parameters to be specified for the top-level
+// value. The form of the params is the same as the field tags.
+func MarshalWithParams(val any, params string) ([]byte, error) {
+	var e encodeState
+	if err := e.encode(reflect.ValueOf(val), internal.ParseFieldParameters(params)); err != nil {
+		return nil, err
+	}
+	if e.n == 0 {
+		// an empty encoding is represented by a single zero octet
+		return []byte{0}, nil
+	}
+	return e.buf, nil
+}
+
+// encodeState holds the bits of an encoding process.
+type encodeState struct {
+	bitWriter
+}
+
+// charSet describes the encoding of a known-multiplier character string type.
+type charSet struct {
+	bits     int    // number of bits per character
+	alphabet string // if non-empty, characters are encoded by their index
+}
+
+// charSets contains the character string types that use a fixed number of bits
+// per character.
+var charSets = map[reflect.Type]charSet{
+	reflect.TypeFor[asn1.NumericString]():   {4, " 0123456789"},
+	reflect.TypeFor[asn1.PrintableString](): {7, ""},
+	reflect.TypeFor[asn1.IA5String]():       {7, ""},
+	reflect.TypeFor[asn1.VisibleString]():   {7, ""},
+	reflect.TypeFor[asn1.BMPString]():       {16, ""},
+	reflect.TypeFor[asn1.UniversalString](): {32, ""},
+}
+
+// visibleString is the character set used for time types.
+var visibleString = charSets[reflect.TypeFor[asn1.VisibleString]()]
+
+var (
+	errRange = errors.New("value violates range constraint")
+	errSize  = errors.New("value violates size constraint")
+	errEnum  = errors.New("ENUMERATED type requires an asn1.EnumDef or a range constraint")
+)
+
+// encode writes the PER encoding of v to e, using the constraints in params.
+func (e *encodeState) encode(v reflect.Value, params internal.FieldParameters) error {
+	if !v.IsValid() {
+		return &UnsupportedTypeError{Type: nil}
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		v = v.Elem()
+	}
+	if internal.IsOptional(v.Type()) {
+		return e.encode(v.Field(0), params)
+	}
+	if params.Choice {
+		return e.encodeChoice(v)
+	}
+
+	vif := v.Interface()
+	switch vv := vif.(type) {
+	case asn1.BitString:
+		if !vv.IsValid() {
+			return &EncodeError{v, errors.New("invalid BIT STRING")}
+		}
+		if err := e.writeLength(vv.BitLength, params.Size); err != nil {
+			return &EncodeError{v, err}
+		}
+		e.writeBitField(vv.Bytes, vv.BitLength)
+		return nil
+	case asn1.Null:
+		return nil
+	case asn1.ObjectIdentifier, asn1.RelativeOID, float32, float64, big.Float:
+		return e.encodeBER(v)
+	case big.Int:
+		if err := e.writeInteger(&vv, params.Range); err != nil {
+			return &EncodeError{v, err}
+		}
+		return nil
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		s, err := notation.Format(v, tag)
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		if err = e.writeChars(s, visibleString, internal.Bounds{}); err != nil {
+			return &EncodeError{v, err}
+		}
+		return nil
+	}
+	if vv, ok := vif.(encoding.BinaryMarshaler); ok {
+		b, err := vv.MarshalBinary()
+		if err == nil {
+			err = e.writeOctets(b, params.Size)
+		}
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		return nil
+	}
+	if err := asn1.ValidateEnum(vif); err != nil {
+		return &EncodeError{v, err}
+	}
+	if vv, ok := vif.(interface{ IsValid() bool }); ok && !vv.IsValid() {
+		return &EncodeError{v, errors.New("invalid value")}
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.Bool:
+		e.writeBit(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if integerTypes[v.Type()] {
+			err = e.writeInteger(big.NewInt(v.Int()), params.Range)
+		} else {
+			err = e.writeEnum(big.NewInt(v.Int()), v.Type(), params.Range)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if integerTypes[v.Type()] {
+			err = e.writeInteger(new(big.Int).SetUint64(v.Uint()), params.Range)
+		} else {
+			err = e.writeEnum(new(big.Int).SetUint64(v.Uint()), v.Type(), params.Range)
+		}
+	case reflect.Float32, reflect.Float64:
+		return e.encodeBER(v)
+	case reflect.String:
+		if cs, ok := charSets[v.Type()]; ok {
+			err = e.writeChars(v.String(), cs, params.Size)
+		} else if !utf8.ValidString(v.String()) {
+			err = errors.New("invalid UTF-8 string")
+		} else {
+			err = e.writeOctets([]byte(v.String()), internal.Bounds{})
+		}
+	case reflect.Struct:
+		return e.encodeSequence(v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			err = e.writeOctets(b, params.Size)
+			break
+		}
+		if err = e.writeLength(v.Len(), params.Size); err != nil {
+			break
+		}
+		for i := range v.Len() {
+			if err := e.encode(v.Index(i), internal.FieldParameters{}); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		return e.encodeSet(v, params)
+	default:
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	return nil
+}
+
+// encodeSequence writes the components of the struct v. The encoding starts
+// with a bitmap indicating the presence of OPTIONAL components.
+func (e *encodeState) encodeSequence(v reflect.Value) error {
+	type component struct {
+		value   reflect.Value
+		params  internal.FieldParameters
+		present bool
+	}
+	var components []component
+	extensible := false
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			extensible = true
+			continue
+		}
+		present := !params.Optional || !(params.OmitZero && isZero(field) || params.OmitEmpty && internal.IsEmpty(field))
+		components = append(components, component{field, params, present})
+	}
+	if extensible {
+		e.writeBit(false)
+	}
+	for _, c := range components {
+		if c.params.Optional {
+			e.writeBit(c.present)
+		}
+	}
+	for _, c := range components {
+		if !c.present {
+			continue
+		}
+		if err := e.encode(c.value, c.params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeSet writes the elements of the set v. The elements are sorted by their
+// encoding to produce a deterministic output.
+func (e *encodeState) encodeSet(v reflect.Value, params internal.FieldParameters) error {
+	if err := e.writeLength(v.Len(), params.Size); err != nil {
+		return &EncodeError{v, err}
+	}
+	elems := make([]bitWriter, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		var es encodeState
+		if err := es.encode(key, internal.FieldParameters{}); err != nil {
+			return err
+		}
+		elems = append(elems, es.bitWriter)
+	}
+	slices.SortFunc(elems, func(a, b bitWriter) int {
+		return cmp.Or(bytes.Compare(a.buf, b.buf), cmp.Compare(a.n, b.n))
+	})
+	for _, elem := range elems {
+		e.writeBitField(elem.buf, elem.n)
+	}
+	return nil
+}
+
+// encodeChoice writes the CHOICE value v. Exactly one field of the struct v
+// must be non-zero. That field is the chosen alternative.
+func (e *encodeState) encodeChoice(v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	var (
+		index      = -1
+		n          int
+		value      reflect.Value
+		params     internal.FieldParameters
+		extensible bool
+	)
+	for field, fieldParams := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			extensible = true
+			continue
+		}
+		if !isZero(field) {
+			if index >= 0 {
+				return &EncodeError{v, errors.New("multiple CHOICE alternatives present")}
+			}
+			index, value, params = n, field, fieldParams
+		}
+		n++
+	}
+	if index < 0 {
+		return &EncodeError{v, errors.New("no CHOICE alternative present")}
+	}
+	if extensible {
+		e.writeBit(false)
+	}
+	e.writeConstrained(uint64(index), uint64(n-1))
+	return e.encode(value, params)
+}
+
+// encodeBER writes the content octets of the BER encoding of v, preceded by a
+// length determinant.
+func (e *encodeState) encodeBER(v reflect.Value) error {
+	b, err := ber.Marshal(v.Interface())
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	_, n, err := tlv.ParseHeader(b)
+	if err == nil {
+		err = e.writeOctets(b[n:], internal.Bounds{})
+	}
+	if err != nil {
+		return &EncodeError{v, err}
+	}
+	return nil
+}
+
+// writeLength writes the length determinant n. If size has an upper bound
+// below 64K, the length is encoded as a constrained whole number. If size
+// specifies a fixed length, nothing is written.
+func (e *encodeState) writeLength(n int, size internal.Bounds) error {
+	if !size.Contains(int64(n)) {
+		return errSize
+	}
+	if size.HasUpper && size.Upper < 1<<16 {
+		e.writeConstrained(uint64(int64(n)-size.Lower), uint64(size.Upper-size.Lower))
+		return nil
+	}
+	switch {
+	case n < 1<<7:
+		e.writeBits(uint64(n), 8)
+	case n < 1<<14:
+		e.writeBits(uint64(n)|0x8000, 16)
+	default:
+		return errors.New("length determinants of 16K or more are not supported")
+	}
+	return nil
+}
+
+// writeOctets writes b, preceded by its length determinant.
+func (e *encodeState) writeOctets(b []byte, size internal.Bounds) error {
+	if err := e.writeLength(len(b), size); err != nil {
+		return err
+	}
+	e.writeBytes(b)
+	return nil
+}
+
+// writeChars writes s using the known-multiplier character set cs, preceded by
+// its length determinant.
+func (e *encodeState) writeChars(s string, cs charSet, size internal.Bounds) error {
+	if err := e.writeLength(utf8.RuneCountInString(s), size); err != nil {
+		return err
+	}
+	for _, r := range s {
+		v := uint64(r)
+		if cs.alphabet != "" {
+			v = uint64(strings.IndexRune(cs.alphabet, r))
+		}
+		e.writeBits(v, cs.bits)
+	}
+	return nil
+}
+
+// writeInteger writes the INTEGER value x. If both bounds of r are known, x is
+// encoded as a constrained whole number. Otherwise x is encoded using the
+// minimal number of octets, preceded by a length determinant.
+func (e *encodeState) writeInteger(x *big.Int, r internal.Bounds) error {
+	if (r.HasLower && x.Cmp(big.NewInt(r.Lower)) < 0) || (r.HasUpper && x.Cmp(big.NewInt(r.Upper)) > 0) {
+		return errRange
+	}
+	var b []byte
+	switch {
+	case r.HasLower && r.HasUpper:
+		offset := new(big.Int).Sub(x, big.NewInt(r.Lower))
+		e.writeConstrained(offset.Uint64(), uint64(r.Upper)-uint64(r.Lower))
+		return nil
+	case r.HasLower:
+		b = new(big.Int).Sub(x, big.NewInt(r.Lower)).Bytes()
+		if len(b) == 0 {
+			b = []byte{0}
+		}
+	default:
+		b = twosComplement(x)
+	}
+	return e.writeOctets(b, internal.Bounds{})
+}
+
+// writeEnum writes the ENUMERATED value x of type t as a constrained whole
+// number holding the index of x in the values of t. See enumValues for
+// details. If all values within the range constraint r are valid, x is encoded
+// like a constrained INTEGER value.
+func (e *encodeState) writeEnum(x *big.Int, t reflect.Type, r internal.Bounds) error {
+	vs, err := enumValues(t, r)
+	if err != nil {
+		return err
+	}
+	if vs == nil {
+		return e.writeInteger(x, r)
+	}
+	if !x.IsInt64() {
+		return errRange
+	}
+	i, ok := slices.BinarySearch(vs, x.Int64())
+	if !ok {
+		return errRange
+	}
+	e.writeConstrained(uint64(i), uint64(len(vs)-1))
+	return nil
+}
+
+// twosComplement returns the minimal two's complement representation of x.
+func twosComplement(x *big.Int) []byte {
+	if x.Sign() >= 0 {
+		b := x.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// -x-1 has the same bits as x, inverted
+	b := new(big.Int).Not(x).Bytes()
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	if len(b) == 0 || b[0]&0x80 == 0 {
+		b = append([]byte{0xff}, b...)
+	}
+	return b
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package per implements the unaligned variant of the ASN.1 Packed Encoding
+// Rules (PER). The Packed Encoding Rules are defined in [Rec. ITU-T X.691].
+//
+// See the package documentation of the asn1 package for details how Go types
+// translate to ASN.1 types. Types following that specification can be encoded
+// into and decoded from PER using this package. The same struct types can be
+// used with the ber package.
+//
+// PER produces a compact encoding by omitting tags and by making use of
+// PER-visible constraints. Constraints are specified using the "range" and
+// "size" struct tags (see the asn1 package). ASN.1 types are encoded as
+// follows:
+//
+//   - BOOLEAN values are encoded as a single bit.
+//   - INTEGER values are encoded using the minimal number of bits if both
+//     bounds of their range constraint are known. Otherwise, the value is
+//     encoded using the minimal number of octets preceded by a length
+//     determinant.
+//   - ENUMERATED values are encoded as the index of the value within the sorted
+//     values of the enumeration. The values are taken from the [asn1.EnumDef]
+//     of the type. Types without an EnumDef require a range constraint. Values
+//     within the range for which the IsValid method of the type reports false
+//     are not part of the enumeration.
+//   - REAL, OBJECT IDENTIFIER and RELATIVE-OID values use the content octets of
+//     their BER encoding, preceded by a length determinant.
+//   - BIT STRING, OCTET STRING, SEQUENCE OF and SET OF values are preceded by a
+//     length determinant unless their size is fixed by a size constraint.
+//   - NumericString, PrintableString, IA5String, VisibleString, BMPString and
+//     UniversalString values use a fixed number of bits per character.
+//     UTF8String values are encoded as octets.
+//   - Time types are encoded as a VisibleString containing their ASN.1 value
+//     notation.
+//   - SEQUENCE values start with a bitmap indicating which OPTIONAL components
+//     are present. A component is absent if it has the "optional" and the
+//     "omitzero" struct tags and its value is the zero value for its type.
+//   - CHOICE values (see the "choice" struct tag) are encoded as the index of
+//     the present alternative followed by the encoding of that alternative.
+//
+// An extensible SEQUENCE or CHOICE (see [codello.dev/asn1.Extensible]) is
+// preceded by a single bit indicating the presence of extension additions.
+// When decoding a SEQUENCE, unknown extension additions are skipped. The
+// complete encoding of a value is padded with zero bits to a multiple of eight
+// bits.
+//
+// The following limitations apply:
+//
+//   - Length determinants of 16384 or more are not supported. Such lengths would
+//     require fragmentation of the encoding.
+//   - Extensible constraints are not supported.
+//   - Permitted alphabet constraints are not supported.
+//   - Decoding into an interface{} is not supported because the ASN.1 type
+//     cannot be determined from the encoding.
+//   - Decoding a CHOICE value that uses an unknown extension alternative
+//     results in an error.
+//
+// [Rec. ITU-T X.691]: https://www.itu.int/rec/T-REC-X.691
+package per
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+)
+
+//region error types
+
+// UnsupportedTypeError indicates that a value was passed to Marshal that cannot
+// be encoded to PER.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "cannot marshal nil value"
+	}
+	if e.Type.Kind() == reflect.Pointer {
+		return "cannot marshal nil pointer of type: " + e.Type.String()
+	} else if e.Type.Kind() == reflect.Interface {
+		return "cannot marshal nil interface of type: " + e.Type.String()
+	}
+	return "cannot marshal value of type " + e.Type.String() + ": unsupported Go type"
+}
+
+// EncodeError indicates that a value failed validation during encoding.
+type EncodeError struct {
+	Value reflect.Value
+	Err   error
+}
+
+func (e *EncodeError) Error() string {
+	var s strings.Builder
+	s.WriteString("encode error")
+	if e.Value.IsValid() {
+		s.WriteString(" for ")
+		s.WriteString(e.Value.Type().String())
+	}
+	s.WriteString(": ")
+	s.WriteString(e.Err.Error())
+	return s.String()
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidDecodeError indicates that an invalid value was passed to Unmarshal.
+// The invalid value might be nested within the passed value.
+type InvalidDecodeError struct {
+	Value reflect.Value
+}
+
+func (e *InvalidDecodeError) Error() string {
+	if !e.Value.IsValid() {
+		return "cannot decode into nil value"
+	}
+	if e.Value.Kind() == reflect.Pointer && e.Value.IsNil() {
+		return "cannot decode into nil pointer of type " + e.Value.Type().String()
+	} else if e.Value.Kind() != reflect.Pointer && !e.Value.CanAddr() {
+		return "cannot decode into non-pointer type " + e.Value.Type().String()
+	}
+	return "unsupported Go type: " + e.Value.Type().String()
+}
+
+// A SyntaxError indicates that the PER data is malformed. Because PER does not
+// use tags, an encoding that does not match the Go type may also result in a
+// SyntaxError.
+type SyntaxError struct {
+	Offset int // bit offset where the syntax error occurred
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	var s strings.Builder
+	s.WriteString("syntax error at bit offset ")
+	s.WriteString(strconv.Itoa(e.Offset))
+	if e.Err != nil {
+		s.WriteString(": ")
+		s.WriteString(e.Err.Error())
+	}
+	return s.String()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// A StructuralError suggests that the PER data is valid, but the Go type which
+// is receiving it doesn't match or can't fit the data.
+type StructuralError struct {
+	Type reflect.Type
+	Err  error
+}
+
+func (e *StructuralError) Error() string {
+	var s strings.Builder
+	s.WriteString("structural error")
+	if e.Type != nil {
+		s.WriteString(" decoding into ")
+		s.WriteString(e.Type.String())
+	}
+	if e.Err != nil {
+		s.WriteString(": ")
+		s.WriteString(e.Err.Error())
+	}
+	return s.String()
+}
+
+func (e *StructuralError) Unwrap() error {
+	return e.Err
+}
+
+//endregion
+
+//region types
+
+// integerTypes contains the Go types that correspond to the ASN.1 INTEGER type.
+// Any other type with an underlying integer type corresponds to the ENUMERATED
+// type.
+var integerTypes = map[reflect.Type]bool{
+	reflect.TypeFor[int]():    true,
+	reflect.TypeFor[int8]():   true,
+	reflect.TypeFor[int16]():  true,
+	reflect.TypeFor[int32]():  true,
+	reflect.TypeFor[int64]():  true,
+	reflect.TypeFor[uint]():   true,
+	reflect.TypeFor[uint8]():  true,
+	reflect.TypeFor[uint16](): true,
+	reflect.TypeFor[uint32](): true,
+	reflect.TypeFor[uint64](): true,
+}
+
+// emptyStructType is used to identify the [asn1.Set] type.
+var emptyStructType = reflect.TypeFor[struct{}]()
+
+// isZero reports whether v is the zero value for its type. If v implements
+// IsZero() bool, that method is consulted.
+func isZero(v reflect.Value) bool {
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
+// validType is the type of the IsValid method of enum types.
+var validType = reflect.TypeFor[interface{ IsValid() bool }]()
+
+// maxEnumRange limits the number of values of an ENUMERATED type that are
+// checked using the IsValid method of the type.
+const maxEnumRange = 1 << 16
+
+var errEnumRange = errors.New("range constraint of ENUMERATED type too large")
+
+// enumValues returns the values of the ENUMERATED type t in ascending order.
+// PER encodes an ENUMERATED value by its index in these values (see Section 14
+// of Rec. ITU-T X.691). The values are taken from the [asn1.EnumDef] of t.
+// Otherwise, the values are the values within the range constraint r for which
+// the IsValid method of t reports true. If t has neither an EnumDef nor an
+// IsValid method, all values within r are valid and enumValues returns nil.
+func enumValues(t reflect.Type, r internal.Bounds) ([]int64, error) {
+	if vs := asn1.EnumValues(t); vs != nil {
+		ret := make([]int64, len(vs))
+		for i, v := range vs {
+			ret[i] = int64(v)
+		}
+		return ret, nil
+	}
+	if !r.HasLower || !r.HasUpper {
+		return nil, errEnum
+	}
+	if !t.Implements(validType) {
+		return nil, nil
+	}
+	if uint64(r.Upper)-uint64(r.Lower) >= maxEnumRange {
+		return nil, errEnumRange
+	}
+	var ret []int64
+	x := reflect.New(t).Elem()
+	for n := r.Lower; ; n++ {
+		if x.CanInt() {
+			x.SetInt(n)
+		} else {
+			x.SetUint(uint64(n))
+		}
+		if x.Interface().(interface{ IsValid() bool }).IsValid() {
+			ret = append(ret, n)
+		}
+		if n == r.Upper {
+			return ret, nil
+		}
+	}
+}
+
+//endregion
@@ -0,0 +1,341 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package per
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"codello.dev/asn1"
+)
+
+type testCase[T any] struct {
+	val     T
+	data    string // hex encoded
+	params  string
+	wantErr error
+}
+
+// otherError is used to indicate that any non-nil error is expected.
+var otherError = errors.New("other error")
+
+// testCodec runs marshal and unmarshal tests for the type T. Test cases in
+// common are run in both directions.
+func testCodec[T any](t *testing.T, common, marshal, unmarshal map[string]testCase[T]) {
+	t.Helper()
+	runMarshal := func(name string, tc testCase[T]) {
+		t.Run("Marshal/"+name, func(t *testing.T) {
+			got, err := MarshalWithParams(tc.val, tc.params)
+			checkError(t, "MarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && hex.EncodeToString(got) != strings.ToLower(tc.data) {
+				t.Errorf("MarshalWithParams() = %X, want %s", got, tc.data)
+			}
+		})
+	}
+	runUnmarshal := func(name string, tc testCase[T]) {
+		t.Run("Unmarshal/"+name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			if err != nil {
+				t.Fatalf("invalid test data: %v", err)
+			}
+			var got T
+			err = UnmarshalWithParams(data, &got, tc.params)
+			checkError(t, "UnmarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && !reflect.DeepEqual(got, tc.val) {
+				t.Errorf("UnmarshalWithParams() = %v, want %v", got, tc.val)
+			}
+		})
+	}
+	for name, tc := range common {
+		runMarshal(name, tc)
+		runUnmarshal(name, tc)
+	}
+	for name, tc := range marshal {
+		runMarshal(name, tc)
+	}
+	for name, tc := range unmarshal {
+		runUnmarshal(name, tc)
+	}
+}
+
+func checkError(t *testing.T, fn string, err, wantErr error) {
+	t.Helper()
+	if wantErr == nil && err != nil {
+		t.Fatalf("%s error = %v, want nil", fn, err)
+	}
+	if wantErr == otherError && err == nil {
+		t.Fatalf("%s error = nil, want non-nil", fn)
+	}
+	if wantErr != nil && wantErr != otherError {
+		if target := reflect.New(reflect.TypeOf(wantErr)); !errors.As(err, target.Interface()) {
+			t.Fatalf("%s error = %v, want %T", fn, err, wantErr)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	testCodec(t, map[string]testCase[bool]{
+		"True":  {val: true, data: "80"},
+		"False": {val: false, data: "00"},
+	}, nil, map[string]testCase[bool]{
+		"Empty":     {data: "", wantErr: &SyntaxError{}},
+		"ExtraData": {data: "8000", wantErr: &SyntaxError{}},
+	})
+}
+
+func TestInteger(t *testing.T) {
+	testCodec(t, map[string]testCase[int]{
+		"Zero":        {val: 0, data: "0100"},
+		"Positive":    {val: 128, data: "020080"},
+		"Negative":    {val: -1, data: "01FF"},
+		"Negative2":   {val: -129, data: "02FF7F"},
+		"Constrained": {val: 5, data: "A0", params: "range:0..7"},
+		"Byte":        {val: 5, data: "05", params: "range:0..255"},
+		"Offset":      {val: -3, data: "40", params: "range:-4..-1"},
+		"Single":      {val: 3, data: "00", params: "range:3..3"},
+		"SemiRange":   {val: 1, data: "0100", params: "range:1..MAX"},
+		"SemiLarge":   {val: 257, data: "020100", params: "range:1..MAX"},
+	}, map[string]testCase[int]{
+		"TooLarge": {val: 8, params: "range:0..7", wantErr: &EncodeError{}},
+		"TooSmall": {val: 0, params: "range:1..MAX", wantErr: &EncodeError{}},
+	}, map[string]testCase[int]{
+		"OutOfRange": {data: "C0", params: "range:0..2", wantErr: &SyntaxError{}},
+		"Truncated":  {data: "02FF", wantErr: &SyntaxError{}},
+	})
+	testCodec(t, map[string]testCase[uint8]{
+		"Max": {val: 255, data: "0200FF"},
+	}, nil, map[string]testCase[uint8]{
+		"Overflow": {data: "020100", wantErr: &StructuralError{}},
+		"Negative": {data: "01FF", wantErr: &StructuralError{}},
+	})
+	big1, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	testCodec(t, map[string]testCase[*big.Int]{
+		"Big": {val: big1, data: "0D018EE90FF6C373E0EE4E3F0AD2"},
+	}, nil, nil)
+}
+
+type color int
+
+type size int
+
+var sizes = asn1.NewEnumDef(map[size]string{0: "small", 5: "medium", 9: "large"})
+
+type parity int
+
+func (p parity) IsValid() bool { return p%2 == 1 }
+
+func TestEnumerated(t *testing.T) {
+	testCodec(t, map[string]testCase[color]{
+		"Value": {val: 2, data: "80", params: "range:0..2"},
+	}, map[string]testCase[color]{
+		"OutOfRange": {val: 3, params: "range:0..2", wantErr: &EncodeError{}},
+		"NoRange":    {val: 1, wantErr: &EncodeError{}},
+	}, map[string]testCase[color]{
+		"NoRange": {data: "00", wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[size]{
+		"First": {val: 0, data: "00"},
+		"Index": {val: 5, data: "40"},
+		"Last":  {val: 9, data: "80"},
+	}, map[string]testCase[size]{
+		"Invalid": {val: 3, wantErr: &EncodeError{}},
+	}, map[string]testCase[size]{
+		"InvalidIndex": {data: "C0", wantErr: &SyntaxError{}},
+	})
+	testCodec(t, map[string]testCase[parity]{
+		"Index": {val: 3, data: "80", params: "range:0..3"},
+	}, map[string]testCase[parity]{
+		"Invalid": {val: 2, params: "range:0..3", wantErr: &EncodeError{}},
+	}, nil)
+}
+
+func TestReal(t *testing.T) {
+	testCodec(t, map[string]testCase[float64]{
+		"Zero": {val: 0, data: "00"},
+		"One":  {val: 1, data: "03800001"},
+	}, nil, nil)
+}
+
+func TestBitString(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.BitString]{
+		"Empty":   {val: asn1.BitString{Bytes: []byte{}}, data: "00"},
+		"Partial": {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, data: "03A0"},
+		"Fixed":   {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, data: "A0", params: "size:3"},
+	}, map[string]testCase[asn1.BitString]{
+		"Invalid":   {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 9}, wantErr: &EncodeError{}},
+		"WrongSize": {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, params: "size:4", wantErr: &EncodeError{}},
+	}, nil)
+}
+
+func TestOctetString(t *testing.T) {
+	testCodec(t, map[string]testCase[[]byte]{
+		"Empty":       {val: []byte{}, data: "00"},
+		"Bytes":       {val: []byte{0x41, 0x42}, data: "024142"},
+		"Fixed":       {val: []byte{0x41, 0x42}, data: "4142", params: "size:2"},
+		"Constrained": {val: []byte{0x41, 0x42}, data: "282840", params: "size:1..8"},
+	}, nil, map[string]testCase[[]byte]{
+		"TooShort": {data: "0141", params: "size:2..MAX", wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[[2]byte]{
+		"Array": {val: [2]byte{0x41, 0x42}, data: "024142"},
+	}, nil, map[string]testCase[[2]byte]{
+		"WrongLength": {data: "0141", wantErr: &StructuralError{}},
+	})
+}
+
+func TestNull(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.Null]{
+		"Null": {val: asn1.Null{}, data: "00"},
+	}, nil, nil)
+}
+
+func TestObjectIdentifier(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.ObjectIdentifier]{
+		"OID": {val: asn1.ObjectIdentifier{1, 2, 840}, data: "032A8648"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.RelativeOID]{
+		"RelativeOID": {val: asn1.RelativeOID{8571, 3, 2}, data: "04C27B0302"},
+	}, nil, nil)
+}
+
+func TestStrings(t *testing.T) {
+	testCodec(t, map[string]testCase[string]{
+		"UTF8": {val: "é", data: "02C3A9"},
+	}, nil, map[string]testCase[string]{
+		"Invalid": {data: "01FF", wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[asn1.IA5String]{
+		"IA5":   {val: "Hi", data: "0291A4"},
+		"Fixed": {val: "Hi", data: "91A4", params: "size:2"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.NumericString]{
+		"Numeric": {val: "12 ", data: "2300", params: "size:3"},
+	}, nil, map[string]testCase[asn1.NumericString]{
+		"Invalid": {data: "F0", params: "size:1", wantErr: &SyntaxError{}},
+	})
+	testCodec(t, map[string]testCase[asn1.BMPString]{
+		"BMP": {val: "€", data: "0120AC"},
+	}, nil, nil)
+}
+
+func TestTime(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.GeneralizedTime]{
+		"GeneralizedTime": {
+			val:  asn1.GeneralizedTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+			data: "0F64C193560C583260CD83460D6D00",
+		},
+	}, nil, nil)
+}
+
+func TestSequence(t *testing.T) {
+	type seq struct {
+		A bool
+		B int  `asn1:"range:0..3"`
+		C *int `asn1:"optional,omitzero"`
+	}
+	five := 5
+	testCodec(t, map[string]testCase[seq]{
+		"Absent":  {val: seq{true, 2, nil}, data: "60"},
+		"Present": {val: seq{true, 2, &five}, data: "E01050"},
+	}, nil, map[string]testCase[seq]{
+		"Truncated": {data: "E010", wantErr: &SyntaxError{}},
+	})
+
+	type optional struct {
+		A bool
+		B asn1.Optional[int] `asn1:"range:0..3"`
+	}
+	testCodec(t, map[string]testCase[optional]{
+		"OptionalAbsent":  {val: optional{A: true}, data: "40"},
+		"OptionalPresent": {val: optional{true, asn1.NewOptional(0)}, data: "C0"},
+	}, nil, nil)
+
+	type extensible struct {
+		A bool
+		asn1.Extensible
+	}
+	testCodec(t, map[string]testCase[extensible]{
+		"Root": {val: extensible{A: true}, data: "40"},
+	}, nil, map[string]testCase[extensible]{
+		"Extension": {val: extensible{A: true}, data: "C0407FC0"},
+	})
+}
+
+func TestSequenceOf(t *testing.T) {
+	testCodec(t, map[string]testCase[[]bool]{
+		"Empty":       {val: []bool{}, data: "00"},
+		"Values":      {val: []bool{true, false, true}, data: "03A0"},
+		"Constrained": {val: []bool{true, false, true}, data: "E8", params: "size:0..3"},
+	}, map[string]testCase[[]bool]{
+		"TooLong": {val: []bool{true, false, true, true}, params: "size:0..3", wantErr: &EncodeError{}},
+	}, nil)
+	testCodec(t, map[string]testCase[asn1.Set[int]]{
+		"Set": {val: asn1.NewSet(2, 1), data: "0201010102"},
+	}, nil, nil)
+}
+
+func TestChoice(t *testing.T) {
+	type choice struct {
+		A *int `asn1:"range:0..15"`
+		B *bool
+	}
+	one, yes := 1, true
+	testCodec(t, map[string]testCase[choice]{
+		"First":  {val: choice{A: &one}, data: "08", params: "choice"},
+		"Second": {val: choice{B: &yes}, data: "C0", params: "choice"},
+	}, map[string]testCase[choice]{
+		"None":     {val: choice{}, params: "choice", wantErr: &EncodeError{}},
+		"Multiple": {val: choice{A: &one, B: &yes}, params: "choice", wantErr: &EncodeError{}},
+	}, nil)
+
+	type extensible struct {
+		A *int `asn1:"range:0..15"`
+		asn1.Extensible
+	}
+	type wrapper struct {
+		C extensible `asn1:"choice"`
+		D bool
+	}
+	testCodec(t, map[string]testCase[wrapper]{
+		"Nested": {val: wrapper{extensible{A: &one}, true}, data: "0C"},
+	}, nil, map[string]testCase[wrapper]{
+		"Extension": {data: "80", wantErr: &StructuralError{}},
+	})
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	if err := Unmarshal([]byte{0x80}, nil); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(nil) error = %v, want InvalidDecodeError", err)
+	}
+	if err := Unmarshal([]byte{0x80}, true); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(non-pointer) error = %v, want InvalidDecodeError", err)
+	}
+	var v any
+	if err := Unmarshal([]byte{0x80}, &v); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(interface) error = %v, want InvalidDecodeError", err)
+	}
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+	tests := map[string]any{
+		"Nil":     nil,
+		"NilPtr":  (*int)(nil),
+		"Map":     map[string]int{},
+		"Chan":    make(chan int),
+		"Complex": complex(1, 2),
+	}
+	for name, val := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Marshal(val); !errors.As(err, new(*UnsupportedTypeError)) {
+				t.Errorf("Marshal() error = %v, want UnsupportedTypeError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,793 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"codello.dev/asn1"
+)
+
+// SyntaxError describes a problem in the notation of a module.
+type SyntaxError struct {
+	Line int // the line of the problem, starting at 1
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return "schema: " + e.Msg
+	}
+	return "schema: line " + strconv.Itoa(e.Line) + ": " + e.Msg
+}
+
+// builtinTypes maps the names of the supported built-in types to their
+// universal tags. Names consisting of multiple words are joined by a space.
+var builtinTypes = map[string]asn1.Tag{
+	"BOOLEAN":           asn1.TagBoolean,
+	"INTEGER":           asn1.TagInteger,
+	"BIT STRING":        asn1.TagBitString,
+	"OCTET STRING":      asn1.TagOctetString,
+	"NULL":              asn1.TagNull,
+	"OBJECT IDENTIFIER": asn1.TagOID,
+	"ObjectDescriptor":  asn1.TagObjectDescriptor,
+	"EXTERNAL":          asn1.TagExternal,
+	"REAL":              asn1.TagReal,
+	"ENUMERATED":        asn1.TagEnumerated,
+	"EMBEDDED PDV":      asn1.TagEmbeddedPDV,
+	"UTF8String":        asn1.TagUTF8String,
+	"RELATIVE-OID":      asn1.TagRelativeOID,
+	"TIME":              asn1.TagTime,
+	"NumericString":     asn1.TagNumericString,
+	"PrintableString":   asn1.TagPrintableString,
+	"TeletexString":     asn1.TagTeletexString,
+	"T61String":         asn1.TagT61String,
+	"VideotexString":    asn1.TagVideotexString,
+	"IA5String":         asn1.TagIA5String,
+	"UTCTime":           asn1.TagUTCTime,
+	"GeneralizedTime":   asn1.TagGeneralizedTime,
+	"GraphicString":     asn1.TagGraphicString,
+	"VisibleString":     asn1.TagVisibleString,
+	"ISO646String":      asn1.TagISO646String,
+	"GeneralString":     asn1.TagGeneralString,
+	"UniversalString":   asn1.TagUniversalString,
+	"CHARACTER STRING":  asn1.TagCharacterString,
+	"BMPString":         asn1.TagBMPString,
+	"DATE":              asn1.TagDate,
+	"TIME-OF-DAY":       asn1.TagTimeOfDay,
+	"DATE-TIME":         asn1.TagDateTime,
+	"DURATION":          asn1.TagDuration,
+}
+
+// Parse parses the ASN.1 module definition in src. If src is not a valid
+// module or uses notation that is not supported, a [*SyntaxError] is returned.
+// All type references must refer to types defined in the module.
+func Parse(src []byte) (*Module, error) {
+	toks, err := lex(string(src))
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	m, err := p.module()
+	if err != nil {
+		return nil, err
+	}
+	if err = m.checkReferences(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// checkReferences checks that all references in m can be resolved and that no type is
+// defined as a reference to itself.
+func (m *Module) checkReferences() error {
+	var check func(t *Type) error
+	check = func(t *Type) error {
+		switch t.Kind {
+		case Reference:
+			seen := map[string]bool{}
+			for r := t; r.Kind == Reference; r = m.Types[r.Name] {
+				if m.Types[r.Name] == nil {
+					return &SyntaxError{Msg: "undefined type " + r.Name}
+				}
+				if seen[r.Name] {
+					return &SyntaxError{Msg: "circular definition of type " + r.Name}
+				}
+				seen[r.Name] = true
+			}
+		case Tagged, SequenceOf, SetOf:
+			return check(t.Elem)
+		case Sequence, Set, Choice:
+			for _, c := range t.Components {
+				if err := check(c.Type); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, t := range m.Types {
+		if err := check(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//region lexer
+
+// tokenKind identifies the kind of a token.
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokWord             // identifiers, references and keywords
+	tokNumber           // non-negative numbers
+	tokString           // quoted strings, including bstring and hstring
+	tokSymbol           // punctuation such as ::= or {
+)
+
+// token is a lexical item of a module definition.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// lex splits src into tokens, discarding comments and whitespace.
+func lex(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(src[i:], "--"):
+			// comments end at the end of the line or at the next "--"
+			i += 2
+			for i < len(src) && src[i] != '\n' && !strings.HasPrefix(src[i:], "--") {
+				i++
+			}
+			if i < len(src) && src[i] != '\n' {
+				i += 2
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, &SyntaxError{line, "unterminated comment"}
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c < unicode.MaxASCII && unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(src) && (isAlnum(src[j]) || src[j] == '-' && j+1 < len(src) && isAlnum(src[j+1])) {
+				j++
+			}
+			toks = append(toks, token{tokWord, src[i:j], line})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j], line})
+			i = j
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(src[i+1:], c)
+			if j < 0 {
+				return nil, &SyntaxError{line, "unterminated string"}
+			}
+			j += i + 2
+			if c == '\'' && j < len(src) && (src[j] == 'B' || src[j] == 'H') {
+				j++
+			}
+			toks = append(toks, token{tokString, src[i:j], line})
+			line += strings.Count(src[i:j], "\n")
+			i = j
+		default:
+			sym := string(c)
+			for _, s := range []string{"::=", "...", ".."} {
+				if strings.HasPrefix(src[i:], s) {
+					sym = s
+					break
+				}
+			}
+			if !strings.Contains("{}()[],|^!;:-.<", sym[:1]) {
+				return nil, &SyntaxError{line, "unexpected character " + strconv.QuoteRune(rune(c))}
+			}
+			toks = append(toks, token{tokSymbol, sym, line})
+			i += len(sym)
+		}
+	}
+	return append(toks, token{tokEOF, "", line}), nil
+}
+
+// isAlnum reports whether c is an ASCII letter or digit.
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+//endregion
+
+//region parser
+
+// parser implements a recursive descent parser for module definitions.
+type parser struct {
+	toks []token
+	pos  int
+	m    *Module
+}
+
+// peek returns the token at offset n from the current token.
+func (p *parser) peek(n int) token {
+	if p.pos+n >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.pos+n]
+}
+
+// next consumes and returns the current token.
+func (p *parser) next() token {
+	t := p.peek(0)
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the current token if its text is s.
+func (p *parser) accept(s string) bool {
+	if t := p.peek(0); t.kind != tokString && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the current token if its text is s and returns an error
+// otherwise.
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %s", s)
+	}
+	return nil
+}
+
+// errorf returns a SyntaxError at the current token.
+func (p *parser) errorf(format string, args ...any) error {
+	t := p.peek(0)
+	found := strconv.Quote(t.text)
+	if t.kind == tokEOF {
+		found = "end of input"
+	}
+	return &SyntaxError{t.line, fmt.Sprintf(format, args...) + ", found " + found}
+}
+
+// word consumes an identifier or reference. If upper is set, the name must
+// start with an uppercase letter.
+func (p *parser) word(upper bool) (string, error) {
+	t := p.peek(0)
+	if t.kind != tokWord || upper && !unicode.IsUpper(rune(t.text[0])) {
+		if upper {
+			return "", p.errorf("expected type reference")
+		}
+		return "", p.errorf("expected identifier")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// number consumes a possibly negative number.
+func (p *parser) number() (int64, error) {
+	neg := p.accept("-")
+	t := p.peek(0)
+	if t.kind != tokNumber {
+		return 0, p.errorf("expected number")
+	}
+	if neg {
+		t.text = "-" + t.text
+	}
+	n, err := strconv.ParseInt(t.text, 10, 64)
+	if err != nil {
+		return 0, p.errorf("number out of range")
+	}
+	p.pos++
+	return n, nil
+}
+
+// module parses a module definition.
+func (p *parser) module() (*Module, error) {
+	name, err := p.word(true)
+	if err != nil {
+		return nil, err
+	}
+	p.m = &Module{Name: name, Types: make(map[string]*Type)}
+	if p.peek(0).text == "{" {
+		return nil, p.errorf("module identifiers are not supported")
+	}
+	if err = p.expect("DEFINITIONS"); err != nil {
+		return nil, err
+	}
+	if d, ok := map[string]TagDefault{"EXPLICIT": ExplicitTags, "IMPLICIT": ImplicitTags, "AUTOMATIC": AutomaticTags}[p.peek(0).text]; ok {
+		p.next()
+		p.m.TagDefault = d
+		if err = p.expect("TAGS"); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("EXTENSIBILITY") {
+		if err = p.expect("IMPLIED"); err != nil {
+			return nil, err
+		}
+	}
+	if err = p.expect("::="); err != nil {
+		return nil, err
+	}
+	if err = p.expect("BEGIN"); err != nil {
+		return nil, err
+	}
+	if p.accept("EXPORTS") {
+		for !p.accept(";") {
+			if p.peek(0).kind == tokEOF {
+				return nil, p.errorf("expected ;")
+			}
+			p.next()
+		}
+	}
+	if p.peek(0).text == "IMPORTS" {
+		return nil, p.errorf("imports are not supported")
+	}
+	for !p.accept("END") {
+		line := p.peek(0).line
+		name, err = p.word(true)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek(0).text == "{" {
+			return nil, p.errorf("parameterized types are not supported")
+		}
+		if err = p.expect("::="); err != nil {
+			return nil, err
+		}
+		if _, ok := p.m.Types[name]; ok {
+			return nil, &SyntaxError{line, "duplicate definition of type " + name}
+		}
+		if p.m.Types[name], err = p.typ(); err != nil {
+			return nil, err
+		}
+		p.m.TypeNames = append(p.m.TypeNames, name)
+	}
+	if p.peek(0).kind != tokEOF {
+		return nil, p.errorf("expected end of input")
+	}
+	return p.m, nil
+}
+
+// typ parses a type, including a prefixed tag and constraints.
+func (p *parser) typ() (*Type, error) {
+	if p.peek(0).text == "[" {
+		return p.tagged()
+	}
+	t, err := p.baseType()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(0).text == "(" {
+		if err = p.constraint(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// tagged parses a tagged type.
+func (p *parser) tagged() (*Type, error) {
+	p.next() // [
+	tag := asn1.ClassContextSpecific
+	switch {
+	case p.accept("UNIVERSAL"):
+		tag = asn1.ClassUniversal
+	case p.accept("APPLICATION"):
+		tag = asn1.ClassApplication
+	case p.accept("PRIVATE"):
+		tag = asn1.ClassPrivate
+	}
+	n, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > asn1.MaxTag {
+		return nil, p.errorf("tag number out of range")
+	}
+	if err = p.expect("]"); err != nil {
+		return nil, err
+	}
+	t := &Type{Kind: Tagged, Tag: tag | asn1.Tag(n), Explicit: p.m.TagDefault == ExplicitTags}
+	if p.accept("IMPLICIT") {
+		t.Explicit = false
+	} else if p.accept("EXPLICIT") {
+		t.Explicit = true
+	}
+	t.Elem, err = p.typ()
+	return t, err
+}
+
+// baseType parses a type without tags and constraints.
+func (p *parser) baseType() (*Type, error) {
+	tok := p.peek(0)
+	if tok.kind != tokWord {
+		return nil, p.errorf("expected type")
+	}
+	name := tok.text
+	switch name {
+	case "SEQUENCE", "SET":
+		p.next()
+		if p.peek(0).text == "{" {
+			kind := Sequence
+			if name == "SET" {
+				kind = Set
+			}
+			return p.components(kind)
+		}
+		t := &Type{Kind: SequenceOf}
+		if name == "SET" {
+			t.Kind = SetOf
+		}
+		if p.peek(0).text == "(" {
+			if err := p.constraint(t); err != nil {
+				return nil, err
+			}
+		} else if p.peek(0).text == "SIZE" {
+			if _, err := p.constraintElem(t); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("OF"); err != nil {
+			return nil, err
+		}
+		if p.peek(0).kind == tokWord && unicode.IsLower(rune(p.peek(0).text[0])) {
+			p.next() // element identifier
+		}
+		var err error
+		t.Elem, err = p.typ()
+		return t, err
+	case "CHOICE":
+		p.next()
+		return p.components(Choice)
+	case "ANY":
+		p.next()
+		if p.accept("DEFINED") {
+			if err := p.expect("BY"); err != nil {
+				return nil, err
+			}
+			if _, err := p.word(false); err != nil {
+				return nil, err
+			}
+		}
+		return &Type{Kind: Any}, nil
+	case "OBJECT", "OCTET", "BIT", "EMBEDDED", "CHARACTER":
+		name += " " + p.peek(1).text
+		if _, ok := builtinTypes[name]; !ok {
+			return nil, p.errorf("expected type")
+		}
+		p.next()
+	}
+	if tag, ok := builtinTypes[name]; ok {
+		p.next()
+		t := &Type{Kind: Builtin, Tag: tag}
+		switch {
+		case tag == asn1.TagEnumerated:
+			return t, p.enumeration(t)
+		case (tag == asn1.TagInteger || tag == asn1.TagBitString) && p.peek(0).text == "{":
+			return t, p.namedNumbers(t)
+		}
+		return t, nil
+	}
+	if !unicode.IsUpper(rune(name[0])) || isKeyword(name) {
+		return nil, p.errorf("expected type")
+	}
+	p.next()
+	if p.peek(0).text == "." {
+		return nil, p.errorf("external type references are not supported")
+	}
+	return &Type{Kind: Reference, Name: name}, nil
+}
+
+// isKeyword reports whether s is a reserved word that cannot be used as a type
+// reference.
+func isKeyword(s string) bool {
+	switch s {
+	case "ABSENT", "ALL", "APPLICATION", "AUTOMATIC", "BEGIN", "BY", "CLASS",
+		"COMPONENT", "COMPONENTS", "CONSTRAINED", "CONTAINING", "DEFAULT", "DEFINED",
+		"DEFINITIONS", "END", "EXCEPT", "EXPLICIT", "EXPORTS", "EXTENSIBILITY",
+		"FALSE", "FROM", "IDENTIFIER", "IMPLICIT", "IMPLIED", "IMPORTS", "INCLUDES",
+		"INSTANCE", "INTERSECTION", "MAX", "MIN", "MINUS-INFINITY", "NOT-A-NUMBER",
+		"OF", "OPTIONAL", "PATTERN", "PDV", "PLUS-INFINITY", "PRESENT", "PRIVATE",
+		"SETTINGS", "SIZE", "STRING", "SYNTAX", "TAGS", "TRUE", "TYPE-IDENTIFIER",
+		"UNION", "UNIQUE", "UNIVERSAL", "WITH":
+		return true
+	}
+	return false
+}
+
+// components parses the component list of a SEQUENCE, SET or CHOICE type.
+func (p *parser) components(kind Kind) (*Type, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	t := &Type{Kind: kind}
+	markers := 0
+	for !p.accept("}") {
+		if len(t.Components) > 0 || markers > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		if p.accept("...") {
+			if markers++; markers > 2 {
+				return nil, p.errorf("too many extension markers")
+			}
+			t.Extensible = true
+			if p.peek(0).text == "!" {
+				return nil, p.errorf("exception specifications are not supported")
+			}
+			continue
+		}
+		if p.peek(0).text == "[" && p.peek(1).text == "[" {
+			return nil, p.errorf("extension addition groups are not supported")
+		}
+		if p.peek(0).text == "COMPONENTS" {
+			return nil, p.errorf("COMPONENTS OF is not supported")
+		}
+		name, err := p.word(false)
+		if err != nil {
+			return nil, err
+		}
+		if !unicode.IsLower(rune(name[0])) {
+			p.pos--
+			return nil, p.errorf("expected identifier")
+		}
+		c := Component{Name: name, Extension: markers == 1}
+		if c.Type, err = p.typ(); err != nil {
+			return nil, err
+		}
+		if kind != Choice {
+			if p.accept("OPTIONAL") {
+				c.Optional = true
+			} else if p.accept("DEFAULT") {
+				c.Optional = true
+				if c.Default, err = p.value(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		t.Components = append(t.Components, c)
+	}
+	if p.m.TagDefault == AutomaticTags {
+		for _, c := range t.Components {
+			if c.Type.Kind == Tagged {
+				return t, nil
+			}
+		}
+		for i := range t.Components {
+			c := &t.Components[i]
+			c.Type = &Type{Kind: Tagged, Tag: asn1.ClassContextSpecific | asn1.Tag(i), Elem: c.Type}
+		}
+	}
+	return t, nil
+}
+
+// value parses a value in value notation and returns its textual
+// representation. Values in braces are returned with their tokens separated by
+// spaces.
+func (p *parser) value() (string, error) {
+	var s []string
+	depth := 0
+	for {
+		t := p.peek(0)
+		switch {
+		case t.kind == tokEOF:
+			return "", p.errorf("expected value")
+		case t.text == "{":
+			depth++
+		case t.text == "}" && depth > 0:
+			depth--
+		case (t.text == "," || t.text == "}") && depth == 0:
+			if len(s) == 0 {
+				return "", p.errorf("expected value")
+			}
+			return strings.Join(s, " "), nil
+		}
+		if t.text == "-" && p.peek(1).kind == tokNumber {
+			p.next()
+			t = p.peek(0)
+			t.text = "-" + t.text
+		}
+		s = append(s, t.text)
+		p.next()
+	}
+}
+
+// namedNumbers parses the named numbers of an INTEGER or the named bits of a
+// BIT STRING type.
+func (p *parser) namedNumbers(t *Type) error {
+	p.next() // {
+	for {
+		n, err := p.namedNumber()
+		if err != nil {
+			return err
+		}
+		t.NamedNumbers = append(t.NamedNumbers, n)
+		if p.accept("}") {
+			return nil
+		}
+		if err = p.expect(","); err != nil {
+			return err
+		}
+	}
+}
+
+// namedNumber parses a single "name(number)" item.
+func (p *parser) namedNumber() (n NamedNumber, err error) {
+	if n.Name, err = p.word(false); err != nil {
+		return n, err
+	}
+	if err = p.expect("("); err != nil {
+		return n, err
+	}
+	if n.Value, err = p.number(); err != nil {
+		return n, err
+	}
+	return n, p.expect(")")
+}
+
+// enumeration parses the items of an ENUMERATED type. Items without a number
+// are numbered as specified in X.680, clause 20.
+func (p *parser) enumeration(t *Type) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	var auto []int // indices of items without number
+	for !p.accept("}") {
+		if len(t.NamedNumbers) > 0 || t.Extensible {
+			if err := p.expect(","); err != nil {
+				return err
+			}
+		}
+		if p.accept("...") {
+			if t.Extensible {
+				return p.errorf("duplicate extension marker")
+			}
+			numberItems(t, auto)
+			t.Extensible = true
+			auto = nil
+			continue
+		}
+		if p.peek(1).text == "(" {
+			n, err := p.namedNumber()
+			if err != nil {
+				return err
+			}
+			t.NamedNumbers = append(t.NamedNumbers, n)
+			continue
+		}
+		name, err := p.word(false)
+		if err != nil {
+			return err
+		}
+		auto = append(auto, len(t.NamedNumbers))
+		t.NamedNumbers = append(t.NamedNumbers, NamedNumber{Name: name, Value: -1})
+	}
+	numberItems(t, auto)
+	return nil
+}
+
+// numberItems assigns numbers to the items of t at the indices in auto. Each
+// item is assigned the smallest non-negative number that is not used by
+// another item and, for extension additions, that is larger than the numbers
+// of all preceding items.
+func numberItems(t *Type, auto []int) {
+	used := make(map[int64]bool, len(t.NamedNumbers))
+	for _, n := range t.NamedNumbers {
+		used[n.Value] = true
+	}
+	var next int64
+	if t.Extensible {
+		for _, n := range t.NamedNumbers {
+			next = max(next, n.Value+1)
+		}
+	}
+	for _, i := range auto {
+		for used[next] {
+			next++
+		}
+		t.NamedNumbers[i].Value = next
+		used[next] = true
+	}
+}
+
+// constraint parses a parenthesized constraint and applies it to t.
+func (p *parser) constraint(t *Type) error {
+	p.next() // (
+	r, err := p.constraintElem(t)
+	if err != nil {
+		return err
+	}
+	if p.accept(",") {
+		if err = p.expect("..."); err != nil {
+			return err
+		}
+		r.Extensible = true
+	}
+	if p.peek(0).text == "|" || p.peek(0).text == "^" {
+		return p.errorf("unions and intersections are not supported")
+	}
+	return p.expect(")")
+}
+
+// constraintElem parses a SIZE constraint or a value range and applies it to
+// t. The returned Range is the constraint that has been applied.
+func (p *parser) constraintElem(t *Type) (*Range, error) {
+	size := p.accept("SIZE")
+	if size {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+	}
+	r, err := p.valueRange()
+	if err != nil {
+		return nil, err
+	}
+	if !size {
+		t.Range = r
+		return r, nil
+	}
+	if r.Min < 0 {
+		return nil, p.errorf("negative size")
+	}
+	if p.accept(",") {
+		if err = p.expect("..."); err != nil {
+			return nil, err
+		}
+		r.Extensible = true
+	}
+	t.Size = r
+	return r, p.expect(")")
+}
+
+// valueRange parses a single value or a range of values.
+func (p *parser) valueRange() (*Range, error) {
+	bound := func(def int64) (int64, error) {
+		if p.accept("MIN") || p.accept("MAX") {
+			return def, nil
+		}
+		return p.number()
+	}
+	lo, err := bound(math.MinInt64)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("..") {
+		return &Range{Min: lo, Max: lo}, nil
+	}
+	hi, err := bound(math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	if lo > hi {
+		return nil, p.errorf("empty range")
+	}
+	return &Range{Min: lo, Max: hi}, nil
+}
+
+//endregion
@@ -0,0 +1,114 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+
+	"codello.dev/asn1"
+)
+
+func TestParse(t *testing.T) {
+	src := `
+-- A module for testing.
+Test DEFINITIONS IMPLICIT TAGS ::= BEGIN
+	Message ::= SEQUENCE {
+		id       INTEGER (0..MAX),
+		name     [0] UTF8String (SIZE (1..64)) OPTIONAL, -- inline -- /* block */
+		kind     Kind DEFAULT request,
+		body     [APPLICATION 1] EXPLICIT Body,
+		...
+	}
+	/* enumeration */
+	Kind ::= ENUMERATED { request, response(5), error, ... , fault }
+	Body ::= CHOICE { text IA5String, items SEQUENCE SIZE (0..8) OF INTEGER }
+	Flags ::= BIT STRING { a(0), b(1) }
+END`
+	m, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.Name != "Test" || m.TagDefault != ImplicitTags || len(m.Types) != 4 {
+		t.Fatalf("Parse() = %v %v with %d types", m.Name, m.TagDefault, len(m.Types))
+	}
+	if want := []string{"Message", "Kind", "Body", "Flags"}; !slices.Equal(m.TypeNames, want) {
+		t.Errorf("Parse() TypeNames = %v, want %v", m.TypeNames, want)
+	}
+	want := &Type{Kind: Sequence, Extensible: true, Components: []Component{
+		{Name: "id", Type: &Type{Kind: Builtin, Tag: asn1.TagInteger, Range: &Range{Min: 0, Max: math.MaxInt64}}},
+		{Name: "name", Type: &Type{Kind: Tagged, Tag: asn1.ClassContextSpecific | 0, Elem: &Type{Kind: Builtin, Tag: asn1.TagUTF8String, Size: &Range{Min: 1, Max: 64}}}, Optional: true},
+		{Name: "kind", Type: &Type{Kind: Reference, Name: "Kind"}, Optional: true, Default: "request"},
+		{Name: "body", Type: &Type{Kind: Tagged, Tag: asn1.ClassApplication | 1, Explicit: true, Elem: &Type{Kind: Reference, Name: "Body"}}},
+	}}
+	if got := m.Types["Message"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() Message = %+v, want %+v", got, want)
+	}
+	wantKind := []NamedNumber{{"request", 0}, {"response", 5}, {"error", 1}, {"fault", 6}}
+	if got := m.Types["Kind"].NamedNumbers; !reflect.DeepEqual(got, wantKind) {
+		t.Errorf("Parse() Kind = %v, want %v", got, wantKind)
+	}
+	if items := m.Types["Body"].Components[1].Type; items.Kind != SequenceOf || *items.Size != (Range{0, 8, false}) {
+		t.Errorf("Parse() items = %+v", items)
+	}
+}
+
+func TestParse_Automatic(t *testing.T) {
+	m, err := Parse([]byte(`A DEFINITIONS AUTOMATIC TAGS ::= BEGIN
+		S ::= SEQUENCE { a INTEGER, b BOOLEAN OPTIONAL, ..., c NULL }
+		T ::= SEQUENCE { a [5] INTEGER }
+	END`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for i, c := range m.Types["S"].Components {
+		if c.Type.Kind != Tagged || c.Type.Tag != asn1.ClassContextSpecific|asn1.Tag(i) || c.Type.Explicit {
+			t.Errorf("Parse() S.%s = %+v, want implicit [%d]", c.Name, c.Type, i)
+		}
+		if c.Extension != (c.Name == "c") {
+			t.Errorf("Parse() S.%s.Extension = %v", c.Name, c.Extension)
+		}
+	}
+	if c := m.Types["T"].Components[0]; c.Type.Tag != asn1.ClassContextSpecific|5 || c.Type.Explicit {
+		t.Errorf("Parse() T.a = %+v, want implicit [5]", c.Type)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		line int
+	}{
+		"Empty":           {"", 1},
+		"MissingEnd":      {"A DEFINITIONS ::= BEGIN\nT ::= INTEGER", 2},
+		"Undefined":       {"A DEFINITIONS ::= BEGIN T ::= U END", 0},
+		"Circular":        {"A DEFINITIONS ::= BEGIN T ::= U U ::= T END", 0},
+		"Duplicate":       {"A DEFINITIONS ::= BEGIN\nT ::= INTEGER\nT ::= NULL END", 3},
+		"Imports":         {"A DEFINITIONS ::= BEGIN IMPORTS B FROM C; END", 1},
+		"Union":           {"A DEFINITIONS ::= BEGIN T ::= INTEGER (1 | 2) END", 1},
+		"EmptyRange":      {"A DEFINITIONS ::= BEGIN T ::= INTEGER (5..1) END", 1},
+		"Unterminated":    {"A DEFINITIONS ::= BEGIN /* T ::= INTEGER END", 1},
+		"InvalidChar":     {"A DEFINITIONS ::= BEGIN T ::= INTEGER # END", 1},
+		"LowercaseType":   {"A DEFINITIONS ::= BEGIN t ::= INTEGER END", 1},
+		"MissingName":     {"A DEFINITIONS ::= BEGIN T ::= SEQUENCE { INTEGER } END", 1},
+		"TagOutOfRange":   {"A DEFINITIONS ::= BEGIN T ::= [99999] INTEGER END", 1},
+		"TrailingContent": {"A DEFINITIONS ::= BEGIN END B", 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.src))
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Parse() error = %v, want *SyntaxError", err)
+			}
+			if serr.Line != tt.line {
+				t.Errorf("Parse() error = %v, want line %d", err, tt.line)
+			}
+		})
+	}
+}
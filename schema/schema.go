@@ -0,0 +1,145 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package schema implements parsing of ASN.1 module definitions written in the
+// notation of [Rec. ITU-T X.680] and the validation of BER-encoded data values
+// against the parsed types. This enables tools to check encodings without
+// defining Go types for them.
+//
+// Only a subset of the notation is supported. A module consists of type
+// assignments using the built-in types, SEQUENCE, SET, SEQUENCE OF, SET OF,
+// CHOICE, ENUMERATED, tagged types, and references to other types of the
+// module. Components can be OPTIONAL or have a DEFAULT value. Types can be
+// constrained by single values, value ranges and SIZE constraints. Value
+// assignments, IMPORTS, parameterized types, information objects and other
+// constraints are not supported:
+//
+//	Example DEFINITIONS AUTOMATIC TAGS ::= BEGIN
+//		Message ::= SEQUENCE {
+//			id      INTEGER (0..65535),
+//			name    UTF8String (SIZE (1..64)) OPTIONAL,
+//			kind    ENUMERATED { request, response, ... } DEFAULT request
+//		}
+//	END
+//
+// [Rec. ITU-T X.680]: https://www.itu.int/rec/T-REC-X.680
+package schema
+
+import (
+	"strconv"
+
+	"codello.dev/asn1"
+)
+
+// Module is a parsed ASN.1 module.
+type Module struct {
+	Name       string
+	TagDefault TagDefault
+
+	// Types contains the types assigned in the module, indexed by their name.
+	Types map[string]*Type
+
+	// TypeNames contains the names of the types in the order of their
+	// definition.
+	TypeNames []string
+}
+
+// TagDefault is the default tagging of a module.
+type TagDefault int
+
+const (
+	ExplicitTags  TagDefault = iota // EXPLICIT TAGS, the default
+	ImplicitTags                    // IMPLICIT TAGS
+	AutomaticTags                   // AUTOMATIC TAGS
+)
+
+// Kind identifies the form of a [Type].
+type Kind int
+
+const (
+	Invalid    Kind = iota
+	Builtin         // a built-in type such as INTEGER, identified by its universal tag
+	Sequence        // SEQUENCE { ... }
+	Set             // SET { ... }
+	SequenceOf      // SEQUENCE OF ...
+	SetOf           // SET OF ...
+	Choice          // CHOICE { ... }
+	Tagged          // [class number] IMPLICIT/EXPLICIT ...
+	Reference       // a reference to another type of the module
+	Any             // ANY, matches any data value
+)
+
+var kindNames = [...]string{"Invalid", "Builtin", "Sequence", "Set", "SequenceOf", "SetOf", "Choice", "Tagged", "Reference", "Any"}
+
+// String returns the name of k.
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
+// Type is an ASN.1 type of a [Module]. Which fields are used depends on the
+// Kind of the type.
+type Type struct {
+	Kind Kind
+
+	// Tag is the universal tag of a Builtin type or the tag of a Tagged type.
+	Tag asn1.Tag
+
+	// Explicit indicates that a Tagged type uses explicit tagging. This is
+	// determined by the IMPLICIT or EXPLICIT keyword or by the tag default of the
+	// module.
+	Explicit bool
+
+	Name       string      // the name of the type referenced by a Reference
+	Elem       *Type       // the type of a Tagged, SequenceOf or SetOf type
+	Components []Component // the components of a Sequence, Set or Choice
+
+	// Extensible indicates that a Sequence, Set, Choice or ENUMERATED type
+	// contains an extension marker.
+	Extensible bool
+
+	// NamedNumbers contains the named numbers of an INTEGER, the enumeration
+	// items of an ENUMERATED and the named bits of a BIT STRING type.
+	NamedNumbers []NamedNumber
+
+	Range *Range // the value constraint of an INTEGER type
+	Size  *Range // the SIZE constraint of a string, SequenceOf or SetOf type
+}
+
+// Component is a component of a SEQUENCE, SET or CHOICE type.
+type Component struct {
+	Name     string
+	Type     *Type
+	Optional bool
+
+	// Default is the value notation of the DEFAULT value of the component, if
+	// any. A component with a DEFAULT value is also Optional.
+	Default string
+
+	// Extension indicates that the component is an extension addition, i.e. it
+	// is listed after an extension marker. Extension additions may be absent
+	// from encodings even if they are not Optional.
+	Extension bool
+}
+
+// NamedNumber is a named number of an INTEGER, ENUMERATED or BIT STRING type.
+type NamedNumber struct {
+	Name  string
+	Value int64
+}
+
+// Range is an inclusive range of values. MIN and MAX are represented by the
+// smallest and largest int64 respectively. Values outside of an Extensible
+// range are permitted.
+type Range struct {
+	Min, Max   int64
+	Extensible bool
+}
+
+// Contains reports whether n is within r.
+func (r *Range) Contains(n int64) bool {
+	return r.Extensible || r.Min <= n && n <= r.Max
+}
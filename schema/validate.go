@@ -0,0 +1,295 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+)
+
+// ValidationError describes a data value that is not a valid value of a type.
+type ValidationError struct {
+	// Path identifies the invalid data value. It starts with the name of the
+	// validated type, followed by component names and indices, e.g.
+	// "Message.items[2]".
+	Path string
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	return "schema: " + e.Path + ": " + e.Msg
+}
+
+// Validate checks that v is a valid value of the type named typ. The tags,
+// the presence of components and the constraints of the type are validated, as
+// is the content of data values of built-in types. DEFAULT values are not
+// validated. Unknown components and alternatives are accepted if the
+// respective type is extensible. If v is invalid, a [*ValidationError] is
+// returned.
+func (m *Module) Validate(typ string, v ber.Value) error {
+	t := m.Types[typ]
+	if t == nil {
+		return &ValidationError{typ, "undefined type"}
+	}
+	return m.validate(typ, t, v, 0)
+}
+
+// ValidateRaw is like [Module.Validate] but validates the data value encoded
+// in rv.
+func (m *Module) ValidateRaw(typ string, rv ber.RawValue) error {
+	v, err := ber.ValueOf(rv)
+	if err != nil {
+		return err
+	}
+	return m.Validate(typ, v)
+}
+
+// ValidateBytes is like [Module.Validate] but validates the data value encoded
+// in b. It can be used to reject invalid input before decoding it into Go
+// values. If b is not a valid BER encoding of a single data value, the error
+// of the decoder is returned.
+func (m *Module) ValidateBytes(typ string, b []byte) error {
+	var v ber.Value
+	if err := ber.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	return m.Validate(typ, v)
+}
+
+// resolve follows references starting at t and returns the first type that is
+// not a Reference.
+func (m *Module) resolve(t *Type) *Type {
+	for t.Kind == Reference {
+		t = m.Types[t.Name]
+	}
+	return t
+}
+
+// matches reports whether a data value with the given tag can be a value of t.
+func (m *Module) matches(t *Type, tag asn1.Tag) bool {
+	t = m.resolve(t)
+	switch t.Kind {
+	case Builtin, Tagged:
+		return tag == t.Tag
+	case Sequence, SequenceOf:
+		return tag == asn1.TagSequence
+	case Set, SetOf:
+		return tag == asn1.TagSet
+	case Choice:
+		return slices.ContainsFunc(t.Components, func(c Component) bool {
+			return m.matches(c.Type, tag)
+		})
+	case Any:
+		return true
+	}
+	return false
+}
+
+// validate validates v against t. If tag is not 0, t is implicitly tagged
+// with tag.
+func (m *Module) validate(path string, t *Type, v ber.Value, tag asn1.Tag) error {
+	errorf := func(msg string) error {
+		return &ValidationError{path, msg}
+	}
+	expect := func(want asn1.Tag, constructed bool) error {
+		if tag != 0 {
+			want = tag
+		}
+		if v.Tag != want {
+			return errorf("expected tag " + want.String() + ", got " + v.Tag.String())
+		}
+		if constructed && !v.Constructed {
+			return errorf("expected constructed encoding")
+		}
+		return nil
+	}
+
+	switch t.Kind {
+	case Reference:
+		if err := m.validate(path, m.Types[t.Name], v, tag); err != nil {
+			return err
+		}
+	case Any:
+		return nil
+	case Tagged:
+		// implicit tagging of CHOICE and ANY types is explicit (X.680, 31.2.7)
+		explicit := t.Explicit
+		if k := m.resolve(t.Elem).Kind; k == Choice || k == Any {
+			explicit = true
+		}
+		if err := expect(t.Tag, explicit); err != nil {
+			return err
+		}
+		if !explicit {
+			return m.validate(path, t.Elem, v, v.Tag)
+		}
+		if len(v.Children) != 1 {
+			return errorf("expected exactly one component in explicitly tagged value, got " + strconv.Itoa(len(v.Children)))
+		}
+		return m.validate(path, t.Elem, v.Children[0], 0)
+	case Builtin:
+		if err := expect(t.Tag, false); err != nil {
+			return err
+		}
+	case Sequence:
+		if err := expect(asn1.TagSequence, true); err != nil {
+			return err
+		}
+		return m.validateSequence(path, t, v.Children)
+	case Set:
+		if err := expect(asn1.TagSet, true); err != nil {
+			return err
+		}
+		return m.validateSet(path, t, v.Children)
+	case SequenceOf, SetOf:
+		want := asn1.TagSequence
+		if t.Kind == SetOf {
+			want = asn1.TagSet
+		}
+		if err := expect(want, true); err != nil {
+			return err
+		}
+		for i, c := range v.Children {
+			if err := m.validate(path+"["+strconv.Itoa(i)+"]", t.Elem, c, 0); err != nil {
+				return err
+			}
+		}
+	case Choice:
+		for _, c := range t.Components {
+			if m.matches(c.Type, v.Tag) {
+				return m.validate(path+"."+c.Name, c.Type, v, 0)
+			}
+		}
+		if !t.Extensible {
+			return errorf("no alternative matches tag " + v.Tag.String())
+		}
+		return nil
+	default:
+		return errorf("invalid type")
+	}
+	return m.validateContent(path, t, v)
+}
+
+// validateSequence validates the components of a SEQUENCE value against the
+// components of t. Components must appear in the order of their definition.
+func (m *Module) validateSequence(path string, t *Type, vs []ber.Value) error {
+	i := 0
+	for _, c := range t.Components {
+		if i < len(vs) && m.matches(c.Type, vs[i].Tag) {
+			if err := m.validate(path+"."+c.Name, c.Type, vs[i], 0); err != nil {
+				return err
+			}
+			i++
+		} else if !c.Optional && !c.Extension {
+			return &ValidationError{path, "missing component " + c.Name}
+		}
+	}
+	if i < len(vs) && !t.Extensible {
+		return &ValidationError{path, "unexpected component with tag " + vs[i].Tag.String()}
+	}
+	return nil
+}
+
+// validateSet validates the components of a SET value against the components
+// of t. Components may appear in any order.
+func (m *Module) validateSet(path string, t *Type, vs []ber.Value) error {
+	seen := make([]bool, len(t.Components))
+	for _, v := range vs {
+		i := slices.IndexFunc(t.Components, func(c Component) bool {
+			return m.matches(c.Type, v.Tag)
+		})
+		if i < 0 {
+			if !t.Extensible {
+				return &ValidationError{path, "unexpected component with tag " + v.Tag.String()}
+			}
+			continue
+		}
+		c := t.Components[i]
+		if seen[i] {
+			return &ValidationError{path, "duplicate component " + c.Name}
+		}
+		seen[i] = true
+		if err := m.validate(path+"."+c.Name, c.Type, v, 0); err != nil {
+			return err
+		}
+	}
+	for i, c := range t.Components {
+		if !seen[i] && !c.Optional && !c.Extension {
+			return &ValidationError{path, "missing component " + c.Name}
+		}
+	}
+	return nil
+}
+
+// validateContent validates the content of v against the built-in type
+// underlying t and checks the constraints of t.
+func (m *Module) validateContent(path string, t *Type, v ber.Value) error {
+	u := m.resolve(t)
+	if u.Kind == SequenceOf || u.Kind == SetOf {
+		if t.Size != nil && !t.Size.Contains(int64(len(v.Children))) {
+			return &ValidationError{path, "size " + strconv.Itoa(len(v.Children)) + " violates constraint"}
+		}
+		return nil
+	}
+	if u.Kind != Builtin || t.Kind == Reference && t.Range == nil && t.Size == nil {
+		// the content has already been validated when validating u
+		return nil
+	}
+
+	v.Tag = u.Tag
+	switch u.Tag {
+	case asn1.TagInteger, asn1.TagEnumerated:
+		v.Tag = asn1.TagInteger
+		var n big.Int
+		if err := v.Decode(&n); err != nil {
+			return &ValidationError{path, err.Error()}
+		}
+		if u.Tag == asn1.TagEnumerated && !u.Extensible && !slices.ContainsFunc(u.NamedNumbers, func(nn NamedNumber) bool {
+			return n.IsInt64() && nn.Value == n.Int64()
+		}) {
+			return &ValidationError{path, "unknown enumeration value " + n.String()}
+		}
+		if t.Range != nil && !t.Range.Extensible && !(n.IsInt64() && t.Range.Contains(n.Int64())) {
+			return &ValidationError{path, "value " + n.String() + " violates constraint"}
+		}
+		return nil
+	}
+
+	var val any
+	if err := v.Decode(&val); err != nil {
+		return &ValidationError{path, err.Error()}
+	}
+	if t.Size == nil {
+		return nil
+	}
+	var size int
+	switch val := val.(type) {
+	case asn1.BitString:
+		size = val.BitLength
+	case []byte:
+		size = len(val)
+	case ber.RawValue:
+		if val.Constructed {
+			return &ValidationError{path, "SIZE constraint not applicable to constructed encoding"}
+		}
+		size = len(val.Bytes)
+	default:
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.String {
+			return &ValidationError{path, "SIZE constraint not applicable"}
+		}
+		size = utf8.RuneCountInString(rv.String())
+	}
+	if !t.Size.Contains(int64(size)) {
+		return &ValidationError{path, "size " + strconv.Itoa(size) + " violates constraint"}
+	}
+	return nil
+}
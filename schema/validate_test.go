@@ -0,0 +1,160 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+)
+
+const testModule = `Test DEFINITIONS IMPLICIT TAGS ::= BEGIN
+	Message ::= SEQUENCE {
+		id    INTEGER (0..100),
+		name  [0] UTF8String (SIZE (1..4)) OPTIONAL,
+		kind  Kind DEFAULT request,
+		body  [1] Body,
+		...
+	}
+	Kind ::= ENUMERATED { request, response }
+	Body ::= CHOICE { text IA5String, items SEQUENCE SIZE (0..2) OF INTEGER }
+	Attrs ::= SET { a [0] BOOLEAN, b [1] NULL OPTIONAL }
+END`
+
+func TestModule_Validate(t *testing.T) {
+	m, err := Parse([]byte(testModule))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	prim := func(tag asn1.Tag, b ...byte) ber.Value {
+		return ber.Value{Tag: tag, Prim: b}
+	}
+	cons := func(tag asn1.Tag, cs ...ber.Value) ber.Value {
+		return ber.Value{Tag: tag, Constructed: true, Children: cs}
+	}
+	id := prim(asn1.TagInteger, 1)
+	text := cons(asn1.ClassContextSpecific|1, prim(asn1.TagIA5String, 'x'))
+	items := func(n int) ber.Value {
+		s := cons(asn1.TagSequence)
+		for range n {
+			s.Children = append(s.Children, prim(asn1.TagInteger, 7))
+		}
+		return cons(asn1.ClassContextSpecific|1, s)
+	}
+	tests := map[string]struct {
+		typ  string
+		val  ber.Value
+		path string // empty if valid
+	}{
+		"Valid":        {"Message", cons(asn1.TagSequence, id, prim(asn1.ClassContextSpecific|0, 'a', 'b'), text), ""},
+		"Default":      {"Message", cons(asn1.TagSequence, id, prim(asn1.TagEnumerated, 1), items(2)), ""},
+		"Extension":    {"Message", cons(asn1.TagSequence, id, text, prim(asn1.TagBoolean, 0)), ""},
+		"Set":          {"Attrs", cons(asn1.TagSet, prim(asn1.ClassContextSpecific|1), prim(asn1.ClassContextSpecific|0, 0xFF)), ""},
+		"Range":        {"Message", cons(asn1.TagSequence, prim(asn1.TagInteger, 101), text), "Message.id"},
+		"Size":         {"Message", cons(asn1.TagSequence, id, prim(asn1.ClassContextSpecific|0, 'a', 'b', 'c', 'd', 'e'), text), "Message.name"},
+		"Enumeration":  {"Message", cons(asn1.TagSequence, id, prim(asn1.TagEnumerated, 2), text), "Message.kind"},
+		"SequenceSize": {"Message", cons(asn1.TagSequence, id, items(3)), "Message.body.items"},
+		"Alternative":  {"Message", cons(asn1.TagSequence, id, cons(asn1.ClassContextSpecific|1, prim(asn1.TagNull))), "Message.body"},
+		"Missing":      {"Message", cons(asn1.TagSequence, id), "Message"},
+		"SetMissing":   {"Attrs", cons(asn1.TagSet, prim(asn1.ClassContextSpecific|1)), "Attrs"},
+		"SetDuplicate": {"Attrs", cons(asn1.TagSet, prim(asn1.ClassContextSpecific|0, 0xFF), prim(asn1.ClassContextSpecific|0, 0xFF)), "Attrs"},
+		"Tag":          {"Attrs", cons(asn1.TagSequence), "Attrs"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := ber.Marshal(tt.val)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			rv, _, err := ber.ParseRawValue(b)
+			if err != nil {
+				t.Fatalf("ParseRawValue() error = %v", err)
+			}
+			err = m.ValidateRaw(tt.typ, rv)
+			if tt.path == "" {
+				if err != nil {
+					t.Errorf("ValidateRaw() error = %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateRaw() error = %v, want *ValidationError", err)
+			}
+			if verr.Path != tt.path {
+				t.Errorf("ValidateRaw() error = %v, want path %q", err, tt.path)
+			}
+		})
+	}
+}
+
+func TestModule_ValidateContent(t *testing.T) {
+	m, err := Parse([]byte(`Test DEFINITIONS ::= BEGIN
+		P ::= PrintableString
+		B ::= [APPLICATION 2] IMPLICIT BOOLEAN
+	END`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := map[string]struct {
+		typ     string
+		val     ber.Value
+		wantErr bool
+	}{
+		"Printable":        {"P", ber.Value{Tag: asn1.TagPrintableString, Prim: []byte("abc")}, false},
+		"InvalidPrintable": {"P", ber.Value{Tag: asn1.TagPrintableString, Prim: []byte("a*c")}, true},
+		"Implicit":         {"B", ber.Value{Tag: asn1.ClassApplication | 2, Prim: []byte{0xFF}}, false},
+		"InvalidBoolean":   {"B", ber.Value{Tag: asn1.ClassApplication | 2, Prim: []byte{0xFF, 0x00}}, true},
+		"UndefinedType":    {"X", ber.Value{}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := m.Validate(tt.typ, tt.val); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModule_ValidateBytes(t *testing.T) {
+	m, err := Parse([]byte(testModule))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := map[string]struct {
+		b       []byte
+		wantErr error
+	}{
+		"Valid":     {[]byte{0x31, 0x05, 0x80, 0x01, 0xFF, 0x81, 0x00}, nil},
+		"Invalid":   {[]byte{0x31, 0x03, 0x81, 0x01, 0x00}, &ValidationError{}},
+		"Truncated": {[]byte{0x31, 0x06, 0x80, 0x01}, &ber.SyntaxError{}},
+		"ExtraData": {[]byte{0x31, 0x03, 0x80, 0x01, 0xFF, 0x00}, ber.ErrExtraData},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := m.ValidateBytes("Attrs", tt.b)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("ValidateBytes() error = %v, want nil", err)
+				}
+			case *ValidationError:
+				if !errors.As(err, &want) {
+					t.Errorf("ValidateBytes() error = %v, want *ValidationError", err)
+				}
+			case *ber.SyntaxError:
+				if !errors.As(err, &want) {
+					t.Errorf("ValidateBytes() error = %v, want *ber.SyntaxError", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Errorf("ValidateBytes() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
@@ -1,12 +1,9 @@
 package tlv
 
 import (
+	"bytes"
 	"errors"
 	"io"
-	"math"
-
-	"codello.dev/asn1"
-	"codello.dev/asn1/internal/vlq"
 )
 
 //region valueReader
@@ -146,27 +143,19 @@ type Decoder struct {
 		io.ByteReader
 	}
 	buf bufferedReader // internal buffering
+	src sliceReader    // used when decoding from a byte slice
 	val valueReader    // reused, saves allocations
 
+	// seeker is the underlying reader if it implements io.Seeker. base is the
+	// position of seeker when d was reset, corresponding to input offset 0.
+	seeker io.Seeker
+	base   int64
+
 	// peekBuf stores the bytes read during the last ReadHeader operation so we can
-	// recover from transient I/O errors. The maximum number of bytes for a valid
-	// header is:
-	//   - 1 identifier byte
-	//   - 2 bytes for the long-form tag (more would be too large or not minimally encoded)
-	//   - 1 byte for the number of length bytes
-	//   - up to 8 length bytes (more would overflow an int)
-	// We add 2 extra bytes for "integer too large" bytes and for padding.
-	//
-	// peekAt indicates the next read/write position in peekBuf and peekLen the
-	// number of valid bytes in peekBuf. peekBytes is the number of bytes read
-	// during the last ReadHeader call. This is equal to peekLen unless the length
-	// bytes have leading zeros, in which case the leading zeros will not be added
-	// to peekBuf or peekLen.
-
-	peekBuf   [14]byte
-	peekAt    int8
-	peekLen   int8
-	peekBytes int // relative to state.offset
+	// recover from transient I/O errors. peekBytes is the number of valid bytes
+	// in peekBuf, relative to state.offset.
+	peekBuf   [maxHeaderLen]byte
+	peekBytes int
 }
 
 // NewDecoder creates a new Decoder reading from r. If r does not implement
@@ -180,6 +169,15 @@ func NewDecoder(r io.Reader) *Decoder {
 	return d
 }
 
+// NewDecoderBytes creates a new Decoder reading from b. Decoding from a byte
+// slice does not require any buffering. Values of primitive TLVs can be
+// accessed without copying via [Decoder.ReadValueBytes].
+func NewDecoderBytes(b []byte) *Decoder {
+	d := new(Decoder)
+	d.ResetBytes(b)
+	return d
+}
+
 // Reset resets the state of d to read from r. See [NewDecoder] for details.
 //
 // Reset reuses the internal buffer of d which may save some allocations
@@ -198,11 +196,31 @@ func (d *Decoder) Reset(r io.Reader) {
 		d.buf.Reset(r)
 		d.br = &d.buf
 	}
-	d.val.d = nil
+	d.src.Reset(nil)
+	d.seeker, d.base = nil, 0
+	if s, ok := r.(io.Seeker); ok {
+		if pos, err := s.Seek(0, io.SeekCurrent); err == nil {
+			d.seeker, d.base = s, pos
+		}
+	}
+	d.resetPeek()
+}
 
+// ResetBytes resets the state of d to read from b. See [NewDecoderBytes] for
+// details.
+func (d *Decoder) ResetBytes(b []byte) {
+	d.state.reset()
+	d.buf.Reset(nil)
+	d.src.Reset(b)
+	d.br = &d.src
+	d.seeker, d.base = nil, 0
+	d.resetPeek()
+}
+
+// resetPeek clears the current value and the header retry buffer of d.
+func (d *Decoder) resetPeek() {
+	d.val.d = nil
 	d.peekBytes = 0
-	d.peekAt = 0
-	d.peekLen = 0
 }
 
 // ReadHeader reads the next TLV header from the input. At the end of
@@ -229,11 +247,11 @@ func (d *Decoder) ReadHeader() (Header, io.ReadCloser, error) {
 	// successful parse, consume the header
 
 	if h.Tag == TagEndOfContents {
+		d.headerBytes += d.peekBytes
 		d.state.pop(d.peekBytes)
 	} else {
 		d.state.push(h, d.peekBytes)
 	}
-	d.peekLen = 0
 	d.peekBytes = 0
 
 	// adjust buffering
@@ -250,6 +268,38 @@ func (d *Decoder) ReadHeader() (Header, io.ReadCloser, error) {
 	return h, &d.val, nil
 }
 
+// ReadValueBytes reads the unread portion of the current primitive value and
+// closes it. The value must have been opened by the preceding call to
+// [Decoder.ReadHeader].
+//
+// If d was created by [NewDecoderBytes], the returned slice aliases the input
+// and no data is copied. The caller must not modify the returned slice. For any
+// other input the value is copied into a newly allocated slice. The slice grows
+// as data is read, so that a truncated input with a large declared length
+// results in [io.ErrUnexpectedEOF].
+func (d *Decoder) ReadValueBytes() ([]byte, error) {
+	if !d.val.isValid() || d.curr.Constructed {
+		return nil, errors.New("tlv: no primitive value to read")
+	}
+	if d.br != &d.src {
+		// The length of the value is taken from the input and may be arbitrarily
+		// large, so the value is read incrementally instead of allocating a
+		// buffer of that size up front.
+		var buf bytes.Buffer
+		_, err := io.CopyN(&buf, &d.val, int64(d.val.Len()))
+		if err == nil {
+			err = d.val.Close()
+		}
+		return buf.Bytes(), noEOF(err)
+	}
+	b := d.src.Next(d.val.Len())
+	d.val.n -= len(b)
+	if d.val.Len() > 0 {
+		return b, io.ErrUnexpectedEOF
+	}
+	return b, d.val.Close()
+}
+
 // PeekHeader reads the next TLV header from the input without advancing d. You
 // can consume the peeked header using the ReadHeader method.
 //
@@ -266,7 +316,6 @@ func (d *Decoder) PeekHeader() (Header, error) {
 			return Header{}, errors.New("tlv: value not closed after reading")
 		}
 	}
-	d.peekAt = 0
 	h, err := d.readHeader()
 	if err != nil {
 		if _, ok := err.(*ioError); err == io.EOF || ok {
@@ -298,109 +347,53 @@ func (d *Decoder) readHeader() (Header, error) {
 	}
 	if h == (Header{}) && !d.root() && d.curr.Header.Length == LengthIndefinite {
 		// The end-of-contents marker is 0x0000, coinciding with the empty header.
-		return h, nil
+		return h, d.checkLimits(h, d.peekBytes)
 	}
 	if h == (Header{}) {
 		err = errUnexpectedEOC
-	} else if h.Tag == TagEndOfContents {
-		// enc-of-contents is a reserved tag
-		err = errInvalidEOC
-	} else if !h.Constructed && h.Length == LengthIndefinite {
-		err = errors.New("indefinite-length primitive data value")
 	} else if h.Length != LengthIndefinite && uint(d.peekBytes+h.Length) > uint(d.curr.Remaining()) {
 		// uint conversion takes care of indefinite length
 		err = errors.New("data value exceeds parent")
+	} else {
+		err = d.checkLimits(h, d.peekBytes)
 	}
 	return h, err
 }
 
-// decodeHeader decodes a TLV header from d. If the encoded TLV header is
-// invalid, or an I/O error occurs, an error is returned. An error is also
-// returned if the header is syntactically valid but cannot be represented by
-// the [Header] type.
+// decodeHeader decodes a TLV header from d using [ParseHeader]. Bytes are read
+// into d.peekBuf until it contains a complete header. If the encoded TLV header
+// is invalid, or an I/O error occurs, an error is returned.
 func (d *Decoder) decodeHeader() (h Header, err error) {
-	b, err := d.readByte()
-	if err != nil {
-		return Header{}, err
-	}
-	h = Header{
-		Tag:         asn1.Class(b>>6)<<14 | asn1.Tag(b&0x1f),
-		Constructed: b&0x20 == 0x20,
-	}
-
-	// If the bottom five bits are set, then the tag number is actually VLQ-encoded
-	if b&0x1f == 0x1f {
-		var n asn1.Tag
-		n, err = vlq.ReadMinimal[asn1.Tag](byteReaderFunc(d.readByte))
-		if err != nil {
-			return h, noEOF(err)
-		}
-
-		h.Tag = h.Tag.Class() | (n &^ (0b11 << 14))
-		if n > asn1.MaxTag {
-			return h, errors.New("tag number too large")
+	for {
+		h, _, err = ParseHeader(d.peekBuf[:d.peekBytes])
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			return h, err
 		}
-	}
-
-	if b, err = d.readByte(); err != nil {
-		return h, noEOF(err)
-	}
-	if b&0x80 == 0 {
-		// The length is encoded in the bottom 7 bits.
-		h.Length = int(b & 0x7f)
-	} else if b == 0x80 {
-		h.Length = LengthIndefinite
-	} else {
-		// Bottom 7 bits give the number of length bytes to follow.
-		for numBytes := int(b & 0x7f); numBytes > 0; numBytes-- {
-			if b, err = d.readByte(); err != nil {
-				return h, noEOF(err)
+		if err = d.readByte(); err != nil {
+			if d.peekBytes > 0 {
+				err = noEOF(err)
 			}
-			if h.Length > math.MaxInt>>8 {
-				// We can't shift h.length up without overflowing.
-				return h, errors.New("length too large")
-			}
-			h.Length = h.Length<<8 | int(b)
-
-			if h.Length == 0 {
-				// an actual read (no from d.peekBuf) returned a leading zero
-				// we do not store those in d.peekBuf
-				d.peekAt--
-				d.peekLen--
-			}
-		}
-		if h == (Header{}) {
-			return h, errInvalidEOC
+			return h, err
 		}
 	}
-	return h, nil
 }
 
-// readByte reads a single byte from the underlying reader of d. If d.peekLen is
-// positive, reads are made from d.peekBuf first. If d.peekLen is negative, no
-// data is read at all and an error is returned.
-//
-// Reads from the underlying reader are stored in d.peekBuf to enable the retry
-// mechanism for transient errors.
-func (d *Decoder) readByte() (b byte, err error) {
+// readByte reads a single byte from the underlying reader of d into d.peekBuf.
+// Storing the bytes in d.peekBuf enables the retry mechanism for transient
+// errors.
+func (d *Decoder) readByte() error {
 	if d.curr.Remaining() == d.peekBytes {
-		return 0, errTruncated
+		return errTruncated
 	}
-
-	if d.peekAt < d.peekLen {
-		b = d.peekBuf[d.peekAt]
-	} else if b, err = d.br.ReadByte(); err == nil {
-		d.peekBuf[d.peekAt] = b
-		d.peekLen++
-		d.peekBytes++
-	} else if err != io.EOF {
-		return 0, &ioError{"read", err}
-	} else {
-		return 0, err
+	b, err := d.br.ReadByte()
+	if err == io.EOF {
+		return err
+	} else if err != nil {
+		return &ioError{"read", err}
 	}
-
-	d.peekAt++
-	return b, nil
+	d.peekBuf[d.peekBytes] = b
+	d.peekBytes++
+	return nil
 }
 
 // discard discards the remainder of the current data value without validating
@@ -446,7 +439,6 @@ func (d *Decoder) valueDone() {
 	// d.peekBytes might be non-zero when discarding a constructed value
 	d.state.pop(d.curr.Remaining() + d.peekBytes)
 	d.peekBytes = 0
-	d.peekLen = 0
 }
 
 // Skip discards the remainder of the current data value. If it uses the primitive
@@ -494,6 +486,97 @@ func (d *Decoder) InputOffset() int64 {
 	return d.offset
 }
 
+// Seek moves d to a new input offset and resets its state, discarding any
+// buffered data. The new offset is interpreted according to whence as
+// described by [io.Seeker]. Offsets are relative to the input position when d
+// was created or reset, as reported by [Decoder.DataValueOffset],
+// [Decoder.InputOffset] and [Decoder.ValueOffsetRange]. Seek returns the new
+// offset. The next call to [Decoder.ReadHeader] reads the data value beginning
+// at that offset as if it was a top-level data value. This makes it possible to
+// record the positions of data values and later decode them again without
+// reading the preceding input.
+//
+// Seek is only supported if d reads from a byte slice or if the underlying
+// reader implements [io.Seeker]. Otherwise, an error is returned.
+func (d *Decoder) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += d.InputOffset()
+	case io.SeekEnd:
+		if d.br == &d.src {
+			offset += int64(len(d.src.b))
+		} else if d.seeker != nil {
+			end, err := d.seeker.Seek(0, io.SeekEnd)
+			if err != nil {
+				return 0, &ioError{"seek", err}
+			}
+			offset += end - d.base
+		}
+	default:
+		return 0, errors.New("tlv: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("tlv: negative offset")
+	}
+	switch {
+	case d.br == &d.src:
+		if offset > int64(len(d.src.b)) {
+			return 0, errors.New("tlv: offset out of range")
+		}
+		d.src.off = int(offset)
+	case d.seeker != nil:
+		if _, err := d.seeker.Seek(d.base+offset, io.SeekStart); err != nil {
+			return 0, &ioError{"seek", err}
+		}
+		if d.br == &d.buf {
+			d.buf.Reset(d.buf.rd)
+		}
+	default:
+		return 0, errors.New("tlv: reader does not implement io.Seeker")
+	}
+	d.state.reset()
+	d.offset = offset
+	d.resetPeek()
+	return offset, nil
+}
+
+// ValueOffsetRange returns the input byte offsets where the current data value
+// starts and ends. The start is the first byte of the identifier octets as
+// reported by [Decoder.DataValueOffset]. The end is the offset of the first
+// byte after the content octets. If the current data value uses the
+// indefinite-length format, end is -1. At the root level an empty range at the
+// current input offset is returned.
+//
+// The offsets can be passed to [Decoder.Seek] to decode the data value again.
+func (d *Decoder) ValueOffsetRange() (start, end int64) {
+	if d.root() {
+		return d.offset, d.offset
+	}
+	if d.curr.Header.Length == LengthIndefinite {
+		return d.curr.Start, -1
+	}
+	return d.curr.Start, d.offset - int64(d.curr.Offset) + int64(d.curr.Header.Length)
+}
+
+// SetMaxDepth limits the nesting depth of TLVs read by d to n. If reading a
+// TLV header would increase [Decoder.StackDepth] beyond n, a [SyntaxError] is
+// returned. A value of 0 (the default) disables the limit. The limit protects
+// against inputs with deeply nested constructed TLVs.
+//
+// The limit is retained when d is reset.
+func (d *Decoder) SetMaxDepth(n int) { d.maxDepth = max(n, 0) }
+
+// SetMaxHeaderBytes limits the total number of identifier and length octets
+// within a single top-level TLV read by d to n. This includes the header of the
+// top-level TLV itself as well as end-of-contents markers. If the limit would
+// be exceeded, a [SyntaxError] is returned. A value of 0 (the default) disables
+// the limit. The limit protects against inputs consisting of a large number of
+// small TLVs.
+//
+// The limit is retained when d is reset.
+func (d *Decoder) SetMaxHeaderBytes(n int) { d.maxHeaderBytes = max(n, 0) }
+
 // StackDepth returns the number of nested constructed TLVs of the current
 // location of d. Each level represents a constructed TLV. It is incremented
 // whenever a constructed TLV is encountered and decremented whenever a
@@ -514,4 +597,38 @@ func (d *Decoder) StackIndex(i int) Header {
 	return d.stack[i].Header
 }
 
+// Remaining returns the number of bytes remaining in the value of the
+// constructed or primitive TLV at the specified stack level. Level must be a
+// number between 0 and [Decoder.StackDepth], inclusive. Bytes already read from
+// a primitive value are taken into account. The remaining length is bounded by
+// the lengths of the enclosing TLVs. The total length of the value is available
+// via [Decoder.StackIndex].
+//
+// If the TLV at level and all enclosing TLVs use the indefinite-length
+// encoding, the remaining length is unknown and [LengthIndefinite] is
+// returned. This is always the case for level 0.
+func (d *Decoder) Remaining(level int) int {
+	e := d.curr
+	if level < len(d.stack) {
+		e = d.stack[level]
+	}
+	// e.Length is the length of the value bounded by the enclosing TLVs, not the
+	// length from the header. It is LengthIndefinite only if e and all enclosing
+	// TLVs use the indefinite-length encoding.
+	if e.Length == LengthIndefinite {
+		return LengthIndefinite
+	}
+	consumed := e.Offset
+	if level < len(d.stack) {
+		for _, s := range d.stack[level+1:] {
+			consumed += s.Offset
+		}
+		consumed += d.curr.Offset
+	}
+	if d.val.isValid() {
+		consumed += d.curr.Remaining() - d.val.Len()
+	}
+	return e.Length - consumed
+}
+
 //endregion
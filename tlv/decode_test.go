@@ -101,6 +101,9 @@ func TestDecoder_ReadHeader(t *testing.T) {
 		"InvalidEOC": {[]any{0x30, 0x80, 0x00, 0x01, 0x00},
 			[]any{Header{asn1.TagSequence, true, LengthIndefinite}, errInvalidEOC, errInvalidEOC},
 			2},
+		"LongLengthEOC": {[]any{0x30, 0x80, 0x00, 0x81, 0x00},
+			[]any{Header{asn1.TagSequence, true, LengthIndefinite}, errInvalidEOC, errInvalidEOC},
+			2},
 
 		// Testing Tag and Length Values
 		"LargeTag": {[]any{0x1F, 0x84, 0x01, 0x00},
@@ -112,6 +115,9 @@ func TestDecoder_ReadHeader(t *testing.T) {
 		"LargePaddedLength": {[]any{0x04, 0x84, 0x00, 0x00, 0x00, 0x03, 0x01, 0x02, 0x03},
 			[]any{Header{asn1.TagOctetString, false, 3}, []byte{0x01, 0x02, 0x03}, noError, io.EOF},
 			9},
+		"LongPaddedLength": {[]any{0x04, 0x90, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x05},
+			[]any{Header{asn1.TagOctetString, false, 1}, []byte{0x05}, noError, io.EOF},
+			19},
 
 		// Structural Errors
 		"ChildExceedsParent": {[]any{0x30, 0x03, 0x02, 0x02, 0x15, 0x15},
@@ -325,3 +331,263 @@ func TestDecoder_Stack(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_Remaining(t *testing.T) {
+	d := NewDecoder(bytes.NewReader([]byte{
+		0x30, 0x0B,
+		0x30, 0x80,
+		0x04, 0x03, 0x01, 0x02, 0x03,
+		0x00, 0x00,
+		0x05, 0x00,
+	}))
+	check := func(step string, want ...int) {
+		t.Helper()
+		for level, w := range want {
+			if got := d.Remaining(level); got != w {
+				t.Errorf("%s: d.Remaining(%d) = %d, want %d", step, level, got, w)
+			}
+		}
+	}
+	check("Root", LengthIndefinite)
+	if _, _, err := d.ReadHeader(); err != nil {
+		t.Fatalf("d.ReadHeader() error = %v", err)
+	}
+	check("Sequence", LengthIndefinite, 11)
+	if _, _, err := d.ReadHeader(); err != nil {
+		t.Fatalf("d.ReadHeader() error = %v", err)
+	}
+	check("Indefinite", LengthIndefinite, 9, 9)
+	_, val, err := d.ReadHeader()
+	if err != nil {
+		t.Fatalf("d.ReadHeader() error = %v", err)
+	}
+	check("Primitive", LengthIndefinite, 7, 7, 3)
+	if _, err = val.Read(make([]byte, 1)); err != nil {
+		t.Fatalf("val.Read() error = %v", err)
+	}
+	check("PartialRead", LengthIndefinite, 6, 6, 2)
+	if err = val.Close(); err != nil {
+		t.Fatalf("val.Close() error = %v", err)
+	}
+	check("AfterPrimitive", LengthIndefinite, 4, 4)
+	if _, _, err = d.ReadHeader(); err != nil {
+		t.Fatalf("d.ReadHeader() error = %v", err)
+	}
+	check("AfterEOC", LengthIndefinite, 2)
+}
+
+func TestDecoder_Remaining_nested(t *testing.T) {
+	tests := map[string]struct {
+		data []byte
+		want []int // after reading all headers
+	}{
+		"IndefiniteInDefinite": {[]byte{0x30, 0x0A, 0x30, 0x80, 0x30, 0x80, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]int{LengthIndefinite, 6, 6, 6}},
+		"DefiniteInIndefinite": {[]byte{0x30, 0x80, 0x30, 0x80, 0x30, 0x02, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]int{LengthIndefinite, LengthIndefinite, LengthIndefinite, 2}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tc.data))
+			for range len(tc.want) - 1 {
+				if _, _, err := d.ReadHeader(); err != nil {
+					t.Fatalf("d.ReadHeader() error = %v", err)
+				}
+			}
+			for level, want := range tc.want {
+				if got := d.Remaining(level); got != want {
+					t.Errorf("d.Remaining(%d) = %d, want %d", level, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoder_Limits(t *testing.T) {
+	tests := map[string]struct {
+		input          []byte
+		maxDepth       int
+		maxHeaderBytes int
+		wantErr        error
+		offset         int64
+	}{
+		"Unlimited": {[]byte{0x30, 0x80, 0x30, 0x80, 0x30, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			0, 0, io.EOF, 12},
+		"MaxDepth": {[]byte{0x30, 0x80, 0x30, 0x80, 0x30, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			2, 0, errMaxDepth, 4},
+		"MaxDepthNotExceeded": {[]byte{0x30, 0x80, 0x30, 0x80, 0x00, 0x00, 0x00, 0x00},
+			2, 0, io.EOF, 8},
+		"MaxHeaderBytes": {[]byte{0x30, 0x09, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x03},
+			0, 6, errMaxHeader, 8},
+		"MaxHeaderBytesEOC": {[]byte{0x30, 0x80, 0x02, 0x01, 0x01, 0x00, 0x00},
+			0, 5, errMaxHeader, 5},
+		"MaxHeaderBytesPerValue": {[]byte{0x30, 0x03, 0x02, 0x01, 0x01, 0x30, 0x03, 0x02, 0x01, 0x01},
+			0, 4, io.EOF, 10},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoderBytes(tc.input)
+			d.SetMaxDepth(tc.maxDepth)
+			d.SetMaxHeaderBytes(tc.maxHeaderBytes)
+			var err error
+			var val io.ReadCloser
+			for err == nil {
+				_, val, err = d.ReadHeader()
+				if err == nil && val != nil {
+					err = val.Close()
+				}
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("d.ReadHeader() error = %v, want %v", err, tc.wantErr)
+			}
+			if d.InputOffset() != tc.offset {
+				t.Errorf("d.InputOffset() = %d, want %d", d.InputOffset(), tc.offset)
+			}
+		})
+	}
+}
+
+func TestDecoder_ReadValueBytes(t *testing.T) {
+	data := []byte{0x30, 0x07, 0x04, 0x02, 0x01, 0x02, 0x02, 0x01, 0x15}
+	tests := map[string]*Decoder{
+		"Bytes":  NewDecoderBytes(data),
+		"Reader": NewDecoder(bytes.NewReader(data)),
+	}
+	for name, d := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := d.ReadHeader(); err != nil {
+				t.Fatalf("d.ReadHeader() returned an unexpected error: %s", err)
+			}
+			if _, err := d.ReadValueBytes(); err == nil {
+				t.Errorf("d.ReadValueBytes() on constructed value did not return an error")
+			}
+			if _, _, err := d.ReadHeader(); err != nil {
+				t.Fatalf("d.ReadHeader() returned an unexpected error: %s", err)
+			}
+			got, err := d.ReadValueBytes()
+			if err != nil {
+				t.Fatalf("d.ReadValueBytes() returned an unexpected error: %s", err)
+			}
+			if !bytes.Equal(got, data[4:6]) {
+				t.Errorf("d.ReadValueBytes() = % x, want % x", got, data[4:6])
+			}
+			if aliased := &got[0] == &data[4]; aliased != (name == "Bytes") {
+				t.Errorf("d.ReadValueBytes() aliases input = %t, want %t", aliased, name == "Bytes")
+			}
+			_, val, err := d.ReadHeader()
+			if err != nil {
+				t.Fatalf("d.ReadHeader() returned an unexpected error: %s", err)
+			}
+			if b, _ := val.(io.ByteReader).ReadByte(); b != 0x15 {
+				t.Errorf("val.ReadByte() = %x, want 0x15", b)
+			}
+			if got, err = d.ReadValueBytes(); err != nil || len(got) != 0 {
+				t.Errorf("d.ReadValueBytes() = % x, %v, want empty slice", got, err)
+			}
+			if d.InputOffset() != int64(len(data)) {
+				t.Errorf("d.InputOffset() = %d, want %d", d.InputOffset(), len(data))
+			}
+		})
+	}
+
+	t.Run("Truncated", func(t *testing.T) {
+		d := NewDecoderBytes([]byte{0x04, 0x03, 0x01})
+		if _, _, err := d.ReadHeader(); err != nil {
+			t.Fatalf("d.ReadHeader() returned an unexpected error: %s", err)
+		}
+		if _, err := d.ReadValueBytes(); err != io.ErrUnexpectedEOF {
+			t.Errorf("d.ReadValueBytes() error = %v, want %v", err, io.ErrUnexpectedEOF)
+		}
+	})
+
+	t.Run("HugeLength", func(t *testing.T) {
+		// The declared length must not be allocated up front.
+		d := NewDecoder(bytes.NewReader([]byte{0x04, 0x88, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}))
+		if _, _, err := d.ReadHeader(); err != nil {
+			t.Fatalf("d.ReadHeader() returned an unexpected error: %s", err)
+		}
+		if _, err := d.ReadValueBytes(); err != io.ErrUnexpectedEOF {
+			t.Errorf("d.ReadValueBytes() error = %v, want %v", err, io.ErrUnexpectedEOF)
+		}
+	})
+}
+
+// readSeeker implements io.ReadSeeker but not io.ByteReader so that the
+// internal buffering of Decoder is used.
+type readSeeker struct {
+	r *bytes.Reader
+}
+
+func (r readSeeker) Read(p []byte) (int, error)                   { return r.r.Read(p) }
+func (r readSeeker) Seek(offset int64, whence int) (int64, error) { return r.r.Seek(offset, whence) }
+
+func TestDecoder_Seek(t *testing.T) {
+	data := []byte{0xFF, 0x30, 0x0A, 0x04, 0x02, 0x01, 0x02, 0x30, 0x80, 0x02, 0x01, 0x15, 0x00, 0x00}
+	newReader := func() *bytes.Reader {
+		r := bytes.NewReader(data)
+		_, _ = r.Seek(1, io.SeekStart)
+		return r
+	}
+	tests := map[string]*Decoder{
+		"Bytes":    NewDecoderBytes(data[1:]),
+		"Reader":   NewDecoder(newReader()),
+		"Buffered": NewDecoder(readSeeker{newReader()}),
+	}
+	for name, d := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := d.ReadHeader(); err != nil {
+				t.Fatalf("d.ReadHeader() returned an unexpected error: %s", err)
+			}
+			if start, end := d.ValueOffsetRange(); start != 0 || end != 12 {
+				t.Errorf("d.ValueOffsetRange() = %d, %d, want 0, 12", start, end)
+			}
+			_, val, _ := d.ReadHeader()
+			_ = val.Close()
+			if start, end := d.ValueOffsetRange(); start != 0 || end != 12 {
+				t.Errorf("d.ValueOffsetRange() after value = %d, %d, want 0, 12", start, end)
+			}
+			if _, _, err := d.ReadHeader(); err != nil {
+				t.Fatalf("d.ReadHeader() returned an unexpected error: %s", err)
+			}
+			if start, end := d.ValueOffsetRange(); start != 6 || end != -1 {
+				t.Errorf("d.ValueOffsetRange() = %d, %d, want 6, -1", start, end)
+			}
+			_, val, _ = d.ReadHeader()
+			start, end := d.ValueOffsetRange()
+			if start != 8 || end != 11 {
+				t.Errorf("d.ValueOffsetRange() = %d, %d, want 8, 11", start, end)
+			}
+			_ = val.Close()
+
+			if off, err := d.Seek(start, io.SeekStart); err != nil || off != start {
+				t.Fatalf("d.Seek() = %d, %v, want %d", off, err, start)
+			}
+			h, _, err := d.ReadHeader()
+			if err != nil {
+				t.Fatalf("d.ReadHeader() after Seek returned an unexpected error: %s", err)
+			}
+			if h != (Header{asn1.TagInteger, false, 1}) || d.StackDepth() != 1 {
+				t.Errorf("d.ReadHeader() after Seek = %v at depth %d, want INTEGER at depth 1", h, d.StackDepth())
+			}
+			if b, err := d.ReadValueBytes(); err != nil || !bytes.Equal(b, []byte{0x15}) {
+				t.Errorf("d.ReadValueBytes() = % x, %v, want 15", b, err)
+			}
+			if d.InputOffset() != end {
+				t.Errorf("d.InputOffset() = %d, want %d", d.InputOffset(), end)
+			}
+			if off, err := d.Seek(-3, io.SeekEnd); err != nil || off != 10 {
+				t.Errorf("d.Seek(-3, io.SeekEnd) = %d, %v, want 10", off, err)
+			}
+			if off, err := d.Seek(-1, io.SeekCurrent); err != nil || off != 9 {
+				t.Errorf("d.Seek(-1, io.SeekCurrent) = %d, %v, want 9", off, err)
+			}
+		})
+	}
+
+	t.Run("NotSeekable", func(t *testing.T) {
+		d := NewDecoder(&testDataReader{[]any{0x05, 0x00}})
+		if _, err := d.Seek(0, io.SeekStart); err == nil {
+			t.Errorf("d.Seek() on non-seekable reader did not return an error")
+		}
+	})
+}
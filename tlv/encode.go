@@ -5,9 +5,10 @@ import (
 	"io"
 	"iter"
 	"math/bits"
+	"slices"
 
 	"codello.dev/asn1"
-	"codello.dev/asn1/internal/vlq"
+	"codello.dev/asn1/vlq"
 )
 
 //region valueWriter
@@ -130,6 +131,12 @@ type Encoder struct {
 	// yield is used by [Sequence] to pause the encoding of values after the first
 	// WriteHeader call in order to calculate the total length of a value.
 	yield func(Header, error) bool
+
+	// definite enables the definite-length mode. In this mode, top-level TLVs
+	// using the indefinite-length encoding are buffered in patch and converted
+	// into the definite-length encoding when they are complete.
+	definite bool
+	patch    patchBuffer
 }
 
 // NewEncoder creates a new [Encoder] writing to w. If w does not implement
@@ -160,6 +167,7 @@ func (e *Encoder) Reset(w io.Writer) {
 		e.wr = &e.buf
 	}
 	e.val.e = nil
+	e.patch = patchBuffer{buf: e.patch.buf[:0]}
 
 	e.peekLen = 0
 }
@@ -201,6 +209,7 @@ func (e *Encoder) WriteHeader(h Header) (io.WriteCloser, error) {
 	}
 
 	if h.Tag == TagEndOfContents {
+		e.headerBytes += int(e.peekAt)
 		e.state.pop(int(e.peekAt))
 	} else {
 		e.state.push(h, int(e.peekAt))
@@ -232,12 +241,20 @@ func (e *Encoder) writeHeader(h Header) error {
 			return errUnexpectedEOC
 		}
 		if e.curr.Header.Length == LengthIndefinite {
+			if err := e.checkLimits(h, HeaderSize(h)); err != nil {
+				return err
+			}
 			if err := e.encodeHeader(h); err != nil {
 				return err
 			}
 		}
 		if e.StackDepth() == 1 {
 			// We have ended a top level data value
+			if e.patch.w != nil {
+				if err := e.flushPatch(); err != nil {
+					return err
+				}
+			}
 			if err := e.buf.Flush(); err != nil {
 				return &ioError{"write", err}
 			}
@@ -249,8 +266,21 @@ func (e *Encoder) writeHeader(h Header) error {
 		return errors.New("indefinite-length primitive data value")
 	} else if h.Length != LengthIndefinite && uint(HeaderSize(h)+h.Length) > uint(e.curr.Remaining()) {
 		return errors.New("data value exceeds parent")
+	} else if err := e.checkLimits(h, HeaderSize(h)); err != nil {
+		return err
 	}
 
+	if e.definite && e.state.root() && h.Length == LengthIndefinite && e.patch.w == nil {
+		// buffer the top-level data value until its length is known
+		e.patch.w = e.wr
+		e.wr = &e.patch
+		if err := e.encodeHeader(h); err != nil {
+			// the data value has not been started
+			e.endPatch()
+			return err
+		}
+		return nil
+	}
 	return e.encodeHeader(h)
 }
 
@@ -351,6 +381,19 @@ func (e *Encoder) valueDone() error {
 	return nil
 }
 
+// SetDefiniteLength enables or disables the definite-length mode of e. In this
+// mode, constructed TLVs can be written with [LengthIndefinite] even if the
+// output must only use the definite-length encoding, as required by DER. The
+// encoding of a top-level TLV that uses the indefinite-length encoding is
+// buffered in memory. When its end-of-contents marker is written, the lengths
+// of all nested constructed TLVs are back-patched and the definite-length
+// encoding is written to the underlying writer. Top-level TLVs using the
+// definite-length encoding are written as usual.
+//
+// In definite-length mode the offsets reported by e refer to the
+// indefinite-length encoding of the TLVs. The mode is retained when e is reset.
+func (e *Encoder) SetDefiniteLength(enabled bool) { e.definite = enabled }
+
 // DataValueOffset returns the output byte offset where the current data value
 // begins. This is the first byte of the identifier octets of the current data
 // value.
@@ -370,6 +413,22 @@ func (e *Encoder) OutputOffset() int64 {
 	return e.offset + int64(e.peekAt)
 }
 
+// SetMaxDepth limits the nesting depth of TLVs written by e to n. If writing a
+// TLV header would increase [Encoder.StackDepth] beyond n, a [SyntaxError] is
+// returned. A value of 0 (the default) disables the limit.
+//
+// The limit is retained when e is reset.
+func (e *Encoder) SetMaxDepth(n int) { e.maxDepth = max(n, 0) }
+
+// SetMaxHeaderBytes limits the total number of identifier and length octets
+// within a single top-level TLV written by e to n. This includes the header of
+// the top-level TLV itself as well as end-of-contents markers. If the limit
+// would be exceeded, a [SyntaxError] is returned. A value of 0 (the default)
+// disables the limit.
+//
+// The limit is retained when e is reset.
+func (e *Encoder) SetMaxHeaderBytes(n int) { e.maxHeaderBytes = max(n, 0) }
+
 // StackDepth returns the depth of nested constructed TLVs that have been opened
 // and not closed by WriteHeader. Each level on the stack represents a
 // constructed TLV. It is incremented whenever a constructed TLV is encountered
@@ -391,6 +450,229 @@ func (e *Encoder) StackIndex(i int) Header {
 	return e.stack[i].Header
 }
 
+// WriteRaw writes a complete TLV consisting of the header h and the value
+// bytes. If h indicates the use of the primitive encoding, value contains the
+// content octets. If h is constructed, value contains the encodings of the
+// nested TLVs. The end-of-contents marker of the constructed TLV is written by
+// WriteRaw and must not be included in value, even if h uses the
+// indefinite-length encoding. If h uses the definite-length encoding, h.Length
+// must be equal to len(value).
+//
+// The nested TLVs in value are validated before anything is written. If the
+// underlying writer returns an error, the state of e is undefined and WriteRaw
+// cannot be retried.
+func (e *Encoder) WriteRaw(h Header, value []byte) error {
+	if h.Length != LengthIndefinite && h.Length != len(value) || h.Tag == TagEndOfContents {
+		return &SyntaxError{ByteOffset: e.OutputOffset(), Header: e.curr.Header, Err: errors.New("value does not match header")}
+	}
+	if !h.Constructed {
+		w, err := e.WriteHeader(h)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(value); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+
+	d := NewDecoderBytes(value)
+	for {
+		_, val, err := d.ReadHeader()
+		if err == io.EOF {
+			break
+		} else if err == nil && val != nil {
+			err = val.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := e.WriteHeader(h); err != nil {
+		return err
+	}
+	d.ResetBytes(value)
+	for {
+		if err := e.CopyFrom(d); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	_, err := e.WriteHeader(EndOfContents)
+	return err
+}
+
+// CopyFrom reads the next TLV from d and writes it to e. If the TLV is
+// constructed, all nested TLVs up to and including the matching
+// end-of-contents marker are copied as well. This enables efficient filtering
+// or rewriting of TLV streams without decoding the copied values. The copied
+// TLVs use the same definite or indefinite-length encoding as the input.
+//
+// If d has no more TLVs at the current level, CopyFrom returns io.EOF. This is
+// the case at the end of the input or if the next header read from d would be
+// an end-of-contents marker. If an error occurs while reading from d or writing
+// to e, the states of d and e are undefined and CopyFrom cannot be retried.
+func (e *Encoder) CopyFrom(d *Decoder) error {
+	return e.copyFrom(d, nil)
+}
+
+// copyFrom implements [Encoder.CopyFrom]. The values of primitive TLVs are
+// copied using buf. If buf is nil, a buffer is allocated as needed.
+func (e *Encoder) copyFrom(d *Decoder, buf []byte) error {
+	h, err := d.PeekHeader()
+	if err != nil {
+		return err
+	} else if h.Tag == TagEndOfContents {
+		return io.EOF
+	}
+	depth := d.StackDepth()
+	for {
+		h, val, err := d.ReadHeader()
+		if err != nil {
+			return noEOF(err)
+		}
+		w, err := e.WriteHeader(h)
+		if err != nil {
+			return err
+		}
+		if val != nil {
+			if _, err = io.CopyBuffer(w, val, buf); err == nil {
+				err = val.Close()
+			}
+			if err == nil {
+				err = w.Close()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if d.StackDepth() == depth {
+			return nil
+		}
+	}
+}
+
+// Copy reads all TLVs from src and writes them to dst until src returns io.EOF.
+// If src is positioned within a constructed TLV, only the remaining TLVs
+// within that TLV are copied and the end-of-contents marker is not consumed.
+// The structure of the TLVs is validated by both src and dst as they are
+// copied. Values are streamed through a fixed-size buffer, so that
+// arbitrarily large TLVs can be relayed using a constant amount of memory.
+//
+// If src reaches the end of the input, Copy returns nil. Otherwise, the first
+// error encountered while reading or writing is returned. The states of src
+// and dst are undefined after an error.
+func Copy(dst *Encoder, src *Decoder) error {
+	buf := make([]byte, 4096)
+	for {
+		if err := dst.copyFrom(src, buf); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+//endregion
+
+//region patchBuffer
+
+// patchBuffer buffers the indefinite-length encoding of a top-level TLV in
+// definite-length mode. See [Encoder.SetDefiniteLength].
+type patchBuffer struct {
+	w interface { // the writer of the Encoder, nil if not buffering
+		io.Writer
+		io.ByteWriter
+	}
+	buf []byte
+	out []byte // the definite-length encoding of buf, once complete
+	n   int    // number of bytes of out written to w
+}
+
+func (b *patchBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *patchBuffer) WriteByte(c byte) error {
+	b.buf = append(b.buf, c)
+	return nil
+}
+
+// flushPatch writes the definite-length encoding of the buffered top-level TLV
+// to the underlying writer and ends buffering. If the underlying writer returns
+// an error, flushPatch can be retried. If the buffered data is not a single
+// valid TLV, it is discarded and an error is returned.
+func (e *Encoder) flushPatch() error {
+	if e.patch.out == nil {
+		out, rest, err := appendDefinite(make([]byte, 0, len(e.patch.buf)), e.patch.buf)
+		if err == nil && len(rest) > 0 {
+			err = errors.New("trailing data")
+		}
+		if err != nil {
+			e.endPatch()
+			return errors.New("invalid buffered data value: " + err.Error())
+		}
+		e.patch.out = out
+	}
+	for e.patch.n < len(e.patch.out) {
+		n, err := e.patch.w.Write(e.patch.out[e.patch.n:])
+		e.patch.n += n
+		if err != nil {
+			return &ioError{"write", err}
+		}
+	}
+	e.endPatch()
+	return nil
+}
+
+// endPatch ends buffering and restores the underlying writer of e. Any buffered
+// data is discarded.
+func (e *Encoder) endPatch() {
+	e.wr = e.patch.w
+	e.patch = patchBuffer{buf: e.patch.buf[:0]}
+}
+
+// appendDefinite appends the definite-length encoding of the TLV at the start
+// of b to dst. The lengths of all constructed TLVs are recalculated. The
+// extended buffer and the remainder of b are returned.
+func appendDefinite(dst, b []byte) ([]byte, []byte, error) {
+	h, n, err := ParseHeader(b)
+	if err != nil {
+		return dst, b, noEOF(err)
+	} else if h == EndOfContents {
+		// end-of-contents of an enclosing TLV is handled by the caller
+		return dst, b, errUnexpectedEOC
+	}
+	b = b[n:]
+	if h.Length != LengthIndefinite && h.Length > len(b) {
+		return dst, b, io.ErrUnexpectedEOF
+	}
+	if !h.Constructed {
+		return append(h.AppendTo(dst), b[:h.Length]...), b[h.Length:], nil
+	}
+	content, rest := b, []byte(nil)
+	if h.Length != LengthIndefinite {
+		content, rest = b[:h.Length], b[h.Length:]
+	}
+	start := len(dst)
+	for {
+		if h.Length == LengthIndefinite && len(content) >= 2 && content[0] == 0 && content[1] == 0 {
+			rest = content[2:]
+			break
+		} else if h.Length != LengthIndefinite && len(content) == 0 {
+			break
+		}
+		if dst, content, err = appendDefinite(dst, content); err != nil {
+			return dst, content, err
+		}
+	}
+	h.Length = len(dst) - start
+	var hdr [12]byte
+	return slices.Insert(dst, start, h.AppendTo(hdr[:0])...), rest, nil
+}
+
 //endregion
 
 //region Sequence
@@ -159,6 +159,38 @@ func TestEncoder_WriteHeader(t *testing.T) {
 	}
 }
 
+func TestEncoder_Limits(t *testing.T) {
+	tests := map[string]struct {
+		input          []Header
+		maxDepth       int
+		maxHeaderBytes int
+		wantErr        error
+	}{
+		"MaxDepth": {[]Header{{asn1.TagSequence, true, LengthIndefinite}, {asn1.TagSequence, true, LengthIndefinite}, {asn1.TagSequence, true, LengthIndefinite}},
+			2, 0, errMaxDepth},
+		"MaxHeaderBytes": {[]Header{{asn1.TagSequence, true, LengthIndefinite}, {asn1.TagSequence, true, 0}, EndOfContents, EndOfContents},
+			0, 5, errMaxHeader},
+		"WithinLimits": {[]Header{{asn1.TagSequence, true, LengthIndefinite}, {asn1.TagSequence, true, 0}, EndOfContents, EndOfContents},
+			2, 6, nil},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEncoder(io.Discard)
+			e.SetMaxDepth(tc.maxDepth)
+			e.SetMaxHeaderBytes(tc.maxHeaderBytes)
+			var err error
+			for _, h := range tc.input {
+				if _, err = e.WriteHeader(h); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("e.WriteHeader() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestSequence(t *testing.T) {
 	encodeInt := func(enc *Encoder) error {
 		val, err := enc.WriteHeader(Header{asn1.TagInteger, false, 1})
@@ -251,3 +283,204 @@ func ExampleSequence() {
 	fmt.Printf("%# x\n", out.Bytes())
 	// Output: 0x30 0x03 0x02 0x01 0x15
 }
+
+func TestEncoder_WriteRaw(t *testing.T) {
+	tests := map[string]struct {
+		h       Header
+		value   []byte
+		want    []byte
+		wantErr bool
+	}{
+		"Primitive":  {Header{asn1.TagInteger, false, 1}, []byte{0x05}, []byte{0x02, 0x01, 0x05}, false},
+		"Definite":   {Header{asn1.TagSequence, true, 3}, []byte{0x01, 0x01, 0xFF}, []byte{0x30, 0x03, 0x01, 0x01, 0xFF}, false},
+		"Indefinite": {Header{asn1.TagSequence, true, LengthIndefinite}, []byte{0x05, 0x00}, []byte{0x30, 0x80, 0x05, 0x00, 0x00, 0x00}, false},
+		"Nested": {Header{asn1.TagSequence, true, 6}, []byte{0x30, 0x80, 0x05, 0x00, 0x00, 0x00},
+			[]byte{0x30, 0x06, 0x30, 0x80, 0x05, 0x00, 0x00, 0x00}, false},
+		"LengthMismatch":  {Header{asn1.TagInteger, false, 2}, []byte{0x05}, nil, true},
+		"InvalidNested":   {Header{asn1.TagSequence, true, 2}, []byte{0x02, 0x05}, nil, true},
+		"IndefinitePrim":  {Header{asn1.TagInteger, false, LengthIndefinite}, []byte{0x05}, nil, true},
+		"EndOfContents":   {EndOfContents, nil, nil, true},
+		"TruncatedNested": {Header{asn1.TagSequence, true, LengthIndefinite}, []byte{0x30, 0x80}, nil, true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			err := e.WriteRaw(tc.h, tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("WriteRaw() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !bytes.Equal(buf.Bytes(), tc.want) {
+				t.Errorf("WriteRaw() wrote % X, want % X", buf.Bytes(), tc.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_CopyFrom(t *testing.T) {
+	// INTEGER 5, SEQUENCE { BOOLEAN TRUE, SEQUENCE {} }, NULL
+	input := []byte{0x02, 0x01, 0x05, 0x30, 0x80, 0x01, 0x01, 0xFF, 0x30, 0x00, 0x00, 0x00, 0x05, 0x00}
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	d := NewDecoder(bytes.NewReader(input))
+	for {
+		h, err := d.PeekHeader()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("PeekHeader() error = %v", err)
+		}
+		if h.Tag == asn1.TagInteger {
+			// filter INTEGER values
+			if _, _, err = d.ReadHeader(); err == nil {
+				err = d.Skip()
+			}
+		} else {
+			err = e.CopyFrom(d)
+		}
+		if err != nil {
+			t.Fatalf("CopyFrom() error = %v", err)
+		}
+	}
+	want := input[3:]
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("CopyFrom() wrote % X, want % X", buf.Bytes(), want)
+	}
+	if err := e.CopyFrom(d); err != io.EOF {
+		t.Errorf("CopyFrom() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	large := append([]byte{0x04, 0x82, 0x27, 0x10}, bytes.Repeat([]byte{0xAB}, 10000)...)
+	tests := map[string]struct {
+		input   []byte
+		wantErr bool
+	}{
+		"Empty":     {nil, false},
+		"Nested":    {[]byte{0x30, 0x80, 0x01, 0x01, 0xFF, 0x30, 0x00, 0x00, 0x00, 0x05, 0x00}, false},
+		"Large":     {append([]byte{0x30, 0x82, 0x27, 0x14}, large...), false},
+		"Truncated": {[]byte{0x30, 0x05, 0x02, 0x01, 0x05}, true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Copy(NewEncoder(&buf), NewDecoder(struct{ io.Reader }{bytes.NewReader(tc.input)}))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Copy() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && !bytes.Equal(buf.Bytes(), tc.input) {
+				t.Errorf("Copy() wrote % X, want % X", buf.Bytes(), tc.input)
+			}
+		})
+	}
+}
+
+func TestEncoder_SetDefiniteLength(t *testing.T) {
+	large := bytes.Repeat([]byte{0xAB}, 200)
+	tests := map[string]struct {
+		input []byte
+		want  []byte
+	}{
+		"Primitive": {[]byte{0x02, 0x01, 0x05}, []byte{0x02, 0x01, 0x05}},
+		"Indefinite": {[]byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x30, 0x80, 0x04, 0x01, 0xFF, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x30, 0x08, 0x02, 0x01, 0x05, 0x30, 0x03, 0x04, 0x01, 0xFF}},
+		"Empty": {[]byte{0x30, 0x80, 0x00, 0x00}, []byte{0x30, 0x00}},
+		"DefiniteInIndefinite": {[]byte{0x30, 0x80, 0x30, 0x06, 0x30, 0x80, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x30, 0x06, 0x30, 0x04, 0x30, 0x02, 0x05, 0x00}},
+		"Definite": {[]byte{0x30, 0x06, 0x30, 0x80, 0x05, 0x00, 0x00, 0x00},
+			[]byte{0x30, 0x06, 0x30, 0x80, 0x05, 0x00, 0x00, 0x00}},
+		"LongLength": {append(append([]byte{0x30, 0x80, 0x04, 0x81, 0xC8}, large...), 0x00, 0x00),
+			append([]byte{0x30, 0x81, 0xCB, 0x04, 0x81, 0xC8}, large...)},
+		"Multiple": {[]byte{0x30, 0x80, 0x00, 0x00, 0x05, 0x00, 0x31, 0x80, 0x05, 0x00, 0x00, 0x00},
+			[]byte{0x30, 0x00, 0x05, 0x00, 0x31, 0x02, 0x05, 0x00}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.SetDefiniteLength(true)
+			if err := Copy(e, NewDecoder(bytes.NewReader(tc.input))); err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tc.want) {
+				t.Errorf("Copy() wrote % X, want % X", buf.Bytes(), tc.want)
+			}
+		})
+	}
+
+	t.Run("Retry", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := &testErrorWriter{wr: &buf, n: 3, err: transientError(0)}
+		e := NewEncoder(w)
+		e.SetDefiniteLength(true)
+		for _, h := range []Header{{asn1.TagSequence, true, LengthIndefinite}, {asn1.TagNull, false, 0}, {asn1.TagNull, false, 0}} {
+			w, err := e.WriteHeader(h)
+			if err != nil {
+				t.Fatalf("WriteHeader(%s) error = %v", h, err)
+			}
+			if w != nil {
+				_ = w.Close()
+			}
+		}
+		if buf.Len() > 0 {
+			t.Fatalf("WriteHeader() wrote % X before end of value", buf.Bytes())
+		}
+		if _, err := e.WriteHeader(EndOfContents); !errors.Is(err, transientError(0)) {
+			t.Fatalf("WriteHeader(EndOfContents) error = %v, want transient error", err)
+		}
+		if _, err := e.WriteHeader(EndOfContents); err != nil {
+			t.Fatalf("WriteHeader(EndOfContents) error = %v", err)
+		}
+		want := []byte{0x30, 0x04, 0x05, 0x00, 0x05, 0x00}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("WriteHeader() wrote % X, want % X", buf.Bytes(), want)
+		}
+	})
+
+	t.Run("RejectedHeader", func(t *testing.T) {
+		// A rejected top-level header must not start buffering.
+		var buf bytes.Buffer
+		w := &testErrorWriter{wr: &buf, n: 1, err: transientError(0)}
+		e := NewEncoder(w)
+		e.SetDefiniteLength(true)
+		if _, err := e.WriteHeader(Header{asn1.TagBoolean, false, 1}); !errors.Is(err, transientError(0)) {
+			t.Fatalf("WriteHeader() error = %v, want transient error", err)
+		}
+		if _, err := e.WriteHeader(Header{asn1.TagSequence, true, LengthIndefinite}); err == nil {
+			t.Fatalf("WriteHeader() error = nil, want error")
+		}
+		buf.Reset()
+		for _, h := range []Header{{asn1.TagNull, false, 0}, {asn1.TagSequence, true, LengthIndefinite}, {asn1.TagNull, false, 0}, EndOfContents} {
+			w, err := e.WriteHeader(h)
+			if err == nil && w != nil {
+				err = w.Close()
+			}
+			if err != nil {
+				t.Fatalf("WriteHeader(%s) error = %v", h, err)
+			}
+		}
+		want := []byte{0x05, 0x00, 0x30, 0x02, 0x05, 0x00}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("WriteHeader() wrote % X, want % X", buf.Bytes(), want)
+		}
+	})
+
+	t.Run("InvalidBuffer", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetDefiniteLength(true)
+		if _, err := e.WriteHeader(Header{asn1.TagSequence, true, LengthIndefinite}); err != nil {
+			t.Fatalf("WriteHeader() error = %v", err)
+		}
+		// simulate a buffer that does not hold a valid TLV
+		e.patch.buf = append(e.patch.buf, 0x02, 0x05)
+		var se *SyntaxError
+		if _, err := e.WriteHeader(EndOfContents); !errors.As(err, &se) {
+			t.Fatalf("WriteHeader(EndOfContents) error = %v, want %T", err, se)
+		}
+		if buf.Len() > 0 {
+			t.Errorf("WriteHeader() wrote % X, want nothing", buf.Bytes())
+		}
+	})
+}
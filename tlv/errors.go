@@ -11,12 +11,14 @@ var (
 	errInvalidEOC    = errors.New("invalid end of contents")
 	errTruncated     = errors.New("truncated data value")
 	errClosed        = errors.New("tlv: value closed")
+	errMaxDepth      = errors.New("maximum nesting depth exceeded")
+	errMaxHeader     = errors.New("maximum number of header bytes exceeded")
 )
 
 // ioError represents an error that occurred when reading from or writing to an
 // underlying data stream.
 type ioError struct {
-	action string // either "read" or "write"
+	action string // either "read", "write" or "seek"
 	err    error
 }
 
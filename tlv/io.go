@@ -53,6 +53,7 @@ func (b *bufferedReader) Reset(r io.Reader) {
 	b.r = 0
 	b.w = 0
 	b.lim = 0
+	b.err = nil
 }
 
 // SetLimit configures the buffer limit of b. b will not read more than n bytes
@@ -213,6 +214,65 @@ func (b *bufferedReader) Discard(n int) (discarded int, err error) {
 
 //endregion
 
+//region sliceReader
+
+// sliceReader reads from an in-memory byte slice. In contrast to
+// [bytes.Reader] it gives access to the underlying slice so that values can be
+// returned without copying.
+type sliceReader struct {
+	b   []byte
+	off int
+}
+
+// Reset resets r to read from b.
+func (r *sliceReader) Reset(b []byte) {
+	r.b = b
+	r.off = 0
+}
+
+// Read implements [io.Reader].
+func (r *sliceReader) Read(p []byte) (n int, err error) {
+	if r.off >= len(r.b) {
+		return 0, io.EOF
+	}
+	n = copy(p, r.b[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// ReadByte implements [io.ByteReader].
+func (r *sliceReader) ReadByte() (byte, error) {
+	if r.off >= len(r.b) {
+		return 0, io.EOF
+	}
+	r.off++
+	return r.b[r.off-1], nil
+}
+
+// Discard skips the next n bytes. If fewer than n bytes remain, all remaining
+// bytes are discarded and io.EOF is returned.
+func (r *sliceReader) Discard(n int) (discarded int, err error) {
+	discarded = min(n, len(r.b)-r.off)
+	r.off += discarded
+	if discarded < n {
+		err = io.EOF
+	}
+	return discarded, err
+}
+
+// Next returns a slice containing the next n bytes and advances r. If fewer
+// than n bytes remain, all remaining bytes are returned. The capacity of the
+// returned slice is limited to its length so that appending to it does not
+// modify the underlying buffer.
+func (r *sliceReader) Next(n int) []byte {
+	n = min(n, len(r.b)-r.off)
+	b := r.b[r.off : r.off+n : r.off+n]
+	r.off += n
+	return b
+}
+
+//endregion
+
 // byteWriterFunc is a function that can write a single byte to an underlying
 // byte stream. It implements [io.ByteWriter].
 type byteWriterFunc func(byte) error
@@ -0,0 +1,140 @@
+package tlv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// ScanValues is a split function for a [bufio.Scanner] that returns the
+// complete encoding of each top-level data value as a token. The contents of
+// the data values are not decoded, but nested indefinite-length encodings are
+// traversed to find their end. If the input is not a valid TLV encoding, a
+// [*SyntaxError] is returned whose ByteOffset is relative to the start of the
+// data value.
+func ScanValues(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	n, err := valueSize(data)
+	if err == io.ErrUnexpectedEOF {
+		if atEOF {
+			return 0, nil, &SyntaxError{Err: io.ErrUnexpectedEOF}
+		}
+		return 0, nil, nil
+	} else if err != nil {
+		return 0, nil, err
+	}
+	return n, data[:n], nil
+}
+
+// valueSize returns the number of bytes of the data value encoding at the
+// start of b. If b does not contain the complete encoding, io.ErrUnexpectedEOF
+// is returned.
+func valueSize(b []byte) (int, error) {
+	pos, depth := 0, 0
+	for {
+		h, n, err := ParseHeader(b[pos:])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return 0, &SyntaxError{Err: err, ByteOffset: int64(pos)}
+		}
+		switch {
+		case h == EndOfContents:
+			if depth == 0 {
+				return 0, &SyntaxError{Err: errUnexpectedEOC, ByteOffset: int64(pos)}
+			}
+			depth--
+			pos += n
+		case h.Length == LengthIndefinite:
+			depth++
+			pos += n
+			continue
+		default:
+			if h.Length > len(b)-pos-n {
+				return 0, io.ErrUnexpectedEOF
+			}
+			pos += n + h.Length
+		}
+		if depth == 0 {
+			return pos, nil
+		}
+	}
+}
+
+// A Scanner splits a stream of concatenated top-level data values into the
+// encodings of the individual data values, similar to a [bufio.Scanner]. This
+// can be used to dispatch raw messages to workers without decoding them:
+//
+//	s := tlv.NewScanner(conn)
+//	for s.Scan() {
+//		msgs <- bytes.Clone(s.Bytes())
+//	}
+//	if err := s.Err(); err != nil {
+//		// handle error
+//	}
+//
+// The size of a single data value encoding is limited to
+// [bufio.MaxScanTokenSize] bytes by default. Use [Scanner.Buffer] to change
+// the limit.
+type Scanner struct {
+	s          *bufio.Scanner
+	start, end int64
+	err        error
+}
+
+// NewScanner returns a new Scanner reading from r.
+func NewScanner(r io.Reader) *Scanner {
+	s := bufio.NewScanner(r)
+	s.Split(ScanValues)
+	return &Scanner{s: s}
+}
+
+// Buffer sets the initial buffer to use when scanning and the maximum size of
+// a data value encoding. See [bufio.Scanner.Buffer] for details. Buffer panics
+// if it is called after scanning has started.
+func (s *Scanner) Buffer(buf []byte, max int) {
+	s.s.Buffer(buf, max)
+}
+
+// Scan advances the Scanner to the next data value, which will then be
+// available through the [Scanner.Bytes] method. It returns false when the scan
+// stops, either by reaching the end of the input or an error. After Scan
+// returns false, the [Scanner.Err] method will return any error that occurred
+// during scanning, except that if it was [io.EOF], Err will return nil.
+func (s *Scanner) Scan() bool {
+	if s.s.Scan() {
+		s.start = s.end
+		s.end += int64(len(s.s.Bytes()))
+		return true
+	}
+	if s.err == nil {
+		s.err = s.s.Err()
+		var se *SyntaxError
+		if errors.As(s.err, &se) {
+			se.ByteOffset += s.end
+		}
+	}
+	return false
+}
+
+// Bytes returns the encoding of the most recent data value generated by a call
+// to [Scanner.Scan], including its header. The underlying array may point to
+// data that will be overwritten by a subsequent call to Scan.
+func (s *Scanner) Bytes() []byte {
+	return s.s.Bytes()
+}
+
+// Range returns the offsets of the first byte and the byte after the last byte
+// of the most recent data value in the input stream.
+func (s *Scanner) Range() (start, end int64) {
+	return s.start, s.end
+}
+
+// Err returns the first non-EOF error that was encountered by the Scanner.
+// Syntax errors are reported as [*SyntaxError] with ByteOffset relative to the
+// start of the input stream.
+func (s *Scanner) Err() error {
+	return s.err
+}
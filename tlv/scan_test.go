@@ -0,0 +1,100 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func ExampleScanner() {
+	data := []byte{
+		0x02, 0x01, 0x05,
+		0x30, 0x80, 0x05, 0x00, 0x00, 0x00,
+		0x04, 0x02, 0xab, 0xcd,
+	}
+	s := NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		start, end := s.Range()
+		fmt.Printf("%d-%d: % X\n", start, end, s.Bytes())
+	}
+	if err := s.Err(); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 0-3: 02 01 05
+	// 3-9: 30 80 05 00 00 00
+	// 9-13: 04 02 AB CD
+}
+
+func TestScanner(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		want    [][]byte
+		wantErr error
+		offset  int64
+	}{
+		"Empty":    {nil, nil, nil, 0},
+		"Single":   {[]byte{0x05, 0x00}, [][]byte{{0x05, 0x00}}, nil, 0},
+		"Multiple": {[]byte{0x05, 0x00, 0x01, 0x01, 0xff}, [][]byte{{0x05, 0x00}, {0x01, 0x01, 0xff}}, nil, 0},
+		"NestedIndefinite": {[]byte{0x30, 0x80, 0x30, 0x80, 0x00, 0x00, 0x30, 0x00, 0x00, 0x00, 0x05, 0x00},
+			[][]byte{{0x30, 0x80, 0x30, 0x80, 0x00, 0x00, 0x30, 0x00, 0x00, 0x00}, {0x05, 0x00}}, nil, 0},
+		"Definite": {[]byte{0x30, 0x03, 0x02, 0x01, 0x05}, [][]byte{{0x30, 0x03, 0x02, 0x01, 0x05}}, nil, 0},
+		"LongTag":  {[]byte{0x5f, 0x81, 0x00, 0x00}, [][]byte{{0x5f, 0x81, 0x00, 0x00}}, nil, 0},
+
+		"TruncatedHeader": {[]byte{0x05, 0x00, 0x1f}, [][]byte{{0x05, 0x00}}, io.ErrUnexpectedEOF, 2},
+		"TruncatedValue":  {[]byte{0x04, 0x05, 0x01}, nil, io.ErrUnexpectedEOF, 0},
+		"MissingEOC":      {[]byte{0x05, 0x00, 0x30, 0x80, 0x05, 0x00}, [][]byte{{0x05, 0x00}}, io.ErrUnexpectedEOF, 2},
+		"TopLevelEOC":     {[]byte{0x05, 0x00, 0x00, 0x00}, [][]byte{{0x05, 0x00}}, errUnexpectedEOC, 2},
+		"NestedInvalid":   {[]byte{0x05, 0x00, 0x30, 0x80, 0x04, 0x80}, [][]byte{{0x05, 0x00}}, nil, 4},
+		"LongLengthEOC":   {[]byte{0x30, 0x80, 0x00, 0x81, 0x00}, nil, errInvalidEOC, 2},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewScanner(bytes.NewReader(tt.data))
+			var got [][]byte
+			for s.Scan() {
+				got = append(got, bytes.Clone(s.Bytes()))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Scan() returned %d values, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tt.want[i]) {
+					t.Errorf("Bytes() = % X, want % X", got[i], tt.want[i])
+				}
+			}
+			err := s.Err()
+			wantErr := tt.wantErr != nil || tt.offset > 0
+			if (err != nil) != wantErr {
+				t.Fatalf("Err() = %v, want error %v", err, wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Err() = %v, want *SyntaxError", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+			}
+			if se.ByteOffset != tt.offset {
+				t.Errorf("Err().ByteOffset = %d, want %d", se.ByteOffset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestScanner_Buffer(t *testing.T) {
+	data := append([]byte{0x04, 0x82, 0x01, 0x00}, make([]byte, 256)...)
+	s := NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, 100)
+	if s.Scan() {
+		t.Fatalf("Scan() = true, want false")
+	}
+	if err := s.Err(); err == nil {
+		t.Errorf("Err() = nil, want error")
+	}
+}
@@ -37,6 +37,15 @@ type state struct {
 	curr  stateEntry // top entry of the stack
 
 	offset int64
+
+	// headerBytes is the number of identifier and length octets processed in
+	// the current top-level data value.
+	headerBytes int
+
+	// maxDepth and maxHeaderBytes are the configured resource limits. A value of
+	// 0 indicates no limit. The limits are not affected by reset.
+	maxDepth       int
+	maxHeaderBytes int
 }
 
 // reset clears the state to a single (virtual) root data value. The allocated
@@ -51,6 +60,24 @@ func (s *state) reset() {
 		Length: LengthIndefinite,
 	}
 	s.offset = 0
+	s.headerBytes = 0
+}
+
+// checkLimits validates that a header of the given size can be processed at the
+// current location without exceeding the configured limits. If h is an
+// end-of-contents marker, only the header bytes are checked.
+func (s *state) checkLimits(h Header, size int) error {
+	if s.maxDepth > 0 && h.Tag != TagEndOfContents && len(s.stack) >= s.maxDepth {
+		return errMaxDepth
+	}
+	n := s.headerBytes
+	if s.root() {
+		n = 0 // a new top-level data value begins
+	}
+	if s.maxHeaderBytes > 0 && n+size > s.maxHeaderBytes {
+		return errMaxHeader
+	}
+	return nil
 }
 
 // root indicates whether s is currently at the root level.
@@ -62,6 +89,10 @@ func (s *state) root() bool {
 // processed. The size argument indicates the size of the identifier and length
 // octets in bytes.
 func (s *state) push(h Header, size int) {
+	if s.root() {
+		s.headerBytes = 0
+	}
+	s.headerBytes += size
 	s.curr.Offset += size
 	s.stack = append(s.stack, s.curr)
 	s.curr = stateEntry{
@@ -29,17 +29,22 @@
 // maintain an internal state to validate whether the sequence of TLVs forms a
 // valid BER encoding.
 //
+// The [Scanner] type splits a stream of concatenated data values into the
+// encodings of the individual data values without decoding their contents.
+//
 // [Rec. ITU-T X.690]: https://www.itu.int/rec/T-REC-X.690
 // [A Layman's Guide to a Subset of ASN.1, BER, and DER]: http://luca.ntop.org/Teaching/Appunti/asn1.html
 package tlv
 
 import (
+	"errors"
+	"io"
 	"math"
 	"math/bits"
 	"strconv"
 
 	"codello.dev/asn1"
-	"codello.dev/asn1/internal/vlq"
+	"codello.dev/asn1/vlq"
 )
 
 // TagEndOfContents is the tag that signifies the end of a constructed data
@@ -115,12 +120,14 @@ func (h Header) String() string {
 	return s
 }
 
-// HeaderSize returns the minimum number of bytes required to encode h.
-func HeaderSize(h Header) int {
+// EncodedLen returns the minimum number of bytes required to encode h. This is
+// the exact number of bytes appended by [Header.AppendTo] and written by an
+// [Encoder].
+func (h Header) EncodedLen() int {
 	l := 1 // identifier octets
 	if h.Tag.Number() >= 31 {
 		// tag does not fit into one byte
-		l += vlq.Size(h.Tag.Number())
+		l += vlq.Len(h.Tag.Number())
 	}
 	l++ // length octets
 	if h.Length == LengthIndefinite || h.Length < 128 {
@@ -130,6 +137,149 @@ func HeaderSize(h Header) int {
 	return l + (bits.Len(uint(h.Length))+7)/8
 }
 
+// HeaderSize returns the minimum number of bytes required to encode h. It is
+// equivalent to h.EncodedLen().
+func HeaderSize(h Header) int {
+	return h.EncodedLen()
+}
+
+// AppendTo appends the TLV encoding of h to dst and returns the extended
+// buffer. The header is encoded using the minimum number of bytes. It is the
+// caller's responsibility to ensure that h is a valid header, e.g. that
+// [LengthIndefinite] is only used for constructed data values.
+func (h Header) AppendTo(dst []byte) []byte {
+	b := uint8(h.Tag.Class() >> 8)
+	if h.Constructed {
+		b |= 0x20
+	}
+	if h.Tag.Number() < 31 {
+		dst = append(dst, b|uint8(h.Tag.Number()))
+	} else {
+		dst = append(dst, b|0x1f)
+		dst = vlq.Append(dst, h.Tag.Number())
+	}
+
+	if h.Length == LengthIndefinite {
+		return append(dst, 0x80)
+	} else if h.Length >= 128 {
+		numBytes := (bits.Len(uint(h.Length)) + 7) / 8
+		dst = append(dst, 0x80|byte(numBytes))
+		for ; numBytes > 0; numBytes-- {
+			dst = append(dst, byte(h.Length>>uint((numBytes-1)*8)))
+		}
+		return dst
+	}
+	return append(dst, byte(h.Length))
+}
+
+// Identifier returns the identifier octet of h. The identifier octet encodes
+// the class, the constructed bit and the tag number. It has the same
+// representation as the asn1.Tag type of the golang.org/x/crypto/cryptobyte/asn1
+// package, so it can be converted to that type directly. If the tag number of h
+// requires more than one identifier octet (i.e. it is 31 or greater), ok is
+// false.
+func (h Header) Identifier() (id uint8, ok bool) {
+	if h.Tag.Number() >= 31 {
+		return 0, false
+	}
+	id = uint8(h.Tag.Class()>>8) | uint8(h.Tag.Number())
+	if h.Constructed {
+		id |= 0x20
+	}
+	return id, true
+}
+
+// IdentifierHeader returns a Header with the class, constructed bit and tag
+// number encoded by the identifier octet id and the given length. It is the
+// inverse of [Header.Identifier] and can be used to convert a tag of the
+// golang.org/x/crypto/cryptobyte/asn1 package. If id indicates a multi-octet
+// tag number (i.e. its bottom five bits are set), the tag number of the
+// returned Header is 31.
+func IdentifierHeader(id uint8, length int) Header {
+	return Header{
+		Tag:         asn1.Class(id>>6)<<14 | asn1.Tag(id&0x1f),
+		Constructed: id&0x20 != 0,
+		Length:      length,
+	}
+}
+
+// AppendHeader appends the TLV encoding of h to dst and returns the extended
+// buffer. It is equivalent to h.AppendTo(dst).
+func AppendHeader(dst []byte, h Header) []byte {
+	return h.AppendTo(dst)
+}
+
+// maxHeaderLen is the maximum number of bytes of a TLV header that is either
+// valid or can be detected as invalid: 1 identifier byte, up to 3 bytes for
+// the long-form tag (more would overflow or be too large), 1 byte for the
+// number of length bytes and up to 127 length bytes.
+const maxHeaderLen = 1 + 3 + 1 + 127
+
+// ParseHeader decodes a TLV header from the beginning of b. It returns the
+// header and the number of bytes consumed. ParseHeader does not look at the
+// value following the header, so the returned length may exceed the remaining
+// bytes in b. The end-of-contents marker 0x0000 is returned as
+// [EndOfContents]. Any other header using [TagEndOfContents] is invalid. The
+// [Decoder] uses ParseHeader to decode headers from its input.
+//
+// If b is empty, io.EOF is returned. If b contains only part of a header,
+// [io.ErrUnexpectedEOF] is returned. An error is also returned if the header
+// is malformed or cannot be represented by the [Header] type.
+func ParseHeader(b []byte) (h Header, n int, err error) {
+	readByte := byteReaderFunc(func() (byte, error) {
+		if n >= len(b) {
+			return 0, io.EOF
+		}
+		n++
+		return b[n-1], nil
+	})
+
+	c, err := readByte()
+	if err != nil {
+		return h, 0, err
+	}
+	h = Header{
+		Tag:         asn1.Class(c>>6)<<14 | asn1.Tag(c&0x1f),
+		Constructed: c&0x20 == 0x20,
+	}
+	if c&0x1f == 0x1f {
+		var num asn1.Tag
+		if num, err = vlq.ReadMinimal[asn1.Tag](readByte); err != nil {
+			return h, n, noEOF(err)
+		}
+		if num > asn1.MaxTag {
+			return h, n, errors.New("tag number too large")
+		}
+		h.Tag = h.Tag.Class() | num
+	}
+
+	if c, err = readByte(); err != nil {
+		return h, n, noEOF(err)
+	}
+	if c&0x80 == 0 {
+		h.Length = int(c & 0x7f)
+	} else if c == 0x80 {
+		h.Length = LengthIndefinite
+	} else {
+		for numBytes := int(c & 0x7f); numBytes > 0; numBytes-- {
+			if c, err = readByte(); err != nil {
+				return h, n, noEOF(err)
+			}
+			if h.Length > math.MaxInt>>8 {
+				return h, n, errors.New("length too large")
+			}
+			h.Length = h.Length<<8 | int(c)
+		}
+	}
+	if h.Tag == TagEndOfContents && (h != (Header{}) || n != 2) {
+		// the end-of-contents marker must use the short form 0x0000
+		return h, n, errInvalidEOC
+	} else if !h.Constructed && h.Length == LengthIndefinite {
+		return h, n, errors.New("indefinite-length primitive data value")
+	}
+	return h, n, nil
+}
+
 // requireKeyedLiterals can be embedded in a struct to require keyed literals.
 type requireKeyedLiterals struct{}
 
@@ -1,9 +1,14 @@
 package tlv
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"testing"
+
+	"codello.dev/asn1"
 )
 
 func ExampleCombinedLength() {
@@ -36,9 +41,115 @@ func TestHeaderSize(t *testing.T) {
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got := HeaderSize(tc.h)
+			got := tc.h.EncodedLen()
 			if got != tc.want {
-				t.Errorf("HeaderSize(%s) = %d, want %d", tc.h, got, tc.want)
+				t.Errorf("%s.EncodedLen() = %d, want %d", tc.h, got, tc.want)
+			}
+			if HeaderSize(tc.h) != got {
+				t.Errorf("HeaderSize(%s) = %d, want %d", tc.h, HeaderSize(tc.h), got)
+			}
+		})
+	}
+}
+
+func TestAppendHeader(t *testing.T) {
+	tests := map[string]struct {
+		h    Header
+		want []byte
+	}{
+		"EndOfContents": {EndOfContents, []byte{0x00, 0x00}},
+		"Primitive":     {Header{asn1.TagInteger, false, 1}, []byte{0x02, 0x01}},
+		"Constructed":   {Header{asn1.TagSequence, true, 3}, []byte{0x30, 0x03}},
+		"Indefinite":    {Header{asn1.ClassApplication | 3, true, LengthIndefinite}, []byte{0x63, 0x80}},
+		"LargeTag":      {Header{asn1.ClassContextSpecific | 5726, false, 0}, []byte{0x9f, 0xac, 0x5e, 0x00}},
+		"LargeLength":   {Header{asn1.TagOctetString, false, 256}, []byte{0x04, 0x82, 0x01, 0x00}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := AppendHeader([]byte{0xff}, tc.h)
+			if !bytes.Equal(got[1:], tc.want) || got[0] != 0xff {
+				t.Errorf("AppendHeader(%s) = % x, want ff % x", tc.h, got, tc.want)
+			}
+			if got = tc.h.AppendTo(nil); !bytes.Equal(got, tc.want) {
+				t.Errorf("%s.AppendTo(nil) = % x, want % x", tc.h, got, tc.want)
+			}
+			if len(tc.want) != tc.h.EncodedLen() {
+				t.Errorf("len(AppendHeader(%s)) = %d, want EncodedLen() = %d", tc.h, len(tc.want), tc.h.EncodedLen())
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	// otherError matches any non-nil error
+	var otherError = errors.New("any error")
+
+	tests := map[string]struct {
+		data    []byte
+		want    Header
+		wantN   int
+		wantErr error
+	}{
+		"Empty":              {nil, Header{}, 0, io.EOF},
+		"EndOfContents":      {[]byte{0x00, 0x00}, EndOfContents, 2, nil},
+		"Primitive":          {[]byte{0x02, 0x01, 0x05}, Header{asn1.TagInteger, false, 1}, 2, nil},
+		"Indefinite":         {[]byte{0x63, 0x80}, Header{asn1.ClassApplication | 3, true, LengthIndefinite}, 2, nil},
+		"LargeTag":           {[]byte{0x9f, 0xac, 0x5e, 0x00}, Header{asn1.ClassContextSpecific | 5726, false, 0}, 4, nil},
+		"NonMinimalLength":   {[]byte{0x04, 0x82, 0x00, 0x05}, Header{asn1.TagOctetString, false, 5}, 4, nil},
+		"ExceedsInput":       {[]byte{0x04, 0x82, 0x01, 0x00}, Header{asn1.TagOctetString, false, 256}, 4, nil},
+		"Truncated":          {[]byte{0x04, 0x82, 0x01}, Header{}, 0, io.ErrUnexpectedEOF},
+		"TruncatedTag":       {[]byte{0x1f, 0x81}, Header{}, 0, io.ErrUnexpectedEOF},
+		"IndefinitePrimitve": {[]byte{0x04, 0x80}, Header{}, 0, otherError},
+		"InvalidEOC":         {[]byte{0x00, 0x80}, Header{}, 0, errInvalidEOC},
+		"LongLengthEOC":      {[]byte{0x00, 0x81, 0x00}, Header{}, 0, errInvalidEOC},
+		"LongTagEOC":         {[]byte{0x1f, 0x00, 0x00}, Header{}, 0, errInvalidEOC},
+		"NonMinimalTag":      {[]byte{0x1f, 0x80, 0x01, 0x00}, Header{}, 0, otherError},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, n, err := ParseHeader(tc.data)
+			if tc.wantErr != nil {
+				if err == nil || (tc.wantErr != otherError && !errors.Is(err, tc.wantErr)) {
+					t.Fatalf("ParseHeader(% x) error = %v, want %v", tc.data, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseHeader(% x) unexpected error: %v", tc.data, err)
+			}
+			if got != tc.want || n != tc.wantN {
+				t.Errorf("ParseHeader(% x) = %s, %d, want %s, %d", tc.data, got, n, tc.want, tc.wantN)
+			}
+		})
+	}
+}
+
+func TestHeader_Identifier(t *testing.T) {
+	tests := map[string]struct {
+		h    Header
+		want uint8
+		ok   bool
+	}{
+		"Integer":       {Header{Tag: asn1.TagInteger}, 0x02, true},
+		"Sequence":      {Header{Tag: asn1.TagSequence, Constructed: true}, 0x30, true},
+		"Context":       {Header{Tag: asn1.ClassContextSpecific | 3, Constructed: true}, 0xA3, true},
+		"Application":   {Header{Tag: asn1.ClassApplication | 1}, 0x41, true},
+		"Private":       {Header{Tag: asn1.ClassPrivate | 30}, 0xDE, true},
+		"LongTagNumber": {Header{Tag: asn1.ClassContextSpecific | 31}, 0, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := tt.h.Identifier()
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("Identifier() = %#x, %v, want %#x, %v", got, ok, tt.want, tt.ok)
+			}
+			if ok {
+				if h := IdentifierHeader(got, tt.h.Length); h != tt.h {
+					t.Errorf("IdentifierHeader(%#x) = %v, want %v", got, h, tt.h)
+				}
+				if b := tt.h.AppendTo(nil); b[0] != got {
+					t.Errorf("AppendTo()[0] = %#x, want %#x", b[0], got)
+				}
 			}
 		})
 	}
@@ -0,0 +1,120 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/vlq"
+)
+
+// This file contains helpers that decode the values of common primitive types.
+// They are intended for parsers that work directly on the TLV layer. Each
+// helper takes the header h of a primitive data value and a reader r of its
+// value, such as the one returned by [Decoder.ReadHeader], and consumes exactly
+// h.Length bytes from r. The caller remains responsible for closing r. The tag
+// of h is not checked, so the helpers work with implicitly tagged values as
+// well:
+//
+//	h, r, err := d.ReadHeader()
+//	if err != nil {
+//		return err
+//	}
+//	if h.Tag != asn1.TagInteger {
+//		return errors.New("expected INTEGER")
+//	}
+//	version, err := tlv.ReadInteger(h, r)
+//	if err == nil {
+//		err = r.Close()
+//	}
+
+// readValue reads the value of the primitive data value with header h from r.
+func readValue(h Header, r io.Reader) ([]byte, error) {
+	if h.Constructed || h.Length < 0 {
+		return nil, errors.New("tlv: " + h.String() + " is not a primitive value")
+	}
+	b, err := io.ReadAll(io.LimitReader(r, int64(h.Length)))
+	if err == nil && len(b) < h.Length {
+		err = io.ErrUnexpectedEOF
+	}
+	return b, err
+}
+
+// ReadBoolean reads the value of a BOOLEAN with header h from r. Any non-zero
+// value octet is interpreted as true.
+func ReadBoolean(h Header, r io.Reader) (bool, error) {
+	b, err := readValue(h, r)
+	if err != nil {
+		return false, err
+	}
+	if len(b) != 1 {
+		return false, errors.New("tlv: invalid boolean")
+	}
+	return b[0] != 0, nil
+}
+
+// ReadInteger reads the value of an INTEGER or ENUMERATED with header h from r.
+// The value must be encoded in two's complement using the minimum number of
+// octets and fit into an int64.
+func ReadInteger(h Header, r io.Reader) (int64, error) {
+	b, err := readValue(h, r)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case len(b) == 0:
+		return 0, errors.New("tlv: empty integer")
+	case len(b) > 1 && (b[0] == 0x00 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0):
+		return 0, errors.New("tlv: integer not minimally encoded")
+	case len(b) > 8:
+		return 0, errors.New("tlv: integer too large")
+	}
+	var i int64
+	if b[0]&0x80 != 0 {
+		i = -1
+	}
+	for _, c := range b {
+		i = i<<8 | int64(c)
+	}
+	return i, nil
+}
+
+// ReadOID reads the value of an OBJECT IDENTIFIER with header h from r. The
+// components of the object identifier must be minimally encoded.
+func ReadOID(h Header, r io.Reader) (asn1.ObjectIdentifier, error) {
+	b, err := readValue(h, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("tlv: zero length OBJECT IDENTIFIER")
+	}
+	br := bytes.NewReader(b)
+	// The first component is 40*value1 + value2 where value1 is 0, 1 or 2.
+	v, err := vlq.ReadMinimal[uint](br)
+	if err != nil {
+		return nil, errors.New("tlv: invalid OBJECT IDENTIFIER: " + noEOF(err).Error())
+	}
+	oid := make(asn1.ObjectIdentifier, 2, len(b)+1)
+	if v < 80 {
+		oid[0], oid[1] = v/40, v%40
+	} else {
+		oid[0], oid[1] = 2, v-80
+	}
+	for br.Len() > 0 {
+		if v, err = vlq.ReadMinimal[uint](br); err != nil {
+			return nil, errors.New("tlv: invalid OBJECT IDENTIFIER: " + noEOF(err).Error())
+		}
+		oid = append(oid, v)
+	}
+	return oid, nil
+}
+
+// ReadString reads the value of a primitive string type with header h from r.
+// The value is returned as-is without validating the character set of the
+// string type. Strings using the constructed encoding are not supported.
+func ReadString(h Header, r io.Reader) (string, error) {
+	b, err := readValue(h, r)
+	return string(b), err
+}
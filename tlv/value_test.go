@@ -0,0 +1,106 @@
+package tlv
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"testing"
+
+	"codello.dev/asn1"
+)
+
+func TestReadInteger(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		want    int64
+		wantErr bool
+	}{
+		"Zero":            {[]byte{0x02, 0x01, 0x00}, 0, false},
+		"Positive":        {[]byte{0x02, 0x02, 0x02, 0xD3}, 723, false},
+		"PositiveHighBit": {[]byte{0x02, 0x02, 0x00, 0x80}, 128, false},
+		"Negative":        {[]byte{0x02, 0x01, 0xFE}, -2, false},
+		"LargeNegative":   {[]byte{0x02, 0x02, 0xFE, 0xFE}, -258, false},
+		"MinInt":          {[]byte{0x02, 0x08, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, math.MinInt64, false},
+		"Empty":           {[]byte{0x02, 0x00}, 0, true},
+		"NonMinimal":      {[]byte{0x02, 0x02, 0x00, 0x7F}, 0, true},
+		"TooLarge":        {[]byte{0x02, 0x09, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 0, true},
+		"Constructed":     {[]byte{0x22, 0x00}, 0, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h, r, err := NewDecoderBytes(tt.data).ReadHeader()
+			if err != nil {
+				t.Fatalf("ReadHeader() error = %v", err)
+			}
+			got, err := ReadInteger(h, r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadInteger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadInteger() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOID(t *testing.T) {
+	tests := map[string]struct {
+		data    []byte
+		want    asn1.ObjectIdentifier
+		wantErr bool
+	}{
+		"RSA":        {[]byte{0x06, 0x06, 0x2A, 0x86, 0x48, 0x86, 0xF7, 0x0D}, asn1.ObjectIdentifier{1, 2, 840, 113549}, false},
+		"Joint":      {[]byte{0x06, 0x02, 0x88, 0x37}, asn1.ObjectIdentifier{2, 999}, false},
+		"Empty":      {[]byte{0x06, 0x00}, nil, true},
+		"NonMinimal": {[]byte{0x06, 0x03, 0x2A, 0x80, 0x01}, nil, true},
+		"Truncated":  {[]byte{0x06, 0x02, 0x2A, 0x86}, nil, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h, r, err := NewDecoderBytes(tt.data).ReadHeader()
+			if err != nil {
+				t.Fatalf("ReadHeader() error = %v", err)
+			}
+			got, err := ReadOID(h, r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadOID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ReadOID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadBoolean(t *testing.T) {
+	h, r, err := NewDecoderBytes([]byte{0x01, 0x01, 0x01}).ReadHeader()
+	if err != nil {
+		t.Fatalf("ReadHeader() error = %v", err)
+	}
+	if got, err := ReadBoolean(h, r); err != nil || !got {
+		t.Errorf("ReadBoolean() = %v, %v, want true, <nil>", got, err)
+	}
+	if _, err = ReadBoolean(Header{Tag: asn1.TagBoolean, Length: 2}, bytes.NewReader([]byte{0x00, 0x00})); err == nil {
+		t.Errorf("ReadBoolean() error = <nil>, want an error")
+	}
+}
+
+func TestReadString(t *testing.T) {
+	d := NewDecoderBytes([]byte{0x0C, 0x05, 'h', 'e', 'l', 'l', 'o', 0x02, 0x01, 0x05})
+	h, r, err := d.ReadHeader()
+	if err != nil {
+		t.Fatalf("ReadHeader() error = %v", err)
+	}
+	if got, err := ReadString(h, r); err != nil || got != "hello" {
+		t.Errorf("ReadString() = %q, %v, want \"hello\", <nil>", got, err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if h, _, err = d.ReadHeader(); err != nil || h.Tag != asn1.TagInteger {
+		t.Errorf("ReadHeader() = %v, %v, want INTEGER header after string", h, err)
+	}
+	if _, err = ReadString(Header{Tag: asn1.TagUTF8String, Length: 4}, bytes.NewReader([]byte("abc"))); err == nil {
+		t.Errorf("ReadString() error = <nil>, want an error for short input")
+	}
+}
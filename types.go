@@ -5,9 +5,13 @@
 package asn1
 
 import (
+	"fmt"
+	"maps"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 	"unsafe"
@@ -88,6 +92,38 @@ func (s BitString) String() string {
 	return sb.String()
 }
 
+// Equal reports whether s and other contain the same bits. Padding bits and
+// bytes beyond the BitLength are ignored.
+func (s BitString) Equal(other BitString) bool {
+	return s.BitLength == other.BitLength && string(s.CanonicalBytes()) == string(other.CanonicalBytes())
+}
+
+// Clone returns a copy of s that does not share memory with s.
+func (s BitString) Clone() BitString {
+	return BitString{Bytes: slices.Clone(s.Bytes), BitLength: s.BitLength}
+}
+
+// CanonicalBytes returns the bits of s packed into the minimum number of bytes
+// with all padding bits set to zero. Missing bytes of an invalid BitString are
+// treated as zero bits. The returned slice does not share memory with s.
+func (s BitString) CanonicalBytes() []byte {
+	b := make([]byte, (s.BitLength+8-1)/8)
+	copy(b, s.Bytes)
+	if pad := len(b)*8 - s.BitLength; pad > 0 {
+		b[len(b)-1] &= 0xFF << pad
+	}
+	return b
+}
+
+// Hash returns a canonical representation of s that can be used as a map key.
+// Two bit strings have the same Hash if and only if they are [BitString.Equal].
+// The representation consists of the number of padding bits followed by
+// [BitString.CanonicalBytes], which matches the DER encoding of the contents.
+func (s BitString) Hash() string {
+	b := s.CanonicalBytes()
+	return string(byte(len(b)*8-s.BitLength)) + string(b)
+}
+
 //endregion
 
 //region [UNIVERSAL 4] OCTET STRING
@@ -100,11 +136,64 @@ func (s BitString) String() string {
 // Null represents the ASN.1 NULL type. If your data structure contains fixed
 // NULL fields this type offers a convenient way to indicate their presence.
 // If your data structure contains fields that may or may not be null, it is
-// probably better to use a nullable type such as a pointer.
+// probably better to use [Nullable] or a pointer with the "nullable" struct
+// tag.
 //
 // See also section 24 of Rec. ITU-T X.680.
 type Null struct{}
 
+// Nullable represents a value of type T that may be NULL instead. It is similar
+// to a field of type *T with the "nullable" struct tag but does not require a
+// heap allocation. If Valid is false, NULL is encoded. Otherwise, Value is
+// encoded as if it were the field itself. When decoding, a NULL sets Valid to
+// false and Value to its zero value. Any other data value is decoded into Value
+// and sets Valid to true:
+//
+//	type Response struct {
+//		Status Nullable[int]
+//	}
+//
+// Struct tags of a Nullable field apply to Value. Support for Nullable depends
+// on the encoding rules.
+type Nullable[T any] struct {
+	Value T
+	Valid bool // Valid is true if Value is not NULL
+}
+
+// NewNullable returns a valid Nullable holding the value v.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Valid: true}
+}
+
+// Optional represents a value of type T that may be absent. A struct field of
+// type Optional is an OPTIONAL component of a SEQUENCE or SET: it is omitted
+// during encoding if Present is false and, when decoding, Present reports
+// whether the component was present in the data value. Unlike a field with the
+// "optional" and "omitzero" struct tags, an Optional distinguishes an absent
+// component from a component holding the zero value of T:
+//
+//	type Request struct {
+//		Limit Optional[int]
+//	}
+//
+// Struct tags of an Optional field apply to Value. The "optional" and
+// "omitzero" tags are implied. Outside of a SEQUENCE or SET an Optional is
+// encoded as its Value.
+type Optional[T any] struct {
+	Value   T
+	Present bool // Present is true if Value is present
+}
+
+// NewOptional returns a present Optional holding the value v.
+func NewOptional[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Present: true}
+}
+
+// IsZero reports whether o is absent.
+func (o Optional[T]) IsZero() bool {
+	return !o.Present
+}
+
 //endregion
 
 //region [UNIVERSAL 6] OBJECT IDENTIFIER
@@ -157,11 +246,174 @@ func (oid ObjectIdentifier) String() string {
 // Enumerated exists as a type mainly for documentation purposes. Any type with
 // an underlying integer type is recognized as the ENUMERATED type. Types may
 // implement an IsValid() bool method to indicate whether a value is valid for
-// the enum.
+// the enum. Alternatively, the values of an enum can be defined using an
+// [EnumDef].
 //
 // See also section 20 of Rec. ITU-T X.680.
 type Enumerated int
 
+// An EnumDef defines the values of the ENUMERATED type T and their names. The
+// definition is registered for T, so that encoding rules reject values of T
+// that are not part of the enumeration with a descriptive error. The methods
+// of an EnumDef are typically used to implement the methods of T:
+//
+//	type Color int
+//
+//	var colors = asn1.NewEnumDef(map[Color]string{0: "red", 1: "green"})
+//
+//	func (c Color) String() string { return colors.String(c) }
+//	func (c Color) IsValid() bool  { return colors.IsValid(c) }
+//
+// An EnumDef is safe for concurrent use.
+type EnumDef[T ~int] struct {
+	names  map[T]string
+	values map[string]T
+}
+
+// enum is implemented by all instantiations of EnumDef.
+type enum interface {
+	validate(v reflect.Value) error
+	name(v int) (string, error)
+	value(name string) (int, error)
+	sorted() []int
+}
+
+// enumDefs maps enum types to their EnumDef. enumsByName maps the names of enum
+// types to the types.
+var enumDefs, enumsByName sync.Map
+
+// NewEnumDef returns an EnumDef with the given values and names and registers
+// it for T. The names must be unique. NewEnumDef panics if an EnumDef has
+// already been registered for T.
+func NewEnumDef[T ~int](names map[T]string) *EnumDef[T] {
+	d := &EnumDef[T]{names: maps.Clone(names), values: make(map[string]T, len(names))}
+	for v, name := range names {
+		if _, dup := d.values[name]; dup {
+			panic("asn1: NewEnumDef with duplicate name " + name)
+		}
+		d.values[name] = v
+	}
+	t := reflect.TypeFor[T]()
+	if _, dup := enumDefs.LoadOrStore(t, d); dup {
+		panic("asn1: NewEnumDef of duplicate type " + t.String())
+	}
+	enumsByName.LoadOrStore(t.String(), t)
+	return d
+}
+
+// String returns the name of v. If v is not a value of the enumeration, the
+// name of T and the numeric value of v are returned, for example "Color(7)".
+func (d *EnumDef[T]) String(v T) string {
+	if name, ok := d.names[v]; ok {
+		return name
+	}
+	return reflect.TypeFor[T]().Name() + "(" + strconv.Itoa(int(v)) + ")"
+}
+
+// Parse returns the value with the given name.
+func (d *EnumDef[T]) Parse(name string) (T, error) {
+	if v, ok := d.values[name]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("invalid name %q for %s", name, reflect.TypeFor[T]().Name())
+}
+
+// IsValid reports whether v is a value of the enumeration.
+func (d *EnumDef[T]) IsValid(v T) bool {
+	_, ok := d.names[v]
+	return ok
+}
+
+// validate returns an error if the value v of type T is not a value of the
+// enumeration.
+func (d *EnumDef[T]) validate(v reflect.Value) error {
+	if !d.IsValid(T(v.Int())) {
+		return fmt.Errorf("invalid value %d for %s", v.Int(), v.Type().Name())
+	}
+	return nil
+}
+
+func (d *EnumDef[T]) name(v int) (string, error) {
+	if name, ok := d.names[T(v)]; ok {
+		return name, nil
+	}
+	return "", fmt.Errorf("invalid value %d for %s", v, reflect.TypeFor[T]().Name())
+}
+
+func (d *EnumDef[T]) value(name string) (int, error) {
+	v, err := d.Parse(name)
+	return int(v), err
+}
+
+func (d *EnumDef[T]) sorted() []int {
+	vs := make([]int, 0, len(d.names))
+	for v := range d.names {
+		vs = append(vs, int(v))
+	}
+	slices.Sort(vs)
+	return vs
+}
+
+// ValidateEnum returns an error if an [EnumDef] has been registered for the
+// type of v and v is not a value of the enumeration. For all other values
+// ValidateEnum returns nil. ValidateEnum is intended for the implementation of
+// encoding rules.
+func ValidateEnum(v any) error {
+	d, ok := enumDefs.Load(reflect.TypeOf(v))
+	if !ok {
+		return nil
+	}
+	return d.(enum).validate(reflect.ValueOf(v))
+}
+
+// EnumName returns the name of the value v of the enumeration defined by an
+// [EnumDef] for the Go type t. EnumName is intended for the implementation of
+// encoding rules.
+func EnumName(t reflect.Type, v int) (string, error) {
+	d, ok := enumDefs.Load(t)
+	if !ok {
+		return "", fmt.Errorf("unknown enum type %v", t)
+	}
+	return d.(enum).name(v)
+}
+
+// EnumValue returns the value with the given name of the enumeration defined
+// by an [EnumDef] for the Go type t. EnumValue is intended for the
+// implementation of encoding rules.
+func EnumValue(t reflect.Type, name string) (int, error) {
+	d, ok := enumDefs.Load(t)
+	if !ok {
+		return 0, fmt.Errorf("unknown enum type %v", t)
+	}
+	return d.(enum).value(name)
+}
+
+// EnumType returns the Go type with the given name for which an [EnumDef] has
+// been created. The name of a type is the string returned by its
+// [reflect.Type.String] method, for example "ldap.ResultCode". If multiple enum
+// types have the same name, the type whose EnumDef was created first is
+// returned. If there is no such type, EnumType returns nil. EnumType is
+// intended for the implementation of the "enum" struct tag.
+func EnumType(name string) reflect.Type {
+	t, ok := enumsByName.Load(name)
+	if !ok {
+		return nil
+	}
+	return t.(reflect.Type)
+}
+
+// EnumValues returns the values of the enumeration defined by an [EnumDef] for
+// the Go type t in ascending order. If no EnumDef has been registered for t,
+// EnumValues returns nil. EnumValues is intended for the implementation of
+// encoding rules.
+func EnumValues(t reflect.Type) []int {
+	d, ok := enumDefs.Load(t)
+	if !ok {
+		return nil
+	}
+	return d.(enum).sorted()
+}
+
 //endregion
 
 //region [UNIVERSAL 11] EMBEDDED PDV
@@ -218,7 +470,8 @@ func (oid RelativeOID) String() string {
 
 // Time represents the ASN.1 TIME type. This type can only hold a subset of
 // valid ASN.1 TIME values, namely those that can be represented by a time
-// instant. In particular recurrences or intervals are not supported.
+// instant. Use [TimeInterval] or [RecurringInterval] for intervals and
+// recurrences.
 //
 // See also section 38 of Rec. ITU-T X.680.
 type Time time.Time
@@ -263,6 +516,67 @@ func (t Time) String() string {
 	return b.String()
 }
 
+// TimeInterval represents an ASN.1 TIME value that is an ISO 8601 time
+// interval. An interval is specified either by a start and an end, by a start
+// and a duration, by a duration and an end, or by a duration alone. Absent
+// components are indicated by their zero values.
+//
+// See also section 38 of Rec. ITU-T X.680.
+type TimeInterval struct {
+	Start    time.Time
+	End      time.Time
+	Duration ISODuration
+}
+
+// IsValid reports whether i uses one of the valid combinations of components.
+func (i TimeInterval) IsValid() bool {
+	if !i.Duration.IsValid() {
+		return false
+	}
+	if !i.Start.IsZero() && !i.End.IsZero() {
+		return i.Duration == (ISODuration{})
+	}
+	return i.Duration != (ISODuration{})
+}
+
+// String returns the ISO 8601 representation of i.
+func (i TimeInterval) String() string {
+	switch {
+	case !i.Start.IsZero() && !i.End.IsZero():
+		return Time(i.Start).String() + "/" + Time(i.End).String()
+	case !i.Start.IsZero():
+		return Time(i.Start).String() + "/" + i.Duration.String()
+	case !i.End.IsZero():
+		return i.Duration.String() + "/" + Time(i.End).String()
+	default:
+		return i.Duration.String()
+	}
+}
+
+// RecurringInterval represents an ASN.1 TIME value that is an ISO 8601
+// recurring time interval.
+//
+// See also section 38 of Rec. ITU-T X.680.
+type RecurringInterval struct {
+	// Recurrences is the number of repetitions of the interval. A negative value
+	// indicates an unbounded number of repetitions.
+	Recurrences int
+	Interval    TimeInterval
+}
+
+// IsValid reports whether r.Interval is valid.
+func (r RecurringInterval) IsValid() bool {
+	return r.Interval.IsValid()
+}
+
+// String returns the ISO 8601 representation of r.
+func (r RecurringInterval) String() string {
+	if r.Recurrences < 0 {
+		return "R/" + r.Interval.String()
+	}
+	return "R" + strconv.Itoa(r.Recurrences) + "/" + r.Interval.String()
+}
+
 //endregion
 
 //region [UNIVERSAL 16] SEQUENCE
@@ -303,6 +617,109 @@ func (s Set[T]) Contains(value T) bool {
 	return ok
 }
 
+// Len returns the number of values in the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Values returns the values of the set as a slice. The order of the values is
+// unspecified. Encoding rules that require a canonical order of the elements of
+// a SET OF, such as DER, sort the elements when encoding the set.
+func (s Set[T]) Values() []T {
+	return slices.Collect(maps.Keys(s))
+}
+
+// Union returns a new set containing the values that are contained in s or
+// other.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	u := make(Set[T], max(len(s), len(other)))
+	maps.Copy(u, s)
+	maps.Copy(u, other)
+	return u
+}
+
+// Intersect returns a new set containing the values that are contained in both
+// s and other.
+func (s Set[T]) Intersect(other Set[T]) Set[T] {
+	if len(other) < len(s) {
+		s, other = other, s
+	}
+	i := make(Set[T])
+	for v := range s {
+		if other.Contains(v) {
+			i.Add(v)
+		}
+	}
+	return i
+}
+
+// SetOf represents the ASN.1 SET OF type for element types that are not
+// comparable and therefore cannot be used with [Set], such as [BitString] or
+// [ObjectIdentifier]. The elements are stored in a slice and compared using an
+// equality function. Adding a value that is equal to an element of the set has
+// no effect.
+//
+// The zero value is an empty set that compares values using
+// [reflect.DeepEqual].
+type SetOf[T any] struct {
+	values []T
+	equal  func(a, b T) bool
+}
+
+// NewSetOf creates a new set with the specified values. Values are compared
+// using equal. If equal is nil, the default equality of the zero SetOf is used.
+func NewSetOf[T any](equal func(a, b T) bool, ts ...T) SetOf[T] {
+	s := SetOf[T]{equal: equal}
+	for _, v := range ts {
+		s.Add(v)
+	}
+	return s
+}
+
+// index returns the index of value in s.values or -1 if value is not in s.
+func (s SetOf[T]) index(value T) int {
+	if s.equal == nil {
+		return slices.IndexFunc(s.values, func(v T) bool { return reflect.DeepEqual(v, value) })
+	}
+	return slices.IndexFunc(s.values, func(v T) bool { return s.equal(v, value) })
+}
+
+// Add adds value to the set, unless an equal value is already present.
+func (s *SetOf[T]) Add(value T) {
+	if s.index(value) < 0 {
+		s.values = append(s.values, value)
+	}
+}
+
+// Remove removes value from the set, if it was present.
+func (s *SetOf[T]) Remove(value T) {
+	if i := s.index(value); i >= 0 {
+		s.values = slices.Delete(s.values, i, i+1)
+	}
+}
+
+// Contains indicates whether value is contained within the set.
+func (s SetOf[T]) Contains(value T) bool {
+	return s.index(value) >= 0
+}
+
+// Clear removes all values from the set. The equality function is retained.
+func (s *SetOf[T]) Clear() {
+	s.values = nil
+}
+
+// Len returns the number of values in the set.
+func (s SetOf[T]) Len() int {
+	return len(s.values)
+}
+
+// Values returns the values of the set as a new slice in the order in which
+// they were added. Like for [Set], encoding rules that require a canonical order
+// sort the elements when encoding the set.
+func (s SetOf[T]) Values() []T {
+	return slices.Clone(s.values)
+}
+
 //endregion
 
 //region [UNIVERSAL 18] NumericString
@@ -705,7 +1122,7 @@ func (t DateTime) String() string {
 
 // Duration represents the ASN.1 DURATION type. Only durations that can be
 // represented as a [time.Duration] are valid, that is durations cannot use
-// units above hours.
+// units above hours. Use [ISODuration] for durations with date components.
 //
 // See also section 38 of Rec. ITU-T X.680.
 type Duration time.Duration
@@ -748,4 +1165,84 @@ func (d Duration) String() string {
 	return b.String()
 }
 
+// ISODuration represents the ASN.1 DURATION type including date components.
+// Unlike [Duration], an ISODuration can represent nominal durations such as
+// months or years whose length depends on the point in time they are applied
+// to. All components must be non-negative. The sign of the duration is
+// indicated by Negative.
+//
+// See also section 38 of Rec. ITU-T X.680.
+type ISODuration struct {
+	Negative bool
+
+	Years, Months, Weeks, Days int
+	Hours, Minutes, Seconds    int
+	Nanoseconds                int // fraction of the last second
+}
+
+// IsValid reports whether all components of d are non-negative and
+// d.Nanoseconds is less than one second.
+func (d ISODuration) IsValid() bool {
+	return d.Years >= 0 && d.Months >= 0 && d.Weeks >= 0 && d.Days >= 0 &&
+		d.Hours >= 0 && d.Minutes >= 0 && d.Seconds >= 0 &&
+		d.Nanoseconds >= 0 && d.Nanoseconds < int(time.Second)
+}
+
+// AddTo returns the time t+d. Date components are added using
+// [time.Time.AddDate].
+func (d ISODuration) AddTo(t time.Time) time.Time {
+	sign := 1
+	if d.Negative {
+		sign = -1
+	}
+	t = t.AddDate(sign*d.Years, sign*d.Months, sign*(7*d.Weeks+d.Days))
+	return t.Add(time.Duration(sign) * (time.Duration(d.Hours)*time.Hour +
+		time.Duration(d.Minutes)*time.Minute +
+		time.Duration(d.Seconds)*time.Second +
+		time.Duration(d.Nanoseconds)))
+}
+
+// String returns the ASN.1 notation of d.
+func (d ISODuration) String() string {
+	b := strings.Builder{}
+	if d.Negative {
+		b.WriteByte('-')
+	}
+	b.WriteByte('P')
+	n := b.Len()
+	for _, c := range []struct {
+		val        int
+		designator byte
+	}{{d.Years, 'Y'}, {d.Months, 'M'}, {d.Weeks, 'W'}, {d.Days, 'D'}} {
+		if c.val != 0 {
+			b.WriteString(strconv.Itoa(c.val))
+			b.WriteByte(c.designator)
+		}
+	}
+	if d.Hours == 0 && d.Minutes == 0 && d.Seconds == 0 && d.Nanoseconds == 0 {
+		if b.Len() == n {
+			return "PT0S"
+		}
+		return b.String()
+	}
+	b.WriteByte('T')
+	if d.Hours != 0 {
+		b.WriteString(strconv.Itoa(d.Hours))
+		b.WriteByte('H')
+	}
+	if d.Minutes != 0 {
+		b.WriteString(strconv.Itoa(d.Minutes))
+		b.WriteByte('M')
+	}
+	if d.Seconds != 0 || d.Nanoseconds != 0 {
+		b.WriteString(strconv.Itoa(d.Seconds))
+		if d.Nanoseconds != 0 {
+			s := strconv.FormatFloat(float64(d.Nanoseconds)/float64(time.Second), 'f', -1, 64)
+			b.WriteString(s[1:])
+		}
+		b.WriteByte('S')
+	}
+	return b.String()
+}
+
 //endregion
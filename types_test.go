@@ -5,6 +5,9 @@
 package asn1
 
 import (
+	"maps"
+	"reflect"
+	"slices"
 	"testing"
 	"time"
 )
@@ -17,6 +20,95 @@ func ExampleEnumerated() {
 	}
 }
 
+type color int
+
+var colors = NewEnumDef(map[color]string{0: "red", 1: "green", 5: "blue"})
+
+func TestEnumDef(t *testing.T) {
+	if got := colors.String(5); got != "blue" {
+		t.Errorf("String(5) = %q, want %q", got, "blue")
+	}
+	if got := colors.String(7); got != "color(7)" {
+		t.Errorf("String(7) = %q, want %q", got, "color(7)")
+	}
+	if got, err := colors.Parse("green"); err != nil || got != 1 {
+		t.Errorf("Parse(%q) = %v, %v, want 1, nil", "green", got, err)
+	}
+	if _, err := colors.Parse("yellow"); err == nil {
+		t.Errorf("Parse(%q) error = nil, want error", "yellow")
+	}
+	if err := ValidateEnum(color(1)); err != nil {
+		t.Errorf("ValidateEnum(1) = %v, want nil", err)
+	}
+	if err := ValidateEnum(color(7)); err == nil || err.Error() != "invalid value 7 for color" {
+		t.Errorf("ValidateEnum(7) = %v, want %q", err, "invalid value 7 for color")
+	}
+	if err := ValidateEnum(7); err != nil {
+		t.Errorf("ValidateEnum(int) = %v, want nil", err)
+	}
+	if got, err := EnumName(reflect.TypeFor[color](), 5); err != nil || got != "blue" {
+		t.Errorf("EnumName(5) = %q, %v, want %q, nil", got, err, "blue")
+	}
+	if got, err := EnumValue(reflect.TypeFor[color](), "green"); err != nil || got != 1 {
+		t.Errorf("EnumValue(%q) = %v, %v, want 1, nil", "green", got, err)
+	}
+	if _, err := EnumName(reflect.TypeFor[int](), 0); err == nil {
+		t.Errorf("EnumName() of unknown type error = nil, want error")
+	}
+	if got := EnumType("asn1.color"); got != reflect.TypeFor[color]() {
+		t.Errorf("EnumType(%q) = %v, want %v", "asn1.color", got, reflect.TypeFor[color]())
+	}
+	if got := EnumType("asn1.unknown"); got != nil {
+		t.Errorf("EnumType(%q) = %v, want nil", "asn1.unknown", got)
+	}
+	if got := EnumValues(reflect.TypeFor[color]()); !slices.Equal(got, []int{0, 1, 5}) {
+		t.Errorf("EnumValues() = %v, want %v", got, []int{0, 1, 5})
+	}
+	if got := EnumValues(reflect.TypeFor[int]()); got != nil {
+		t.Errorf("EnumValues(int) = %v, want nil", got)
+	}
+}
+
+func TestBitString_Equal(t *testing.T) {
+	tests := map[string]struct {
+		a, b BitString
+		want bool
+	}{
+		"Empty":          {BitString{}, BitString{Bytes: []byte{}}, true},
+		"Same":           {BitString{[]byte{0xAB, 0xC0}, 12}, BitString{[]byte{0xAB, 0xC0}, 12}, true},
+		"Padding":        {BitString{[]byte{0xAB, 0xC0}, 12}, BitString{[]byte{0xAB, 0xCF}, 12}, true},
+		"ExtraBytes":     {BitString{[]byte{0xAB}, 8}, BitString{[]byte{0xAB, 0xFF}, 8}, true},
+		"DifferentBits":  {BitString{[]byte{0xAB, 0xC0}, 12}, BitString{[]byte{0xAB, 0xD0}, 12}, false},
+		"DifferentLen":   {BitString{[]byte{0x80}, 1}, BitString{[]byte{0x80}, 2}, false},
+		"TrailingZeroes": {BitString{[]byte{0x00}, 0}, BitString{[]byte{0x00}, 8}, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := tt.a.Hash() == tt.b.Hash(); got != tt.want {
+				t.Errorf("Hash() == Hash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitString_Clone(t *testing.T) {
+	s := BitString{[]byte{0xAB, 0xCF}, 12}
+	c := s.Clone()
+	c.Bytes[0] = 0
+	if s.Bytes[0] != 0xAB {
+		t.Errorf("Clone() shares memory with original")
+	}
+	if got := s.CanonicalBytes(); !slices.Equal(got, []byte{0xAB, 0xC0}) {
+		t.Errorf("CanonicalBytes() = % X, want AB C0", got)
+	}
+	if got := s.Hash(); got != "\x04\xAB\xC0" {
+		t.Errorf("Hash() = %q, want %q", got, "\x04\xAB\xC0")
+	}
+}
+
 func TestTime_String(t *testing.T) {
 	tests := map[string]struct {
 		t    time.Time
@@ -36,6 +128,81 @@ func TestTime_String(t *testing.T) {
 	}
 }
 
+func TestTimeInterval_String(t *testing.T) {
+	start := time.Date(2014, 3, 12, 13, 0, 0, 0, time.UTC)
+	end := time.Date(2014, 3, 14, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		i    TimeInterval
+		want string
+	}{
+		"StartEnd":      {TimeInterval{Start: start, End: end}, "2014-03-12T13:00:00Z/2014-03-14T12:00:00Z"},
+		"StartDuration": {TimeInterval{Start: start, Duration: ISODuration{Days: 1}}, "2014-03-12T13:00:00Z/P1D"},
+		"DurationEnd":   {TimeInterval{Duration: ISODuration{Hours: 2}, End: end}, "PT2H/2014-03-14T12:00:00Z"},
+		"Duration":      {TimeInterval{Duration: ISODuration{Months: 1}}, "P1M"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.i.String(); got != tt.want {
+				t.Errorf("TimeInterval.String() = %v, want %v", got, tt.want)
+			}
+			if !tt.i.IsValid() {
+				t.Errorf("TimeInterval.IsValid() = false, want true")
+			}
+		})
+	}
+	if (TimeInterval{}).IsValid() {
+		t.Errorf("TimeInterval{}.IsValid() = true, want false")
+	}
+	r := RecurringInterval{Recurrences: -1, Interval: TimeInterval{Start: start, Duration: ISODuration{Weeks: 1}}}
+	if got, want := r.String(), "R/2014-03-12T13:00:00Z/P1W"; got != want {
+		t.Errorf("RecurringInterval.String() = %v, want %v", got, want)
+	}
+}
+
+func TestSet(t *testing.T) {
+	a := NewSet(1, 2, 3)
+	b := NewSet(3, 4)
+	if a.Len() != 3 {
+		t.Errorf("a.Len() = %d, want 3", a.Len())
+	}
+	if u := a.Union(b); !maps.Equal(u, NewSet(1, 2, 3, 4)) {
+		t.Errorf("a.Union(b) = %v, want [1 2 3 4]", slices.Sorted(maps.Keys(u)))
+	}
+	if i := a.Intersect(b); !maps.Equal(i, NewSet(3)) {
+		t.Errorf("a.Intersect(b) = %v, want [3]", slices.Sorted(maps.Keys(i)))
+	}
+	if v := a.Values(); len(v) != 3 || !a.Contains(v[0]) || !a.Contains(v[1]) || !a.Contains(v[2]) {
+		t.Errorf("a.Values() = %v, want permutation of [1 2 3]", v)
+	}
+}
+
+func TestSetOf(t *testing.T) {
+	s := NewSetOf(BitString.Equal, BitString{[]byte{0x80}, 1}, BitString{[]byte{0xFF}, 1})
+	if s.Len() != 1 {
+		t.Errorf("NewSetOf().Len() = %d, want 1", s.Len())
+	}
+	s.Add(BitString{[]byte{0x40}, 2})
+	if !s.Contains(BitString{[]byte{0x7F}, 2}) {
+		t.Errorf("Contains() = false, want true")
+	}
+	s.Remove(BitString{[]byte{0xC0}, 1})
+	if got := s.Values(); len(got) != 1 || !got[0].Equal(BitString{[]byte{0x40}, 2}) {
+		t.Errorf("Values() = %v, want [01]", got)
+	}
+
+	var oids SetOf[ObjectIdentifier]
+	oids.Add(ObjectIdentifier{1, 2})
+	oids.Add(ObjectIdentifier{1, 2})
+	oids.Add(ObjectIdentifier{1, 3})
+	if oids.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", oids.Len())
+	}
+	oids.Clear()
+	if oids.Len() != 0 {
+		t.Errorf("Clear(); Len() = %d, want 0", oids.Len())
+	}
+}
+
 func TestUTCTime_String(t *testing.T) {
 	tests := map[string]struct {
 		t    time.Time
@@ -150,6 +317,47 @@ func TestDuration_String(t *testing.T) {
 	}
 }
 
+func TestISODuration_String(t *testing.T) {
+	tests := map[string]struct {
+		d    ISODuration
+		want string
+	}{
+		"Zero":       {ISODuration{}, "PT0S"},
+		"Months":     {ISODuration{Months: 3}, "P3M"},
+		"Weeks":      {ISODuration{Weeks: 2}, "P2W"},
+		"DateTime":   {ISODuration{Days: 1, Hours: 2}, "P1DT2H"},
+		"Full":       {ISODuration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}, "P1Y2M3DT4H5M6S"},
+		"Fractional": {ISODuration{Nanoseconds: 500000000}, "PT0.5S"},
+		"Negative":   {ISODuration{Negative: true, Years: 1}, "-P1Y"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.d.String(); got != tt.want {
+				t.Errorf("ISODuration.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestISODuration_AddTo(t *testing.T) {
+	start := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		d    ISODuration
+		want time.Time
+	}{
+		"Month":    {ISODuration{Months: 1}, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)},
+		"Week":     {ISODuration{Weeks: 1, Hours: 1}, time.Date(2024, 2, 7, 13, 0, 0, 0, time.UTC)},
+		"Negative": {ISODuration{Negative: true, Days: 1, Minutes: 30}, time.Date(2024, 1, 30, 11, 30, 0, 0, time.UTC)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.d.AddTo(start); !got.Equal(tt.want) {
+				t.Errorf("ISODuration.AddTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestItoaN(t *testing.T) {
 	tests := map[string]struct {
 		i    int
@@ -0,0 +1,119 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asn1
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// UUID represents a universally unique identifier as specified in RFC 4122.
+// Like any byte array, a UUID is encoded as an OCTET STRING containing the 16
+// octets of the UUID.
+//
+// Rec. ITU-T X.667 additionally defines a mapping of UUIDs into the OBJECT
+// IDENTIFIER tree below the arc 2.25. Because the resulting arc exceeds the
+// range of [ObjectIdentifier], this form is only supported in its dot-separated
+// notation via [UUID.OIDString] and [ParseUUIDOID].
+type UUID [16]byte
+
+// uuidArc is the dot-separated notation of the OBJECT IDENTIFIER arc for UUIDs
+// including the trailing dot.
+const uuidArc = "2.25."
+
+// ParseUUID parses s in the format produced by [UUID.String]. Hexadecimal
+// digits are accepted in upper and lower case. The URN form prefixed with
+// "urn:uuid:" is also accepted.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	str := s
+	if len(str) == 45 && strings.EqualFold(str[:9], "urn:uuid:") {
+		str = str[9:]
+	}
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return u, &UUIDParseError{s, "invalid format"}
+	}
+	b := []byte(str[:8] + str[9:13] + str[14:18] + str[19:23] + str[24:])
+	if _, err := hex.Decode(u[:], b); err != nil {
+		return u, &UUIDParseError{s, "invalid hexadecimal digit"}
+	}
+	return u, nil
+}
+
+// ParseUUIDOID parses the dot-separated notation of an OBJECT IDENTIFIER in
+// the arc 2.25 and returns the UUID it represents. This is the inverse of
+// [UUID.OIDString].
+func ParseUUIDOID(s string) (UUID, error) {
+	var u UUID
+	str, ok := strings.CutPrefix(s, uuidArc)
+	if !ok {
+		return u, &UUIDParseError{s, "not in arc " + uuidArc[:len(uuidArc)-1]}
+	}
+	if len(str) > 1 && str[0] == '0' {
+		return u, &UUIDParseError{s, "leading zero"}
+	}
+	n, ok := new(big.Int).SetString(str, 10)
+	if !ok || n.Sign() < 0 || strings.HasPrefix(str, "+") {
+		return u, &UUIDParseError{s, "invalid arc " + strconv.Quote(str)}
+	}
+	if n.BitLen() > 128 {
+		return u, &UUIDParseError{s, "arc out of range"}
+	}
+	n.FillBytes(u[:])
+	return u, nil
+}
+
+// String returns the RFC 4122 string form of u, consisting of 32 lower case
+// hexadecimal digits in groups of 8-4-4-4-12 separated by hyphens.
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[:8], u[:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+// OIDString returns the dot-separated notation of the OBJECT IDENTIFIER
+// representing u as specified in Rec. ITU-T X.667. The identifier consists of
+// the arc 2.25 followed by the UUID interpreted as an unsigned 128-bit integer.
+func (u UUID) OIDString() string {
+	return uuidArc + new(big.Int).SetBytes(u[:]).String()
+}
+
+// MarshalText implements [encoding.TextMarshaler]. The UUID is formatted as
+// described in [UUID.String].
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. The text is parsed using
+// [ParseUUID].
+func (u *UUID) UnmarshalText(text []byte) error {
+	uuid, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = uuid
+	return nil
+}
+
+// A UUIDParseError is returned by [ParseUUID] and [ParseUUIDOID] if the input
+// is not a valid UUID notation.
+type UUIDParseError struct {
+	Input string // the string that was parsed
+	Msg   string // description of the problem
+}
+
+func (e *UUIDParseError) Error() string {
+	return "asn1: cannot parse UUID " + strconv.Quote(e.Input) + ": " + e.Msg
+}
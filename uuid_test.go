@@ -0,0 +1,73 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asn1
+
+import "testing"
+
+func TestParseUUID(t *testing.T) {
+	// example from Rec. ITU-T X.667
+	want := UUID{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0, 0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6}
+	tests := map[string]struct {
+		s       string
+		want    UUID
+		wantErr bool
+	}{
+		"Lower":      {"f81d4fae-7dec-11d0-a765-00a0c91e6bf6", want, false},
+		"Upper":      {"F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6", want, false},
+		"URN":        {"urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6", want, false},
+		"Nil":        {"00000000-0000-0000-0000-000000000000", UUID{}, false},
+		"NoHyphens":  {"f81d4fae7dec11d0a76500a0c91e6bf6", UUID{}, true},
+		"WrongGroup": {"f81d4fa-e7dec-11d0-a765-00a0c91e6bf6", UUID{}, true},
+		"InvalidHex": {"g81d4fae-7dec-11d0-a765-00a0c91e6bf6", UUID{}, true},
+		"Empty":      {"", UUID{}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseUUID(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUUID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUUID() got = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && tt.s[0] != 'F' && got.String() != tt.s[len(tt.s)-36:] {
+				t.Errorf("String() = %q, want %q", got.String(), tt.s[len(tt.s)-36:])
+			}
+		})
+	}
+}
+
+func TestParseUUIDOID(t *testing.T) {
+	tests := map[string]struct {
+		s       string
+		want    UUID
+		wantErr bool
+	}{
+		"X667": {"2.25.329800735698586629295641978511506172918",
+			UUID{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0, 0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6}, false},
+		"Zero":        {"2.25.0", UUID{}, false},
+		"Max":         {"2.25.340282366920938463463374607431768211455", UUID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, false},
+		"Overflow":    {"2.25.340282366920938463463374607431768211456", UUID{}, true},
+		"WrongArc":    {"2.26.1", UUID{}, true},
+		"LeadingZero": {"2.25.01", UUID{}, true},
+		"Negative":    {"2.25.-1", UUID{}, true},
+		"Sign":        {"2.25.+1", UUID{}, true},
+		"Empty":       {"2.25.", UUID{}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseUUIDOID(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUUIDOID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUUIDOID() got = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && got.OIDString() != tt.s {
+				t.Errorf("OIDString() = %q, want %q", got.OIDString(), tt.s)
+			}
+		})
+	}
+}
@@ -3,6 +3,11 @@
 // with the addition of the eighth bit to mark continuation of bytes. VLQ is
 // identical to [LEB128] except in endianness.
 //
+// In ASN.1 encodings VLQs are used for the components of object identifiers
+// and for tag numbers of 31 or greater. Encodings produced by [Write] and
+// [Append] are always minimal. Use [ReadMinimal] to reject non-minimal
+// encodings when decoding.
+//
 // [Variable-length quantity]: https://en.wikipedia.org/wiki/Variable-length_quantity
 // [LEB128]: https://en.wikipedia.org/wiki/LEB128
 package vlq
@@ -15,8 +20,10 @@ import (
 )
 
 var (
-	errNotMinimal = errors.New("vlq is not minimally encoded")
-	errOverflow   = errors.New("vlq too large for target type")
+	// ErrNotMinimal is returned by ReadMinimal if a VLQ has leading zeros.
+	ErrNotMinimal = errors.New("vlq is not minimally encoded")
+	// ErrOverflow is returned if a VLQ does not fit into the target type.
+	ErrOverflow = errors.New("vlq too large for target type")
 )
 
 // Read parses an unsigned VLQ from r. The maximum allowed value is limited by
@@ -46,7 +53,7 @@ func read[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](r io.ByteReader, minim
 		return 0, err
 	}
 	if b == 0x80 && minimal {
-		return 0, errNotMinimal
+		return 0, ErrNotMinimal
 	}
 
 	ret = T(b & 0x7f)
@@ -65,7 +72,7 @@ func read[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](r io.ByteReader, minim
 			numBits += 7
 		}
 		if numBits > int(unsafe.Sizeof(ret)*8) {
-			return 0, errOverflow
+			return 0, ErrOverflow
 		}
 	}
 	if err == io.EOF {
@@ -74,8 +81,8 @@ func read[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](r io.ByteReader, minim
 	return ret, err
 }
 
-// Size returns the number of bytes needed to encode n as a VLQ.
-func Size[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](n T) int {
+// Len returns the number of bytes needed to encode n as a VLQ.
+func Len[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](n T) int {
 	// n|1 ensures that we don't return 0 without returning a too large number
 	return (bits.Len64(uint64(n|1)) + 6) / 7
 }
@@ -83,7 +90,7 @@ func Size[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](n T) int {
 // Write encodes i as a VLQ into w. Any error returned by w is returned by this
 // function.
 func Write[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](w io.ByteWriter, i T) (n int, err error) {
-	l := Size(i)
+	l := Len(i)
 
 	j := l - 1
 	for ; j >= 0 && err == nil; j-- {
@@ -96,3 +103,15 @@ func Write[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](w io.ByteWriter, i T)
 
 	return l - 1 - j, err
 }
+
+// Append appends the VLQ encoding of i to dst and returns the extended slice.
+func Append[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](dst []byte, i T) []byte {
+	for j := Len(i) - 1; j >= 0; j-- {
+		b := byte(i>>(j*7)) & 0x7f
+		if j > 0 {
+			b |= 0x80
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
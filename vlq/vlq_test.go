@@ -3,6 +3,7 @@ package vlq
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"runtime"
@@ -11,6 +12,22 @@ import (
 	"testing"
 )
 
+func Example() {
+	b := Append(nil, uint(113549))
+	fmt.Printf("% X\n", b)
+
+	n, err := ReadMinimal[uint](bytes.NewReader(b))
+	fmt.Println(n, err)
+
+	_, err = ReadMinimal[uint](bytes.NewReader([]byte{0x80, 0x01}))
+	fmt.Println(errors.Is(err, ErrNotMinimal))
+
+	// Output:
+	// 86 F7 0D
+	// 113549 <nil>
+	// true
+}
+
 //region Testing Helpers
 
 // readTestCase represents a single reading test case for type T.
@@ -52,9 +69,9 @@ type writeTestcase[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64] struct {
 func testWrite[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](t *testing.T, tc writeTestcase[T]) {
 	t.Helper()
 
-	l := Size(tc.value)
+	l := Len(tc.value)
 	if l != len(tc.want) {
-		t.Errorf("Size(%d) = %d, want %d", tc.value, l, len(tc.want))
+		t.Errorf("Len(%d) = %d, want %d", tc.value, l, len(tc.want))
 	}
 	var buf bytes.Buffer
 	buf.Grow(l)
@@ -68,6 +85,9 @@ func testWrite[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](t *testing.T, tc
 	if got := buf.Bytes(); !slices.Equal(got, tc.want) {
 		t.Errorf("Write(%d) = %# x, want %# x", tc.value, got, tc.want)
 	}
+	if got := Append([]byte{0xff}, tc.value); got[0] != 0xff || !slices.Equal(got[1:], tc.want) {
+		t.Errorf("Append(%d) = %# x, want 0xff %# x", tc.value, got, tc.want)
+	}
 }
 
 //endregion
@@ -80,7 +100,7 @@ func Test_Read(t *testing.T) {
 		"MultiByte":     {[]byte{0x85, 0x01, 0x00}, 1, 641, nil},
 		"EOF":           {nil, 0, 0, io.EOF},
 		"UnexpectedEOF": {[]byte{0x81, 0x80}, 0, 0, io.ErrUnexpectedEOF},
-		"Overflow":      {[]byte{0x81, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, 0, 0, errOverflow}, // assumes uint size of 8 bytes (64 bit architecture)
+		"Overflow":      {[]byte{0x81, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, 0, 0, ErrOverflow}, // assumes uint size of 8 bytes (64 bit architecture)
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -92,7 +112,7 @@ func Test_Read(t *testing.T) {
 func TestRead8(t *testing.T) {
 	tests := map[string]readTestCase[uint8]{
 		"SingleByte": {[]byte{0x05}, 0, 5, nil},
-		"Overflow":   {[]byte{0x85, 0x01, 0x00}, 0, 0, errOverflow},
+		"Overflow":   {[]byte{0x85, 0x01, 0x00}, 0, 0, ErrOverflow},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -103,7 +123,7 @@ func TestRead8(t *testing.T) {
 
 func TestReadMinimal(t *testing.T) {
 	tests := map[string]readTestCase[uint]{
-		"NonMinimal": {[]byte{0x80, 0x85, 0x01}, 0, 0, errNotMinimal},
+		"NonMinimal": {[]byte{0x80, 0x85, 0x01}, 0, 0, ErrNotMinimal},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -145,6 +165,6 @@ func TestWrite8(t *testing.T) {
 
 func BenchmarkLength(b *testing.B) {
 	for b.Loop() {
-		Size(uint8(200))
+		Len(uint8(200))
 	}
 }
@@ -0,0 +1,175 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package x509asn1 provides Go types for the ASN.1 structures of X.509
+// certificates as defined in [RFC 5280]. The types can be used with the ber
+// package to parse and construct certificates and related structures on the
+// ASN.1 level, without the interpretation applied by the crypto/x509 package.
+//
+// The types preserve the encoding of open types (ANY) using [ber.RawValue], so
+// that decoding and re-encoding a DER-encoded certificate reproduces the
+// original bytes. Components with a DEFAULT value are omitted during encoding
+// if they contain their default value.
+//
+// [RFC 5280]: https://www.rfc-editor.org/rfc/rfc5280
+package x509asn1
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+	"codello.dev/asn1/tlv"
+)
+
+// Certificate is the top-level structure of an X.509 certificate.
+type Certificate struct {
+	TBSCertificate     TBSCertificate
+	SignatureAlgorithm AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+// TBSCertificate contains the signed contents of a [Certificate].
+type TBSCertificate struct {
+	Version              int `asn1:"optional,omitzero,explicit,tag:0"` // DEFAULT v1
+	SerialNumber         *big.Int
+	Signature            AlgorithmIdentifier
+	Issuer               Name
+	Validity             Validity
+	Subject              Name
+	SubjectPublicKeyInfo SubjectPublicKeyInfo
+	IssuerUniqueID       asn1.BitString `asn1:"optional,omitzero,tag:1"`
+	SubjectUniqueID      asn1.BitString `asn1:"optional,omitzero,tag:2"`
+	Extensions           Extensions     `asn1:"optional,omitzero,explicit,tag:3"`
+}
+
+// AlgorithmIdentifier identifies an algorithm and its parameters. The type of
+// the parameters is defined by the algorithm. The parameters are absent if
+// Parameters is the zero value.
+type AlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters ber.RawValue `asn1:"optional,omitzero"` // ANY DEFINED BY Algorithm
+}
+
+// SubjectPublicKeyInfo contains a public key and identifies its algorithm.
+type SubjectPublicKeyInfo struct {
+	Algorithm        AlgorithmIdentifier
+	SubjectPublicKey asn1.BitString
+}
+
+// Validity is the time interval during which a certificate is valid.
+type Validity struct {
+	NotBefore Time
+	NotAfter  Time
+}
+
+// Name is the distinguished name of the issuer or subject of a certificate.
+// Name is a CHOICE type with RDNSequence as its only alternative.
+type Name = RDNSequence
+
+// RDNSequence is a sequence of relative distinguished names, starting with the
+// most significant one.
+type RDNSequence []RelativeDistinguishedName
+
+// RelativeDistinguishedName is a SET OF attributes identifying an entry within
+// its superior entry. Most relative distinguished names consist of a single
+// attribute. The order of the attributes is preserved during encoding.
+type RelativeDistinguishedName []AttributeTypeAndValue
+
+// BerEncode encodes rdn as SET OF.
+func (rdn RelativeDistinguishedName) BerEncode() (ber.Header, io.WriterTo, error) {
+	s, err := ber.SequenceOf([]AttributeTypeAndValue(rdn))
+	if err != nil {
+		return ber.Header{}, nil, err
+	}
+	s.Tag = asn1.TagSet
+	return s.BerEncode()
+}
+
+// BerMatch reports whether tag identifies a SET.
+func (rdn *RelativeDistinguishedName) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagSet
+}
+
+// BerDecode decodes the attributes of a SET OF into rdn.
+func (rdn *RelativeDistinguishedName) BerDecode(_ asn1.Tag, r ber.Reader) error {
+	*rdn = nil
+	for atv, err := range ber.DecodeSeq[AttributeTypeAndValue](r) {
+		if err != nil {
+			return err
+		}
+		*rdn = append(*rdn, atv)
+	}
+	return nil
+}
+
+// AttributeTypeAndValue is a single attribute of a distinguished name. The
+// type of the value depends on the attribute type. Usually it is a string type
+// such as PrintableString or UTF8String.
+type AttributeTypeAndValue struct {
+	Type  asn1.ObjectIdentifier
+	Value ber.RawValue // ANY DEFINED BY Type
+}
+
+// Extensions is the sequence of extensions of a certificate.
+type Extensions []Extension
+
+// Extension is a single certificate extension. The Value contains the DER
+// encoding of the extension value whose type is defined by ExtnID.
+type Extension struct {
+	ExtnID   asn1.ObjectIdentifier
+	Critical bool `asn1:"optional,omitzero"` // DEFAULT FALSE
+	Value    []byte
+}
+
+// Time is a CHOICE of UTCTime and GeneralizedTime. As required by RFC 5280,
+// times in the years 1950 through 2049 are encoded as UTCTime and all other
+// times as GeneralizedTime. Times are converted to UTC and truncated to whole
+// seconds during encoding.
+type Time struct {
+	time.Time
+}
+
+// BerEncode encodes t as UTCTime or GeneralizedTime.
+func (t Time) BerEncode() (ber.Header, io.WriterTo, error) {
+	u := t.UTC().Truncate(time.Second)
+	var b []byte
+	var err error
+	if u.Year() >= 1950 && u.Year() < 2050 {
+		b, err = ber.Marshal(asn1.UTCTime(u))
+	} else {
+		b, err = ber.Marshal(asn1.GeneralizedTime(u))
+	}
+	if err != nil {
+		return ber.Header{}, nil, err
+	}
+	rv, _, err := ber.ParseRawValue(b)
+	return ber.Header{Tag: rv.Tag, Length: len(rv.Bytes)}, bytes.NewReader(rv.Bytes), err
+}
+
+// BerMatch reports whether tag identifies one of the alternatives of t.
+func (t *Time) BerMatch(tag asn1.Tag) bool {
+	return tag == asn1.TagUTCTime || tag == asn1.TagGeneralizedTime
+}
+
+// BerDecode decodes a UTCTime or GeneralizedTime into t.
+func (t *Time) BerDecode(tag asn1.Tag, r ber.Reader) error {
+	content, err := ber.NewStringReader(tag, r).Bytes()
+	if err != nil {
+		return err
+	}
+	b := tlv.Header{Tag: tag, Length: len(content)}.AppendTo(nil)
+	b = append(b, content...)
+	switch tag {
+	case asn1.TagUTCTime:
+		return ber.Unmarshal(b, (*asn1.UTCTime)(&t.Time))
+	case asn1.TagGeneralizedTime:
+		return ber.Unmarshal(b, (*asn1.GeneralizedTime)(&t.Time))
+	}
+	return errors.New("x509asn1: invalid time type " + tag.String())
+}
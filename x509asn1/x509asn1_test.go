@@ -0,0 +1,132 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509asn1
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/ber"
+)
+
+// newCertificate creates a self-signed certificate using the given key.
+func newCertificate(t *testing.T, key crypto.Signer, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(4711),
+		Subject: pkix.Name{
+			CommonName:   "Test",
+			Organization: []string{"Example Org"},
+			Country:      []string{"DE"},
+		},
+		NotBefore:             time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"example.com"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("x509.ParseCertificate() error = %v", err)
+	}
+	return cert
+}
+
+func TestCertificate(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	tests := map[string]struct {
+		key      crypto.Signer
+		notAfter time.Time
+	}{
+		"ECDSA":           {ecKey, time.Date(2034, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"Ed25519":         {edKey, time.Date(2034, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"RSA":             {rsaKey, time.Date(2034, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"GeneralizedTime": {ecKey, time.Date(2054, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			want := newCertificate(t, tt.key, tt.notAfter)
+			var got Certificate
+			if err := ber.Unmarshal(want.Raw, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			tbs := got.TBSCertificate
+			if tbs.Version != want.Version-1 {
+				t.Errorf("Version = %d, want %d", tbs.Version, want.Version-1)
+			}
+			if tbs.SerialNumber.Cmp(want.SerialNumber) != 0 {
+				t.Errorf("SerialNumber = %v, want %v", tbs.SerialNumber, want.SerialNumber)
+			}
+			if !tbs.Validity.NotBefore.Equal(want.NotBefore) || !tbs.Validity.NotAfter.Equal(want.NotAfter) {
+				t.Errorf("Validity = %v, want %v - %v", tbs.Validity, want.NotBefore, want.NotAfter)
+			}
+			if len(tbs.Subject) != 3 || !tbs.Subject[2][0].Type.Equal(asn1.ObjectIdentifier{2, 5, 4, 3}) {
+				t.Errorf("Subject = %v, want 3 attributes ending with commonName", tbs.Subject)
+			}
+			if len(tbs.Extensions) != len(want.Extensions) {
+				t.Errorf("len(Extensions) = %d, want %d", len(tbs.Extensions), len(want.Extensions))
+			}
+			if !bytes.Equal(got.SignatureValue.Bytes, want.Signature) {
+				t.Errorf("SignatureValue = % X, want % X", got.SignatureValue.Bytes, want.Signature)
+			}
+
+			data, err := ber.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(data, want.Raw) {
+				t.Errorf("Marshal() = % X, want % X", data, want.Raw)
+			}
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	tests := map[string]struct {
+		t    time.Time
+		want []byte
+	}{
+		"UTCTime":         {time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC), append([]byte{0x17, 0x0D}, "491231235959Z"...)},
+		"GeneralizedTime": {time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC), append([]byte{0x18, 0x0F}, "20500101000000Z"...)},
+		"Early":           {time.Date(1949, 12, 31, 23, 59, 59, 0, time.UTC), append([]byte{0x18, 0x0F}, "19491231235959Z"...)},
+		"Truncated":       {time.Date(2024, 5, 6, 7, 8, 9, 500, time.FixedZone("", 3600)), append([]byte{0x17, 0x0D}, "240506060809Z"...)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ber.Marshal(Time{tt.t})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal() = % X, want % X", got, tt.want)
+			}
+			var dec Time
+			if err = ber.Unmarshal(got, &dec); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !dec.Equal(tt.t.Truncate(time.Second)) {
+				t.Errorf("Unmarshal() = %v, want %v", dec, tt.t.Truncate(time.Second))
+			}
+		})
+	}
+}
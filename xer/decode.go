@@ -0,0 +1,631 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+)
+
+// Unmarshal parses the XER-encoded data and stores the result in the value
+// pointed to by val. If val is nil or not a pointer, Unmarshal returns an
+// error. The name of the top-level element is not validated.
+//
+// Malformed XML is reported using the error types of the [encoding/xml]
+// package. If the XML data does not match the Go type of val, a
+// [StructuralError] is returned.
+func Unmarshal(data []byte, val any) error {
+	return UnmarshalWithParams(data, val, "")
+}
+
+// UnmarshalWithParams allows field parameters to be specified for the top-level
+// value. The form of the params is the same as the field tags.
+func UnmarshalWithParams(data []byte, val any, params string) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return &InvalidDecodeError{v}
+	}
+	d := &decodeState{d: xml.NewDecoder(bytes.NewReader(data))}
+	start, err := d.nextElement()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	if err = d.decode(start, v.Elem(), internal.ParseFieldParameters(params)); err != nil {
+		return err
+	}
+	if _, err = d.nextElement(); err == nil {
+		return errors.New("extra data after top-level element")
+	} else if err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// decodeState wraps an [xml.Decoder] and adds the ability to look ahead one
+// token.
+type decodeState struct {
+	d      *xml.Decoder
+	peeked xml.Token
+}
+
+// token returns the next token, skipping comments, processing instructions
+// and directives.
+func (d *decodeState) token() (xml.Token, error) {
+	if d.peeked != nil {
+		t := d.peeked
+		d.peeked = nil
+		return t, nil
+	}
+	for {
+		t, err := d.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.Comment, xml.ProcInst, xml.Directive:
+			continue
+		case xml.CharData:
+			return t.Copy(), nil
+		default:
+			return t, nil
+		}
+	}
+}
+
+// peek returns the next token without consuming it.
+func (d *decodeState) peek() (xml.Token, error) {
+	if d.peeked == nil {
+		t, err := d.token()
+		if err != nil {
+			return nil, err
+		}
+		d.peeked = t
+	}
+	return d.peeked, nil
+}
+
+// nextElement returns the next start element, skipping whitespace. If an end
+// element is encountered instead, nextElement returns nil and no error. Any
+// non-whitespace character data causes an error.
+func (d *decodeState) nextElement() (*xml.StartElement, error) {
+	for {
+		t, err := d.token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			return &t, nil
+		case xml.EndElement:
+			return nil, nil
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return nil, &StructuralError{Err: fmt.Errorf("unexpected character data %q", t)}
+			}
+		}
+	}
+}
+
+// text reads the character data of the current element up to and including
+// its end element. If the element contains child elements, an error is
+// returned.
+func (d *decodeState) text(start *xml.StartElement) (string, error) {
+	var s strings.Builder
+	for {
+		t, err := d.token()
+		if err != nil {
+			return "", err
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			s.Write(t)
+		case xml.EndElement:
+			return s.String(), nil
+		case xml.StartElement:
+			return "", &StructuralError{Element: start.Name.Local, Err: fmt.Errorf("unexpected element <%s>", t.Name.Local)}
+		}
+	}
+}
+
+// content reads the content of the current element up to and including its
+// end element. The content may either be character data or a single empty
+// element. If the content is an empty element, its name is returned and
+// special is true.
+func (d *decodeState) content(start *xml.StartElement) (s string, special bool, err error) {
+	t, err := d.peek()
+	if err != nil {
+		return "", false, err
+	}
+	if cd, ok := t.(xml.CharData); ok && len(bytes.TrimSpace(cd)) > 0 {
+		s, err = d.text(start)
+		return strings.TrimSpace(s), false, err
+	}
+	child, err := d.nextElement()
+	if err != nil || child == nil {
+		return "", false, err
+	}
+	if _, err = d.text(child); err != nil {
+		return "", false, err
+	}
+	if end, err := d.nextElement(); err != nil {
+		return "", false, err
+	} else if end != nil {
+		return "", false, &StructuralError{Element: start.Name.Local, Err: fmt.Errorf("unexpected element <%s>", end.Name.Local)}
+	}
+	return child.Name.Local, true, nil
+}
+
+// decode decodes the element start into v. The content of the element is read
+// up to and including its end element.
+func (d *decodeState) decode(start *xml.StartElement, v reflect.Value, params internal.FieldParameters) error {
+	v0 := v
+	if internal.IsNullable(v.Type()) {
+		if t, err := d.peek(); err != nil {
+			return err
+		} else if _, ok := t.(xml.EndElement); ok {
+			_, _ = d.token()
+			v.SetZero()
+			return nil
+		}
+		if err := d.decode(start, v.Field(0), params); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+	if internal.IsOptional(v.Type()) {
+		if err := d.decode(start, v.Field(0), params); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+
+	// If v is a named type and is addressable, start with its address, so that if
+	// the type has pointer methods, we find them.
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.Kind() == reflect.Interface {
+			if e := v.Elem(); e.Kind() == reflect.Pointer && !e.IsNil() {
+				v = e
+				continue
+			}
+			return &InvalidDecodeError{v}
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if !notation.IsKnownType(v.Type().Elem()) {
+			if u, ok := v.Interface().(xml.Unmarshaler); ok {
+				return u.UnmarshalXML(d.d, *start)
+			}
+		}
+		v = v.Elem()
+	}
+	if !v.CanSet() {
+		return &InvalidDecodeError{v}
+	}
+
+	if params.Nullable {
+		if t, err := d.peek(); err != nil {
+			return err
+		} else if _, ok := t.(xml.EndElement); ok {
+			_, _ = d.token()
+			v0.SetZero()
+			return nil
+		}
+	}
+	if err := d.decodeContent(start, v, params); err != nil {
+		var se *StructuralError
+		if errors.As(err, &se) && se.Element == "" {
+			se.Element = start.Name.Local
+		}
+		return err
+	}
+	return nil
+}
+
+// decodeContent decodes the content of the element start into the settable
+// value v.
+func (d *decodeState) decodeContent(start *xml.StartElement, v reflect.Value, params internal.FieldParameters) error {
+	switch vp := v.Addr().Interface().(type) {
+	case *asn1.BitString:
+		s, err := d.text(start)
+		if err != nil {
+			return err
+		}
+		return decodeBitString(strings.TrimSpace(s), vp)
+	case *asn1.Null:
+		if end, err := d.nextElement(); err != nil {
+			return err
+		} else if end != nil {
+			return &StructuralError{Type: v.Type(), Err: errors.New("expected empty element")}
+		}
+		return nil
+	case *asn1.ObjectIdentifier:
+		s, err := d.text(start)
+		if err != nil {
+			return err
+		}
+		*vp, err = decodeOID(strings.TrimSpace(s), v.Type())
+		return err
+	case *asn1.RelativeOID:
+		s, err := d.text(start)
+		if err != nil {
+			return err
+		}
+		oid, err := decodeOID(strings.TrimSpace(s), v.Type())
+		*vp = asn1.RelativeOID(oid)
+		return err
+	case *big.Int:
+		s, err := d.text(start)
+		if err != nil {
+			return err
+		}
+		if _, ok := vp.SetString(strings.TrimSpace(s), 10); !ok {
+			return &StructuralError{Type: v.Type(), Err: errors.New("invalid INTEGER")}
+		}
+		return nil
+	case *big.Float:
+		s, special, err := d.content(start)
+		if err != nil {
+			return err
+		}
+		return decodeBigFloat(s, special, vp)
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		s, err := d.text(start)
+		if err != nil {
+			return err
+		}
+		if err = notation.Parse(strings.TrimSpace(s), v, tag); err != nil {
+			return &StructuralError{Type: v.Type(), Err: err}
+		}
+		return nil
+	}
+	if vv, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+		b, err := d.hex(start, v.Type())
+		if err != nil {
+			return err
+		}
+		return vv.UnmarshalBinary(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		s, _, err := d.content(start)
+		if err != nil {
+			return err
+		}
+		return decodeBool(s, v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s, special, err := d.content(start)
+		if err != nil {
+			return err
+		}
+		if special {
+			i, err := asn1.EnumValue(v.Type(), s)
+			if err != nil {
+				return &StructuralError{Type: v.Type(), Err: err}
+			}
+			v.SetInt(int64(i))
+			return nil
+		}
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return &StructuralError{Type: v.Type(), Err: errors.New("invalid INTEGER")}
+		}
+		v.SetInt(i)
+		if err = asn1.ValidateEnum(v.Interface()); err != nil {
+			return &StructuralError{Type: v.Type(), Err: err}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s, err := d.text(start)
+		if err != nil {
+			return err
+		}
+		i, err := strconv.ParseUint(strings.TrimSpace(s), 10, v.Type().Bits())
+		if err != nil {
+			return &StructuralError{Type: v.Type(), Err: errors.New("invalid INTEGER")}
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		s, special, err := d.content(start)
+		if err != nil {
+			return err
+		}
+		f, err := decodeFloat(s, special, v.Type())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, err := d.text(start)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+		if vv, ok := v.Interface().(interface{ IsValid() bool }); ok && !vv.IsValid() {
+			return &StructuralError{Type: v.Type(), Err: errors.New("invalid characters")}
+		}
+	case reflect.Struct:
+		decode := d.decodeStruct
+		if params.Choice {
+			decode = d.decodeChoice
+		}
+		if err := decode(v); err != nil {
+			return err
+		}
+		if err := internal.Validate(v); err != nil {
+			return &StructuralError{Type: v.Type(), Err: err}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.hex(start, v.Type())
+			if err != nil {
+				return err
+			}
+			if v.Kind() == reflect.Slice {
+				v.SetBytes(b)
+			} else if reflect.Copy(v, reflect.ValueOf(b)) != len(b) || len(b) != v.Len() {
+				return &StructuralError{Type: v.Type(), Err: errors.New("wrong number of bytes")}
+			}
+			return nil
+		}
+		if v.Kind() == reflect.Slice {
+			v.SetLen(0)
+		}
+		i := 0
+		err := d.decodeElements(v.Type().Elem(), func(elem reflect.Value) {
+			if v.Kind() == reflect.Slice {
+				v.Set(reflect.Append(v, elem))
+			} else if i < v.Len() {
+				v.Index(i).Set(elem)
+			}
+			i++
+		})
+		if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		} else if v.Kind() == reflect.Array && i != v.Len() {
+			return &StructuralError{Type: v.Type(), Err: errors.New("wrong number of values")}
+		}
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &InvalidDecodeError{v}
+		}
+		v.Set(reflect.MakeMap(v.Type()))
+		empty := reflect.ValueOf(struct{}{})
+		return d.decodeElements(v.Type().Key(), func(elem reflect.Value) {
+			v.SetMapIndex(elem, empty)
+		})
+	default:
+		return &InvalidDecodeError{v}
+	}
+	return nil
+}
+
+// decodeStruct decodes the child elements of the current element into the
+// fields of the struct v. The elements must appear in the order of the fields.
+// Additional elements are only accepted if the struct is extensible.
+func (d *decodeState) decodeStruct(v reflect.Value) error {
+	child, err := d.nextElement()
+	if err != nil {
+		return err
+	}
+	extensible := false
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) {
+			extensible = true
+			continue
+		}
+		if child == nil || child.Name.Local != params.Name {
+			if !params.Optional {
+				return &StructuralError{Type: v.Type(), Err: fmt.Errorf("missing element <%s>", params.Name)}
+			}
+			internal.SetAbsent(field)
+			continue
+		}
+		if err = d.decode(child, field, params); err != nil {
+			return err
+		}
+		if child, err = d.nextElement(); err != nil {
+			return err
+		}
+	}
+	for child != nil {
+		if !extensible {
+			return &StructuralError{Type: v.Type(), Err: fmt.Errorf("unexpected element <%s>", child.Name.Local)}
+		}
+		if err = d.d.Skip(); err != nil {
+			return err
+		}
+		if child, err = d.nextElement(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeChoice decodes the single child element of the current element into the
+// field of the CHOICE struct v named by the element. All other fields of v are
+// set to their zero value.
+func (d *decodeState) decodeChoice(v reflect.Value) error {
+	child, err := d.nextElement()
+	if err != nil {
+		return err
+	}
+	if child == nil {
+		return &StructuralError{Type: v.Type(), Err: errors.New("missing CHOICE alternative")}
+	}
+	v.SetZero()
+	found := false
+	for field, params := range internal.StructFields(v) {
+		if internal.IsExtensible(field.Type()) || child.Name.Local != params.Name {
+			continue
+		}
+		if err = d.decode(child, field, params); err != nil {
+			return err
+		}
+		found = true
+		break
+	}
+	if !found {
+		return &StructuralError{Type: v.Type(), Err: fmt.Errorf("unknown CHOICE alternative <%s>", child.Name.Local)}
+	}
+	if child, err = d.nextElement(); err != nil {
+		return err
+	} else if child != nil {
+		return &StructuralError{Type: v.Type(), Err: fmt.Errorf("unexpected element <%s>", child.Name.Local)}
+	}
+	return nil
+}
+
+// decodeElements decodes the components of a SEQUENCE OF or SET OF with
+// element type t and invokes add for each of them. Components of type BOOLEAN
+// are encoded as a list of values.
+func (d *decodeState) decodeElements(t reflect.Type, add func(reflect.Value)) error {
+	for {
+		child, err := d.nextElement()
+		if err != nil || child == nil {
+			return err
+		}
+		elem := reflect.New(t).Elem()
+		if t.Kind() == reflect.Bool {
+			if _, err = d.text(child); err == nil {
+				err = decodeBool(child.Name.Local, elem)
+			}
+		} else {
+			err = d.decode(child, elem, internal.FieldParameters{})
+		}
+		if err != nil {
+			return err
+		}
+		add(elem)
+	}
+}
+
+// hex reads the hexadecimal content of the current element.
+func (d *decodeState) hex(start *xml.StartElement, t reflect.Type) ([]byte, error) {
+	s, err := d.text(start)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		return nil, &StructuralError{Type: t, Err: err}
+	}
+	return b, nil
+}
+
+// decodeBool decodes the BOOLEAN value s into v. The value s is the name of the
+// empty element or the character data of the element.
+func decodeBool(s string, v reflect.Value) error {
+	switch s {
+	case "true":
+		v.SetBool(true)
+	case "false":
+		v.SetBool(false)
+	default:
+		return &StructuralError{Type: v.Type(), Err: fmt.Errorf("invalid BOOLEAN %q", s)}
+	}
+	return nil
+}
+
+// decodeBitString decodes a string of 0 and 1 characters into bs.
+func decodeBitString(s string, bs *asn1.BitString) error {
+	s = strings.Join(strings.Fields(s), "")
+	ret := asn1.BitString{Bytes: make([]byte, (len(s)+7)/8), BitLength: len(s)}
+	for i, c := range []byte(s) {
+		switch c {
+		case '0':
+		case '1':
+			ret.Bytes[i/8] |= 0x80 >> (i % 8)
+		default:
+			return &StructuralError{Type: reflect.TypeFor[asn1.BitString](), Err: fmt.Errorf("invalid BIT STRING %q", s)}
+		}
+	}
+	*bs = ret
+	return nil
+}
+
+// decodeOID decodes an object identifier in dot notation.
+func decodeOID(s string, t reflect.Type) (asn1.ObjectIdentifier, error) {
+	if s == "" {
+		return nil, &StructuralError{Type: t, Err: errors.New("empty object identifier")}
+	}
+	var oid asn1.ObjectIdentifier
+	for part := range strings.SplitSeq(s, ".") {
+		n, err := strconv.ParseUint(part, 10, strconv.IntSize)
+		if err != nil {
+			return nil, &StructuralError{Type: t, Err: fmt.Errorf("invalid object identifier %q", s)}
+		}
+		oid = append(oid, uint(n))
+	}
+	return oid, nil
+}
+
+// decodeFloat decodes a REAL value. If special is true, s is the name of an
+// empty element identifying a special value.
+func decodeFloat(s string, special bool, t reflect.Type) (float64, error) {
+	if special {
+		switch s {
+		case "PLUS-INFINITY":
+			return math.Inf(1), nil
+		case "MINUS-INFINITY":
+			return math.Inf(-1), nil
+		case "NOT-A-NUMBER":
+			return math.NaN(), nil
+		}
+		return 0, &StructuralError{Type: t, Err: fmt.Errorf("invalid REAL <%s/>", s)}
+	}
+	// strconv.ParseFloat accepts additional formats such as hexadecimal floats
+	f, err := strconv.ParseFloat(s, t.Bits())
+	if err != nil || strings.Trim(s, "0123456789+-.eE") != "" {
+		return 0, &StructuralError{Type: t, Err: errors.New("invalid REAL")}
+	}
+	return f, nil
+}
+
+// decodeBigFloat decodes a REAL value into f. NaN values cannot be represented
+// by [big.Float].
+func decodeBigFloat(s string, special bool, f *big.Float) error {
+	t := reflect.TypeFor[big.Float]()
+	if special {
+		switch s {
+		case "PLUS-INFINITY":
+			f.SetInf(false)
+		case "MINUS-INFINITY":
+			f.SetInf(true)
+		default:
+			return &StructuralError{Type: t, Err: fmt.Errorf("invalid REAL <%s/>", s)}
+		}
+		return nil
+	}
+	if _, ok := f.SetString(s); !ok {
+		return &StructuralError{Type: t, Err: errors.New("invalid REAL")}
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+)
+
+// Marshal returns the XER encoding of val or an error if encoding fails.
+func Marshal(val any) ([]byte, error) {
+	return MarshalWithParams(val, "")
+}
+
+// MarshalWithParams returns the XER encoding of val. The format of the params
+// is described in the asn1 package. The "name" parameter sets the name of the
+// top-level element.
+func MarshalWithParams(val any, params string) ([]byte, error) {
+	var e encodeState
+	if err := e.encode(reflect.ValueOf(val), internal.ParseFieldParameters(params)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// encodeState holds the XML output of an encoding process.
+type encodeState struct {
+	bytes.Buffer
+}
+
+// encode writes the XER encoding of v as an XML element to e. The element name
+// is taken from params. If params do not specify a name, the name is derived
+// from the type of v.
+func (e *encodeState) encode(v reflect.Value, params internal.FieldParameters) error {
+	if !v.IsValid() {
+		return &UnsupportedTypeError{Type: nil}
+	}
+	if internal.IsNullable(v.Type()) {
+		if !v.Field(1).Bool() {
+			name := params.Name
+			if name == "" {
+				name = elementName(v.Field(0).Type(), params)
+			}
+			e.writeEmpty(name)
+			return nil
+		}
+		return e.encode(v.Field(0), params)
+	}
+	if internal.IsOptional(v.Type()) {
+		return e.encode(v.Field(0), params)
+	}
+
+	// If v is a named type and is addressable, start with its address, so that if
+	// the type has pointer methods, we find them.
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if params.Nullable && params.Name != "" {
+				e.writeEmpty(params.Name)
+				return nil
+			}
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		if m, ok := v.Interface().(xml.Marshaler); ok && !notation.IsKnownType(v.Type().Elem()) {
+			return e.marshalXML(v, m, params)
+		}
+		v = v.Elem()
+	}
+	name := params.Name
+	if name == "" {
+		name = elementName(v.Type(), params)
+	}
+	if m, ok := v.Interface().(xml.Marshaler); ok && !notation.IsKnownType(v.Type()) {
+		return e.marshalXML(v, m, params)
+	}
+	if params.Nullable {
+		if z, ok := v.Interface().(interface{ IsZero() bool }); (ok && z.IsZero()) || (!ok && v.IsZero()) {
+			e.writeEmpty(name)
+			return nil
+		}
+	}
+
+	e.WriteByte('<')
+	e.WriteString(name)
+	e.WriteByte('>')
+	start := e.Len()
+	if err := e.encodeContent(v, params); err != nil {
+		return err
+	}
+	if e.Len() == start {
+		// use an empty-element tag instead
+		e.Truncate(start - 1)
+		e.WriteString("/>")
+		return nil
+	}
+	e.WriteString("</")
+	e.WriteString(name)
+	e.WriteByte('>')
+	return nil
+}
+
+// encodeContent writes the content of the XML element representing v to e.
+func (e *encodeState) encodeContent(v reflect.Value, params internal.FieldParameters) error {
+	vif := v.Interface()
+	switch vv := vif.(type) {
+	case asn1.BitString:
+		if !vv.IsValid() {
+			return &EncodeError{v, errors.New("invalid BIT STRING")}
+		}
+		for i := range vv.Len() {
+			e.WriteByte('0' + byte(vv.At(i)))
+		}
+		return nil
+	case asn1.Null:
+		return nil
+	case asn1.ObjectIdentifier:
+		e.WriteString(vv.String())
+		return nil
+	case asn1.RelativeOID:
+		e.WriteString(vv.String())
+		return nil
+	case big.Int:
+		e.WriteString(vv.String())
+		return nil
+	case big.Float:
+		switch {
+		case vv.IsInf() && vv.Signbit():
+			e.WriteString("<MINUS-INFINITY/>")
+		case vv.IsInf():
+			e.WriteString("<PLUS-INFINITY/>")
+		case vv.Sign() == 0 && vv.Signbit():
+			e.WriteString("-0")
+		default:
+			e.WriteString(strings.Replace(vv.Text('E', -1), "E+", "E", 1))
+		}
+		return nil
+	}
+	if tag, ok := notation.Tag(v.Type(), params); ok {
+		s, err := notation.Format(v, tag)
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		e.writeText(s)
+		return nil
+	}
+	if vv, ok := vif.(encoding.BinaryMarshaler); ok {
+		b, err := vv.MarshalBinary()
+		if err != nil {
+			return &EncodeError{v, err}
+		}
+		e.WriteString(strings.ToUpper(hex.EncodeToString(b)))
+		return nil
+	}
+	if err := asn1.ValidateEnum(vif); err != nil {
+		return &EncodeError{v, err}
+	}
+	if vv, ok := vif.(interface{ IsValid() bool }); ok && !vv.IsValid() {
+		return &EncodeError{v, errors.New("invalid value")}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.WriteString("<true/>")
+		} else {
+			e.WriteString("<false/>")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if name, err := asn1.EnumName(v.Type(), int(v.Int())); err == nil {
+			e.writeEmpty(name)
+		} else {
+			e.WriteString(strconv.FormatInt(v.Int(), 10))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		e.writeFloat(v.Float(), v.Type().Bits())
+	case reflect.String:
+		e.writeText(v.String())
+	case reflect.Struct:
+		if params.Choice {
+			// only the chosen alternative is encoded
+			alt, altParams, err := internal.ChoiceAlternative(v)
+			if err != nil {
+				return &EncodeError{v, err}
+			}
+			return e.encode(alt, altParams)
+		}
+		for field, params := range internal.StructFields(v) {
+			if internal.IsExtensible(field.Type()) {
+				continue
+			}
+			if params.OmitEmpty && internal.IsEmpty(field) {
+				continue
+			}
+			if params.OmitZero {
+				if z, ok := field.Interface().(interface{ IsZero() bool }); (ok && z.IsZero()) || (!ok && field.IsZero()) {
+					continue
+				}
+			}
+			if err := e.encode(field, params); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.WriteString(strings.ToUpper(hex.EncodeToString(b)))
+			return nil
+		}
+		for i := range v.Len() {
+			if err := e.encodeElement(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem() != emptyStructType {
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		// sort the elements by their encoding to produce a deterministic output
+		elems := make([][]byte, 0, v.Len())
+		var es encodeState
+		for _, key := range v.MapKeys() {
+			es.Reset()
+			if err := es.encodeElement(key); err != nil {
+				return err
+			}
+			elems = append(elems, bytes.Clone(es.Bytes()))
+		}
+		slices.SortFunc(elems, bytes.Compare)
+		e.Write(slices.Concat(elems...))
+	default:
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	return nil
+}
+
+// encodeElement writes a component of a SEQUENCE OF or SET OF. Components of
+// type BOOLEAN are written as a list of values without enclosing elements, as
+// described in Section 9.3.4 of Rec. ITU-T X.693.
+func (e *encodeState) encodeElement(v reflect.Value) error {
+	if v.Kind() == reflect.Bool {
+		return e.encodeContent(v, internal.FieldParameters{})
+	}
+	return e.encode(v, internal.FieldParameters{})
+}
+
+// marshalXML invokes m to write the element representing v.
+func (e *encodeState) marshalXML(v reflect.Value, m xml.Marshaler, params internal.FieldParameters) error {
+	name := params.Name
+	if name == "" {
+		name = elementName(v.Type(), params)
+	}
+	enc := xml.NewEncoder(&e.Buffer)
+	if err := m.MarshalXML(enc, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+		return &EncodeError{v, err}
+	}
+	return enc.Close()
+}
+
+// writeEmpty writes an empty element with the specified name.
+func (e *encodeState) writeEmpty(name string) {
+	e.WriteByte('<')
+	e.WriteString(name)
+	e.WriteString("/>")
+}
+
+// writeText writes s as escaped character data.
+func (e *encodeState) writeText(s string) {
+	_ = xml.EscapeText(&e.Buffer, []byte(s))
+}
+
+// writeFloat writes the REAL value f. Special values are written as empty
+// elements.
+func (e *encodeState) writeFloat(f float64, bitSize int) {
+	switch {
+	case math.IsInf(f, 1):
+		e.WriteString("<PLUS-INFINITY/>")
+	case math.IsInf(f, -1):
+		e.WriteString("<MINUS-INFINITY/>")
+	case math.IsNaN(f):
+		e.WriteString("<NOT-A-NUMBER/>")
+	case f == 0 && math.Signbit(f):
+		e.WriteString("-0")
+	default:
+		e.WriteString(strings.Replace(strconv.FormatFloat(f, 'G', -1, bitSize), "E+", "E", 1))
+	}
+}
+
+// emptyStructType is used to identify the [asn1.Set] type.
+var emptyStructType = reflect.TypeFor[struct{}]()
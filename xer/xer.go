@@ -0,0 +1,255 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xer implements the basic ASN.1 XML Encoding Rules (XER). The XML
+// Encoding Rules are defined in [Rec. ITU-T X.693].
+//
+// See the package documentation of the asn1 package for details how Go types
+// translate to ASN.1 types. Types following that specification can be encoded
+// into and decoded from XML using this package. The same struct types can be
+// used with the ber package.
+//
+// Each value is represented by an XML element. The element name of a
+// top-level value or a component of a SEQUENCE OF or SET OF is the name of its
+// Go type. If the Go type is unnamed or is defined by the asn1 package, the
+// XML name of the ASN.1 type is used instead (e.g. INTEGER or OCTET_STRING).
+// The components of a SEQUENCE use the ASN.1 identifiers of the struct fields
+// as element names (see the asn1 package). The element content is the basic
+// XER encoding of the value:
+//
+//   - BOOLEAN values are represented by an empty <true/> or <false/> element.
+//   - INTEGER values use their decimal representation.
+//   - ENUMERATED values are represented by an empty element named by the
+//     identifier of the value, for example <red/>. The identifiers are taken
+//     from the [asn1.EnumDef] registered for the Go type.
+//   - REAL values use their decimal representation. The special values are
+//     represented by empty <PLUS-INFINITY/>, <MINUS-INFINITY/> and
+//     <NOT-A-NUMBER/> elements.
+//   - BIT STRING values consist of the characters 0 and 1.
+//   - OCTET STRING values use hexadecimal digits.
+//   - NULL values are represented by an empty element.
+//   - OBJECT IDENTIFIER and RELATIVE-OID values use the dot notation.
+//   - Character string and time types use their ASN.1 value notation.
+//   - CHOICE values (see the "choice" struct tag) contain a single element for
+//     the chosen alternative.
+//
+// ASN.1 tags do not have any effect on the XML encoding. A value that is
+// "nullable" is encoded as an empty element if it is the zero value for its
+// type. The same applies to an [asn1.Nullable] that is not valid. Types that implement [encoding/xml.Marshaler] or
+// [encoding/xml.Unmarshaler] can customize their XER encoding.
+//
+// The following limitations apply:
+//
+//   - ENUMERATED values of types without an [asn1.EnumDef] are encoded by their
+//     numerical value because their identifiers are not known. The numerical
+//     value is also accepted when decoding.
+//   - Decoding into an interface{} is not supported because the ASN.1 type
+//     cannot be determined from the XML alone.
+//   - When decoding into a byte array or a Go array, the number of elements must
+//     match the length of the array exactly.
+//   - The element names of SEQUENCE OF and SET OF components are not
+//     validated during decoding.
+//
+// [Rec. ITU-T X.693]: https://www.itu.int/rec/T-REC-X.693
+package xer
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+
+	"codello.dev/asn1"
+	"codello.dev/asn1/internal"
+	"codello.dev/asn1/internal/notation"
+)
+
+//region error types
+
+// UnsupportedTypeError indicates that a value was passed to Marshal that cannot
+// be encoded to XER.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "cannot marshal nil value"
+	}
+	if e.Type.Kind() == reflect.Pointer {
+		return "cannot marshal nil pointer of type: " + e.Type.String()
+	} else if e.Type.Kind() == reflect.Interface {
+		return "cannot marshal nil interface of type: " + e.Type.String()
+	}
+	return "cannot marshal value of type " + e.Type.String() + ": unsupported Go type"
+}
+
+// EncodeError indicates that a value failed validation during encoding.
+type EncodeError struct {
+	Value reflect.Value
+	Err   error
+}
+
+func (e *EncodeError) Error() string {
+	var s strings.Builder
+	s.WriteString("encode error")
+	if e.Value.IsValid() {
+		s.WriteString(" for ")
+		s.WriteString(e.Value.Type().String())
+	}
+	s.WriteString(": ")
+	s.WriteString(e.Err.Error())
+	return s.String()
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidDecodeError indicates that an invalid value was passed to Unmarshal.
+// The invalid value might be nested within the passed value.
+type InvalidDecodeError struct {
+	Value reflect.Value
+}
+
+func (e *InvalidDecodeError) Error() string {
+	if !e.Value.IsValid() {
+		return "cannot decode into nil value"
+	}
+	if e.Value.Kind() == reflect.Pointer && e.Value.IsNil() {
+		return "cannot decode into nil pointer of type " + e.Value.Type().String()
+	} else if e.Value.Kind() != reflect.Pointer && !e.Value.CanAddr() {
+		return "cannot decode into non-pointer type " + e.Value.Type().String()
+	}
+	return "unsupported Go type: " + e.Value.Type().String()
+}
+
+// A StructuralError suggests that the XML data is well-formed, but the Go type
+// which is receiving it doesn't match or can't fit the data. Malformed XML is
+// reported using the error types of the [encoding/xml] package.
+type StructuralError struct {
+	Element string // name of the element where the error occurred
+	Type    reflect.Type
+	Err     error
+}
+
+func (e *StructuralError) Error() string {
+	var s strings.Builder
+	s.WriteString("structural error")
+	if e.Element != "" || e.Type != nil {
+		s.WriteString(" decoding")
+		if e.Element != "" {
+			s.WriteString(" <")
+			s.WriteString(e.Element)
+			s.WriteByte('>')
+		}
+		if e.Type != nil {
+			s.WriteString(" into ")
+			s.WriteString(e.Type.String())
+		}
+	}
+	if e.Err != nil {
+		s.WriteString(": ")
+		s.WriteString(e.Err.Error())
+	}
+	return s.String()
+}
+
+func (e *StructuralError) Unwrap() error {
+	return e.Err
+}
+
+//endregion
+
+//region element names
+
+// tagNames contains the XML names of universal ASN.1 types. See Table 4 of
+// Rec. ITU-T X.693.
+var tagNames = map[asn1.Tag]string{
+	asn1.TagBoolean:         "BOOLEAN",
+	asn1.TagInteger:         "INTEGER",
+	asn1.TagBitString:       "BIT_STRING",
+	asn1.TagOctetString:     "OCTET_STRING",
+	asn1.TagNull:            "NULL",
+	asn1.TagOID:             "OBJECT_IDENTIFIER",
+	asn1.TagReal:            "REAL",
+	asn1.TagEnumerated:      "ENUMERATED",
+	asn1.TagUTF8String:      "UTF8String",
+	asn1.TagRelativeOID:     "RELATIVE_OID",
+	asn1.TagTime:            "TIME",
+	asn1.TagSequence:        "SEQUENCE",
+	asn1.TagSet:             "SET",
+	asn1.TagNumericString:   "NumericString",
+	asn1.TagPrintableString: "PrintableString",
+	asn1.TagIA5String:       "IA5String",
+	asn1.TagUTCTime:         "UTCTime",
+	asn1.TagGeneralizedTime: "GeneralizedTime",
+	asn1.TagVisibleString:   "VisibleString",
+	asn1.TagUniversalString: "UniversalString",
+	asn1.TagBMPString:       "BMPString",
+	asn1.TagDate:            "DATE",
+	asn1.TagTimeOfDay:       "TIME_OF_DAY",
+	asn1.TagDateTime:        "DATE_TIME",
+	asn1.TagDuration:        "DURATION",
+}
+
+// typeTags contains the universal tags of Go types that are not identified by
+// their kind.
+var typeTags = map[reflect.Type]asn1.Tag{
+	reflect.TypeFor[asn1.BitString]():        asn1.TagBitString,
+	reflect.TypeFor[asn1.Null]():             asn1.TagNull,
+	reflect.TypeFor[asn1.ObjectIdentifier](): asn1.TagOID,
+	reflect.TypeFor[asn1.RelativeOID]():      asn1.TagRelativeOID,
+	reflect.TypeFor[big.Int]():               asn1.TagInteger,
+	reflect.TypeFor[big.Float]():             asn1.TagReal,
+	reflect.TypeFor[asn1.UTF8String]():       asn1.TagUTF8String,
+	reflect.TypeFor[asn1.NumericString]():    asn1.TagNumericString,
+	reflect.TypeFor[asn1.PrintableString]():  asn1.TagPrintableString,
+	reflect.TypeFor[asn1.IA5String]():        asn1.TagIA5String,
+	reflect.TypeFor[asn1.VisibleString]():    asn1.TagVisibleString,
+	reflect.TypeFor[asn1.UniversalString]():  asn1.TagUniversalString,
+	reflect.TypeFor[asn1.BMPString]():        asn1.TagBMPString,
+}
+
+// asn1PkgPath is the package path of the asn1 package.
+var asn1PkgPath = reflect.TypeFor[asn1.Tag]().PkgPath()
+
+// elementName returns the XML element name for a value of type t that is not a
+// component of a SEQUENCE. Named types use their name, unless they are defined
+// by the asn1 package or have a predefined ASN.1 type.
+func elementName(t reflect.Type, params internal.FieldParameters) string {
+	if t.Name() != "" && t.PkgPath() != "" && t.PkgPath() != asn1PkgPath && !notation.IsKnownType(t) {
+		name, _, _ := strings.Cut(t.Name(), "[")
+		return name
+	}
+	if tag, ok := notation.Tag(t, params); ok {
+		return tagNames[tag]
+	}
+	if tag, ok := typeTags[t]; ok {
+		return tagNames[tag]
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t.Name() != "" && t.PkgPath() != "" {
+			return "ENUMERATED"
+		}
+		return "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		return "REAL"
+	case reflect.String:
+		return "UTF8String"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "OCTET_STRING"
+		}
+		return "SEQUENCE_OF"
+	case reflect.Map:
+		return "SET_OF"
+	}
+	return "SEQUENCE"
+}
+
+//endregion
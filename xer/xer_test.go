@@ -0,0 +1,326 @@
+// Copyright 2025 Kim Wittenburg. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xer
+
+import (
+	"encoding/xml"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"codello.dev/asn1"
+)
+
+type testCase[T any] struct {
+	val     T
+	data    string
+	params  string
+	wantErr error
+}
+
+// otherError is used to indicate that any non-nil error is expected.
+var otherError = errors.New("other error")
+
+// testCodec runs marshal and unmarshal tests for the type T. Test cases in
+// common are run in both directions.
+func testCodec[T any](t *testing.T, common, marshal, unmarshal map[string]testCase[T]) {
+	t.Helper()
+	runMarshal := func(name string, tc testCase[T]) {
+		t.Run("Marshal/"+name, func(t *testing.T) {
+			got, err := MarshalWithParams(tc.val, tc.params)
+			checkError(t, "MarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && string(got) != tc.data {
+				t.Errorf("MarshalWithParams() = %s, want %s", got, tc.data)
+			}
+		})
+	}
+	runUnmarshal := func(name string, tc testCase[T]) {
+		t.Run("Unmarshal/"+name, func(t *testing.T) {
+			var got T
+			err := UnmarshalWithParams([]byte(tc.data), &got, tc.params)
+			checkError(t, "UnmarshalWithParams()", err, tc.wantErr)
+			if tc.wantErr == nil && !reflect.DeepEqual(got, tc.val) {
+				t.Errorf("UnmarshalWithParams() = %v, want %v", got, tc.val)
+			}
+		})
+	}
+	for name, tc := range common {
+		runMarshal(name, tc)
+		runUnmarshal(name, tc)
+	}
+	for name, tc := range marshal {
+		runMarshal(name, tc)
+	}
+	for name, tc := range unmarshal {
+		runUnmarshal(name, tc)
+	}
+}
+
+func checkError(t *testing.T, fn string, err, wantErr error) {
+	t.Helper()
+	if wantErr == nil && err != nil {
+		t.Fatalf("%s error = %v, want nil", fn, err)
+	}
+	if wantErr == otherError && err == nil {
+		t.Fatalf("%s error = nil, want non-nil", fn)
+	}
+	if wantErr != nil && wantErr != otherError {
+		if target := reflect.New(reflect.TypeOf(wantErr)); !errors.As(err, target.Interface()) {
+			t.Fatalf("%s error = %v, want %T", fn, err, wantErr)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	testCodec(t, map[string]testCase[bool]{
+		"True":  {val: true, data: "<BOOLEAN><true/></BOOLEAN>"},
+		"False": {val: false, data: "<BOOLEAN><false/></BOOLEAN>"},
+	}, nil, map[string]testCase[bool]{
+		"Whitespace": {val: true, data: "<BOOLEAN>\n  <true/>\n</BOOLEAN>"},
+		"Text":       {val: true, data: "<BOOLEAN>true</BOOLEAN>"},
+		"Invalid":    {data: "<BOOLEAN><yes/></BOOLEAN>", wantErr: &StructuralError{}},
+		"Empty":      {data: "<BOOLEAN/>", wantErr: &StructuralError{}},
+	})
+}
+
+type myEnum int
+
+type color int
+
+var colors = asn1.NewEnumDef(map[color]string{0: "red", 1: "green"})
+
+func TestInteger(t *testing.T) {
+	testCodec(t, map[string]testCase[int]{
+		"Positive": {val: 42, data: "<INTEGER>42</INTEGER>"},
+		"Negative": {val: -7, data: "<INTEGER>-7</INTEGER>"},
+	}, nil, map[string]testCase[int]{
+		"Whitespace": {val: 3, data: "<INTEGER> 3 </INTEGER>"},
+		"Invalid":    {data: "<INTEGER>x</INTEGER>", wantErr: &StructuralError{}},
+		"Element":    {data: "<INTEGER><x/></INTEGER>", wantErr: &StructuralError{}},
+	})
+	testCodec(t, map[string]testCase[myEnum]{
+		"Named": {val: 2, data: "<myEnum>2</myEnum>"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.Enumerated]{
+		"Enumerated": {val: 2, data: "<ENUMERATED>2</ENUMERATED>"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[color]{
+		"Identifier": {val: 1, data: "<color><green/></color>"},
+	}, map[string]testCase[color]{
+		"InvalidValue": {val: 7, wantErr: &EncodeError{}},
+	}, map[string]testCase[color]{
+		"Number":            {val: 1, data: "<color>1</color>"},
+		"InvalidIdentifier": {data: "<color><blue/></color>", wantErr: &StructuralError{}},
+	})
+	big1, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	testCodec(t, map[string]testCase[*big.Int]{
+		"Big": {val: big1, data: "<INTEGER>123456789012345678901234567890</INTEGER>"},
+	}, nil, nil)
+}
+
+func TestReal(t *testing.T) {
+	testCodec(t, map[string]testCase[float64]{
+		"Number":   {val: 1.5, data: "<REAL>1.5</REAL>"},
+		"Exponent": {val: 1e100, data: "<REAL>1E100</REAL>"},
+		"Inf":      {val: math.Inf(1), data: "<REAL><PLUS-INFINITY/></REAL>"},
+		"NegInf":   {val: math.Inf(-1), data: "<REAL><MINUS-INFINITY/></REAL>"},
+		"NegZero":  {val: math.Copysign(0, -1), data: "<REAL>-0</REAL>"},
+	}, nil, map[string]testCase[float64]{
+		"Hex":     {data: "<REAL>0x1p-2</REAL>", wantErr: &StructuralError{}},
+		"Special": {data: "<REAL><INF/></REAL>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestBitString(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.BitString]{
+		"Empty":   {val: asn1.BitString{Bytes: []byte{}}, data: "<BIT_STRING/>"},
+		"Partial": {val: asn1.BitString{Bytes: []byte{0xA0}, BitLength: 3}, data: "<BIT_STRING>101</BIT_STRING>"},
+	}, nil, map[string]testCase[asn1.BitString]{
+		"Invalid": {data: "<BIT_STRING>102</BIT_STRING>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestOctetString(t *testing.T) {
+	testCodec(t, map[string]testCase[[]byte]{
+		"Empty": {val: []byte{}, data: "<OCTET_STRING/>"},
+		"Bytes": {val: []byte{0x01, 0xAB}, data: "<OCTET_STRING>01AB</OCTET_STRING>"},
+	}, nil, map[string]testCase[[]byte]{
+		"Whitespace": {val: []byte{0x01, 0xAB}, data: "<OCTET_STRING>01 ab</OCTET_STRING>"},
+		"InvalidHex": {data: "<OCTET_STRING>0</OCTET_STRING>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestNull(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.Null]{
+		"Null": {val: asn1.Null{}, data: "<NULL/>"},
+	}, nil, map[string]testCase[asn1.Null]{
+		"NotEmpty": {data: "<NULL><x/></NULL>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestNullable(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.Nullable[int]]{
+		"Null":  {val: asn1.Nullable[int]{}, data: "<INTEGER/>"},
+		"Valid": {val: asn1.NewNullable(5), data: "<INTEGER>5</INTEGER>"},
+		"Named": {val: asn1.Nullable[int]{}, params: "name:count", data: "<count/>"},
+	}, nil, nil)
+}
+
+func TestObjectIdentifier(t *testing.T) {
+	testCodec(t, map[string]testCase[asn1.ObjectIdentifier]{
+		"OID": {val: asn1.ObjectIdentifier{1, 2, 840, 113549}, data: "<OBJECT_IDENTIFIER>1.2.840.113549</OBJECT_IDENTIFIER>"},
+	}, nil, map[string]testCase[asn1.ObjectIdentifier]{
+		"Invalid": {data: "<OBJECT_IDENTIFIER>1..2</OBJECT_IDENTIFIER>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestStrings(t *testing.T) {
+	testCodec(t, map[string]testCase[string]{
+		"Escaped": {val: "a<b & c", data: "<UTF8String>a&lt;b &amp; c</UTF8String>"},
+		"Empty":   {val: "", data: "<UTF8String/>"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[asn1.PrintableString]{
+		"Printable": {val: "Hello", data: "<PrintableString>Hello</PrintableString>"},
+	}, map[string]testCase[asn1.PrintableString]{
+		"Invalid": {val: "Hello!", wantErr: &EncodeError{}},
+	}, map[string]testCase[asn1.PrintableString]{
+		"Invalid": {data: "<PrintableString>Hello!</PrintableString>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestTime(t *testing.T) {
+	date := time.Date(1991, 5, 6, 23, 45, 40, 0, time.UTC)
+	testCodec(t, map[string]testCase[asn1.UTCTime]{
+		"UTCTime": {val: asn1.UTCTime(date), data: "<UTCTime>910506234540Z</UTCTime>"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[time.Time]{
+		"GeneralizedTime": {val: date, data: "<GeneralizedTime>19910506234540Z</GeneralizedTime>", params: "universal,tag:24"},
+	}, nil, nil)
+}
+
+type Record struct {
+	Num   int
+	Str   string `asn1:"optional,omitzero,name:text"`
+	Flag  bool
+	Data  []byte        `asn1:"application,tag:5"`
+	Inner *Inner        `asn1:"optional,omitzero"`
+	Ptr   *int          `asn1:"nullable"`
+	List  []Inner       `asn1:"optional,omitzero"`
+	Bools []bool        `asn1:"optional,omitzero"`
+	Set   asn1.Set[int] `asn1:"optional,omitzero"`
+}
+
+type Inner struct {
+	A int
+}
+
+func TestSequence(t *testing.T) {
+	testCodec(t, map[string]testCase[Record]{
+		"Full": {val: Record{1, "a", true, []byte{0x0F}, &Inner{2}, nil, []Inner{{3}, {4}}, []bool{true, false}, asn1.NewSet(2, 1)},
+			data: "<Record><num>1</num><text>a</text><flag><true/></flag><data>0F</data><inner><a>2</a></inner>" +
+				"<ptr/><list><Inner><a>3</a></Inner><Inner><a>4</a></Inner></list><bools><true/><false/></bools>" +
+				"<set><INTEGER>1</INTEGER><INTEGER>2</INTEGER></set></Record>"},
+		"Omitted": {val: Record{Num: 1, Data: []byte{}},
+			data: "<Record><num>1</num><flag><false/></flag><data/><ptr/></Record>"},
+	}, nil, map[string]testCase[Record]{
+		"Formatted": {val: Record{Num: 1, Data: []byte{}},
+			data: "<?xml version=\"1.0\"?>\n<Record>\n  <num>1</num>\n  <!-- comment -->\n  <flag><false/></flag>\n  <data></data>\n  <ptr/>\n</Record>\n"},
+		"Missing":    {data: "<Record><num>1</num></Record>", wantErr: &StructuralError{}},
+		"Unexpected": {data: "<Record><num>1</num><flag><false/></flag><data/><ptr/><foo/></Record>", wantErr: &StructuralError{}},
+		"Reordered":  {data: "<Record><flag><false/></flag><num>1</num><data/><ptr/></Record>", wantErr: &StructuralError{}},
+		"Text":       {data: "<Record>1</Record>", wantErr: &StructuralError{}},
+		"Malformed":  {data: "<Record><num>1</Record>", wantErr: &xml.SyntaxError{}},
+		"ExtraData":  {data: "<Record><num>1</num><flag><false/></flag><data/><ptr/></Record><x/>", wantErr: otherError},
+	})
+
+	type extensible struct {
+		A int
+		asn1.Extensible
+	}
+	testCodec(t, map[string]testCase[extensible]{
+		"Extensible": {val: extensible{A: 1}, data: "<extensible><a>1</a></extensible>"},
+	}, nil, map[string]testCase[extensible]{
+		"Extension": {val: extensible{A: 1}, data: "<extensible><a>1</a><b><c>2</c></b></extensible>"},
+	})
+}
+
+func TestChoice(t *testing.T) {
+	type body struct {
+		A *int
+		B *bool
+	}
+	type msg struct {
+		Body body `asn1:"choice"`
+	}
+	b := true
+	i := 5
+	testCodec(t, map[string]testCase[msg]{
+		"First":  {val: msg{body{A: &i}}, data: "<msg><body><a>5</a></body></msg>"},
+		"Second": {val: msg{body{B: &b}}, data: "<msg><body><b><true/></b></body></msg>"},
+	}, map[string]testCase[msg]{
+		"None":     {val: msg{}, wantErr: &EncodeError{}},
+		"Multiple": {val: msg{body{A: &i, B: &b}}, wantErr: &EncodeError{}},
+	}, map[string]testCase[msg]{
+		"Empty":    {data: "<msg><body/></msg>", wantErr: &StructuralError{}},
+		"Multiple": {data: "<msg><body><a>5</a><b><true/></b></body></msg>", wantErr: &StructuralError{}},
+		"Unknown":  {data: "<msg><body><c>1</c></body></msg>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestSequenceOf(t *testing.T) {
+	testCodec(t, map[string]testCase[[]int]{
+		"Slice": {val: []int{1, 2}, data: "<SEQUENCE_OF><INTEGER>1</INTEGER><INTEGER>2</INTEGER></SEQUENCE_OF>"},
+		"Empty": {val: []int{}, data: "<SEQUENCE_OF/>"},
+	}, nil, nil)
+	testCodec(t, map[string]testCase[[2]int]{
+		"Array": {val: [2]int{1, 2}, data: "<SEQUENCE_OF><INTEGER>1</INTEGER><INTEGER>2</INTEGER></SEQUENCE_OF>"},
+	}, nil, map[string]testCase[[2]int]{
+		"WrongLength": {data: "<SEQUENCE_OF><INTEGER>1</INTEGER></SEQUENCE_OF>", wantErr: &StructuralError{}},
+	})
+}
+
+func TestMarshalWithParams_Name(t *testing.T) {
+	got, err := MarshalWithParams(5, "name:count")
+	if err != nil || string(got) != "<count>5</count>" {
+		t.Errorf("MarshalWithParams() = %s, %v", got, err)
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	var i int
+	if err := Unmarshal([]byte("<INTEGER>1</INTEGER>"), i); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(non-pointer) error = %v, want InvalidDecodeError", err)
+	}
+	var v any
+	if err := Unmarshal([]byte("<INTEGER>1</INTEGER>"), &v); !errors.As(err, new(*InvalidDecodeError)) {
+		t.Errorf("Unmarshal(interface) error = %v, want InvalidDecodeError", err)
+	}
+}
+
+// xmlValue implements xml.Marshaler and xml.Unmarshaler.
+type xmlValue struct{ s string }
+
+func (v xmlValue) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "v"}, Value: v.s}}
+	return e.EncodeElement("", start)
+}
+
+func (v *xmlValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v.s = start.Attr[0].Value
+	return d.Skip()
+}
+
+func TestCustom(t *testing.T) {
+	type custom struct {
+		V xmlValue
+		N int
+	}
+	testCodec(t, map[string]testCase[custom]{
+		"Custom": {val: custom{xmlValue{"x"}, 1}, data: `<custom><v v="x"></v><n>1</n></custom>`},
+	}, nil, nil)
+}